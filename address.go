@@ -0,0 +1,148 @@
+package paymail
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress is when the paymail address (or handle) cannot be parsed
+	ErrInvalidAddress = errors.New("invalid paymail address")
+
+	// ErrMissingAddress is when the paymail address is empty (the zero value of Address, or a missing alias or domain)
+	ErrMissingAddress = errors.New("missing paymail address")
+)
+
+// Address is a validated & sanitized paymail address (alias@domain.tld), use ParseAddress() or NewAddress()
+//
+// The alias is lowercase (NFC for unicode aliases) and the domain is lowercase (punycode for IDN domains),
+// the zero value is an empty address that is rejected by the client
+type Address struct {
+	alias  string
+	domain string
+}
+
+// ParseAddress will parse, validate & sanitize the paymail address, handles are converted
+// with the DefaultHandleRegistry (IE: $handle = handle@handcash.io)
+func ParseAddress(paymailAddress string) (Address, error) {
+	return parseAddress(ConvertHandle(strings.TrimSpace(paymailAddress), false))
+}
+
+// NewAddress will return the address of the alias & domain (validated & sanitized)
+func NewAddress(alias, domain string) (Address, error) {
+	return parseAddress(strings.TrimSpace(alias) + "@" + strings.TrimSpace(domain))
+}
+
+// MustParseAddress is the same as ParseAddress() but panics if the address is invalid
+func MustParseAddress(paymailAddress string) Address {
+	address, err := ParseAddress(paymailAddress)
+	if err != nil {
+		panic(err)
+	}
+	return address
+}
+
+// parseAddress will validate & sanitize the paymail address (no handles)
+func parseAddress(paymailAddress string) (Address, error) {
+	if strings.Count(paymailAddress, "@") != 1 {
+		return Address{}, fmt.Errorf("%w: %s", ErrInvalidAddress, paymailAddress)
+	} else if err := ValidatePaymail(paymailAddress); err != nil {
+		return Address{}, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	alias, domain, address := SanitizePaymail(paymailAddress)
+	if len(address) == 0 {
+		return Address{}, fmt.Errorf("%w: %s", ErrInvalidAddress, paymailAddress)
+	}
+	return Address{alias: alias, domain: domain}, nil
+}
+
+// Alias will return the alias (the part before the @)
+func (a Address) Alias() string {
+	return a.alias
+}
+
+// Domain will return the domain (the part after the @, punycode for IDN domains)
+func (a Address) Domain() string {
+	return a.domain
+}
+
+// String will return the paymail address (alias@domain.tld), empty for the zero value
+func (a Address) String() string {
+	if a.IsZero() {
+		return ""
+	}
+	return a.alias + "@" + a.domain
+}
+
+// Display will return the display form of the address (unicode domain), see: DisplayPaymail()
+func (a Address) Display() string {
+	return DisplayPaymail(a.String())
+}
+
+// IsZero will return true if the address is empty
+func (a Address) IsZero() bool {
+	return len(a.alias) == 0 && len(a.domain) == 0
+}
+
+// Equal will return true if both addresses are the same (case-insensitive)
+func (a Address) Equal(other Address) bool {
+	return strings.EqualFold(a.alias, other.alias) && strings.EqualFold(a.domain, other.domain)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler (an empty value is the zero address)
+func (a *Address) UnmarshalText(text []byte) (err error) {
+	if len(strings.TrimSpace(string(text))) == 0 {
+		*a = Address{}
+		return
+	}
+	*a, err = ParseAddress(string(text))
+	return
+}
+
+// MarshalJSON implements json.Marshaler (the address is a string)
+func (a Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler (null or an empty string is the zero address)
+func (a *Address) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*a = Address{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return a.UnmarshalText([]byte(value))
+}
+
+// Value implements driver.Valuer (NULL for the zero address)
+func (a Address) Value() (driver.Value, error) {
+	if a.IsZero() {
+		return nil, nil
+	}
+	return a.String(), nil
+}
+
+// Scan implements sql.Scanner (NULL is the zero address)
+func (a *Address) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*a = Address{}
+		return nil
+	case string:
+		return a.UnmarshalText([]byte(value))
+	case []byte:
+		return a.UnmarshalText(value)
+	}
+	return fmt.Errorf("%w: cannot scan type %T", ErrInvalidAddress, src)
+}
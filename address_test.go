@@ -0,0 +1,294 @@
+package paymail
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseAddress will test the method ParseAddress()
+func TestParseAddress(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input          string
+		expectedAlias  string
+		expectedDomain string
+		expectedError  bool
+	}{
+		{"mrz@test.com", "mrz", "test.com", false},
+		{" MrZ@Test.COM ", "mrz", "test.com", false},
+		{"$MrZ", "mrz", "handcash.io", false},
+		{"1mrz", "mrz", "relayx.io", false},
+		{"Müller@Bücher.de", "müller", "xn--bcher-kva.de", false},
+		{"", "", "", true},
+		{"mrz", "", "", true},
+		{"mrz@", "", "", true},
+		{"@test.com", "", "", true},
+		{"mrz@test@test.com", "", "", true},
+		{"p\u0430ypal@test.com", "", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			address, err := ParseAddress(test.input)
+			if test.expectedError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidAddress)
+				assert.True(t, address.IsZero())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedAlias, address.Alias())
+			assert.Equal(t, test.expectedDomain, address.Domain())
+			assert.Equal(t, test.expectedAlias+"@"+test.expectedDomain, address.String())
+		})
+	}
+}
+
+// TestNewAddress will test the method NewAddress()
+func TestNewAddress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid alias & domain", func(t *testing.T) {
+		address, err := NewAddress("MrZ", "WWW.Test.com")
+		require.NoError(t, err)
+		assert.Equal(t, "mrz@test.com", address.String())
+	})
+
+	t.Run("handles are not converted", func(t *testing.T) {
+		_, err := NewAddress("$mrz", "")
+		require.Error(t, err)
+	})
+
+	t.Run("missing domain", func(t *testing.T) {
+		_, err := NewAddress(testAlias, "")
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+	})
+
+	t.Run("must parse panics", func(t *testing.T) {
+		assert.Panics(t, func() { _ = MustParseAddress("invalid") })
+	})
+}
+
+// TestAddress_Equal will test the method Equal()
+func TestAddress_Equal(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, testPaymail.Equal(MustParseAddress("MRZ@TEST.COM")))
+	assert.True(t, Address{alias: "MrZ", domain: "Test.com"}.Equal(testPaymail))
+	assert.False(t, testPaymail.Equal(MustParseAddress("satchmo@test.com")))
+	assert.False(t, testPaymail.Equal(Address{}))
+	assert.True(t, Address{}.Equal(Address{}))
+}
+
+// TestAddress_Display will test the method Display()
+func TestAddress_Display(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "müller@bücher.de", MustParseAddress("müller@bücher.de").Display())
+	assert.Equal(t, testPaymail.String(), testPaymail.Display())
+	assert.Equal(t, "", Address{}.Display())
+}
+
+// TestAddress_JSON will test the methods MarshalJSON() & UnmarshalJSON()
+func TestAddress_JSON(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Paymail Address `json:"paymail"`
+	}
+
+	t.Run("marshal", func(t *testing.T) {
+		b, err := json.Marshal(&payload{Paymail: testPaymail})
+		require.NoError(t, err)
+		assert.Equal(t, `{"paymail":"mrz@test.com"}`, string(b))
+
+		b, err = json.Marshal(&payload{})
+		require.NoError(t, err)
+		assert.Equal(t, `{"paymail":""}`, string(b))
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		p := new(payload)
+		require.NoError(t, json.Unmarshal([]byte(`{"paymail":"MrZ@Test.com"}`), p))
+		assert.True(t, testPaymail.Equal(p.Paymail))
+
+		p = new(payload)
+		require.NoError(t, json.Unmarshal([]byte(`{"paymail":null}`), p))
+		assert.True(t, p.Paymail.IsZero())
+
+		require.NoError(t, json.Unmarshal([]byte(`{"paymail":""}`), p))
+		assert.True(t, p.Paymail.IsZero())
+	})
+
+	t.Run("unmarshal invalid", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"paymail":"invalid"}`), new(payload))
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+
+		err = json.Unmarshal([]byte(`{"paymail":1337}`), new(payload))
+		assert.Error(t, err)
+	})
+
+	t.Run("map keys (text)", func(t *testing.T) {
+		b, err := json.Marshal(map[Address]int{testPaymail: 1})
+		require.NoError(t, err)
+		assert.Equal(t, `{"mrz@test.com":1}`, string(b))
+
+		m := make(map[Address]int)
+		require.NoError(t, json.Unmarshal(b, &m))
+		assert.Equal(t, 1, m[testPaymail])
+	})
+}
+
+// TestAddress_SQL will test the methods Value() & Scan()
+func TestAddress_SQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value", func(t *testing.T) {
+		value, err := testPaymail.Value()
+		require.NoError(t, err)
+		assert.Equal(t, driver.Value("mrz@test.com"), value)
+
+		value, err = Address{}.Value()
+		require.NoError(t, err)
+		assert.Nil(t, value)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var address Address
+		require.NoError(t, address.Scan("mrz@test.com"))
+		assert.True(t, testPaymail.Equal(address))
+
+		require.NoError(t, address.Scan([]byte("satchmo@test.com")))
+		assert.Equal(t, "satchmo@test.com", address.String())
+
+		require.NoError(t, address.Scan(nil))
+		assert.True(t, address.IsZero())
+	})
+
+	t.Run("scan invalid", func(t *testing.T) {
+		var address Address
+		assert.ErrorIs(t, address.Scan("invalid"), ErrInvalidAddress)
+		assert.ErrorIs(t, address.Scan(1337), ErrInvalidAddress)
+	})
+}
+
+// TestClient_requestAddress will test the alias & domain validation of the client requests
+func TestClient_requestAddress(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("invalid address never reaches the wire", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", "mr z", testDomain)
+		require.ErrorIs(t, err, ErrInvalidAddress)
+		_, err = client.GetPublicProfile(testServerURL+"public-profile/{alias}@{domain.tld}", testAlias, "test")
+		require.ErrorIs(t, err, ErrInvalidAddress)
+		_, err = client.GetSFPAssetInformation(testServerURL+"asset/{alias}@{domain.tld}", testAlias, "test..com")
+		require.ErrorIs(t, err, ErrInvalidAddress)
+		_, err = client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", "", testDomain)
+		require.ErrorIs(t, err, ErrMissingAddress)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("address is sanitized", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK,
+				`{"bsvalias": "1.0","handle": "`+testAlias+`@`+testDomain+`","pubkey": "`+testPubKey+`"}`),
+		)
+
+		pki, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", "MrZ", "Test.com")
+		require.NoError(t, err)
+		assert.Equal(t, testAlias+"@"+testDomain, pki.Handle)
+	})
+}
+
+// TestClient_ForAddress will test the client methods that accept a paymail address
+func TestClient_ForAddress(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("zero address never reaches the wire", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+
+		ctx := context.Background()
+		var address Address
+		_, err := client.GetP2PPaymentDestinationForAddress(ctx, testServerURL+"p2p", address, &PaymentRequest{Satoshis: 100})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.GetP2PTokenPaymentDestinationForAddress(ctx, testServerURL+"p2p-token", address,
+			&TokenPaymentRequest{Amount: 100, Scheme: TokenSchemeSTAS, TokenID: "token"})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.GetPaymentApprovalForAddress(ctx, testServerURL+"approval", address, "id")
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.GetPikeOutputsForAddress(ctx, testServerURL+"pike", address, &PikeOutputsRequest{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.GetPKIForAddress(ctx, testServerURL+"id", address)
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.GetPublicProfileForAddress(ctx, testServerURL+"public-profile", address)
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.GetSFPAssetInformationForAddress(ctx, testServerURL+"asset", address)
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.ResolveAddressForAddress(ctx, testServerURL+"address", address, &SenderRequest{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.SendP2PPaymentForAddress(ctx, testServerURL+"receive", address, &PaymentDestinationPayload{}, &P2PPayment{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.SendP2PTransactionForAddress(ctx, testServerURL+"receive", address, &P2PTransaction{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.SendPikeContactForAddress(ctx, testServerURL+"pike", address, &PikeContactPayload{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.SFPAuthoriseActionForAddress(ctx, testServerURL+"authorise", address, &SFPAuthoriseRequest{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.SFPBuildActionForAddress(ctx, testServerURL+"build", address, &SFPBuildRequest{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.SubmitPaymentApprovalForAddress(ctx, testServerURL+"approval", address, &SenderRequest{})
+		require.ErrorIs(t, err, ErrMissingAddress)
+		_, err = client.VerifyPubKeyForAddress(ctx, testServerURL+"verify", address, testPubKey)
+		require.ErrorIs(t, err, ErrMissingAddress)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("parsed address", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK,
+				`{"bsvalias": "1.0","handle": "`+testAlias+`@`+testDomain+`","pubkey": "`+testPubKey+`"}`),
+		)
+
+		pki, err := client.GetPKIForAddress(context.Background(), testServerURL+"id/{alias}@{domain.tld}",
+			MustParseAddress("MrZ@Test.com"))
+		require.NoError(t, err)
+		assert.Equal(t, testAlias+"@"+testDomain, pki.Handle)
+		assert.Equal(t, testPubKey, pki.PubKey)
+	})
+}
+
+// ExampleParseAddress example using the method ParseAddress()
+//
+// See more examples in /examples/
+func ExampleParseAddress() {
+	address, err := ParseAddress("MrZ@Test.com")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Println(address.Alias() + " " + address.Domain())
+	// Output:mrz test.com
+}
+
+// BenchmarkParseAddress benchmarks the method ParseAddress()
+func BenchmarkParseAddress(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseAddress("mrz@test.com")
+	}
+}
@@ -0,0 +1,431 @@
+package paymail
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript/interpreter"
+)
+
+// BEEFVersion is the version marker of a BEEF envelope (0100BEEF)
+//
+// Specs: https://bsv.brc.dev/transactions/0062
+const BEEFVersion uint32 = 4022206465
+
+// Errors returned when verifying a BEEF envelope
+var (
+	// ErrBEEFMissingAncestor is when an unmined transaction spends an output that is not in the envelope
+	ErrBEEFMissingAncestor = errors.New("beef is missing an ancestor transaction")
+
+	// ErrBEEFInvalidMerkleRoot is when the merkle root of a BUMP is not valid for the block height
+	ErrBEEFInvalidMerkleRoot = errors.New("beef has an invalid merkle root")
+
+	// ErrBEEFInvalidScript is when an input of an unmined transaction fails the script evaluation
+	ErrBEEFInvalidScript = errors.New("beef has an invalid input script")
+)
+
+// BlockHeadersProvider is used to check the merkle roots of a BEEF envelope against the block headers
+// (IE: a block headers service, or a local copy of the headers)
+type BlockHeadersProvider interface {
+	IsValidRootForHeight(ctx context.Context, merkleRoot string, blockHeight uint64) (bool, error)
+}
+
+// DecodedBEEF is a BEEF envelope (BRC-62): the transaction with its ancestors back to the mined
+// transactions (with merkle paths), the last transaction is the subject (the payment)
+type DecodedBEEF struct {
+	BUMPs        []*BUMP            `json:"bumps"`        // Merkle paths of the mined transactions
+	Transactions []*BEEFTransaction `json:"transactions"` // Transactions sorted by dependency (ancestors first)
+}
+
+// BEEFTransaction is a transaction of a BEEF envelope
+type BEEFTransaction struct {
+	BUMPIndex   int    `json:"bumpIndex"` // Index of the BUMP for a mined transaction (-1 if not mined)
+	Transaction *bt.Tx `json:"-"`         // The transaction
+}
+
+// BUMP is the merkle path of one or more transactions of a block (BRC-74)
+//
+// Specs: https://bsv.brc.dev/transactions/0074
+type BUMP struct {
+	BlockHeight uint64        `json:"blockHeight"` // Height of the block
+	Path        [][]*BUMPLeaf `json:"path"`        // Leaves of each level of the tree (level 0 are the txids)
+}
+
+// BUMPLeaf is a node of a merkle path
+type BUMPLeaf struct {
+	Duplicate bool   `json:"duplicate,omitempty"` // The node is a duplicate of its sibling (no hash)
+	Hash      string `json:"hash,omitempty"`      // Hash of the node (hex, same byte order as a txid)
+	Offset    uint64 `json:"offset"`              // Position of the node in the level
+	TxID      bool   `json:"txid,omitempty"`      // The hash is a txid of the envelope
+}
+
+// BUMP leaf flags
+const (
+	bumpFlagDuplicate byte = 1
+	bumpFlagTxID      byte = 2
+)
+
+// DecodeBEEF will decode a BEEF envelope (hex) into the merkle paths & the transactions
+//
+// Specs: https://bsv.brc.dev/transactions/0062
+func DecodeBEEF(beefHex string) (*DecodedBEEF, error) {
+	b, err := hex.DecodeString(beefHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid beef hex: %w", err)
+	}
+	reader := &beefReader{b: b}
+
+	// Check the version marker
+	if version := reader.uint32(); reader.err != nil || version != BEEFVersion {
+		return nil, errors.New("invalid beef: missing version marker")
+	}
+
+	// Decode the BUMPs
+	beef := &DecodedBEEF{}
+	numBUMPs := reader.varInt()
+	for i := uint64(0); i < numBUMPs && reader.err == nil; i++ {
+		beef.BUMPs = append(beef.BUMPs, reader.bump())
+	}
+
+	// Decode the transactions
+	numTransactions := reader.varInt()
+	for i := uint64(0); i < numTransactions && reader.err == nil; i++ {
+		beef.Transactions = append(beef.Transactions, reader.transaction())
+	}
+
+	// Check the result
+	if reader.err != nil {
+		return nil, fmt.Errorf("invalid beef: %w", reader.err)
+	} else if len(reader.b) > 0 {
+		return nil, errors.New("invalid beef: unexpected trailing bytes")
+	} else if len(beef.Transactions) == 0 {
+		return nil, errors.New("invalid beef: missing transactions")
+	}
+	for _, tx := range beef.Transactions {
+		if tx.BUMPIndex >= len(beef.BUMPs) {
+			return nil, fmt.Errorf("invalid beef: bump index %d is out of range", tx.BUMPIndex)
+		}
+	}
+	return beef, nil
+}
+
+// SubjectTx will return the subject transaction of the envelope (the last transaction)
+func (d *DecodedBEEF) SubjectTx() *bt.Tx {
+	if len(d.Transactions) == 0 {
+		return nil
+	}
+	return d.Transactions[len(d.Transactions)-1].Transaction
+}
+
+// Verify will verify the envelope (SPV): the mined transactions are checked against the block headers
+// and the inputs of the unmined transactions against their ancestors (outputs & scripts)
+func (d *DecodedBEEF) Verify(ctx context.Context, headers BlockHeadersProvider) error {
+	if headers == nil {
+		return errors.New("block headers provider is required")
+	} else if len(d.Transactions) == 0 {
+		return errors.New("beef is missing transactions")
+	}
+
+	// Check all transactions (ancestors are before their children)
+	roots := make(map[string]uint64)
+	known := make(map[string]*bt.Tx, len(d.Transactions))
+	for _, beefTx := range d.Transactions {
+		tx := beefTx.Transaction
+		txID := tx.TxID()
+
+		// Mined: the merkle path proves the transaction (the root is checked below)
+		if beefTx.BUMPIndex >= 0 {
+			bump := d.BUMPs[beefTx.BUMPIndex]
+			root, err := bump.CalculateMerkleRoot(txID)
+			if err != nil {
+				return err
+			}
+			roots[root] = bump.BlockHeight
+			known[txID] = tx
+			continue
+		}
+
+		// Unmined: all inputs spend outputs of the envelope
+		if err := verifyInputs(tx, known); err != nil {
+			return fmt.Errorf("transaction %s: %w", txID, err)
+		}
+		known[txID] = tx
+	}
+
+	// Check the merkle roots with the block headers
+	for root, height := range roots {
+		valid, err := headers.IsValidRootForHeight(ctx, root, height)
+		if err != nil {
+			return fmt.Errorf("failed checking merkle root %s: %w", root, err)
+		} else if !valid {
+			return fmt.Errorf("%w: %s for block %d", ErrBEEFInvalidMerkleRoot, root, height)
+		}
+	}
+	return nil
+}
+
+// Bytes will return the encoded envelope
+func (d *DecodedBEEF) Bytes() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	_ = binary.Write(buf, binary.LittleEndian, BEEFVersion)
+
+	// Encode the BUMPs
+	buf.Write(bt.VarInt(len(d.BUMPs)).Bytes())
+	for _, bump := range d.BUMPs {
+		buf.Write(bt.VarInt(bump.BlockHeight).Bytes())
+		buf.WriteByte(byte(len(bump.Path)))
+		for _, level := range bump.Path {
+			buf.Write(bt.VarInt(len(level)).Bytes())
+			for _, leaf := range level {
+				buf.Write(bt.VarInt(leaf.Offset).Bytes())
+				if leaf.Duplicate {
+					buf.WriteByte(bumpFlagDuplicate)
+					continue
+				} else if leaf.TxID {
+					buf.WriteByte(bumpFlagTxID)
+				} else {
+					buf.WriteByte(0)
+				}
+				hash, _ := hex.DecodeString(leaf.Hash)
+				buf.Write(bt.ReverseBytes(hash))
+			}
+		}
+	}
+
+	// Encode the transactions
+	buf.Write(bt.VarInt(len(d.Transactions)).Bytes())
+	for _, tx := range d.Transactions {
+		buf.Write(tx.Transaction.Bytes())
+		if tx.BUMPIndex >= 0 {
+			buf.WriteByte(1)
+			buf.Write(bt.VarInt(tx.BUMPIndex).Bytes())
+		} else {
+			buf.WriteByte(0)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Hex will return the encoded envelope as a hexadecimal string
+func (d *DecodedBEEF) Hex() string {
+	return hex.EncodeToString(d.Bytes())
+}
+
+// CalculateMerkleRoot will calculate the merkle root (hex) from the path of the txid
+func (b *BUMP) CalculateMerkleRoot(txID string) (string, error) {
+
+	// Find the txid in the first level
+	var offset uint64
+	found := false
+	if len(b.Path) > 0 {
+		for _, leaf := range b.Path[0] {
+			if leaf.Hash == txID {
+				offset, found = leaf.Offset, true
+				break
+			}
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("bump for block %d is missing txid %s", b.BlockHeight, txID)
+	}
+
+	// Hash up the tree (a missing sibling is calculated from the level below)
+	working, err := decodeHash(txID)
+	if err != nil {
+		return "", err
+	}
+	for level := range b.Path {
+		var sibling []byte
+		if leaf := b.leaf(level, offset^1); leaf != nil && leaf.Duplicate {
+			sibling = working
+		} else if sibling, err = b.hashAt(level, offset^1); err != nil {
+			return "", err
+		}
+		if offset%2 == 0 {
+			working = merkleParent(working, sibling)
+		} else {
+			working = merkleParent(sibling, working)
+		}
+		offset >>= 1
+	}
+	return hex.EncodeToString(bt.ReverseBytes(working)), nil
+}
+
+// leaf will return the leaf at the level & offset (nil if not found)
+func (b *BUMP) leaf(level int, offset uint64) *BUMPLeaf {
+	for _, leaf := range b.Path[level] {
+		if leaf.Offset == offset {
+			return leaf
+		}
+	}
+	return nil
+}
+
+// hashAt will return the hash (internal byte order) of the node at the level & offset
+func (b *BUMP) hashAt(level int, offset uint64) ([]byte, error) {
+	if leaf := b.leaf(level, offset); leaf != nil && !leaf.Duplicate {
+		return decodeHash(leaf.Hash)
+	} else if level == 0 || leaf != nil {
+		return nil, fmt.Errorf("bump for block %d is missing node %d at level %d", b.BlockHeight, offset, level)
+	}
+
+	// Calculate the node from its children
+	left, err := b.hashAt(level-1, offset*2)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if child := b.leaf(level-1, offset*2+1); child == nil || !child.Duplicate {
+		if right, err = b.hashAt(level-1, offset*2+1); err != nil {
+			return nil, err
+		}
+	}
+	return merkleParent(left, right), nil
+}
+
+// verifyInputs will check the inputs of an unmined transaction against its ancestors
+func verifyInputs(tx *bt.Tx, known map[string]*bt.Tx) error {
+	if len(tx.Inputs) == 0 {
+		return errors.New("transaction has no inputs")
+	}
+	var totalInputs uint64
+	for index, input := range tx.Inputs {
+
+		// Find the spent output
+		parent, ok := known[input.PreviousTxIDStr()]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrBEEFMissingAncestor, input.PreviousTxIDStr())
+		} else if int(input.PreviousTxOutIndex) >= len(parent.Outputs) {
+			return fmt.Errorf("%w: output %d of %s", ErrBEEFMissingAncestor, input.PreviousTxOutIndex, input.PreviousTxIDStr())
+		}
+		output := parent.Outputs[input.PreviousTxOutIndex]
+		totalInputs += output.Satoshis
+
+		// Evaluate the unlocking script
+		if err := interpreter.NewEngine().Execute(
+			interpreter.WithTx(tx, index, output),
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+		); err != nil {
+			return fmt.Errorf("%w: input %d: %s", ErrBEEFInvalidScript, index, err.Error())
+		}
+	}
+
+	// Outputs cannot spend more than the inputs
+	if totalInputs < tx.TotalOutputSatoshis() {
+		return errors.New("transaction outputs exceed the inputs")
+	}
+	return nil
+}
+
+// decodeHash will decode a hash (hex, txid byte order) into the internal byte order
+func decodeHash(hash string) ([]byte, error) {
+	b, err := hex.DecodeString(hash)
+	if err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("invalid hash: %s", hash)
+	}
+	return bt.ReverseBytes(b), nil
+}
+
+// merkleParent will return the parent node of two nodes (double sha256)
+func merkleParent(left, right []byte) []byte {
+	first := sha256.Sum256(append(append(make([]byte, 0, 64), left...), right...))
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// beefReader is a reader for the binary envelope (the first error stops all reads)
+type beefReader struct {
+	b   []byte
+	err error
+}
+
+// read will return the next n bytes
+func (r *beefReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	} else if n < 0 || len(r.b) < n {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+// uint32 will return the next little endian uint32
+func (r *beefReader) uint32() uint32 {
+	if b := r.read(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// varInt will return the next VarInt
+func (r *beefReader) varInt() uint64 {
+	if r.err != nil {
+		return 0
+	} else if len(r.b) == 0 {
+		r.err = errors.New("unexpected end of data")
+		return 0
+	}
+	value, size := bt.NewVarIntFromBytes(r.b)
+	r.read(size)
+	return uint64(value)
+}
+
+// bump will return the next BUMP
+func (r *beefReader) bump() *BUMP {
+	bump := &BUMP{BlockHeight: r.varInt()}
+	treeHeight := r.read(1)
+	if r.err != nil {
+		return bump
+	}
+	bump.Path = make([][]*BUMPLeaf, treeHeight[0])
+	for level := range bump.Path {
+		numLeaves := r.varInt()
+		for i := uint64(0); i < numLeaves && r.err == nil; i++ {
+			leaf := &BUMPLeaf{Offset: r.varInt()}
+			flags := r.read(1)
+			if r.err != nil {
+				break
+			}
+			leaf.Duplicate = flags[0]&bumpFlagDuplicate != 0
+			leaf.TxID = flags[0]&bumpFlagTxID != 0
+			if !leaf.Duplicate {
+				if hash := r.read(32); hash != nil {
+					leaf.Hash = hex.EncodeToString(bt.ReverseBytes(hash))
+				}
+			}
+			bump.Path[level] = append(bump.Path[level], leaf)
+		}
+	}
+	return bump
+}
+
+// transaction will return the next transaction (and its BUMP index)
+func (r *beefReader) transaction() *BEEFTransaction {
+	beefTx := &BEEFTransaction{BUMPIndex: -1}
+	if r.err != nil {
+		return beefTx
+	}
+	tx, size, err := bt.NewTxFromStream(r.b)
+	if err != nil {
+		r.err = fmt.Errorf("invalid transaction: %w", err)
+		return beefTx
+	}
+	r.read(size)
+	beefTx.Transaction = tx
+
+	// Check for a BUMP
+	if hasBUMP := r.read(1); hasBUMP != nil && hasBUMP[0] == 1 {
+		beefTx.BUMPIndex = int(r.varInt())
+	}
+	return beefTx
+}
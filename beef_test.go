@@ -0,0 +1,286 @@
+package paymail
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBlockHeight = 800000
+	testSiblingHash = "b1fea52486ce0c62bb442b530a3f0132b826c74e473d1f2c220bfa78111c5082"
+)
+
+// mockBlockHeaders is a block headers provider with known merkle roots (root -> height)
+type mockBlockHeaders struct {
+	err   error
+	roots map[string]uint64
+}
+
+// IsValidRootForHeight is a mock implementation of this interface
+func (m *mockBlockHeaders) IsValidRootForHeight(_ context.Context, merkleRoot string, blockHeight uint64) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	height, ok := m.roots[merkleRoot]
+	return ok && height == blockHeight, nil
+}
+
+// newTestBEEF will return an envelope with a mined parent (paying the test private key)
+// and the subject transaction spending it, and the block headers for the parent
+func newTestBEEF(t *testing.T) (*DecodedBEEF, *mockBlockHeaders) {
+
+	// The parent is mined (the input is not checked)
+	address, err := bitcoin.GetAddressFromPrivateKeyString(testPrivateKey, true)
+	require.NoError(t, err)
+	lockingScript, err := bscript.NewP2PKHFromAddress(address)
+	require.NoError(t, err)
+	parent := bt.NewTx()
+	require.NoError(t, parent.From(testPrevTxID, 0, lockingScript.String(), 20000))
+	parent.AddOutput(&bt.Output{LockingScript: lockingScript, Satoshis: 10000})
+
+	// The subject transaction spends the parent
+	payment := newTestPayment(t)
+	payment.UTXOs = NewStaticUTXOSource(&bt.UTXO{
+		LockingScript: lockingScript, Satoshis: 10000, TxID: parent.TxIDBytes(), Vout: 0,
+	})
+	transaction, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), payment)
+	require.NoError(t, err)
+	subject, err := bt.NewTxFromString(transaction.Hex)
+	require.NoError(t, err)
+
+	// The merkle path of the parent (block with 2 transactions)
+	bump := &BUMP{BlockHeight: testBlockHeight, Path: [][]*BUMPLeaf{{
+		{Hash: parent.TxID(), Offset: 0, TxID: true},
+		{Hash: testSiblingHash, Offset: 1},
+	}}}
+	root, err := bump.CalculateMerkleRoot(parent.TxID())
+	require.NoError(t, err)
+
+	return &DecodedBEEF{
+		BUMPs: []*BUMP{bump},
+		Transactions: []*BEEFTransaction{
+			{BUMPIndex: 0, Transaction: parent},
+			{BUMPIndex: -1, Transaction: subject},
+		},
+	}, &mockBlockHeaders{roots: map[string]uint64{root: testBlockHeight}}
+}
+
+// TestDecodeBEEF will test the method DecodeBEEF()
+func TestDecodeBEEF(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode & decode", func(t *testing.T) {
+		beef, _ := newTestBEEF(t)
+		decoded, err := DecodeBEEF(beef.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, beef.BUMPs, decoded.BUMPs)
+		require.Len(t, decoded.Transactions, 2)
+		assert.Equal(t, 0, decoded.Transactions[0].BUMPIndex)
+		assert.Equal(t, beef.Transactions[0].Transaction.TxID(), decoded.Transactions[0].Transaction.TxID())
+		assert.Equal(t, -1, decoded.Transactions[1].BUMPIndex)
+		assert.Equal(t, beef.SubjectTx().TxID(), decoded.SubjectTx().TxID())
+		assert.Equal(t, beef.Hex(), decoded.Hex())
+	})
+
+	t.Run("duplicate leaf", func(t *testing.T) {
+		beef, _ := newTestBEEF(t)
+		beef.BUMPs[0].Path[0][1] = &BUMPLeaf{Duplicate: true, Offset: 1}
+		decoded, err := DecodeBEEF(beef.Hex())
+		require.NoError(t, err)
+		assert.True(t, decoded.BUMPs[0].Path[0][1].Duplicate)
+		assert.Empty(t, decoded.BUMPs[0].Path[0][1].Hash)
+	})
+
+	t.Run("invalid envelopes", func(t *testing.T) {
+		beef, _ := newTestBEEF(t)
+		valid := beef.Hex()
+
+		outOfRange, _ := newTestBEEF(t)
+		outOfRange.Transactions[0].BUMPIndex = 1
+
+		var tests = []struct {
+			name string
+			hex  string
+		}{
+			{"empty", ""},
+			{"invalid hex", "0100beefzz"},
+			{"missing marker", "01000000" + valid[8:]},
+			{"truncated", valid[:len(valid)-10]},
+			{"trailing bytes", valid + "00"},
+			{"no transactions", "0100beef0000"},
+			{"bump index out of range", outOfRange.Hex()},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				decoded, err := DecodeBEEF(test.hex)
+				require.Error(t, err)
+				assert.Nil(t, decoded)
+			})
+		}
+	})
+}
+
+// TestBUMP_CalculateMerkleRoot will test the method CalculateMerkleRoot()
+func TestBUMP_CalculateMerkleRoot(t *testing.T) {
+	t.Parallel()
+
+	// Block 170 (the first transaction between two people)
+	const (
+		coinbase = "b1fea52486ce0c62bb442b530a3f0132b826c74e473d1f2c220bfa78111c5082"
+		payment  = "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16"
+		root     = "7dac2c5666815c17a3b36427de37bb9d2e2c5ccec3f8633eb91a4205cb4c10ff"
+	)
+
+	t.Run("block 170", func(t *testing.T) {
+		bump := &BUMP{BlockHeight: 170, Path: [][]*BUMPLeaf{{
+			{Hash: coinbase, Offset: 0},
+			{Hash: payment, Offset: 1, TxID: true},
+		}}}
+		calculated, err := bump.CalculateMerkleRoot(payment)
+		require.NoError(t, err)
+		assert.Equal(t, root, calculated)
+
+		calculated, err = bump.CalculateMerkleRoot(coinbase)
+		require.NoError(t, err)
+		assert.Equal(t, root, calculated)
+	})
+
+	t.Run("duplicate & calculated nodes", func(t *testing.T) {
+		// Tree of 3 transactions: [payment, coinbase, coinbase, (duplicate)]
+		bump := &BUMP{BlockHeight: 1, Path: [][]*BUMPLeaf{
+			{{Hash: payment, Offset: 0}, {Hash: coinbase, Offset: 1}, {Hash: coinbase, Offset: 2, TxID: true}, {Duplicate: true, Offset: 3}},
+			{},
+		}}
+		fromLast, err := bump.CalculateMerkleRoot(coinbase)
+		require.NoError(t, err)
+
+		// The same tree with the first branch as a node
+		compound := &BUMP{BlockHeight: 1, Path: [][]*BUMPLeaf{
+			{{Hash: coinbase, Offset: 2, TxID: true}, {Duplicate: true, Offset: 3}},
+			{{Hash: hex.EncodeToString(bt.ReverseBytes(merkleParent(mustDecodeHash(t, payment), mustDecodeHash(t, coinbase)))), Offset: 0}},
+		}}
+		fromNode, err := compound.CalculateMerkleRoot(coinbase)
+		require.NoError(t, err)
+		assert.Equal(t, fromLast, fromNode)
+	})
+
+	t.Run("missing txid", func(t *testing.T) {
+		bump := &BUMP{BlockHeight: 170, Path: [][]*BUMPLeaf{{{Hash: coinbase, Offset: 0}}}}
+		_, err := bump.CalculateMerkleRoot(payment)
+		require.Error(t, err)
+	})
+
+	t.Run("missing sibling", func(t *testing.T) {
+		bump := &BUMP{BlockHeight: 170, Path: [][]*BUMPLeaf{{{Hash: coinbase, Offset: 0}}}}
+		_, err := bump.CalculateMerkleRoot(coinbase)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid hash", func(t *testing.T) {
+		bump := &BUMP{BlockHeight: 170, Path: [][]*BUMPLeaf{{{Hash: coinbase, Offset: 0}, {Hash: "zz", Offset: 1}}}}
+		_, err := bump.CalculateMerkleRoot(coinbase)
+		require.Error(t, err)
+	})
+}
+
+// TestDecodedBEEF_Verify will test the method Verify()
+func TestDecodedBEEF_Verify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid envelope", func(t *testing.T) {
+		beef, headers := newTestBEEF(t)
+		require.NoError(t, beef.Verify(context.Background(), headers))
+	})
+
+	t.Run("unknown merkle root", func(t *testing.T) {
+		beef, _ := newTestBEEF(t)
+		err := beef.Verify(context.Background(), &mockBlockHeaders{})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBEEFInvalidMerkleRoot)
+	})
+
+	t.Run("block headers error", func(t *testing.T) {
+		beef, _ := newTestBEEF(t)
+		err := beef.Verify(context.Background(), &mockBlockHeaders{err: errors.New("headers offline")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "headers offline")
+	})
+
+	t.Run("missing block headers", func(t *testing.T) {
+		beef, _ := newTestBEEF(t)
+		require.Error(t, beef.Verify(context.Background(), nil))
+	})
+
+	t.Run("missing ancestor", func(t *testing.T) {
+		beef, headers := newTestBEEF(t)
+		beef.Transactions = beef.Transactions[1:]
+		err := beef.Verify(context.Background(), headers)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBEEFMissingAncestor)
+	})
+
+	t.Run("missing ancestor output", func(t *testing.T) {
+		beef, headers := newTestBEEF(t)
+		beef.SubjectTx().Inputs[0].PreviousTxOutIndex = 5
+		err := beef.Verify(context.Background(), headers)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBEEFMissingAncestor)
+	})
+
+	t.Run("invalid script", func(t *testing.T) {
+		beef, headers := newTestBEEF(t)
+		unlockingScript, err := bscript.NewFromASM("OP_1")
+		require.NoError(t, err)
+		beef.SubjectTx().Inputs[0].UnlockingScript = unlockingScript
+		err = beef.Verify(context.Background(), headers)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBEEFInvalidScript)
+	})
+
+	t.Run("txid missing from bump", func(t *testing.T) {
+		beef, headers := newTestBEEF(t)
+		beef.BUMPs[0].Path[0][0].Hash = testSiblingHash
+		require.Error(t, beef.Verify(context.Background(), headers))
+	})
+
+	t.Run("no transactions", func(t *testing.T) {
+		_, headers := newTestBEEF(t)
+		require.Error(t, (&DecodedBEEF{}).Verify(context.Background(), headers))
+	})
+}
+
+// mustDecodeHash will decode the hash (txid byte order) into the internal byte order
+func mustDecodeHash(t *testing.T, hash string) []byte {
+	b, err := decodeHash(hash)
+	require.NoError(t, err)
+	return b
+}
+
+// ExampleDecodeBEEF example using DecodeBEEF()
+func ExampleDecodeBEEF() {
+	beef, err := DecodeBEEF("0100beef00010100000001f19bef4e6d8da9b52c00a323403d9532ff4df21de61600a2cf847a7abffaddf3000000000000000000010000000000000000016a0000000000")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("transactions: %d, mined: %t", len(beef.Transactions), beef.Transactions[0].BUMPIndex >= 0)
+	// Output:transactions: 1, mined: false
+}
+
+// BenchmarkDecodeBEEF benchmarks the method DecodeBEEF()
+func BenchmarkDecodeBEEF(b *testing.B) {
+	beef, _ := newTestBEEF(&testing.T{})
+	beefHex := beef.Hex()
+	for i := 0; i < b.N; i++ {
+		_, _ = DecodeBEEF(beefHex)
+	}
+}
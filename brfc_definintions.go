@@ -3,11 +3,13 @@ package paymail
 // All BRFC IDs that have been used/referenced in the library
 const (
 	BRFCBasicAddressResolution         = "759684b1a19a"       // more info: http://bsvalias.org/04-01-basic-address-resolution.html
+	BRFCBeefTransaction                = "5c55a7fdb7bb"       // more info: https://bsv.brc.dev/transactions/0062
 	BRFCP2PPaymentDestination          = "2a40af698840"       // more info: https://docs.moneybutton.com/docs/paymail/paymail-07-p2p-payment-destination.html
 	BRFCP2PPaymentDestinationWithToken = "f792b6eff07a"       //nolint:gosec // more info: https://docs.moneybutton.com/docs/paymail/paymail-11-p2p-payment-destination-tokens.html
 	BRFCP2PTransactions                = "5f1323cddf31"       // more info: https://docs.moneybutton.com/docs/paymail/paymail-06-p2p-transactions.html
 	BRFCPaymentDestination             = "paymentDestination" // more info: http://bsvalias.org/04-01-basic-address-resolution.html
 	BRFCPayToProtocolPrefix            = "7bd25e5a1fc6"       // more info: http://bsvalias.org/04-04-payto-protocol-prefix.html
+	BRFCPike                           = "8c4ed5ef8ace"       // more info: PIKE (paymail identity key exchange), see: pike.go
 	BRFCPki                            = "pki"                // more info: http://bsvalias.org/03-public-key-infrastructure.html
 	BRFCPkiAlternate                   = "0c4339ef99c2"       // more info: http://bsvalias.org/03-public-key-infrastructure.html
 	BRFCPublicProfile                  = "f12f968c92d6"       // more info: https://github.com/bitcoin-sv-specs/brfc-paymail/pull/7/files
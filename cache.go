@@ -0,0 +1,158 @@
+package paymail
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache key prefixes
+const (
+	capabilitiesCacheKeyPrefix = "paymail:capabilities:" // Prefix for cached capabilities (+ url)
+	srvCacheKeyPrefix          = "paymail:srv:"          // Prefix for cached SRV records (+ cname)
+)
+
+// Cache is the interface for caching capabilities & SRV records
+//
+// Values are opaque bytes and the backend is responsible for expiring keys after the ttl,
+// which makes it easy to implement with Redis, Memcached, etc.
+// Cache errors should not fail a paymail request, so the backend is expected to treat errors as a miss
+type Cache interface {
+	Delete(ctx context.Context, key string)
+	Get(ctx context.Context, key string) (value []byte, found bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+}
+
+// MemoryCache is an in-memory LRU cache with per-key expiration (default Cache for the client)
+type MemoryCache struct {
+	items      map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+	mu         sync.Mutex
+}
+
+// memoryCacheItem is an item stored in the MemoryCache
+type memoryCacheItem struct {
+	expiresAt time.Time
+	key       string
+	value     []byte
+}
+
+// NewMemoryCache will return a new in-memory LRU cache
+//
+// If maxEntries is zero or less, the cache will not evict on size (only on expiration)
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get will return the value for the key if found and not expired
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := element.Value.(*memoryCacheItem) //nolint:forcetypeassert // only memoryCacheItem is stored
+
+	// Expired? (remove it)
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		m.removeElement(element)
+		return nil, false
+	}
+
+	m.lru.MoveToFront(element)
+	return item.value, true
+}
+
+// Set will store the value for the key (a ttl of zero or less never expires)
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	// Update an existing item
+	if element, ok := m.items[key]; ok {
+		item := element.Value.(*memoryCacheItem) //nolint:forcetypeassert // only memoryCacheItem is stored
+		item.expiresAt = expiresAt
+		item.value = value
+		m.lru.MoveToFront(element)
+		return
+	}
+
+	// Add a new item
+	m.items[key] = m.lru.PushFront(&memoryCacheItem{
+		expiresAt: expiresAt,
+		key:       key,
+		value:     value,
+	})
+
+	// Evict the least recently used item(s)
+	for m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		m.removeElement(m.lru.Back())
+	}
+}
+
+// Delete will remove the key from the cache
+func (m *MemoryCache) Delete(_ context.Context, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.removeElement(element)
+	}
+}
+
+// Len will return the number of items in the cache (including expired items not yet removed)
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+// removeElement will remove the element from the list and map (lock must be held)
+func (m *MemoryCache) removeElement(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.items, element.Value.(*memoryCacheItem).key) //nolint:forcetypeassert // only memoryCacheItem is stored
+}
+
+// cacheControl is the parsed Cache-Control response header
+type cacheControl struct {
+	hasMaxAge bool
+	maxAge    time.Duration
+	noCache   bool
+	noStore   bool
+}
+
+// parseCacheControl will parse the directives used for caching from a Cache-Control header
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc9111#section-5.2.2
+func parseCacheControl(header string) (cc cacheControl) {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			cc.noStore = true
+		case directive == "no-cache":
+			cc.noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(directive, "max-age="), `"`)); err == nil && seconds >= 0 {
+				cc.hasMaxAge = true
+				cc.maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return
+}
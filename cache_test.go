@@ -0,0 +1,338 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail/interfaces"
+	"github.com/tonicpow/go-paymail/tester"
+)
+
+// testCapabilitiesURL is the capabilities url for the test domain
+const testCapabilitiesURL = "https://" + testDomain + ":443/.well-known/" + DefaultServiceName
+
+// TestMemoryCache will test the MemoryCache
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("set, get and delete", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), time.Minute)
+
+		value, found := cache.Get(ctx, "key")
+		assert.Equal(t, true, found)
+		assert.Equal(t, []byte("value"), value)
+
+		cache.Set(ctx, "key", []byte("updated"), time.Minute)
+		value, found = cache.Get(ctx, "key")
+		assert.Equal(t, true, found)
+		assert.Equal(t, []byte("updated"), value)
+		assert.Equal(t, 1, cache.Len())
+
+		cache.Delete(ctx, "key")
+		value, found = cache.Get(ctx, "key")
+		assert.Equal(t, false, found)
+		assert.Nil(t, value)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		_, found := cache.Get(ctx, "missing")
+		assert.Equal(t, false, found)
+		cache.Delete(ctx, "missing")
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		_, found := cache.Get(ctx, "key")
+		assert.Equal(t, false, found)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("no expiration", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), 0)
+		_, found := cache.Get(ctx, "key")
+		assert.Equal(t, true, found)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		cache := NewMemoryCache(2)
+		cache.Set(ctx, "a", []byte("a"), time.Minute)
+		cache.Set(ctx, "b", []byte("b"), time.Minute)
+
+		// Use "a" so "b" is the least recently used
+		_, found := cache.Get(ctx, "a")
+		require.Equal(t, true, found)
+
+		cache.Set(ctx, "c", []byte("c"), time.Minute)
+		assert.Equal(t, 2, cache.Len())
+
+		_, found = cache.Get(ctx, "b")
+		assert.Equal(t, false, found)
+		_, found = cache.Get(ctx, "a")
+		assert.Equal(t, true, found)
+		_, found = cache.Get(ctx, "c")
+		assert.Equal(t, true, found)
+	})
+
+	t.Run("unlimited entries", func(t *testing.T) {
+		cache := NewMemoryCache(0)
+		for i := 0; i < 100; i++ {
+			cache.Set(ctx, fmt.Sprintf("key-%d", i), []byte("value"), time.Minute)
+		}
+		assert.Equal(t, 100, cache.Len())
+	})
+}
+
+// TestParseCacheControl will test the method parseCacheControl()
+func TestParseCacheControl(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		header   string
+		expected cacheControl
+	}{
+		{"", cacheControl{}},
+		{"public", cacheControl{}},
+		{"max-age=60", cacheControl{hasMaxAge: true, maxAge: time.Minute}},
+		{"public, MAX-AGE=120", cacheControl{hasMaxAge: true, maxAge: 2 * time.Minute}},
+		{`max-age="30"`, cacheControl{hasMaxAge: true, maxAge: 30 * time.Second}},
+		{"max-age=0", cacheControl{hasMaxAge: true}},
+		{"max-age=-1", cacheControl{}},
+		{"max-age=abc", cacheControl{}},
+		{"no-cache", cacheControl{noCache: true}},
+		{"no-store", cacheControl{noStore: true}},
+		{"no-cache, no-store, max-age=10", cacheControl{hasMaxAge: true, maxAge: 10 * time.Second, noCache: true, noStore: true}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, parseCacheControl(test.header), "header: %s", test.header)
+	}
+}
+
+// TestClient_GetCapabilitiesCache will test caching in the method GetCapabilities()
+func TestClient_GetCapabilitiesCache(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := newTestClient(t)
+		mockCapabilities(http.StatusOK)
+
+		for i := 0; i < 2; i++ {
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+			assert.Equal(t, false, response.FromCache)
+		}
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("cached for the ttl", func(t *testing.T) {
+		client := newTestClient(t, WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilities(http.StatusOK)
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, false, response.FromCache)
+
+		response, err = client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, true, response.FromCache)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, DefaultBsvAliasVersion, response.BsvAlias)
+		assert.Equal(t, true, response.Has(BRFCPki, BRFCPkiAlternate))
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		client := newTestClient(t, WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilities(http.StatusNotFound)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.Error(t, err)
+		}
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("revalidated using the etag", func(t *testing.T) {
+		client := newTestClient(t, WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilitiesWithHeaders(map[string]string{"Cache-Control": "no-cache", "ETag": `"v1"`}, `"v1"`)
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, false, response.FromCache)
+
+		response, err = client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, true, response.FromCache)
+		assert.Equal(t, http.StatusNotModified, response.StatusCode)
+		assert.Equal(t, true, response.Has(BRFCPki, BRFCPkiAlternate))
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("max-age overrides the ttl", func(t *testing.T) {
+		client := newTestClient(t, WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilitiesWithHeaders(map[string]string{"Cache-Control": "max-age=0"}, "")
+
+		for i := 0; i < 2; i++ {
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+			assert.Equal(t, false, response.FromCache)
+		}
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("no-store is not cached", func(t *testing.T) {
+		client := newTestClient(t, WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilitiesWithHeaders(map[string]string{"Cache-Control": "no-store", "ETag": `"v1"`}, `"v1"`)
+
+		for i := 0; i < 2; i++ {
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+			assert.Equal(t, false, response.FromCache)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("invalidate capabilities", func(t *testing.T) {
+		client := newTestClient(t, WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilities(http.StatusOK)
+
+		_, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+
+		client.InvalidateCapabilities(context.Background(), testDomain, DefaultPort)
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, false, response.FromCache)
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("invalid cache entry is ignored", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(context.Background(), capabilitiesCacheKeyPrefix+testCapabilitiesURL, []byte("invalid"), time.Minute)
+		client := newTestClient(t, WithCache(cache), WithCapabilitiesCacheTTL(time.Minute))
+		mockCapabilities(http.StatusOK)
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, false, response.FromCache)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+}
+
+// TestClient_GetSRVRecordCache will test caching in the method GetSRVRecord()
+func TestClient_GetSRVRecordCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("cached for the ttl", func(t *testing.T) {
+		client := newTestClient(t, WithSRVCacheTTL(time.Minute))
+
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "www."+testDomain, srv.Target)
+
+		// Change the records, the cached record should still be returned
+		client.WithCustomResolver(newSRVTestResolver("new." + testDomain))
+		srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "www."+testDomain, srv.Target)
+
+		// Invalidate, the new record should be returned
+		client.InvalidateSRVRecord(ctx, "", "", testDomain)
+		srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "new."+testDomain, srv.Target)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := newTestClient(t)
+
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "www."+testDomain, srv.Target)
+
+		client.WithCustomResolver(newSRVTestResolver("new." + testDomain))
+		srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "new."+testDomain, srv.Target)
+	})
+}
+
+// mockCapabilitiesWithHeaders is used for mocking capabilities with response headers
+//
+// If the eTag is set and matches If-None-Match, a 304 is returned
+func mockCapabilitiesWithHeaders(headers map[string]string, eTag string) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, testCapabilitiesURL,
+		func(req *http.Request) (*http.Response, error) {
+			var resp *http.Response
+			if len(eTag) > 0 && req.Header.Get("If-None-Match") == eTag {
+				resp = httpmock.NewStringResponse(http.StatusNotModified, "")
+			} else {
+				resp = httpmock.NewStringResponse(
+					http.StatusOK,
+					`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities":
+{"6745385c3fc0": false,"pki": "`+testServerURL+`id/{alias}@{domain.tld}"}}`,
+				)
+			}
+			for key, value := range headers {
+				resp.Header.Set(key, value)
+			}
+			return resp, nil
+		},
+	)
+}
+
+// newSRVTestResolver will return a resolver with a single SRV record for the test domain
+func newSRVTestResolver(target string) interfaces.DNSResolver {
+	return tester.NewCustomResolver(nil, nil, map[string][]*net.SRV{
+		DefaultServiceName + DefaultProtocol + testDomain: {{Target: target, Port: 443, Priority: 10, Weight: 10}},
+	}, nil)
+}
+
+// ExampleWithCapabilitiesCacheTTL example using WithCapabilitiesCacheTTL()
+//
+// See more examples in /examples/
+func ExampleWithCapabilitiesCacheTTL() {
+	// Load the client
+	client := newTestClient(nil, WithCapabilitiesCacheTTL(10*time.Minute))
+
+	mockCapabilities(http.StatusOK)
+
+	// The second request is returned from the cache
+	_, _ = client.GetCapabilities(testDomain, DefaultPort)
+	capabilities, err := client.GetCapabilities(testDomain, DefaultPort)
+	if err != nil {
+		fmt.Printf("error occurred in GetCapabilities: %s", err.Error())
+		return
+	}
+	fmt.Printf("found %d capabilities, from cache: %t", len(capabilities.Capabilities), capabilities.FromCache)
+	// Output:found 3 capabilities, from cache: true
+}
+
+// BenchmarkMemoryCache_Get benchmarks the method Get()
+func BenchmarkMemoryCache_Get(b *testing.B) {
+	cache := NewMemoryCache(defaultCacheMaxEntries)
+	ctx := context.Background()
+	cache.Set(ctx, "key", []byte("value"), time.Minute)
+	for i := 0; i < b.N; i++ {
+		_, _ = cache.Get(ctx, "key")
+	}
+}
@@ -1,10 +1,12 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 /*
@@ -24,6 +26,7 @@ Default:
 type CapabilitiesResponse struct {
 	StandardResponse
 	CapabilitiesPayload
+	FromCache bool `json:"-"` // If the capabilities were returned from the cache
 }
 
 // CapabilitiesPayload is the actual payload response
@@ -79,7 +82,13 @@ func (c *CapabilitiesPayload) GetBool(brfcID, alternateID string) bool {
 // GetCapabilities will return a list of capabilities for a given domain & port
 //
 // Specs: http://bsvalias.org/02-02-capability-discovery.html
-func (c *Client) GetCapabilities(target string, port int) (response *CapabilitiesResponse, err error) {
+func (c *Client) GetCapabilities(target string, port int) (*CapabilitiesResponse, error) {
+	return c.GetCapabilitiesContext(context.Background(), target, port)
+}
+
+// GetCapabilitiesContext is the same as GetCapabilities() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetCapabilitiesContext(ctx context.Context, target string, port int) (response *CapabilitiesResponse, err error) {
 
 	// Basic requirements for the request
 	if len(target) == 0 {
@@ -91,25 +100,48 @@ func (c *Client) GetCapabilities(target string, port int) (response *Capabilitie
 	}
 
 	// Set the base url and path
-	// https://<host-discovery-target>:<host-discovery-port>/.well-known/bsvalias[network]
-	reqURL := fmt.Sprintf("https://%s:%d/.well-known/%s%s", target, port, DefaultServiceName, c.options.network.URLSuffix()) //nolint:nosprintfhostport // no need to check
+	reqURL := c.capabilitiesURL(target, port)
+
+	// Check the cache (if enabled)
+	var cached *cachedCapabilities
+	if c.options.capabilitiesTTL > 0 {
+		if cached = c.getCachedCapabilities(ctx, reqURL); cached != nil && time.Now().Before(cached.ExpiresAt) {
+			response = &CapabilitiesResponse{
+				CapabilitiesPayload: cached.CapabilitiesPayload,
+				FromCache:           true,
+			}
+			response.StatusCode = http.StatusOK
+			return
+		}
+	}
+
+	// Revalidate expired capabilities using the ETag
+	var headers map[string]string
+	if cached != nil && len(cached.ETag) > 0 {
+		headers = map[string]string{"If-None-Match": cached.ETag}
+	}
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequestWithHeaders(ctx, reqURL, headers); err != nil {
+		err = newRequestError(StepCapabilities, reqURL, err)
 		return
 	}
 
 	// Start the response
 	response = &CapabilitiesResponse{StandardResponse: resp}
 
+	// Not modified, use the cached capabilities
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		response.CapabilitiesPayload = cached.CapabilitiesPayload
+		response.FromCache = true
+		c.cacheCapabilities(ctx, reqURL, response, cached.ETag)
+		return
+	}
+
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newResponseError(StepCapabilities, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
@@ -123,21 +155,107 @@ func (c *Client) GetCapabilities(target string, port int) (response *Capabilitie
 			bodyString := strings.Replace(strings.Replace(string(resp.Body), `“`, `"`, -1), `”`, `"`, -1)
 
 			// Parse again after fixing quotes
-			if err = json.Unmarshal([]byte(bodyString), &response); err != nil {
-				return
-			}
+			err = json.Unmarshal([]byte(bodyString), &response)
 		}
 
 		// Still have an error?
 		if err != nil {
+			err = newInvalidResponseError(StepCapabilities, reqURL, response.StatusCode, err)
 			return
 		}
 	}
 
 	// Invalid version detected
 	if len(response.BsvAlias) == 0 {
-		err = fmt.Errorf("missing %s version", DefaultServiceName)
+		err = newInvalidResponseError(StepCapabilities, reqURL, response.StatusCode, fmt.Errorf("missing %s version", DefaultServiceName))
+		return
 	}
 
+	// Cache the capabilities (if enabled)
+	c.cacheCapabilities(ctx, reqURL, response, "")
+
 	return
 }
+
+// InvalidateCapabilities will remove the cached capabilities for a given domain & port
+func (c *Client) InvalidateCapabilities(ctx context.Context, target string, port int) {
+	c.options.cache.Delete(ctx, capabilitiesCacheKeyPrefix+c.capabilitiesURL(target, port))
+}
+
+// capabilitiesURL will return the capabilities url for the target & port (and network)
+//
+// https://<host-discovery-target>:<host-discovery-port>/.well-known/bsvalias[network]
+func (c *Client) capabilitiesURL(target string, port int) string {
+	return fmt.Sprintf("https://%s:%d/.well-known/%s%s", target, port, DefaultServiceName, c.options.network.URLSuffix()) //nolint:nosprintfhostport // no need to check
+}
+
+// cachedCapabilities is the capabilities entry stored in the cache
+type cachedCapabilities struct {
+	CapabilitiesPayload
+	ETag      string    `json:"etag,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// getCachedCapabilities will return the cached capabilities (fresh or expired) if found
+func (c *Client) getCachedCapabilities(ctx context.Context, reqURL string) *cachedCapabilities {
+	data, found := c.options.cache.Get(ctx, capabilitiesCacheKeyPrefix+reqURL)
+	if !found {
+		return nil
+	}
+	cached := new(cachedCapabilities)
+	if err := json.Unmarshal(data, cached); err != nil {
+		return nil
+	}
+	return cached
+}
+
+// cacheCapabilities will store the capabilities following the Cache-Control & ETag headers
+//
+// Entries are kept for the freshness lifetime plus the TTL, so they can be revalidated once expired
+func (c *Client) cacheCapabilities(ctx context.Context, reqURL string,
+	response *CapabilitiesResponse, previousETag string) {
+
+	// Caching is disabled
+	if c.options.capabilitiesTTL <= 0 {
+		return
+	}
+
+	// The provider does not allow storing the response
+	key := capabilitiesCacheKeyPrefix + reqURL
+	cc := parseCacheControl(response.Header.Get("Cache-Control"))
+	if cc.noStore {
+		c.options.cache.Delete(ctx, key)
+		return
+	}
+
+	// Set the freshness lifetime (max-age overrides the TTL, no-cache always revalidates)
+	freshness := c.options.capabilitiesTTL
+	if cc.hasMaxAge {
+		freshness = cc.maxAge
+	}
+	if cc.noCache {
+		freshness = 0
+	}
+
+	// Keep the previous ETag if a new one was not returned (304)
+	eTag := response.Header.Get("ETag")
+	if len(eTag) == 0 {
+		eTag = previousETag
+	}
+
+	// Nothing to cache (cannot be used fresh or revalidated)
+	if freshness <= 0 && len(eTag) == 0 {
+		c.options.cache.Delete(ctx, key)
+		return
+	}
+
+	data, err := json.Marshal(&cachedCapabilities{
+		CapabilitiesPayload: response.CapabilitiesPayload,
+		ETag:                eTag,
+		ExpiresAt:           time.Now().Add(freshness),
+	})
+	if err != nil {
+		return
+	}
+	c.options.cache.Set(ctx, key, data, freshness+c.options.capabilitiesTTL)
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -27,6 +28,28 @@ func TestClient_GetCapabilities(t *testing.T) {
 		assert.Equal(t, true, response.Has(BRFCPki, ""))
 	})
 
+	t.Run("successful response - with context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockCapabilities(http.StatusOK)
+
+		response, err := client.GetCapabilitiesContext(context.Background(), testDomain, DefaultPort)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, DefaultBsvAliasVersion, response.BsvAlias)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockCapabilities(http.StatusOK)
+
+		response, err := client.GetCapabilitiesContext(canceledContext(), testDomain, DefaultPort)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, response)
+	})
+
 	t.Run("successful testnet response", func(t *testing.T) {
 		client := newTestClient(t, WithNetwork(Testnet))
 
@@ -53,6 +76,23 @@ func TestClient_GetCapabilities(t *testing.T) {
 		assert.Equal(t, true, response.Has(BRFCPki, ""))
 	})
 
+	t.Run("successful regtest & custom network responses", func(t *testing.T) {
+		custom, err := RegisterNetwork("capabilities-net", "-capabilities-net", TestnetAddressVersion)
+		require.NoError(t, err)
+		t.Cleanup(func() { unregisterNetwork(custom) })
+
+		for _, network := range []Network{Regtest, custom} {
+			client := newTestClient(t, WithNetwork(network))
+
+			mockCapabilitiesNetwork(http.StatusOK, network)
+
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err, network.String())
+			require.NotNil(t, response)
+			assert.Equal(t, true, response.Has(BRFCPki, ""))
+		}
+	})
+
 	t.Run("status not modified", func(t *testing.T) {
 		client := newTestClient(t)
 
@@ -1,9 +1,12 @@
 package paymail
 
 import (
+	"context"
+	"crypto/x509"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/miekg/dns"
 	"github.com/tonicpow/go-paymail/interfaces"
 )
 
@@ -17,18 +20,28 @@ type (
 
 	// ClientOptions holds all the configuration for client requests and default resources
 	ClientOptions struct {
-		brfcSpecs         []*BRFCSpec   // List of BRFC specifications
-		dnsPort           string        // Default DNS port for SRV checks
-		dnsTimeout        time.Duration // Default timeout in seconds for DNS fetching
-		httpTimeout       time.Duration // Default timeout in seconds for GET requests
-		nameServer        string        // Default name server for DNS checks
-		nameServerNetwork string        // Default name server network
-		requestTracing    bool          // If enabled, it will trace the request timing
-		retryCount        int           // Default retry count for HTTP requests
-		sslDeadline       time.Duration // Default timeout in seconds for SSL deadline
-		sslTimeout        time.Duration // Default timeout in seconds for SSL timeout
-		userAgent         string        // User agent for all outgoing requests
-		network           Network       // The bitcoin network to operate on
+		brfcSpecs          []*BRFCSpec     // List of BRFC specifications
+		cache              Cache           // Cache for capabilities & SRV records (default is in-memory)
+		capabilitiesTTL    time.Duration   // Default TTL for cached capabilities (0 = disabled)
+		dnsPort            string          // Default DNS port for SRV checks
+		dnssecTrustAnchors []*dns.DS       // Trust anchors for DNSSEC chain validation (default is the root zone)
+		dnssecValidation   bool            // If enabled, CheckDNSSEC() will also validate the chain of trust
+		dnsTimeout         time.Duration   // Default timeout in seconds for DNS fetching
+		handleRegistry     *HandleRegistry // Handle resolvers for Resolve() & ConvertHandle() (default is DefaultHandleRegistry)
+		httpTimeout        time.Duration   // Default timeout in seconds for GET requests
+		nameServer         string          // Default name server for DNS checks
+		nameServerNetwork  string          // Default name server network
+		requestTracing     bool            // If enabled, it will trace the request timing
+		retryCount         int             // Default retry count for HTTP requests
+		srvFailover        bool            // If enabled, Resolve() will try the next SRV record if the capabilities fail
+		srvTTL             time.Duration   // Default TTL for cached SRV records (0 = disabled)
+		sslDeadline        time.Duration   // Default timeout in seconds for SSL deadline
+		sslTimeout         time.Duration   // Default timeout in seconds for SSL timeout
+		tlsCritical        time.Duration   // CheckTLS() is critical if the certificate expires within this duration
+		tlsRootCAs         *x509.CertPool  // Root CAs for CheckTLS() (default is the system pool)
+		tlsWarning         time.Duration   // CheckTLS() is a warning if the certificate expires within this duration
+		userAgent          string          // User agent for all outgoing requests
+		network            Network         // The bitcoin network to operate on
 	}
 )
 
@@ -61,6 +74,11 @@ func NewClient(opts ...ClientOps) (ClientInterface, error) {
 		}
 	}
 
+	// Set the cache (only used if a TTL is set)
+	if client.options.cache == nil {
+		client.options.cache = NewMemoryCache(defaultCacheMaxEntries)
+	}
+
 	// Set the resolver
 	if client.resolver == nil {
 		r := client.defaultResolver()
@@ -99,10 +117,21 @@ func (c *Client) GetResolver() interfaces.DNSResolver {
 }
 
 // getRequest is a standard GET request for all outgoing HTTP requests
-func (c *Client) getRequest(requestURL string) (response StandardResponse, err error) {
+func (c *Client) getRequest(ctx context.Context, requestURL string) (response StandardResponse, err error) {
+	return c.getRequestWithHeaders(ctx, requestURL, nil)
+}
+
+// getRequestWithHeaders is a standard GET request with additional request headers (IE: If-None-Match)
+func (c *Client) getRequestWithHeaders(ctx context.Context, requestURL string,
+	headers map[string]string) (response StandardResponse, err error) {
+
+	// Do not fire the request if the context is already done
+	if err = ctx.Err(); err != nil {
+		return
+	}
 
-	// Set the user agent
-	req := c.httpClient.R().SetHeader("User-Agent", c.options.userAgent)
+	// Set the context, headers & user agent
+	req := c.httpClient.R().SetContext(ctx).SetHeaders(headers).SetHeader("User-Agent", c.options.userAgent)
 
 	// Enable tracing
 	if c.options.requestTracing {
@@ -120,8 +149,9 @@ func (c *Client) getRequest(requestURL string) (response StandardResponse, err e
 		response.Tracing = resp.Request.TraceInfo()
 	}
 
-	// Set the status code
+	// Set the status code & headers
 	response.StatusCode = resp.StatusCode()
+	response.Header = resp.Header()
 
 	// Set the body
 	response.Body = resp.Body()
@@ -129,10 +159,15 @@ func (c *Client) getRequest(requestURL string) (response StandardResponse, err e
 }
 
 // postRequest is a standard POST request for all outgoing HTTP requests
-func (c *Client) postRequest(requestURL string, data interface{}) (response StandardResponse, err error) {
+func (c *Client) postRequest(ctx context.Context, requestURL string, data interface{}) (response StandardResponse, err error) {
+
+	// Do not fire the request if the context is already done
+	if err = ctx.Err(); err != nil {
+		return
+	}
 
-	// Set the user agent
-	req := c.httpClient.R().SetBody(data).SetHeader("User-Agent", c.options.userAgent)
+	// Set the context, body & user agent
+	req := c.httpClient.R().SetContext(ctx).SetBody(data).SetHeader("User-Agent", c.options.userAgent)
 
 	// Enable tracing
 	if c.options.requestTracing {
@@ -150,8 +185,9 @@ func (c *Client) postRequest(requestURL string, data interface{}) (response Stan
 		response.Tracing = resp.Request.TraceInfo()
 	}
 
-	// Set the status code
+	// Set the status code & headers
 	response.StatusCode = resp.StatusCode()
+	response.Header = resp.Header()
 
 	// Set the body
 	response.Body = resp.Body()
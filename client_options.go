@@ -1,9 +1,11 @@
 package paymail
 
 import (
+	"crypto/x509"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/miekg/dns"
 	"github.com/tonicpow/go-paymail/interfaces"
 )
 
@@ -17,17 +19,20 @@ type ClientOps func(c *ClientOptions)
 func defaultClientOptions() (opts *ClientOptions, err error) {
 	// Set the default options
 	opts = &ClientOptions{
-		dnsPort:           defaultDNSPort,
-		dnsTimeout:        defaultDNSTimeout,
-		httpTimeout:       defaultHTTPTimeout,
-		nameServer:        defaultNameServer,
-		nameServerNetwork: defaultNameServerNetwork,
-		requestTracing:    false,
-		retryCount:        defaultRetryCount,
-		sslDeadline:       defaultSSLDeadline,
-		sslTimeout:        defaultSSLTimeout,
-		userAgent:         defaultUserAgent,
-		network:           Network(defaultNetwork),
+		dnssecTrustAnchors: defaultTrustAnchors(),
+		dnsPort:            defaultDNSPort,
+		dnsTimeout:         defaultDNSTimeout,
+		httpTimeout:        defaultHTTPTimeout,
+		nameServer:         defaultNameServer,
+		nameServerNetwork:  defaultNameServerNetwork,
+		requestTracing:     false,
+		retryCount:         defaultRetryCount,
+		sslDeadline:        defaultSSLDeadline,
+		sslTimeout:         defaultSSLTimeout,
+		tlsCritical:        defaultTLSCritical,
+		tlsWarning:         defaultTLSWarning,
+		userAgent:          defaultUserAgent,
+		network:            Network(defaultNetwork),
 	}
 
 	// Load the default BRFC specs
@@ -131,6 +136,92 @@ func WithNetwork(n Network) ClientOps {
 	}
 }
 
+// WithCache will overwrite the default in-memory cache used for capabilities & SRV records.
+// Useful for sharing a cache between instances (IE: Redis).
+// The cache is only used if a TTL is set via WithCapabilitiesCacheTTL() or WithSRVCacheTTL().
+func WithCache(cache Cache) ClientOps {
+	return func(c *ClientOptions) {
+		if cache != nil {
+			c.cache = cache
+		}
+	}
+}
+
+// WithCapabilitiesCacheTTL will enable caching of capabilities for the given duration.
+// A Cache-Control max-age from the provider overrides the TTL, and no-store disables caching.
+// Expired capabilities are revalidated using the ETag (If-None-Match) if one was returned.
+// Default is 0 (disabled).
+func WithCapabilitiesCacheTTL(ttl time.Duration) ClientOps {
+	return func(c *ClientOptions) {
+		c.capabilitiesTTL = ttl
+	}
+}
+
+// WithSRVCacheTTL will enable caching of SRV records for the given duration.
+// Default is 0 (disabled).
+func WithSRVCacheTTL(ttl time.Duration) ClientOps {
+	return func(c *ClientOptions) {
+		c.srvTTL = ttl
+	}
+}
+
+// WithDNSSECTrustAnchors will overwrite the trust anchors used for DNSSEC chain validation.
+// The zone of each anchor is the owner name of the DS record, the closest zone to the domain is used.
+// Default is the IANA root zone KSKs.
+func WithDNSSECTrustAnchors(anchors ...*dns.DS) ClientOps {
+	return func(c *ClientOptions) {
+		if len(anchors) > 0 {
+			c.dnssecTrustAnchors = anchors
+		}
+	}
+}
+
+// WithDNSSECValidation will enable the validation of the DNSSEC chain of trust in CheckDNSSEC().
+// Default is false.
+func WithDNSSECValidation() ClientOps {
+	return func(c *ClientOptions) {
+		c.dnssecValidation = true
+	}
+}
+
+// WithTLSRootCAs will set the root CAs used by CheckTLS() to verify the chain,
+// useful for providers using a private CA.
+// Default is the system pool.
+func WithTLSRootCAs(pool *x509.CertPool) ClientOps {
+	return func(c *ClientOptions) {
+		c.tlsRootCAs = pool
+	}
+}
+
+// WithTLSExpiryThresholds will overwrite the certificate expiry thresholds used by CheckTLS().
+// Default is a warning within 30 days and critical within 24 hours.
+func WithTLSExpiryThresholds(warning, critical time.Duration) ClientOps {
+	return func(c *ClientOptions) {
+		if warning > 0 {
+			c.tlsWarning = warning
+		}
+		if critical > 0 {
+			c.tlsCritical = critical
+		}
+	}
+}
+
+// WithSRVFailover will enable failover discovery in Resolve(), if the capabilities fail
+// on the first SRV target, the next target (ordered per RFC 2782) will be tried
+func WithSRVFailover() ClientOps {
+	return func(c *ClientOptions) {
+		c.srvFailover = true
+	}
+}
+
+// WithHandleRegistry will overwrite the default handle registry (DefaultHandleRegistry), the
+// handles (IE: $handle) are converted into paymail addresses by Resolve() & ConvertHandle()
+func WithHandleRegistry(registry *HandleRegistry) ClientOps {
+	return func(c *ClientOptions) {
+		c.handleRegistry = registry
+	}
+}
+
 // WithCustomResolver will allow you to supply a custom  dns resolver,
 // useful for testing etc.
 func (c *Client) WithCustomResolver(resolver interfaces.DNSResolver) ClientInterface {
@@ -1,12 +1,15 @@
 package paymail
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"testing"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tonicpow/go-paymail/tester"
@@ -34,14 +37,21 @@ func newTestClient(t *testing.T, opts ...ClientOps) ClientInterface {
 	r := tester.NewCustomResolver(
 		client.GetResolver(),
 		map[string][]string{
-			testDomain:      {"44.225.125.175", "35.165.117.200", "54.190.182.236"},
-			"norecords.com": {},
+			testDomain:                      {"44.225.125.175", "35.165.117.200", "54.190.182.236"},
+			"norecords.com":                 {},
+			"primary." + testFailoverDomain: {"44.225.125.176"},
+			"backup." + testFailoverDomain:  {"44.225.125.177"},
 		},
 		map[string][]*net.SRV{
 			DefaultServiceName + DefaultProtocol + testDomain:      {{Target: "www." + testDomain, Port: 443, Priority: 10, Weight: 10}},
 			"invalid" + DefaultProtocol + testDomain:               {{Target: "www." + testDomain, Port: 443, Priority: 10, Weight: 10}},
 			DefaultServiceName + DefaultProtocol + "relayx.io":     {{Target: "relayx.io", Port: 443, Priority: 10, Weight: 10}},
 			DefaultServiceName + DefaultProtocol + "norecords.com": {},
+			DefaultServiceName + DefaultProtocol + testFailoverDomain: {
+				{Target: "backup." + testFailoverDomain + ".", Port: 443, Priority: 20, Weight: 10},
+				{Target: "primary." + testFailoverDomain + ".", Port: 443, Priority: 10, Weight: 10},
+			},
+			DefaultServiceName + DefaultProtocol + "unavailable.com": {{Target: ".", Port: 0, Priority: 0, Weight: 0}},
 		},
 		map[string][]net.IPAddr{
 			"example.com": {net.IPAddr{IP: net.ParseIP("8.8.8.8"), Zone: "eth0"}},
@@ -53,6 +63,13 @@ func newTestClient(t *testing.T, opts ...ClientOps) ClientInterface {
 	return client
 }
 
+// canceledContext will return a context that has already been canceled
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
 // TestNewClient will test the method NewClient()
 func TestNewClient(t *testing.T) {
 	t.Parallel()
@@ -71,6 +88,9 @@ func TestNewClient(t *testing.T) {
 		assert.Equal(t, defaultHTTPTimeout, client.GetOptions().httpTimeout)
 		assert.Equal(t, defaultRetryCount, client.GetOptions().retryCount)
 		assert.Equal(t, false, client.GetOptions().requestTracing)
+		assert.Equal(t, time.Duration(0), client.GetOptions().capabilitiesTTL)
+		assert.Equal(t, time.Duration(0), client.GetOptions().srvTTL)
+		assert.NotNil(t, client.GetOptions().cache)
 		assert.NotEqual(t, 0, len(client.GetOptions().brfcSpecs))
 		assert.Greater(t, len(client.GetBRFCs()), 6)
 	})
@@ -133,6 +153,70 @@ func TestNewClient(t *testing.T) {
 		assert.Equal(t, 7*time.Second, client.GetOptions().sslDeadline)
 	})
 
+	t.Run("custom cache", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		client, err := NewClient(WithCache(cache))
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, cache, client.GetOptions().cache)
+	})
+
+	t.Run("nil cache uses default", func(t *testing.T) {
+		client, err := NewClient(WithCache(nil))
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.NotNil(t, client.GetOptions().cache)
+	})
+
+	t.Run("custom cache ttls", func(t *testing.T) {
+		client, err := NewClient(WithCapabilitiesCacheTTL(time.Minute), WithSRVCacheTTL(2*time.Minute))
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, time.Minute, client.GetOptions().capabilitiesTTL)
+		assert.Equal(t, 2*time.Minute, client.GetOptions().srvTTL)
+	})
+
+	t.Run("dnssec validation", func(t *testing.T) {
+		anchor := &dns.DS{Hdr: dns.RR_Header{Name: "test."}, KeyTag: 1}
+		client, err := NewClient(WithDNSSECValidation(), WithDNSSECTrustAnchors(anchor))
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, true, client.GetOptions().dnssecValidation)
+		assert.Equal(t, []*dns.DS{anchor}, client.GetOptions().dnssecTrustAnchors)
+	})
+
+	t.Run("empty dnssec trust anchors uses default", func(t *testing.T) {
+		client, err := NewClient(WithDNSSECTrustAnchors())
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, 2, len(client.GetOptions().dnssecTrustAnchors))
+	})
+
+	t.Run("srv failover", func(t *testing.T) {
+		client, err := NewClient(WithSRVFailover())
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, true, client.GetOptions().srvFailover)
+	})
+
+	t.Run("tls options", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		client, err := NewClient(WithTLSRootCAs(pool), WithTLSExpiryThresholds(7*24*time.Hour, time.Hour))
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, pool, client.GetOptions().tlsRootCAs)
+		assert.Equal(t, 7*24*time.Hour, client.GetOptions().tlsWarning)
+		assert.Equal(t, time.Hour, client.GetOptions().tlsCritical)
+	})
+
+	t.Run("zero tls thresholds uses default", func(t *testing.T) {
+		client, err := NewClient(WithTLSExpiryThresholds(0, 0))
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Equal(t, defaultTLSWarning, client.GetOptions().tlsWarning)
+		assert.Equal(t, defaultTLSCritical, client.GetOptions().tlsCritical)
+	})
+
 	t.Run("custom options", func(t *testing.T) {
 		client, err := NewClient(WithUserAgent("custom user agent"))
 		assert.NotNil(t, client)
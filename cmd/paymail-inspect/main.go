@@ -0,0 +1,121 @@
+/*
+Package main is the paymail-inspect command, it runs a full conformance check of a paymail
+provider (domain or address) against the bsvalias specs and the known BRFC specifications
+
+Usage:
+
+	paymail-inspect [flags] <domain|alias@domain.tld>
+
+Exit codes: 0 = pass (or warnings), 1 = a check failed, 2 = invalid usage
+*/
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tonicpow/go-paymail"
+)
+
+// Exit codes
+const (
+	exitFail  = 1 // A check failed
+	exitPass  = 0 // All checks passed (or warned)
+	exitUsage = 2 // Invalid usage or the target could not be inspected
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, nil))
+}
+
+// run will parse the arguments, inspect the target and write the report
+//
+// If no client is given, one is created from the flags
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, client paymail.ClientInterface) int {
+
+	// Parse the flags
+	flags := flag.NewFlagSet("paymail-inspect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "usage: paymail-inspect [flags] <domain|alias@domain.tld>")
+		flags.PrintDefaults()
+	}
+	caFile := flags.String("ca", "", "PEM file of root CAs for the TLS check (for providers using a private CA)")
+	jsonOutput := flags.Bool("json", false, "write the report as JSON")
+	nameServer := flags.String("nameserver", "", "name server for the DNSSEC check (default 8.8.8.8)")
+	skipDNSSEC := flags.Bool("skip-dnssec", false, "skip the DNSSEC check")
+	skipTLS := flags.Bool("skip-tls", false, "skip the TLS check")
+	timeout := flags.Duration("timeout", time.Minute, "timeout for the whole inspection")
+	validateChain := flags.Bool("dnssec-chain", false, "validate the DNSSEC chain of trust (required to pass)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	} else if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	// Create the client
+	if client == nil {
+		var opts []paymail.ClientOps
+		if len(*caFile) > 0 {
+			pem, err := os.ReadFile(*caFile)
+			if err != nil {
+				_, _ = fmt.Fprintf(stderr, "error reading ca file: %s\n", err.Error())
+				return exitUsage
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				_, _ = fmt.Fprintf(stderr, "no certificates found in ca file: %s\n", *caFile)
+				return exitUsage
+			}
+			opts = append(opts, paymail.WithTLSRootCAs(pool))
+		}
+		if len(*nameServer) > 0 {
+			opts = append(opts, paymail.WithNameServer(*nameServer))
+		}
+		if *validateChain {
+			opts = append(opts, paymail.WithDNSSECValidation())
+		}
+
+		var err error
+		if client, err = paymail.NewClient(opts...); err != nil {
+			_, _ = fmt.Fprintf(stderr, "error loading client: %s\n", err.Error())
+			return exitUsage
+		}
+	}
+
+	// Run the inspection
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	report, err := client.InspectContext(ctx, flags.Arg(0), &paymail.InspectRequest{
+		SkipDNSSEC: *skipDNSSEC,
+		SkipTLS:    *skipTLS,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error inspecting %s: %s\n", flags.Arg(0), err.Error())
+		return exitUsage
+	}
+
+	// Write the report
+	if *jsonOutput {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(report); err != nil {
+			_, _ = fmt.Fprintf(stderr, "error writing report: %s\n", err.Error())
+			return exitUsage
+		}
+	} else {
+		_, _ = fmt.Fprint(stdout, report.String())
+	}
+
+	if report.Status == paymail.InspectStatusFail {
+		return exitFail
+	}
+	return exitPass
+}
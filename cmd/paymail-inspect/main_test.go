@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/tester"
+)
+
+// newTestClient will return a client where the test domain shows the service is not available
+func newTestClient(t *testing.T) paymail.ClientInterface {
+	client, err := paymail.NewClient()
+	require.NoError(t, err)
+	client.WithCustomResolver(tester.NewCustomResolver(
+		client.GetResolver(), nil,
+		map[string][]*net.SRV{
+			paymail.DefaultServiceName + paymail.DefaultProtocol + "unavailable.com": {{Target: "."}},
+		},
+		nil,
+	))
+	return client
+}
+
+// Test_run will test the method run()
+func Test_run(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text report", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), []string{"unavailable.com"}, &stdout, &stderr, newTestClient(t))
+		assert.Equal(t, exitFail, code)
+		assert.Contains(t, stdout.String(), "paymail inspection: unavailable.com")
+		assert.Contains(t, stdout.String(), "[FAIL] srv")
+		assert.Contains(t, stdout.String(), "result: FAIL")
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("json report", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), []string{"-json", "unavailable.com"}, &stdout, &stderr, newTestClient(t))
+		assert.Equal(t, exitFail, code)
+
+		var report paymail.InspectReport
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+		assert.Equal(t, "unavailable.com", report.Domain)
+		assert.Equal(t, paymail.InspectStatusFail, report.Status)
+		require.NotEmpty(t, report.Checks)
+		assert.Equal(t, paymail.InspectCheckSRV, report.Checks[0].Name)
+	})
+
+	t.Run("invalid usage", func(t *testing.T) {
+		var tests = [][]string{
+			{},
+			{"one.com", "two.com"},
+			{"-unknown", "test.com"},
+			{"-ca", "missing-file.pem", "test.com"},
+			{"invalid@"},
+		}
+		for _, args := range tests {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, exitUsage, run(context.Background(), args, &stdout, &stderr, nil), args)
+			assert.NotEmpty(t, stderr.String(), args)
+		}
+	})
+}
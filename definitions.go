@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -8,6 +9,7 @@ import (
 
 // Defaults for paymail functions
 const (
+	defaultCacheMaxEntries   = 1000                     // Default max entries for the in-memory cache
 	defaultDNSPort           = "53"                     // Default port for DNS / NameServer checks
 	defaultDNSTimeout        = 5 * time.Second          // In seconds
 	defaultHTTPTimeout       = 20 * time.Second         // Default timeout for all GET requests in seconds
@@ -16,6 +18,8 @@ const (
 	defaultRetryCount        = 2                        // Default retry count for HTTP requests
 	defaultSSLDeadline       = 10 * time.Second         // Default deadline in seconds
 	defaultSSLTimeout        = 10 * time.Second         // Default timeout in seconds
+	defaultTLSCritical       = 24 * time.Hour           // Default critical threshold for certificate expiry
+	defaultTLSWarning        = 30 * 24 * time.Hour      // Default warning threshold for certificate expiry
 	defaultUserAgent         = "go-paymail: " + version // Default user agent
 	defaultNetwork           = byte(Mainnet)            // Default network
 	version                  = "v0.10.3"                // Go-Paymail version
@@ -50,6 +54,7 @@ const (
 // StandardResponse is the standard fields returned on all responses
 type StandardResponse struct {
 	Body       []byte          `json:"-"` // Body of the response request
+	Header     http.Header     `json:"-"` // Headers returned on the request
 	StatusCode int             `json:"-"` // Status code returned on the request
 	Tracing    resty.TraceInfo `json:"-"` // Trace information if enabled on the request
 }
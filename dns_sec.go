@@ -1,12 +1,12 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/miekg/dns"
-	"golang.org/x/net/idna"
 	"golang.org/x/net/publicsuffix"
 )
 
@@ -19,12 +19,13 @@ https://dnssec-analyzer.verisignlabs.com/domain.com
 
 // DNSCheckResult struct is returned for the DNS check
 type DNSCheckResult struct {
-	Answer       answer    `json:"answer"`
-	CheckTime    time.Time `json:"check_time"`
-	DNSSEC       bool      `json:"dnssec"`
-	Domain       string    `json:"domain,omitempty"`
-	ErrorMessage string    `json:"error_message,omitempty"`
-	NSEC         nsec      `json:"nsec"`
+	Answer       answer             `json:"answer"`
+	Chain        *DNSSECChainResult `json:"chain,omitempty"`
+	CheckTime    time.Time          `json:"check_time"`
+	DNSSEC       bool               `json:"dnssec"`
+	Domain       string             `json:"domain,omitempty"`
+	ErrorMessage string             `json:"error_message,omitempty"`
+	NSEC         nsec               `json:"nsec"`
 }
 
 // nsec struct for NSEC type
@@ -85,7 +86,14 @@ var (
 // CheckDNSSEC will check the DNSSEC for a given domain
 //
 // Paymail providers should have DNSSEC enabled for their domain
-func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
+// Use WithDNSSECValidation() to also validate the chain of trust (see: CheckDNSSECChain())
+func (c *Client) CheckDNSSEC(domain string) *DNSCheckResult {
+	return c.CheckDNSSECContext(context.Background(), domain)
+}
+
+// CheckDNSSECContext is the same as CheckDNSSEC() but accepts a context
+// that is used for cancellation and deadlines on all DNS exchanges
+func (c *Client) CheckDNSSECContext(ctx context.Context, domain string) (result *DNSCheckResult) {
 
 	// Start the new result
 	result = new(DNSCheckResult)
@@ -94,7 +102,7 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 	var err error
 
 	// Valid domain name (ASCII or IDN)
-	if domain, err = idna.ToASCII(domain); err != nil {
+	if domain, err = ToASCIIDomain(domain); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in ToASCII: %s", err.Error())
 		return
 	}
@@ -121,21 +129,21 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 
 	// Set the registry name server
 	var registryNameserver string
-	if registryNameserver, err = resolveOneNS(tld, c.options.nameServer, c.options.dnsPort); err != nil {
+	if registryNameserver, err = resolveOneNS(ctx, tld, c.options.nameServer, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveOneNS: %s", err.Error())
 		return
 	}
 
 	// Set the domain name server
 	var domainNameserver string
-	if domainNameserver, err = resolveOneNS(domain, c.options.nameServer, c.options.dnsPort); err != nil {
+	if domainNameserver, err = resolveOneNS(ctx, domain, c.options.nameServer, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveOneNS: %s", err.Error())
 		return
 	}
 
 	// Domain name servers at registrar Host
 	var domainDsRecord []*domainDS
-	if domainDsRecord, err = resolveDomainDS(domain, registryNameserver, c.options.dnsPort); err != nil {
+	if domainDsRecord, err = resolveDomainDS(ctx, domain, registryNameserver, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveDomainDS: %s", err.Error())
 		return
 	}
@@ -146,7 +154,7 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 
 	// Resolve domain DNSKey
 	var dnsKey []*domainDNSKEY
-	if dnsKey, err = resolveDomainDNSKEY(domain, domainNameserver, c.options.dnsPort); err != nil {
+	if dnsKey, err = resolveDomainDNSKEY(ctx, domain, domainNameserver, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveDomainDNSKEY: %s", err.Error())
 		return
 	}
@@ -164,7 +172,7 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 	// Check the DS record
 	if result.Answer.DSRecordCount > 0 && result.Answer.DNSKEYRecordCount > 0 {
 		var calculatedDS []*domainDS
-		if calculatedDS, err = calculateDSRecord(domain, domainNameserver, c.options.dnsPort, digest); err != nil {
+		if calculatedDS, err = calculateDSRecord(ctx, domain, domainNameserver, c.options.dnsPort, digest); err != nil {
 			result.ErrorMessage = fmt.Sprintf("failed in calculateDSRecord: %s", err.Error())
 			return
 		}
@@ -173,7 +181,7 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 
 	// Resolve the domain NSEC
 	var nSec *dns.NSEC
-	if nSec, err = resolveDomainNSEC(domain, c.options.nameServer, c.options.dnsPort); err != nil {
+	if nSec, err = resolveDomainNSEC(ctx, domain, c.options.nameServer, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveDomainNSEC: %s", err.Error())
 		return
 	} else if nSec != nil {
@@ -183,7 +191,7 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 
 	// Resolve the domain NSEC3
 	var nSec3 *dns.NSEC3
-	if nSec3, err = resolveDomainNSEC3(domain, c.options.nameServer, c.options.dnsPort); err != nil {
+	if nSec3, err = resolveDomainNSEC3(ctx, domain, c.options.nameServer, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveDomainNSEC3: %s", err.Error())
 		return
 	} else if nSec3 != nil {
@@ -193,7 +201,7 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 
 	// Resolve the domain NSEC3PARAM
 	var nSec3param *dns.NSEC3PARAM
-	if nSec3param, err = resolveDomainNSEC3PARAM(domain, c.options.nameServer, c.options.dnsPort); err != nil {
+	if nSec3param, err = resolveDomainNSEC3PARAM(ctx, domain, c.options.nameServer, c.options.dnsPort); err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed in resolveDomainNSEC3PARAM: %s", err.Error())
 		return
 	} else if nSec3param != nil {
@@ -220,6 +228,15 @@ func (c *Client) CheckDNSSEC(domain string) (result *DNSCheckResult) {
 		result.DNSSEC = false
 	}
 
+	// Validate the chain of trust (if enabled)
+	if c.options.dnssecValidation {
+		result.Chain = c.CheckDNSSECChainContext(ctx, result.Domain)
+		result.DNSSEC = result.DNSSEC && result.Chain.Valid
+		if !result.Chain.Valid {
+			result.ErrorMessage = result.Chain.ErrorMessage
+		}
+	}
+
 	// Complete
 	return
 }
@@ -230,13 +247,13 @@ License: https://github.com/binaryfigments/dnscheck/blob/master/LICENSE
 */
 
 // newDNSMessage will create a new DNS message and fire the exchange request
-func newDNSMessage(domain, nameServer, dnsPort string, dnsType uint16) (*dns.Msg, error) {
+func newDNSMessage(ctx context.Context, domain, nameServer, dnsPort string, dnsType uint16) (*dns.Msg, error) {
 	m := new(dns.Msg)
 	m.MsgHdr.RecursionDesired = true
 	m.SetQuestion(dns.Fqdn(domain), dnsType)
 	m.SetEdns0(4096, true)
 	c := new(dns.Client)
-	in, _, err := c.Exchange(m, nameServer+":"+dnsPort)
+	in, _, err := c.ExchangeContext(ctx, m, nameServer+":"+dnsPort)
 	if err != nil {
 		return nil, err
 	}
@@ -244,10 +261,10 @@ func newDNSMessage(domain, nameServer, dnsPort string, dnsType uint16) (*dns.Msg
 }
 
 // resolveOneNS will resolve one name server
-func resolveOneNS(domain, nameServer, dnsPort string) (string, error) {
+func resolveOneNS(ctx context.Context, domain, nameServer, dnsPort string) (string, error) {
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeNS)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeNS)
 	if err != nil {
 		return "", err
 	}
@@ -265,10 +282,10 @@ func resolveOneNS(domain, nameServer, dnsPort string) (string, error) {
 }
 
 // resolveDomainNSEC will resolve a domain NSEC
-func resolveDomainNSEC(domain, nameServer, dnsPort string) (*dns.NSEC, error) {
+func resolveDomainNSEC(ctx context.Context, domain, nameServer, dnsPort string) (*dns.NSEC, error) {
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeNSEC)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeNSEC)
 	if err != nil {
 		return nil, err
 	}
@@ -284,10 +301,10 @@ func resolveDomainNSEC(domain, nameServer, dnsPort string) (*dns.NSEC, error) {
 }
 
 // resolveDomainNSEC3 will resolve a domain NSEC3
-func resolveDomainNSEC3(domain, nameServer, dnsPort string) (*dns.NSEC3, error) {
+func resolveDomainNSEC3(ctx context.Context, domain, nameServer, dnsPort string) (*dns.NSEC3, error) {
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeNSEC3)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeNSEC3)
 	if err != nil {
 		return nil, err
 	}
@@ -303,10 +320,10 @@ func resolveDomainNSEC3(domain, nameServer, dnsPort string) (*dns.NSEC3, error)
 }
 
 // resolveDomainNSEC3PARAM will resolve a domain NSEC3PARAM
-func resolveDomainNSEC3PARAM(domain, nameServer, dnsPort string) (*dns.NSEC3PARAM, error) {
+func resolveDomainNSEC3PARAM(ctx context.Context, domain, nameServer, dnsPort string) (*dns.NSEC3PARAM, error) {
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeNSEC3PARAM)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeNSEC3PARAM)
 	if err != nil {
 		return nil, err
 	}
@@ -322,11 +339,11 @@ func resolveDomainNSEC3PARAM(domain, nameServer, dnsPort string) (*dns.NSEC3PARA
 }
 
 // resolveDomainDS will resolve a domain DS
-func resolveDomainDS(domain, nameServer, dnsPort string) ([]*domainDS, error) {
+func resolveDomainDS(ctx context.Context, domain, nameServer, dnsPort string) ([]*domainDS, error) {
 	var ds []*domainDS
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeDS)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeDS)
 	if err != nil {
 		return ds, err
 	}
@@ -346,11 +363,11 @@ func resolveDomainDS(domain, nameServer, dnsPort string) ([]*domainDS, error) {
 }
 
 // resolveDomainDNSKEY will resolve a domain DNSKEY
-func resolveDomainDNSKEY(domain, nameServer, dnsPort string) ([]*domainDNSKEY, error) {
+func resolveDomainDNSKEY(ctx context.Context, domain, nameServer, dnsPort string) ([]*domainDNSKEY, error) {
 	var dnskey []*domainDNSKEY
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeDNSKEY)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeDNSKEY)
 	if err != nil {
 		return dnskey, err
 	}
@@ -372,11 +389,11 @@ func resolveDomainDNSKEY(domain, nameServer, dnsPort string) ([]*domainDNSKEY, e
 // calculateDSRecord function for generating DS records from the DNSKEY
 // Input: domain, digest and name server from the host
 // Output: one of more structs with DS information
-func calculateDSRecord(domain, nameServer, dnsPort string, digest uint8) ([]*domainDS, error) {
+func calculateDSRecord(ctx context.Context, domain, nameServer, dnsPort string, digest uint8) ([]*domainDS, error) {
 	var calculatedDS []*domainDS
 
 	// Fire the request
-	msg, err := newDNSMessage(domain, nameServer, dnsPort, dns.TypeDNSKEY)
+	msg, err := newDNSMessage(ctx, domain, nameServer, dnsPort, dns.TypeDNSKEY)
 	if err != nil {
 		return calculatedDS, err
 	}
@@ -0,0 +1,398 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Link types used in DNSSECLink.Type
+const (
+	DNSSECLinkDNSKEY = "DNSKEY" // The DNSKEY RRset of a zone (validated against the DS or trust anchor)
+	DNSSECLinkDS     = "DS"     // The DS RRset of a zone (signed by the parent zone)
+	DNSSECLinkNSEC   = "NSEC"   // The denial of existence of a DS or SRV RRset (NSEC, signed by the zone)
+	DNSSECLinkNSEC3  = "NSEC3"  // The denial of existence of a DS or SRV RRset (NSEC3, signed by the zone)
+	DNSSECLinkSRV    = "SRV"    // The _bsvalias._tcp SRV RRset (signed by the paymail domain zone)
+)
+
+// rootTrustAnchors are the IANA root zone KSK trust anchors (KSK-2017 & KSK-2024)
+//
+// Specs: https://data.iana.org/root-anchors/root-anchors.xml
+var rootTrustAnchors = []string{
+	". 172800 IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". 172800 IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+// DNSSECLink is a single link in the DNSSEC chain of trust
+type DNSSECLink struct {
+	Error  string `json:"error,omitempty"`  // Why the link failed validation
+	Name   string `json:"name"`             // Owner name of the RRset (IE: example.com.)
+	Signer string `json:"signer,omitempty"` // The key that validated the RRset (zone & key tag)
+	Type   string `json:"type"`             // DNSKEY, DS or SRV
+	Valid  bool   `json:"valid"`            // If the link was validated
+}
+
+// DNSSECChainResult is returned for the DNSSEC chain of trust validation
+type DNSSECChainResult struct {
+	CheckTime    time.Time     `json:"check_time"`
+	Domain       string        `json:"domain,omitempty"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	FailedLink   *DNSSECLink   `json:"failed_link,omitempty"`
+	Insecure     bool          `json:"insecure"` // The chain ends at an unsigned delegation (or an NSEC3 opt-out)
+	Links        []*DNSSECLink `json:"links"`
+	TrustAnchor  string        `json:"trust_anchor,omitempty"`
+	Valid        bool          `json:"valid"`
+}
+
+// defaultTrustAnchors will return the parsed root trust anchors
+func defaultTrustAnchors() (anchors []*dns.DS) {
+	for _, anchor := range rootTrustAnchors {
+		rr, err := dns.NewRR(anchor)
+		if err != nil {
+			continue
+		}
+		if ds, ok := rr.(*dns.DS); ok {
+			anchors = append(anchors, ds)
+		}
+	}
+	return
+}
+
+// CheckDNSSECChain will validate the DNSSEC chain of trust for a given paymail domain
+//
+// RRSIGs over the DNSKEY and DS RRsets of every zone from the trust anchor (default is the root zone)
+// down to the domain are verified, followed by the _bsvalias._tcp SRV RRset. Missing DS or SRV records
+// must be proven by signed NSEC or NSEC3 records. The first link that fails is returned in FailedLink.
+//
+// # A proven unsigned delegation (or NSEC3 opt-out) is not Valid, Insecure is set instead
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc4035#section-5
+func (c *Client) CheckDNSSECChain(domain string) *DNSSECChainResult {
+	return c.CheckDNSSECChainContext(context.Background(), domain)
+}
+
+// CheckDNSSECChainContext is the same as CheckDNSSECChain() but accepts a context
+// that is used for cancellation and deadlines on all DNS exchanges
+func (c *Client) CheckDNSSECChainContext(ctx context.Context, domain string) (result *DNSSECChainResult) {
+
+	// Start the new result
+	result = new(DNSSECChainResult)
+	result.CheckTime = time.Now()
+
+	// Valid domain name (ASCII or IDN)
+	var err error
+	if domain, err = ToASCIIDomain(strings.TrimSuffix(strings.TrimSpace(domain), ".")); err != nil {
+		result.ErrorMessage = fmt.Sprintf("failed in ToASCII: %s", err.Error())
+		return
+	} else if len(domain) == 0 {
+		result.ErrorMessage = "missing domain"
+		return
+	}
+	result.Domain = strings.ToLower(domain)
+	zone := dns.Fqdn(result.Domain)
+
+	// Find the trust anchor for the domain
+	var anchors []*dns.DS
+	if result.TrustAnchor, anchors = c.trustAnchorsFor(zone); len(anchors) == 0 {
+		result.ErrorMessage = fmt.Sprintf("no trust anchor found for %s", result.Domain)
+		return
+	}
+
+	// Validate the keys of the trust anchor zone
+	var keys []*dns.DNSKEY
+	var anchorKeys *dnssecAnswer
+	if anchorKeys, err = c.queryDNSSEC(ctx, result.TrustAnchor, dns.TypeDNSKEY); err != nil {
+		result.addLink(&DNSSECLink{Name: result.TrustAnchor, Type: DNSSECLinkDNSKEY}, err)
+		return
+	}
+	if keys, err = validateZoneKeys(result, result.TrustAnchor, anchorKeys.rrset, anchorKeys.sigs, anchors); err != nil {
+		return
+	}
+
+	// Walk down the chain of trust to the domain
+	parent := result.TrustAnchor
+	for _, name := range childZones(result.TrustAnchor, zone) {
+
+		// Get the keys & DS records for the name
+		var zoneKeys, dsRecords *dnssecAnswer
+		if zoneKeys, err = c.queryDNSSEC(ctx, name, dns.TypeDNSKEY); err != nil {
+			result.addLink(&DNSSECLink{Name: name, Type: DNSSECLinkDNSKEY}, err)
+			return
+		}
+		link := &DNSSECLink{Name: name, Type: DNSSECLinkDS}
+		if dsRecords, err = c.queryDNSSEC(ctx, name, dns.TypeDS); err != nil {
+			result.addLink(link, err)
+			return
+		}
+
+		// No DS records: the parent zone must prove it (NSEC or NSEC3), names that are
+		// not a zone cut are skipped and a delegation without DS records is insecure
+		if len(dsRecords.rrset) == 0 {
+			denial := &DNSSECLink{Name: name, Type: DNSSECLinkNSEC}
+			var proof *denialProof
+			if proof, err = verifyDenial(dsRecords, name, dns.TypeDS, keys, parent, denial); !result.addLink(denial, err) {
+				return
+			} else if proof.delegation || len(zoneKeys.rrset) > 0 {
+				result.Insecure = true
+				result.addLink(link, fmt.Errorf("no DS records in parent zone %s (insecure delegation)", parent))
+				return
+			}
+			continue
+		}
+
+		// The DS records must be signed by the parent zone
+		if link.Signer, err = verifyRRSet(dsRecords.rrset, dsRecords.sigs, keys, parent); !result.addLink(link, err) {
+			return
+		}
+
+		// The DNSKEY records must match the DS records
+		var ds []*dns.DS
+		for _, rr := range dsRecords.rrset {
+			ds = append(ds, rr.(*dns.DS)) //nolint:forcetypeassert // filtered by type in queryDNSSEC
+		}
+		if keys, err = validateZoneKeys(result, name, zoneKeys.rrset, zoneKeys.sigs, ds); err != nil {
+			return
+		}
+		parent = name
+	}
+
+	// The SRV records must be signed by the domain zone
+	link := &DNSSECLink{Name: "_" + DefaultServiceName + "._" + DefaultProtocol + "." + zone, Type: DNSSECLinkSRV}
+	var srvRecords *dnssecAnswer
+	if srvRecords, err = c.queryDNSSEC(ctx, link.Name, dns.TypeSRV); err != nil {
+		result.addLink(link, err)
+		return
+	} else if len(srvRecords.rrset) > 0 {
+		if link.Signer, err = verifyRRSet(srvRecords.rrset, srvRecords.sigs, keys, parent); !result.addLink(link, err) {
+			return
+		}
+	} else {
+
+		// No SRV records: the domain zone must prove it (an NSEC3 opt-out span is insecure)
+		denial := &DNSSECLink{Name: link.Name, Type: DNSSECLinkNSEC}
+		var proof *denialProof
+		if proof, err = verifyDenial(srvRecords, link.Name, dns.TypeSRV, keys, parent, denial); err == nil && proof.optOut {
+			result.Insecure = true
+			err = errors.New("only denied by an NSEC3 opt-out span (insecure)")
+		}
+		if !result.addLink(denial, err) {
+			return
+		}
+	}
+
+	// Every link was validated
+	result.Valid = true
+	return
+}
+
+// addLink will add the link to the result and set the failed link (if err is set)
+//
+// Returns true if the link is valid
+func (r *DNSSECChainResult) addLink(link *DNSSECLink, err error) bool {
+	if err != nil {
+		link.Error = err.Error()
+		r.FailedLink = link
+		r.ErrorMessage = fmt.Sprintf("%s %s: %s", link.Type, link.Name, err.Error())
+	} else {
+		link.Valid = true
+	}
+	r.Links = append(r.Links, link)
+	return err == nil
+}
+
+// trustAnchorsFor will return the trust anchors (and zone) for the closest enclosing zone of the name
+func (c *Client) trustAnchorsFor(name string) (closest string, anchors []*dns.DS) {
+	for _, anchor := range c.options.dnssecTrustAnchors {
+		zone := strings.ToLower(dns.Fqdn(anchor.Hdr.Name))
+		if !dns.IsSubDomain(zone, name) {
+			continue
+		}
+		if len(zone) > len(closest) {
+			closest = zone
+			anchors = nil
+		}
+		if zone == closest {
+			anchors = append(anchors, anchor)
+		}
+	}
+	return
+}
+
+// validateZoneKeys will validate the DNSKEY RRset of a zone against the DS records (or trust anchors)
+//
+// The RRset must be signed by one of the keys that matches a DS record
+func validateZoneKeys(result *DNSSECChainResult, zone string, records []dns.RR,
+	sigs []*dns.RRSIG, ds []*dns.DS) (keys []*dns.DNSKEY, err error) {
+
+	// The zone must have keys
+	link := &DNSSECLink{Name: zone, Type: DNSSECLinkDNSKEY}
+	if len(records) == 0 {
+		err = errors.New("no DNSKEY records found (zone is not signed)")
+		result.addLink(link, err)
+		return
+	}
+
+	// Find the keys that match a DS record
+	var allKeys, secureEntryPoints []*dns.DNSKEY
+	var rejected error
+	for _, rr := range records {
+		key := rr.(*dns.DNSKEY) //nolint:forcetypeassert // filtered by type in queryDNSSEC
+
+		// Only zone keys that are not revoked can validate RRsets (RFC 4034 section 2.1.1 & RFC 5011 section 2.1)
+		if key.Flags&dns.ZONE == 0 || key.Flags&dns.REVOKE != 0 {
+			if matchesDS(key, ds) {
+				if key.Flags&dns.REVOKE != 0 {
+					rejected = fmt.Errorf("DNSKEY (key tag %d) matching the DS records is revoked", key.KeyTag())
+				} else {
+					rejected = fmt.Errorf("DNSKEY (key tag %d) matching the DS records is not a zone key", key.KeyTag())
+				}
+			}
+			continue
+		}
+		allKeys = append(allKeys, key)
+		if matchesDS(key, ds) {
+			secureEntryPoints = append(secureEntryPoints, key)
+		}
+	}
+	if len(secureEntryPoints) == 0 {
+		if err = rejected; err == nil {
+			err = errors.New("no DNSKEY matches the DS records")
+		}
+		result.addLink(link, err)
+		return
+	}
+
+	// The DNSKEY RRset must be signed by a key matching the DS records
+	if link.Signer, err = verifyRRSet(records, sigs, secureEntryPoints, zone); !result.addLink(link, err) {
+		return
+	}
+
+	keys = allKeys
+	return
+}
+
+// matchesDS will return true if the key matches one of the DS records (key tag, algorithm & digest)
+func matchesDS(key *dns.DNSKEY, ds []*dns.DS) bool {
+	for _, record := range ds {
+		if record.KeyTag != key.KeyTag() || record.Algorithm != key.Algorithm {
+			continue
+		}
+		if calculated := key.ToDS(record.DigestType); calculated != nil && strings.EqualFold(calculated.Digest, record.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyRRSet will verify the RRset using the RRSIGs from the signer zone and the given keys
+//
+// Returns the signer (zone & key tag) that validated the RRset
+func verifyRRSet(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY, signerZone string) (signer string, err error) {
+	if len(sigs) == 0 {
+		err = errors.New("missing RRSIG records")
+		return
+	}
+
+	err = fmt.Errorf("no RRSIG records from %s", signerZone)
+	now := time.Now()
+	for _, sig := range sigs {
+		if !strings.EqualFold(dns.Fqdn(sig.SignerName), dns.Fqdn(signerZone)) {
+			continue
+		}
+		for _, key := range keys {
+			if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm ||
+				!strings.EqualFold(key.Hdr.Name, sig.SignerName) {
+				continue
+			}
+			if !sig.ValidityPeriod(now) {
+				err = fmt.Errorf("RRSIG by %s (key tag %d) is expired or not yet valid", sig.SignerName, sig.KeyTag)
+				continue
+			}
+			if verifyErr := sig.Verify(key, rrset); verifyErr != nil {
+				err = fmt.Errorf("RRSIG by %s (key tag %d) failed verification: %w", sig.SignerName, sig.KeyTag, verifyErr)
+				continue
+			}
+			signer = fmt.Sprintf("%s (key tag %d)", sig.SignerName, sig.KeyTag)
+			err = nil
+			return
+		}
+	}
+	return
+}
+
+// childZones will return the names between the ancestor (excluded) and the name (included)
+//
+// IE: com. & paymail.example.com. = [example.com. paymail.example.com.]
+func childZones(ancestor, name string) (zones []string) {
+	labels := dns.SplitDomainName(name)
+	ancestorLabels := dns.CountLabel(ancestor)
+	for i := len(labels) - ancestorLabels - 1; i >= 0; i-- {
+		zones = append(zones, dns.Fqdn(strings.Join(labels[i:], ".")))
+	}
+	return
+}
+
+// dnssecAnswer is the answer of a DNSSEC query, the RRset & RRSIGs, or the denial of existence
+// (NSEC or NSEC3 records & RRSIGs from the authority section) for a negative answer
+type dnssecAnswer struct {
+	denial     []dns.RR     // NSEC or NSEC3 records
+	denialSigs []*dns.RRSIG // RRSIGs of the NSEC or NSEC3 records
+	nameError  bool         // The name does not exist (NXDOMAIN)
+	rrset      []dns.RR     // Records of the type
+	sigs       []*dns.RRSIG // RRSIGs of the records
+}
+
+// queryDNSSEC will query the name server for the RRset & RRSIGs (DNSSEC OK, checking disabled)
+func (c *Client) queryDNSSEC(ctx context.Context, name string, qType uint16) (answer *dnssecAnswer, err error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qType)
+	m.SetEdns0(4096, true)
+	m.RecursionDesired = true
+	m.CheckingDisabled = true
+
+	client := &dns.Client{Net: c.options.nameServerNetwork, Timeout: c.options.dnsTimeout}
+	var in *dns.Msg
+	if in, _, err = client.ExchangeContext(
+		ctx, m, net.JoinHostPort(c.options.nameServer, c.options.dnsPort),
+	); err != nil {
+		return
+	} else if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+		err = fmt.Errorf("query for %s %s failed: %s", name, dns.TypeToString[qType], dns.RcodeToString[in.Rcode])
+		return
+	}
+
+	answer = &dnssecAnswer{nameError: in.Rcode == dns.RcodeNameError}
+	for _, rr := range in.Answer {
+		if !strings.EqualFold(rr.Header().Name, dns.Fqdn(name)) {
+			continue
+		}
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			if sig.TypeCovered == qType {
+				answer.sigs = append(answer.sigs, sig)
+			}
+		} else if rr.Header().Rrtype == qType {
+			answer.rrset = append(answer.rrset, rr)
+		}
+	}
+
+	// The denial of existence is only used for negative answers
+	if len(answer.rrset) > 0 {
+		return
+	}
+	for _, rr := range in.Ns {
+		switch record := rr.(type) {
+		case *dns.NSEC, *dns.NSEC3:
+			answer.denial = append(answer.denial, rr)
+		case *dns.RRSIG:
+			if record.TypeCovered == dns.TypeNSEC || record.TypeCovered == dns.TypeNSEC3 {
+				answer.denialSigs = append(answer.denialSigs, record)
+			}
+		}
+	}
+	return
+}
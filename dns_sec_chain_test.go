@@ -0,0 +1,563 @@
+package paymail
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigningKey is a DNSSEC key (and private key) used to sign the test zones
+type testSigningKey struct {
+	key    *dns.DNSKEY
+	signer crypto.Signer
+}
+
+// testSignedZones are the signed records for the test zones (test. & example.test.)
+type testSignedZones struct {
+	anchor     *dns.DS
+	denials    map[string][]dns.RR
+	exampleKSK *testSigningKey
+	exampleZSK *testSigningKey
+	nameErrors map[string]bool
+	records    map[string][]dns.RR
+	testKey    *testSigningKey
+}
+
+// newTestSigningKey will generate a new signing key for the zone
+func newTestSigningKey(t testing.TB, zone string, flags uint16) *testSigningKey {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	privateKey, err := key.Generate(256)
+	require.NoError(t, err)
+	signer, ok := privateKey.(crypto.Signer)
+	require.True(t, ok)
+	return &testSigningKey{key: key, signer: signer}
+}
+
+// sign will return the RRSIG for the RRset (valid for an hour either side of now)
+func (k *testSigningKey) sign(t testing.TB, validFrom time.Time, rrset ...dns.RR) *dns.RRSIG {
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: rrset[0].Header().Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 3600},
+		Algorithm:  k.key.Algorithm,
+		Expiration: uint32(validFrom.Add(2 * time.Hour).Unix()),
+		Inception:  uint32(validFrom.Unix()),
+		KeyTag:     k.key.KeyTag(),
+		SignerName: k.key.Hdr.Name,
+	}
+	require.NoError(t, sig.Sign(k.signer, rrset))
+	return sig
+}
+
+// srvRecord will return a bsvalias SRV record for the name
+func srvRecord(name string, port uint16) *dns.SRV {
+	return &dns.SRV{
+		Hdr:      dns.RR_Header{Name: "_bsvalias._tcp." + name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 3600},
+		Port:     port,
+		Priority: DefaultPriority,
+		Target:   "www." + name,
+		Weight:   DefaultWeight,
+	}
+}
+
+// nsecRecord will return an NSEC record with the types
+func nsecRecord(owner, next string, types ...uint16) *dns.NSEC {
+	return &dns.NSEC{
+		Hdr:        dns.RR_Header{Name: owner, Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 3600},
+		NextDomain: next,
+		TypeBitMap: types,
+	}
+}
+
+// nsec3Record will return an NSEC3 record (no salt or iterations) that matches the name
+// and covers every other name in the zone (the next hashed name is the owner)
+func nsec3Record(zone, name string, flags uint8, types ...uint16) *dns.NSEC3 {
+	hash := dns.HashName(name, dns.SHA1, 0, "")
+	return &dns.NSEC3{
+		Hdr:        dns.RR_Header{Name: hash + "." + zone, Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 3600},
+		Flags:      flags,
+		Hash:       dns.SHA1,
+		HashLength: 20,
+		NextDomain: hash,
+		TypeBitMap: types,
+	}
+}
+
+// newTestSignedZones will create the signed zones:
+//
+// test. (trust anchor, single key), example.test. (KSK & ZSK) and paymail.example.test. (not a zone cut)
+func newTestSignedZones(t testing.TB) *testSignedZones {
+	z := &testSignedZones{
+		denials:    make(map[string][]dns.RR),
+		exampleKSK: newTestSigningKey(t, "example.test.", 257),
+		exampleZSK: newTestSigningKey(t, "example.test.", 256),
+		nameErrors: make(map[string]bool),
+		records:    make(map[string][]dns.RR),
+		testKey:    newTestSigningKey(t, "test.", 257),
+	}
+	z.anchor = z.testKey.key.ToDS(dns.SHA256)
+	now := time.Now().Add(-time.Hour)
+
+	// test. (signed by the trust anchor key)
+	z.set("test.", dns.TypeDNSKEY, z.testKey.key, z.testKey.sign(t, now, z.testKey.key))
+
+	// example.test. (DS signed by test., DNSKEY signed by the KSK)
+	ds := z.exampleKSK.key.ToDS(dns.SHA256)
+	ds.Hdr.Ttl = 3600
+	z.set("example.test.", dns.TypeDS, ds, z.testKey.sign(t, now, ds))
+	z.set("example.test.", dns.TypeDNSKEY, z.exampleKSK.key, z.exampleZSK.key,
+		z.exampleKSK.sign(t, now, z.exampleKSK.key, z.exampleZSK.key))
+
+	// SRV records (signed by the ZSK)
+	srv := srvRecord("example.test.", DefaultPort)
+	z.set(srv.Hdr.Name, dns.TypeSRV, srv, z.exampleZSK.sign(t, now, srv))
+	subSRV := srvRecord("paymail.example.test.", DefaultPort)
+	z.set(subSRV.Hdr.Name, dns.TypeSRV, subSRV, z.exampleZSK.sign(t, now, subSRV))
+
+	// paymail.example.test. is an empty non-terminal (no DS records, proven by the ZSK)
+	z.deny(t, z.exampleZSK, "paymail.example.test.", dns.TypeDS, false,
+		nsecRecord("_bsvalias._tcp.example.test.", "_bsvalias._tcp.paymail.example.test.", dns.TypeSRV, dns.TypeRRSIG, dns.TypeNSEC))
+
+	return z
+}
+
+// set will set the answer for the name & type
+func (z *testSignedZones) set(name string, qType uint16, records ...dns.RR) {
+	z.records[name+"/"+dns.TypeToString[qType]] = records
+}
+
+// deny will set the denial of existence (signed by the key) for the name & type
+func (z *testSignedZones) deny(t testing.TB, k *testSigningKey, name string, qType uint16, nameError bool, records ...dns.RR) {
+	var authority []dns.RR
+	for _, rr := range records {
+		authority = append(authority, rr, k.sign(t, time.Now().Add(-time.Hour), rr))
+	}
+	z.denials[name+"/"+dns.TypeToString[qType]] = authority
+	z.nameErrors[name+"/"+dns.TypeToString[qType]] = nameError
+}
+
+// setExampleKSK will replace the KSK of example.test. (DS & DNSKEY records)
+func (z *testSignedZones) setExampleKSK(t testing.TB, ksk *testSigningKey) {
+	now := time.Now().Add(-time.Hour)
+	z.exampleKSK = ksk
+	ds := ksk.key.ToDS(dns.SHA256)
+	ds.Hdr.Ttl = 3600
+	z.set("example.test.", dns.TypeDS, ds, z.testKey.sign(t, now, ds))
+	z.set("example.test.", dns.TypeDNSKEY, ksk.key, z.exampleZSK.key, ksk.sign(t, now, ksk.key, z.exampleZSK.key))
+}
+
+// client will start the DNS server and return a client using the server & the trust anchor
+func (z *testSignedZones) client(t testing.TB) ClientInterface {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Authoritative = true
+			q := r.Question[0]
+			key := strings.ToLower(q.Name) + "/" + dns.TypeToString[q.Qtype]
+			m.Answer = z.records[key]
+			m.Ns = z.denials[key]
+			if z.nameErrors[key] {
+				m.Rcode = dns.RcodeNameError
+			}
+			_ = w.WriteMsg(m)
+		}),
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	_, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	testT, _ := t.(*testing.T)
+	return newTestClient(testT,
+		WithNameServer("127.0.0.1"),
+		WithDNSPort(port),
+		WithDNSTimeout(5*time.Second),
+		WithDNSSECTrustAnchors(z.anchor),
+	)
+}
+
+// TestClient_CheckDNSSECChain will test the method CheckDNSSECChain()
+func TestClient_CheckDNSSECChain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid chain", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		result := z.client(t).CheckDNSSECChain("example.test")
+		require.NotNil(t, result)
+		assert.Equal(t, "", result.ErrorMessage)
+		assert.Equal(t, true, result.Valid)
+		assert.Nil(t, result.FailedLink)
+		assert.Equal(t, "example.test", result.Domain)
+		assert.Equal(t, "test.", result.TrustAnchor)
+		require.Equal(t, 4, len(result.Links))
+		assert.Equal(t, DNSSECLinkDNSKEY, result.Links[0].Type)
+		assert.Equal(t, "test.", result.Links[0].Name)
+		assert.Equal(t, DNSSECLinkDS, result.Links[1].Type)
+		assert.Equal(t, fmt.Sprintf("test. (key tag %d)", z.testKey.key.KeyTag()), result.Links[1].Signer)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.Links[2].Type)
+		assert.Equal(t, fmt.Sprintf("example.test. (key tag %d)", z.exampleKSK.key.KeyTag()), result.Links[2].Signer)
+		assert.Equal(t, DNSSECLinkSRV, result.Links[3].Type)
+		assert.Equal(t, "_bsvalias._tcp.example.test.", result.Links[3].Name)
+		assert.Equal(t, fmt.Sprintf("example.test. (key tag %d)", z.exampleZSK.key.KeyTag()), result.Links[3].Signer)
+		for _, link := range result.Links {
+			assert.Equal(t, true, link.Valid)
+		}
+	})
+
+	t.Run("name that is not a zone cut", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		result := z.client(t).CheckDNSSECChain("Paymail.Example.Test.")
+		require.NotNil(t, result)
+		assert.Equal(t, true, result.Valid, result.ErrorMessage)
+		require.Equal(t, 5, len(result.Links))
+		assert.Equal(t, DNSSECLinkNSEC, result.Links[3].Type)
+		assert.Equal(t, "paymail.example.test.", result.Links[3].Name)
+		assert.Equal(t, fmt.Sprintf("example.test. (key tag %d)", z.exampleZSK.key.KeyTag()), result.Links[3].Signer)
+		assert.Equal(t, "_bsvalias._tcp.paymail.example.test.", result.Links[4].Name)
+	})
+
+	t.Run("name that is not a zone cut without proof", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.denials, "paymail.example.test./DS")
+		result := z.client(t).CheckDNSSECChain("paymail.example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkNSEC, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "missing NSEC or NSEC3 records")
+	})
+
+	t.Run("no srv records", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		z.deny(t, z.exampleZSK, "_bsvalias._tcp.example.test.", dns.TypeSRV, true,
+			nsecRecord("example.test.", "_bsvalias._tcp.paymail.example.test.",
+				dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeNSEC, dns.TypeDNSKEY))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, true, result.Valid, result.ErrorMessage)
+		assert.Equal(t, false, result.Insecure)
+		require.Equal(t, 4, len(result.Links))
+		assert.Equal(t, DNSSECLinkNSEC, result.Links[3].Type)
+	})
+
+	t.Run("no srv records without proof", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkNSEC, result.FailedLink.Type)
+		assert.Equal(t, "_bsvalias._tcp.example.test.", result.FailedLink.Name)
+	})
+
+	t.Run("srv proof does not cover the wildcard", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		z.deny(t, z.exampleZSK, "_bsvalias._tcp.example.test.", dns.TypeSRV, true,
+			nsecRecord("_a.example.test.", "_u.example.test.", dns.TypeTXT, dns.TypeRRSIG, dns.TypeNSEC))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Contains(t, result.FailedLink.Error, "no NSEC record denies the wildcard *.example.test.")
+	})
+
+	t.Run("srv proof has the srv type", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		z.deny(t, z.exampleZSK, "_bsvalias._tcp.example.test.", dns.TypeSRV, false,
+			nsecRecord("_bsvalias._tcp.example.test.", "example.test.", dns.TypeSRV, dns.TypeRRSIG, dns.TypeNSEC))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Contains(t, result.FailedLink.Error, "has the SRV type")
+	})
+
+	t.Run("srv proof signed by the wrong key", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		z.deny(t, newTestSigningKey(t, "example.test.", 256), "_bsvalias._tcp.example.test.", dns.TypeSRV, true,
+			nsecRecord("example.test.", "_bsvalias._tcp.paymail.example.test.",
+				dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeNSEC, dns.TypeDNSKEY))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkNSEC, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "no RRSIG records from example.test.")
+	})
+
+	t.Run("no srv records (nsec3)", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		z.deny(t, z.exampleZSK, "_bsvalias._tcp.example.test.", dns.TypeSRV, true,
+			nsec3Record("example.test.", "example.test.", 0,
+				dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeDNSKEY, dns.TypeNSEC3PARAM))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, true, result.Valid, result.ErrorMessage)
+		require.Equal(t, 4, len(result.Links))
+		assert.Equal(t, DNSSECLinkNSEC3, result.Links[3].Type)
+	})
+
+	t.Run("no srv records in an nsec3 opt-out span", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "_bsvalias._tcp.example.test./SRV")
+		z.deny(t, z.exampleZSK, "_bsvalias._tcp.example.test.", dns.TypeSRV, true,
+			nsec3Record("example.test.", "example.test.", nsec3OptOut,
+				dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeDNSKEY, dns.TypeNSEC3PARAM))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		assert.Equal(t, true, result.Insecure)
+		require.NotNil(t, result.FailedLink)
+		assert.Contains(t, result.FailedLink.Error, "opt-out")
+	})
+
+	t.Run("trust anchor does not match", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.anchor = newTestSigningKey(t, "test.", 257).key.ToDS(dns.SHA256)
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.FailedLink.Type)
+		assert.Equal(t, "test.", result.FailedLink.Name)
+		assert.Contains(t, result.FailedLink.Error, "no DNSKEY matches the DS records")
+	})
+
+	t.Run("tampered ds record", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.records["example.test./DS"][0].(*dns.DS).Digest = strings.Repeat("A", 64)
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDS, result.FailedLink.Type)
+		assert.Equal(t, "example.test.", result.FailedLink.Name)
+		assert.Contains(t, result.FailedLink.Error, "failed verification")
+	})
+
+	t.Run("ds does not match the zone keys", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		ds := newTestSigningKey(t, "example.test.", 257).key.ToDS(dns.SHA256)
+		z.set("example.test.", dns.TypeDS, ds, z.testKey.sign(t, time.Now().Add(-time.Hour), ds))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.FailedLink.Type)
+		assert.Equal(t, "example.test.", result.FailedLink.Name)
+		assert.Equal(t, 3, len(result.Links))
+	})
+
+	t.Run("dnskey signed by the wrong key", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.set("example.test.", dns.TypeDNSKEY, z.exampleKSK.key, z.exampleZSK.key,
+			z.exampleZSK.sign(t, time.Now().Add(-time.Hour), z.exampleKSK.key, z.exampleZSK.key))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "no RRSIG records from example.test.")
+	})
+
+	t.Run("insecure delegation", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "example.test./DS")
+		delete(z.records, "example.test./DNSKEY")
+		z.deny(t, z.testKey, "example.test.", dns.TypeDS, false,
+			nsecRecord("example.test.", "test.", dns.TypeNS, dns.TypeRRSIG, dns.TypeNSEC))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		assert.Equal(t, true, result.Insecure)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDS, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "insecure delegation")
+		require.Equal(t, 3, len(result.Links))
+		assert.Equal(t, DNSSECLinkNSEC, result.Links[1].Type)
+		assert.Empty(t, result.Links[1].Error)
+	})
+
+	t.Run("insecure delegation (nsec3 opt-out)", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "example.test./DS")
+		delete(z.records, "example.test./DNSKEY")
+		z.deny(t, z.testKey, "example.test.", dns.TypeDS, false,
+			nsec3Record("test.", "test.", nsec3OptOut,
+				dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeDNSKEY, dns.TypeNSEC3PARAM))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		assert.Equal(t, true, result.Insecure)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDS, result.FailedLink.Type)
+		assert.Equal(t, DNSSECLinkNSEC3, result.Links[1].Type)
+	})
+
+	t.Run("missing ds records without proof", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "example.test./DS")
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		assert.Equal(t, false, result.Insecure)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkNSEC, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "missing NSEC or NSEC3 records")
+	})
+
+	t.Run("ds proof has the ds type", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "example.test./DS")
+		z.deny(t, z.testKey, "example.test.", dns.TypeDS, false,
+			nsecRecord("example.test.", "test.", dns.TypeNS, dns.TypeDS, dns.TypeRRSIG, dns.TypeNSEC))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		assert.Equal(t, false, result.Insecure)
+		require.NotNil(t, result.FailedLink)
+		assert.Contains(t, result.FailedLink.Error, "has the DS type")
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.setExampleKSK(t, newTestSigningKey(t, "example.test.", 257|dns.REVOKE))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "is revoked")
+	})
+
+	t.Run("not a zone key", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.setExampleKSK(t, newTestSigningKey(t, "example.test.", dns.SEP))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "is not a zone key")
+	})
+
+	t.Run("delegated zone is not signed", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		delete(z.records, "example.test./DNSKEY")
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkDNSKEY, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "zone is not signed")
+	})
+
+	t.Run("tampered srv record", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.records["_bsvalias._tcp.example.test./SRV"][0].(*dns.SRV).Target = "evil.test."
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkSRV, result.FailedLink.Type)
+		assert.Equal(t, "_bsvalias._tcp.example.test.", result.FailedLink.Name)
+		assert.Contains(t, result.ErrorMessage, "SRV _bsvalias._tcp.example.test.: RRSIG by example.test.")
+	})
+
+	t.Run("expired srv signature", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		srv := srvRecord("example.test.", DefaultPort)
+		z.set(srv.Hdr.Name, dns.TypeSRV, srv, z.exampleZSK.sign(t, time.Now().Add(-3*time.Hour), srv))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkSRV, result.FailedLink.Type)
+		assert.Contains(t, result.FailedLink.Error, "expired or not yet valid")
+	})
+
+	t.Run("missing srv signature", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		z.set("_bsvalias._tcp.example.test.", dns.TypeSRV, srvRecord("example.test.", DefaultPort))
+		result := z.client(t).CheckDNSSECChain("example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, DNSSECLinkSRV, result.FailedLink.Type)
+		assert.Equal(t, "missing RRSIG records", result.FailedLink.Error)
+	})
+
+	t.Run("no trust anchor for the domain", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		result := z.client(t).CheckDNSSECChain("example.com")
+		assert.Equal(t, false, result.Valid)
+		assert.Nil(t, result.FailedLink)
+		assert.Equal(t, "no trust anchor found for example.com", result.ErrorMessage)
+	})
+
+	t.Run("missing domain", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		result := z.client(t).CheckDNSSECChain(" ")
+		assert.Equal(t, false, result.Valid)
+		assert.Equal(t, "missing domain", result.ErrorMessage)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		z := newTestSignedZones(t)
+		result := z.client(t).CheckDNSSECChainContext(canceledContext(), "example.test")
+		assert.Equal(t, false, result.Valid)
+		require.NotNil(t, result.FailedLink)
+		assert.Equal(t, "test.", result.FailedLink.Name)
+	})
+}
+
+// Test_childZones will test the method childZones()
+func Test_childZones(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"com.", "example.com."}, childZones(".", "example.com."))
+	assert.Equal(t, []string{"example.com.", "paymail.example.com."}, childZones("com.", "paymail.example.com."))
+	assert.Nil(t, childZones("example.com.", "example.com."))
+}
+
+// Test_defaultTrustAnchors will test the method defaultTrustAnchors()
+func Test_defaultTrustAnchors(t *testing.T) {
+	t.Parallel()
+
+	anchors := defaultTrustAnchors()
+	require.Equal(t, 2, len(anchors))
+	assert.Equal(t, ".", anchors[0].Hdr.Name)
+	assert.Equal(t, uint16(20326), anchors[0].KeyTag)
+	assert.Equal(t, uint16(38696), anchors[1].KeyTag)
+}
+
+// ExampleClient_CheckDNSSECChain example using CheckDNSSECChain()
+//
+// See more examples in /examples/
+func ExampleClient_CheckDNSSECChain() {
+	// Load the client (uses the root zone trust anchors)
+	client := newTestClient(nil, WithDNSSECTrustAnchors())
+
+	// Validate the chain of trust
+	result := client.CheckDNSSECChainContext(canceledContext(), "")
+	if !result.Valid {
+		fmt.Printf("invalid DNSSEC chain: %s", result.ErrorMessage)
+		return
+	}
+	fmt.Printf("valid DNSSEC chain found for: %s", result.Domain)
+	// Output:invalid DNSSEC chain: missing domain
+}
+
+// BenchmarkClient_CheckDNSSECChain benchmarks the method CheckDNSSECChain()
+func BenchmarkClient_CheckDNSSECChain(b *testing.B) {
+	z := newTestSignedZones(b)
+	client := z.client(b)
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		_ = client.CheckDNSSECChainContext(ctx, "example.test")
+	}
+}
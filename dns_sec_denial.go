@@ -0,0 +1,294 @@
+package paymail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// nsec3OptOut is the opt-out flag of an NSEC3 record
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc5155#section-3.1.2.1
+const nsec3OptOut = 1
+
+// denialProof is a validated denial of existence (NSEC or NSEC3)
+type denialProof struct {
+	delegation bool // The name is a delegation without DS records (unsigned, insecure)
+	optOut     bool // Only denied by an NSEC3 opt-out span (unsigned names may exist, insecure)
+}
+
+// verifyDenial will validate the NSEC or NSEC3 records (signed by the zone) of a negative answer,
+// proving that the name does not exist or does not have any records of the type
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc4035#section-5.4 & https://www.rfc-editor.org/rfc/rfc5155#section-8
+func verifyDenial(answer *dnssecAnswer, name string, qType uint16, keys []*dns.DNSKEY,
+	zone string, link *DNSSECLink) (proof *denialProof, err error) {
+
+	// A negative answer must have NSEC or NSEC3 records
+	if len(answer.denial) == 0 {
+		err = errors.New("missing NSEC or NSEC3 records (no denial of existence)")
+		return
+	}
+
+	// Every NSEC & NSEC3 RRset must be from the zone and signed by the zone
+	var nsecs []*dns.NSEC
+	var nsec3s []*dns.NSEC3
+	for _, rr := range answer.denial {
+		owner := rr.Header().Name
+		if !dns.IsSubDomain(zone, owner) {
+			err = fmt.Errorf("%s record %s is not in zone %s", dns.TypeToString[rr.Header().Rrtype], owner, zone)
+			return
+		}
+		var sigs []*dns.RRSIG
+		for _, sig := range answer.denialSigs {
+			if sig.TypeCovered == rr.Header().Rrtype && strings.EqualFold(sig.Hdr.Name, owner) {
+				sigs = append(sigs, sig)
+			}
+		}
+		var signer string
+		if signer, err = verifyRRSet([]dns.RR{rr}, sigs, keys, zone); err != nil {
+			err = fmt.Errorf("%s %s: %w", dns.TypeToString[rr.Header().Rrtype], owner, err)
+			return
+		} else if len(link.Signer) == 0 {
+			link.Signer = signer
+		}
+		switch record := rr.(type) {
+		case *dns.NSEC:
+			nsecs = append(nsecs, record)
+		case *dns.NSEC3:
+			nsec3s = append(nsec3s, record)
+		}
+	}
+
+	// Zones use either NSEC3 (hashed names) or NSEC
+	if len(nsec3s) > 0 {
+		link.Type = DNSSECLinkNSEC3
+		return denyNSEC3(nsec3s, name, qType, answer.nameError, zone)
+	}
+	link.Type = DNSSECLinkNSEC
+	return denyNSEC(nsecs, name, qType, answer.nameError)
+}
+
+// denyNSEC will return the proof from the NSEC records
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc4035#section-5.4
+func denyNSEC(nsecs []*dns.NSEC, name string, qType uint16, nameError bool) (*denialProof, error) {
+
+	// No data: the NSEC of the name does not have the type
+	for _, nsec := range nsecs {
+		if !strings.EqualFold(nsec.Hdr.Name, name) {
+			continue
+		} else if nameError {
+			return nil, fmt.Errorf("NSEC for %s contradicts the name error", name)
+		} else if hasType(nsec.TypeBitMap, qType) || hasType(nsec.TypeBitMap, dns.TypeCNAME) {
+			return nil, fmt.Errorf("NSEC for %s has the %s type", name, dns.TypeToString[qType])
+		}
+		return &denialProof{delegation: qType == dns.TypeDS && isDelegation(nsec.TypeBitMap)}, nil
+	}
+
+	// Otherwise, the name must be covered by an NSEC
+	cover := nsecCovering(nsecs, name)
+	if cover == nil {
+		return nil, fmt.Errorf("no NSEC record covers %s", name)
+	}
+
+	// No data for an empty non-terminal (the next name is below the name)
+	if !nameError {
+		if dns.IsSubDomain(name, cover.NextDomain) {
+			return &denialProof{}, nil
+		}
+		return nil, fmt.Errorf("no NSEC record matches %s", name)
+	}
+
+	// The name does not exist, and neither does the wildcard at the closest encloser
+	wildcard := "*." + nsecClosestEncloser(name, cover)
+	if nsecCovering(nsecs, wildcard) == nil {
+		return nil, fmt.Errorf("no NSEC record denies the wildcard %s", wildcard)
+	}
+	return &denialProof{}, nil
+}
+
+// denyNSEC3 will return the proof from the NSEC3 records
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc5155#section-8
+func denyNSEC3(nsec3s []*dns.NSEC3, name string, qType uint16, nameError bool, zone string) (*denialProof, error) {
+
+	// No data: the NSEC3 of the name does not have the type
+	if match := nsec3Matching(nsec3s, name); match != nil {
+		if nameError {
+			return nil, fmt.Errorf("NSEC3 for %s contradicts the name error", name)
+		} else if hasType(match.TypeBitMap, qType) || hasType(match.TypeBitMap, dns.TypeCNAME) {
+			return nil, fmt.Errorf("NSEC3 for %s has the %s type", name, dns.TypeToString[qType])
+		}
+		return &denialProof{delegation: qType == dns.TypeDS && isDelegation(match.TypeBitMap)}, nil
+	}
+
+	// Otherwise, the closest encloser must exist & the next closer name must be covered
+	closestEncloser, nextCloser, err := nsec3ClosestEncloser(nsec3s, name, zone)
+	if err != nil {
+		return nil, err
+	}
+	cover := nsec3Covering(nsec3s, nextCloser)
+	if cover == nil {
+		return nil, fmt.Errorf("no NSEC3 record covers the next closer name %s", nextCloser)
+	}
+
+	// An opt-out span does not prove anything (unsigned delegations are not listed)
+	if cover.Flags&nsec3OptOut != 0 {
+		return &denialProof{delegation: qType == dns.TypeDS, optOut: true}, nil
+	} else if !nameError {
+		return nil, fmt.Errorf("no NSEC3 record matches %s", name)
+	}
+
+	// The name does not exist, and neither does the wildcard at the closest encloser
+	wildcard := "*." + closestEncloser
+	if nsec3Covering(nsec3s, wildcard) == nil {
+		return nil, fmt.Errorf("no NSEC3 record denies the wildcard %s", wildcard)
+	}
+	return &denialProof{}, nil
+}
+
+// nsecCovering will return the NSEC that covers the name (nil if not found)
+//
+// NSEC records at a delegation (or DNAME) cannot deny the names below it (RFC 6840 section 4.1)
+func nsecCovering(nsecs []*dns.NSEC, name string) *dns.NSEC {
+	for _, nsec := range nsecs {
+		owner, next := nsec.Hdr.Name, nsec.NextDomain
+		if dns.IsSubDomain(owner, name) && !strings.EqualFold(owner, name) &&
+			(isDelegation(nsec.TypeBitMap) || hasType(nsec.TypeBitMap, dns.TypeDNAME)) {
+			continue
+		}
+		if canonicalCompare(owner, next) < 0 {
+			if canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0 {
+				return nsec
+			}
+		} else if canonicalCompare(owner, name) < 0 && dns.IsSubDomain(next, name) {
+			return nsec // The last NSEC of the zone (the next name is the zone apex)
+		}
+	}
+	return nil
+}
+
+// nsecClosestEncloser will return the closest existing ancestor of the name, from the covering NSEC
+func nsecClosestEncloser(name string, cover *dns.NSEC) string {
+	count := dns.CompareDomainName(name, cover.Hdr.Name)
+	if next := dns.CompareDomainName(name, cover.NextDomain); next > count {
+		count = next
+	}
+	labels := dns.SplitDomainName(name)
+	return dns.Fqdn(strings.Join(labels[len(labels)-count:], "."))
+}
+
+// nsec3Matching will return the NSEC3 that matches the name (nil if not found)
+func nsec3Matching(nsec3s []*dns.NSEC3, name string) *dns.NSEC3 {
+	for _, nsec3 := range nsec3s {
+		if nsec3.Match(name) {
+			return nsec3
+		}
+	}
+	return nil
+}
+
+// nsec3Covering will return the NSEC3 that covers the name (nil if not found)
+func nsec3Covering(nsec3s []*dns.NSEC3, name string) *dns.NSEC3 {
+	for _, nsec3 := range nsec3s {
+		if nsec3.Cover(name) {
+			return nsec3
+		}
+	}
+	return nil
+}
+
+// nsec3ClosestEncloser will return the closest ancestor of the name with a matching NSEC3
+// (up to the zone apex) and the next closer name (one label longer)
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc5155#section-8.3
+func nsec3ClosestEncloser(nsec3s []*dns.NSEC3, name, zone string) (closestEncloser, nextCloser string, err error) {
+	labels := dns.SplitDomainName(name)
+	for i := 1; i <= len(labels); i++ {
+		candidate := dns.Fqdn(strings.Join(labels[i:], "."))
+		if !dns.IsSubDomain(zone, candidate) {
+			break
+		}
+		match := nsec3Matching(nsec3s, candidate)
+		if match == nil {
+			continue
+		}
+
+		// The closest encloser cannot be a delegation (or DNAME) from the zone
+		if !strings.EqualFold(candidate, zone) &&
+			(isDelegation(match.TypeBitMap) || hasType(match.TypeBitMap, dns.TypeDNAME)) {
+			err = fmt.Errorf("NSEC3 closest encloser %s is a delegation", candidate)
+			return
+		}
+		closestEncloser = candidate
+		nextCloser = dns.Fqdn(strings.Join(labels[i-1:], "."))
+		return
+	}
+	err = fmt.Errorf("no NSEC3 closest encloser found for %s", name)
+	return
+}
+
+// isDelegation will return true if the type bitmap is for a delegation (NS without SOA)
+func isDelegation(bitmap []uint16) bool {
+	return hasType(bitmap, dns.TypeNS) && !hasType(bitmap, dns.TypeSOA)
+}
+
+// hasType will return true if the type bitmap has the type
+func hasType(bitmap []uint16, qType uint16) bool {
+	for _, t := range bitmap {
+		if t == qType {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalCompare will compare the names in the canonical DNS name order
+// (labels from right to left, case-insensitive), returns -1, 0 or 1
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc4034#section-6.1
+func canonicalCompare(a, b string) int {
+	aLabels := dns.SplitDomainName(a)
+	bLabels := dns.SplitDomainName(b)
+	for i, j := len(aLabels)-1, len(bLabels)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := bytes.Compare(canonicalLabel(aLabels[i]), canonicalLabel(bLabels[j])); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(aLabels) < len(bLabels):
+		return -1
+	case len(aLabels) > len(bLabels):
+		return 1
+	}
+	return 0
+}
+
+// canonicalLabel will return the wire format of the label (escapes decoded, US-ASCII letters in lowercase)
+func canonicalLabel(label string) []byte {
+	raw := make([]byte, 0, len(label))
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if c == '\\' && i+3 < len(label) && isDigit(label[i+1]) && isDigit(label[i+2]) && isDigit(label[i+3]) {
+			c = (label[i+1]-'0')*100 + (label[i+2]-'0')*10 + (label[i+3] - '0')
+			i += 3
+		} else if c == '\\' && i+1 < len(label) {
+			i++
+			c = label[i]
+		}
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		raw = append(raw, c)
+	}
+	return raw
+}
+
+// isDigit will return true if the character is a decimal digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,68 @@
+package paymail
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_canonicalCompare will test the method canonicalCompare()
+func Test_canonicalCompare(t *testing.T) {
+	t.Parallel()
+
+	// Canonical order from RFC 4034 section 6.1
+	ordered := []string{
+		"example.",
+		"a.example.",
+		"yljkjljk.a.example.",
+		"Z.a.example.",
+		"zABC.a.EXAMPLE.",
+		"z.example.",
+		"\\001.z.example.",
+		"*.z.example.",
+		"\\200.z.example.",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		assert.Equal(t, -1, canonicalCompare(ordered[i], ordered[i+1]), "%s < %s", ordered[i], ordered[i+1])
+		assert.Equal(t, 1, canonicalCompare(ordered[i+1], ordered[i]), "%s > %s", ordered[i+1], ordered[i])
+	}
+	assert.Equal(t, 0, canonicalCompare("Example.Test.", "example.test."))
+}
+
+// Test_nsecCovering will test the method nsecCovering()
+func Test_nsecCovering(t *testing.T) {
+	t.Parallel()
+
+	nsecs := []*dns.NSEC{
+		nsecRecord("example.test.", "b.example.test.", dns.TypeNS, dns.TypeSOA),
+		nsecRecord("b.example.test.", "d.example.test.", dns.TypeNS),
+		nsecRecord("d.example.test.", "example.test.", dns.TypeA),
+	}
+
+	var tests = []struct {
+		name          string
+		expectedOwner string
+	}{
+		{"a.example.test.", "example.test."},
+		{"c.example.test.", "b.example.test."},
+		{"z.example.test.", "d.example.test."},
+		{"a.d.example.test.", "d.example.test."},
+		{"b.example.test.", ""},
+		{"a.b.example.test.", ""},
+		{"other.test.", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cover := nsecCovering(nsecs, test.name)
+			if len(test.expectedOwner) == 0 {
+				assert.Nil(t, cover)
+				return
+			}
+			if assert.NotNil(t, cover) {
+				assert.Equal(t, test.expectedOwner, cover.Hdr.Name)
+			}
+		})
+	}
+}
@@ -45,6 +45,23 @@ func TestClient_CheckDNSSEC(t *testing.T) {
 	// todo: test results, test using mock interfaces for DNS resolving
 }
 
+// TestClient_CheckDNSSECContext will test the method CheckDNSSECContext()
+func TestClient_CheckDNSSECContext(t *testing.T) {
+
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestClient(t)
+
+	t.Run("canceled context", func(t *testing.T) {
+		result := client.CheckDNSSECContext(canceledContext(), "google.com")
+		if len(result.ErrorMessage) == 0 {
+			t.Errorf("%s Failed: error was expected for a canceled context", t.Name())
+		} else if result.DNSSEC {
+			t.Errorf("%s Failed: DNSSEC should not be true for a canceled context", t.Name())
+		}
+	})
+}
+
 // ExampleClient_CheckDNSSEC example using CheckDNSSEC()
 //
 // See more examples in /examples/
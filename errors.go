@@ -0,0 +1,268 @@
+package paymail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Error codes returned in the ServerError from a paymail server
+//
+// Specs: http://bsvalias.org/99-01-recommendations.html
+const (
+	ErrorCodeApprovalNotFound    = "approval-not-found"
+	ErrorCodeContactNotFound     = "contact-not-found"
+	ErrorCodeFindingPaymail      = "error-finding-paymail"
+	ErrorCodeInvalidBeef         = "invalid-beef"
+	ErrorCodeInvalidDt           = "invalid-dt"
+	ErrorCodeInvalidParameter    = "invalid-parameter"
+	ErrorCodeInvalidPubKey       = "invalid-pubkey"
+	ErrorCodeInvalidSenderHandle = "invalid-sender-handle"
+	ErrorCodeInvalidSignature    = "invalid-signature"
+	ErrorCodeMethodNotFound      = "method-405"
+	ErrorCodeMissingHex          = "missing-hex"
+	ErrorCodeMissingReference    = "missing-reference"
+	ErrorCodeMissingSatoshis     = "missing-satoshis"
+	ErrorCodePaymailNotFound     = "not-found"
+	ErrorCodePaymentNotApproved  = "payment-not-approved"
+	ErrorCodeRecordingTx         = "error-recording-tx"
+	ErrorCodeReplayedRequest     = "replayed-request"
+	ErrorCodeRequestNotFound     = "request-404"
+	ErrorCodeScript              = "script-error"
+	ErrorCodeUnknownDomain       = "unknown-domain"
+)
+
+// Steps reported in ProviderError.Step
+const (
+	StepAddressResolution   = "address_resolution"
+	StepCapabilities        = "capabilities"
+	StepHandleLookup        = "handle_lookup"
+	StepP2PDestination      = "p2p_payment_destination"
+	StepP2PSendTransaction  = "p2p_send_transaction"
+	StepP2PTokenDestination = "p2p_token_payment_destination"
+	StepPikeInvite          = "pike_invite"
+	StepPikeOutputs         = "pike_outputs"
+	StepPKI                 = "pki"
+	StepPublicProfile       = "public_profile"
+	StepReceiverApprovals   = "receiver_approvals"
+	StepSFPAssetInformation = "sfp_asset_information"
+	StepSFPAuthorise        = "sfp_authorise"
+	StepSFPBuild            = "sfp_build"
+	StepSRV                 = "srv"
+	StepSSL                 = "ssl"
+	StepVerifyPubKey        = "verify_pubkey"
+)
+
+var (
+	// ErrPaymailNotFound is when the paymail address was not found by the provider
+	ErrPaymailNotFound = errors.New("paymail address not found")
+
+	// ErrUnknownDomain is when the domain is not handled by the provider
+	ErrUnknownDomain = errors.New("unknown domain")
+
+	// ErrInvalidParameter is when the provider rejected a parameter in the request
+	ErrInvalidParameter = errors.New("invalid parameter")
+
+	// ErrInvalidDt is when the provider rejected the dt (timestamp) in the request
+	ErrInvalidDt = errors.New("invalid dt")
+
+	// ErrInvalidPubKey is when the provider rejected the pubkey in the request
+	ErrInvalidPubKey = errors.New("invalid pubkey")
+
+	// ErrInvalidSenderHandle is when the provider rejected the sender handle in the request
+	ErrInvalidSenderHandle = errors.New("invalid sender handle")
+
+	// ErrInvalidSignature is when the provider rejected the signature in the request
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrApprovalNotFound is when the payment approval was not found by the provider
+	ErrApprovalNotFound = errors.New("payment approval not found")
+
+	// ErrContactNotFound is when the sender is not a contact of the receiver (PIKE)
+	ErrContactNotFound = errors.New("contact not found")
+
+	// ErrPaymentNotApproved is when the receiver requires an approved payment before issuing a destination
+	ErrPaymentNotApproved = errors.New("payment not approved by the receiver")
+
+	// ErrReplayedRequest is when the provider already processed the signed request (replay protection)
+	ErrReplayedRequest = errors.New("request was already processed by the provider")
+
+	// ErrInvalidTransaction is when the provider rejected the transaction (script error)
+	ErrInvalidTransaction = errors.New("invalid transaction")
+
+	// ErrNotSupported is when the provider does not support the request (route or method not found)
+	ErrNotSupported = errors.New("request not supported by paymail provider")
+
+	// ErrProviderFailure is when the provider failed to process the request (internal error)
+	ErrProviderFailure = errors.New("paymail provider failure")
+
+	// ErrInvalidResponse is when the provider returned an unexpected status or an invalid body
+	ErrInvalidResponse = errors.New("invalid response from paymail provider")
+
+	// ErrDNS is when a DNS lookup failed or returned an invalid record
+	ErrDNS = errors.New("dns lookup failed")
+
+	// ErrTLS is when the TLS handshake or certificate verification failed
+	ErrTLS = errors.New("tls failure")
+
+	// ErrTimeout is when the request timed out (or the context deadline was exceeded)
+	ErrTimeout = errors.New("request timed out")
+
+	// ErrRequestFailed is when the request failed for any other reason (IE: connection refused)
+	ErrRequestFailed = errors.New("request failed")
+)
+
+// errorCodes maps the server error codes onto the sentinel errors
+var errorCodes = map[string]error{
+	ErrorCodeApprovalNotFound:    ErrApprovalNotFound,
+	ErrorCodeContactNotFound:     ErrContactNotFound,
+	ErrorCodeFindingPaymail:      ErrProviderFailure,
+	ErrorCodeInvalidBeef:         ErrInvalidTransaction,
+	ErrorCodeInvalidDt:           ErrInvalidDt,
+	ErrorCodeInvalidParameter:    ErrInvalidParameter,
+	ErrorCodeInvalidPubKey:       ErrInvalidPubKey,
+	ErrorCodeInvalidSenderHandle: ErrInvalidSenderHandle,
+	ErrorCodeInvalidSignature:    ErrInvalidSignature,
+	ErrorCodeMethodNotFound:      ErrNotSupported,
+	ErrorCodeMissingHex:          ErrInvalidParameter,
+	ErrorCodeMissingReference:    ErrInvalidParameter,
+	ErrorCodeMissingSatoshis:     ErrInvalidParameter,
+	ErrorCodePaymailNotFound:     ErrPaymailNotFound,
+	ErrorCodePaymentNotApproved:  ErrPaymentNotApproved,
+	ErrorCodeRecordingTx:         ErrProviderFailure,
+	ErrorCodeReplayedRequest:     ErrReplayedRequest,
+	ErrorCodeRequestNotFound:     ErrNotSupported,
+	ErrorCodeScript:              ErrInvalidTransaction,
+	ErrorCodeUnknownDomain:       ErrUnknownDomain,
+}
+
+// ErrorForCode will return the sentinel error for a server error code (nil if unknown)
+func ErrorForCode(code string) error {
+	return errorCodes[code]
+}
+
+// ProviderError is the error returned when a request to a paymail provider fails
+//
+// Use errors.Is() with the sentinel errors (IE: ErrPaymailNotFound, ErrTimeout)
+// or errors.As() to get the status code, server error and the url of the request
+type ProviderError struct {
+	Cause      error  // The underlying error (IE: from the http client or json decoding)
+	Code       string // Code from the ServerError (if returned)
+	Err        error  // The sentinel error (IE: ErrPaymailNotFound)
+	Message    string // Message from the ServerError (if returned)
+	StatusCode int    // Status code returned by the provider (0 if no response)
+	Step       string // The step that failed (IE: StepPKI)
+	URL        string // The url of the request (or the host/domain)
+}
+
+// Error will return the error message
+func (e *ProviderError) Error() string {
+	switch {
+	case e.Cause != nil:
+		return e.Cause.Error()
+	case e.StatusCode > 0 && len(e.Message) > 0:
+		return fmt.Sprintf("bad response from paymail provider: code %d, message: %s", e.StatusCode, e.Message)
+	case e.StatusCode > 0:
+		return fmt.Sprintf("%s: code %d", e.Err, e.StatusCode)
+	case len(e.Message) > 0:
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return "paymail provider error"
+}
+
+// Is will return true if the target is the sentinel error
+func (e *ProviderError) Is(target error) bool {
+	return e.Err != nil && e.Err == target //nolint:errorlint // sentinel errors are compared directly
+}
+
+// Unwrap will return the underlying error
+func (e *ProviderError) Unwrap() error {
+	return e.Cause
+}
+
+// newRequestError will return a ProviderError for a request that failed without a response
+func newRequestError(step, requestURL string, err error) error {
+	return &ProviderError{
+		Cause: err,
+		Err:   classifyError(err),
+		Step:  step,
+		URL:   requestURL,
+	}
+}
+
+// newResponseError will return a ProviderError for a bad status code
+//
+// The ServerError (code & message) is decoded from the body if found
+func newResponseError(step, requestURL string, statusCode int, body []byte) error {
+	providerErr := &ProviderError{
+		StatusCode: statusCode,
+		Step:       step,
+		URL:        requestURL,
+	}
+
+	// Decode the server error (if any)
+	serverError := &ServerError{}
+	if json.Unmarshal(body, serverError) == nil {
+		providerErr.Code = serverError.Code
+		providerErr.Message = serverError.Message
+	}
+
+	// Use the code, otherwise fall back to the status code
+	if providerErr.Err = ErrorForCode(providerErr.Code); providerErr.Err == nil {
+		switch {
+		case statusCode == http.StatusNotFound && step != StepCapabilities:
+			providerErr.Err = ErrPaymailNotFound
+		case statusCode >= http.StatusInternalServerError:
+			providerErr.Err = ErrProviderFailure
+		default:
+			providerErr.Err = ErrInvalidResponse
+		}
+	}
+	return providerErr
+}
+
+// newInvalidResponseError will return a ProviderError for an invalid response body
+func newInvalidResponseError(step, requestURL string, statusCode int, err error) error {
+	return &ProviderError{
+		Cause:      err,
+		Err:        ErrInvalidResponse,
+		StatusCode: statusCode,
+		Step:       step,
+		URL:        requestURL,
+	}
+}
+
+// classifyError will return the sentinel error for a failed request (timeout, dns, tls, etc.)
+func classifyError(err error) error {
+	var (
+		dnsErr        *net.DNSError
+		hostnameErr   x509.HostnameError
+		invalidErr    x509.CertificateInvalidError
+		netErr        net.Error
+		recordErr     tls.RecordHeaderError
+		unknownCAErr  x509.UnknownAuthorityError
+		systemRootErr x509.SystemRootsError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout {
+			return ErrTimeout
+		}
+		return ErrDNS
+	case errors.As(err, &hostnameErr), errors.As(err, &invalidErr), errors.As(err, &unknownCAErr),
+		errors.As(err, &systemRootErr), errors.As(err, &recordErr):
+		return ErrTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	}
+	return ErrRequestFailed
+}
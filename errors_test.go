@@ -0,0 +1,281 @@
+package paymail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProviderError will test the ProviderError methods
+func TestProviderError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error messages", func(t *testing.T) {
+		var tests = []struct {
+			err      *ProviderError
+			expected string
+		}{
+			{&ProviderError{Cause: errors.New("cause"), Err: ErrTimeout}, "cause"},
+			{&ProviderError{Err: ErrPaymailNotFound, StatusCode: http.StatusNotFound, Message: "not found: mrz"}, "bad response from paymail provider: code 404, message: not found: mrz"},
+			{&ProviderError{Err: ErrPaymailNotFound, StatusCode: http.StatusNotFound}, "paymail address not found: code 404"},
+			{&ProviderError{Err: ErrDNS, Message: "invalid cname"}, "invalid cname"},
+			{&ProviderError{Err: ErrDNS}, ErrDNS.Error()},
+			{&ProviderError{}, "paymail provider error"},
+		}
+		for _, test := range tests {
+			assert.Equal(t, test.expected, test.err.Error())
+		}
+	})
+
+	t.Run("is and as", func(t *testing.T) {
+		var err error = &ProviderError{
+			Cause:      context.DeadlineExceeded,
+			Err:        ErrTimeout,
+			Step:       StepPKI,
+			URL:        testServerURL,
+			StatusCode: 0,
+		}
+		wrapped := fmt.Errorf("resolve failed: %w", err)
+		assert.ErrorIs(t, wrapped, ErrTimeout)
+		assert.ErrorIs(t, wrapped, context.DeadlineExceeded)
+		assert.NotErrorIs(t, wrapped, ErrDNS)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, wrapped, &providerErr)
+		assert.Equal(t, StepPKI, providerErr.Step)
+		assert.Equal(t, testServerURL, providerErr.URL)
+	})
+}
+
+// TestErrorForCode will test the method ErrorForCode()
+func TestErrorForCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ErrPaymailNotFound, ErrorForCode(ErrorCodePaymailNotFound))
+	assert.Equal(t, ErrInvalidSignature, ErrorForCode(ErrorCodeInvalidSignature))
+	assert.Equal(t, ErrInvalidTransaction, ErrorForCode(ErrorCodeScript))
+	assert.Nil(t, ErrorForCode("unknown-code"))
+	assert.Nil(t, ErrorForCode(""))
+}
+
+// Test_newResponseError will test the method newResponseError()
+func Test_newResponseError(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name            string
+		step            string
+		statusCode      int
+		body            string
+		expected        error
+		expectedCode    string
+		expectedMessage string
+	}{
+		{"code not-found", StepPKI, http.StatusNotFound, `{"code":"not-found","message":"not found"}`, ErrPaymailNotFound, ErrorCodePaymailNotFound, "not found"},
+		{"code invalid-dt", StepAddressResolution, http.StatusBadRequest, `{"code":"invalid-dt","message":"bad dt"}`, ErrInvalidDt, ErrorCodeInvalidDt, "bad dt"},
+		{"status 404", StepPKI, http.StatusNotFound, ``, ErrPaymailNotFound, "", ""},
+		{"status 404 capabilities", StepCapabilities, http.StatusNotFound, ``, ErrInvalidResponse, "", ""},
+		{"status 500", StepPKI, http.StatusInternalServerError, `not json`, ErrProviderFailure, "", ""},
+		{"unknown code", StepPKI, http.StatusBadRequest, `{"code":"unknown","message":"bad"}`, ErrInvalidResponse, "unknown", "bad"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := newResponseError(test.step, testServerURL, test.statusCode, []byte(test.body))
+			assert.ErrorIs(t, err, test.expected)
+
+			var providerErr *ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, test.statusCode, providerErr.StatusCode)
+			assert.Equal(t, test.expectedCode, providerErr.Code)
+			assert.Equal(t, test.expectedMessage, providerErr.Message)
+			assert.Equal(t, test.step, providerErr.Step)
+			assert.Equal(t, testServerURL, providerErr.URL)
+		})
+	}
+}
+
+// Test_classifyError will test the method classifyError()
+func Test_classifyError(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"deadline exceeded", fmt.Errorf("get: %w", context.DeadlineExceeded), ErrTimeout},
+		{"dns error", &net.DNSError{Err: "no such host", Name: testDomain}, ErrDNS},
+		{"dns timeout", &net.DNSError{Err: "timeout", Name: testDomain, IsTimeout: true}, ErrTimeout},
+		{"net timeout", &net.OpError{Op: "dial", Err: &timeoutError{}}, ErrTimeout},
+		{"unknown authority", fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), ErrTLS},
+		{"hostname error", x509.HostnameError{Host: testDomain, Certificate: &x509.Certificate{}}, ErrTLS},
+		{"record header", tls.RecordHeaderError{Msg: "bad record"}, ErrTLS},
+		{"canceled", context.Canceled, ErrRequestFailed},
+		{"other", errors.New("connection refused"), ErrRequestFailed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, classifyError(test.err))
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+// TestClient_TypedErrors will test the typed errors returned by the client methods
+func TestClient_TypedErrors(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	pkiURL := testServerURL + "id/{alias}@{domain.tld}"
+	reqURL := testServerURL + "id/" + testAlias + "@" + testDomain
+
+	t.Run("paymail not found", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, reqURL,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"Paymail not found: `+testAlias+`@`+testDomain+`"}`),
+		)
+
+		_, err := client.GetPKI(pkiURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, http.StatusNotFound, providerErr.StatusCode)
+		assert.Equal(t, ErrorCodePaymailNotFound, providerErr.Code)
+		assert.Equal(t, "Paymail not found: "+testAlias+"@"+testDomain, providerErr.Message)
+		assert.Equal(t, reqURL, providerErr.URL)
+		assert.Equal(t, StepPKI, providerErr.Step)
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, reqURL,
+			httpmock.NewStringResponder(http.StatusOK, `{"bsvalias": "1.0","handle": "`+testAlias+`@`+testDomain+`","pubkey": "invalid"}`),
+		)
+
+		_, err := client.GetPKI(pkiURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, reqURL,
+			httpmock.NewStringResponder(http.StatusOK, `{"bsvalias": `),
+		)
+
+		_, err := client.GetPKI(pkiURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, reqURL,
+			httpmock.NewErrorResponder(context.DeadlineExceeded),
+		)
+
+		_, err := client.GetPKI(pkiURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("request failed", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, reqURL,
+			httpmock.NewErrorResponder(errors.New("connection refused")),
+		)
+
+		_, err := client.GetPKI(pkiURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrRequestFailed)
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusBadRequest, `{"code":"script-error","message":"invalid script"}`),
+		)
+
+		_, err := client.SendP2PTransaction(testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain,
+			&P2PTransaction{Hex: "00", Reference: "ref"},
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidTransaction)
+		assert.Equal(t, "bad response from paymail provider: code 400, message: invalid script", err.Error())
+	})
+
+	t.Run("srv service not available", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, "unavailable.com")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrDNS)
+	})
+
+	t.Run("missing capability", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		mockFailoverCapabilities(http.StatusOK)
+
+		_, err := client.Resolve(testAlias+"@"+testFailoverDomain, &ResolveRequest{Operation: ResolveOperationPublicProfile})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotSupported)
+	})
+}
+
+// ExampleProviderError example using errors.Is() and errors.As() with a ProviderError
+//
+// See more examples in /examples/
+func ExampleProviderError() {
+	client := newTestClient(nil)
+
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"not found"}`),
+	)
+
+	_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	var providerErr *ProviderError
+	if errors.Is(err, ErrPaymailNotFound) && errors.As(err, &providerErr) {
+		fmt.Printf("paymail not found (status: %d, code: %s, step: %s)", providerErr.StatusCode, providerErr.Code, providerErr.Step)
+	}
+	// Output:paymail not found (status: 404, code: not-found, step: pki)
+}
+
+// BenchmarkNewResponseError benchmarks the method newResponseError()
+func BenchmarkNewResponseError(b *testing.B) {
+	body := []byte(`{"code":"not-found","message":"not found"}`)
+	for i := 0; i < b.N; i++ {
+		_ = newResponseError(StepPKI, testServerURL, http.StatusNotFound, body)
+	}
+}
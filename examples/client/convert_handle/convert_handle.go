@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"regexp"
 
 	"github.com/tonicpow/go-paymail"
 )
@@ -21,4 +22,16 @@ func main() {
 	// Convert the handle to paymail address
 	address = paymail.ConvertHandle(handle, false)
 	log.Printf("handle %s was converted to: %s", handle, address)
+
+	// Register a custom handle (IE: ~handle = handle@example.com)
+	if err := paymail.DefaultHandleRegistry.Register(
+		paymail.StaticHandleResolver("example", "~", regexp.MustCompile(`^~[a-z0-9]+$`), "example.com", ""),
+	); err != nil {
+		log.Fatalf("error registering handle resolver: %s", err.Error())
+	}
+
+	// Convert the custom handle to paymail address
+	handle = "~mrz"
+	address = paymail.ConvertHandle(handle, false)
+	log.Printf("handle %s was converted to: %s", handle, address)
 }
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+
+	"github.com/tonicpow/go-paymail"
+)
+
+func main() {
+
+	// Load the client
+	client, err := paymail.NewClient()
+	if err != nil {
+		log.Fatalf("error loading client: %s", err.Error())
+	}
+
+	// Run a full conformance check of the provider
+	var report *paymail.InspectReport
+	if report, err = client.Inspect("mrz@moneybutton.com", &paymail.InspectRequest{}); err != nil {
+		log.Fatal("error inspecting paymail: " + err.Error())
+	}
+	log.Println(report.String())
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+
+	"github.com/tonicpow/go-paymail"
+)
+
+func main() {
+
+	// Load the client
+	client, err := paymail.NewClient()
+	if err != nil {
+		log.Fatalf("error loading client: %s", err.Error())
+	}
+
+	// Discover the host & capabilities, then get the PKI (all in one request)
+	var result *paymail.ResolveResult
+	if result, err = client.Resolve("mrz@moneybutton.com", &paymail.ResolveRequest{
+		Operation: paymail.ResolveOperationPKI,
+	}); err != nil {
+		log.Fatal("error resolving paymail: " + err.Error())
+	}
+	log.Println("found host: ", result.SRV.Target)
+	log.Println("found capabilities: ", len(result.Capabilities.Capabilities))
+	log.Println("found pki:", result.PKI.PubKey)
+	for _, step := range result.Steps {
+		log.Printf("step %s took %s", step.Name, step.Duration)
+	}
+}
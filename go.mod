@@ -1,6 +1,8 @@
 module github.com/tonicpow/go-paymail
 
-go 1.19
+// The minimum go version is set by the golang.org/x dependencies (x/net v0.38.0, x/crypto,
+// x/sys & x/text all declare go 1.23.0)
+go 1.23.0
 
 require (
 	github.com/bitcoinschema/go-bitcoin/v2 v2.0.5
@@ -17,6 +19,7 @@ require (
 	github.com/newrelic/go-agent/v3/integrations/nrhttprouter v1.1.1
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/net v0.38.0
+	golang.org/x/text v0.23.0
 )
 
 require (
@@ -36,7 +39,6 @@ require (
 	golang.org/x/mod v0.18.0 // indirect
 	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/tools v0.22.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240308144416-29370a3891b7 // indirect
 	google.golang.org/grpc v1.62.1 // indirect
@@ -0,0 +1,212 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrHandleResolverInvalid is when a handle resolver is missing a name, a match or the resolve func
+	ErrHandleResolverInvalid = errors.New("invalid handle resolver")
+
+	// ErrHandleNotResolved is when a handle resolver did not return a paymail address
+	ErrHandleNotResolved = errors.New("handle not resolved")
+)
+
+// HandleResolveFunc converts the handle into a paymail address (alias@domain.tld)
+//
+// The client is only set for remote lookups (IE: Client.ConvertHandle()),
+// it is nil when converting with the ConvertHandle() function
+type HandleResolveFunc func(ctx context.Context, client *Client, handle string, isBeta bool) (string, error)
+
+// HandleResolver converts the handles with a prefix (IE: $handle) and/or matching a pattern into paymail addresses
+type HandleResolver struct {
+	Name    string            // Unique name of the resolver (IE: handcash)
+	Pattern *regexp.Regexp    // Handles must match the pattern (optional)
+	Prefix  string            // Handles must start with the prefix (optional, IE: $)
+	Resolve HandleResolveFunc // Converts the handle into a paymail address
+}
+
+// Match will return true if the handle has the prefix & matches the pattern of the resolver
+func (h *HandleResolver) Match(handle string) bool {
+	if len(h.Prefix) > 0 && !strings.HasPrefix(handle, h.Prefix) {
+		return false
+	}
+	return h.Pattern == nil || h.Pattern.MatchString(handle)
+}
+
+// StaticHandleResolver will return a resolver that maps the handles with the prefix onto
+// a domain (IE: $handle = handle@handcash.io), the betaDomain is used if isBeta is set (optional)
+//
+// The prefix is only removed once and the alias is lowercase
+func StaticHandleResolver(name, prefix string, pattern *regexp.Regexp, domain, betaDomain string) *HandleResolver {
+	return &HandleResolver{
+		Name:    name,
+		Pattern: pattern,
+		Prefix:  prefix,
+		Resolve: func(_ context.Context, _ *Client, handle string, isBeta bool) (string, error) {
+			alias := strings.ToLower(strings.TrimPrefix(handle, prefix))
+			if isBeta && len(betaDomain) > 0 {
+				return alias + "@" + betaDomain, nil
+			}
+			return alias + "@" + domain, nil
+		},
+	}
+}
+
+/*
+Example (remote lookup response):
+{
+  "paymail": "alias@domain.tld"
+}
+*/
+
+// RemoteHandleResolver will return a resolver that looks up the handles with the prefix using
+// the client (GET request), the {handle} in the lookupURL is replaced with the handle (without the prefix)
+//
+// IE: https://api.example.com/handles/{handle} returns {"paymail": "alias@domain.tld"}
+func RemoteHandleResolver(name, prefix string, pattern *regexp.Regexp, lookupURL string) *HandleResolver {
+	return &HandleResolver{
+		Name:    name,
+		Pattern: pattern,
+		Prefix:  prefix,
+		Resolve: func(ctx context.Context, client *Client, handle string, _ bool) (string, error) {
+			if client == nil {
+				return "", fmt.Errorf("%w: a client is required for the %s lookup", ErrHandleNotResolved, name)
+			} else if !strings.Contains(lookupURL, "https://") {
+				return "", fmt.Errorf("invalid url: %s", lookupURL)
+			}
+
+			// Fire the GET request
+			reqURL := strings.Replace(lookupURL, "{handle}", strings.TrimPrefix(handle, prefix), -1)
+			resp, err := client.getRequest(ctx, reqURL)
+			if err != nil {
+				return "", newRequestError(StepHandleLookup, reqURL, err)
+			}
+
+			// Decode the response
+			response := &struct {
+				Paymail string `json:"paymail"`
+			}{}
+			if err = decodeServiceResponse(StepHandleLookup, reqURL, resp, response); err != nil {
+				return "", err
+			} else if len(response.Paymail) == 0 {
+				return "", newInvalidResponseError(StepHandleLookup, reqURL, resp.StatusCode, ErrHandleNotResolved)
+			}
+			return response.Paymail, nil
+		},
+	}
+}
+
+// HandCashHandleResolver will return the resolver for HandCash: $handle = handle@handcash.io or handle@beta.handcash.io
+func HandCashHandleResolver() *HandleResolver {
+	return StaticHandleResolver("handcash", "$", nil, "handcash.io", "beta.handcash.io")
+}
+
+// RelayXHandleResolver will return the resolver for RelayX: 1handle = handle@relayx.io
+//
+// Bitcoin addresses (25+ characters) and paymail addresses are not converted
+func RelayXHandleResolver() *HandleResolver {
+	return StaticHandleResolver("relayx", "1", regexp.MustCompile(`^1[^@]{0,23}$`), "relayx.io", "")
+}
+
+// HandleRegistry is a list of handle resolvers, the first resolver matching the handle converts it
+//
+// The registry is safe for concurrent use
+type HandleRegistry struct {
+	mu        sync.RWMutex
+	resolvers []*HandleResolver
+}
+
+// DefaultHandleRegistry is the registry used by ConvertHandle() and the client (unless WithHandleRegistry() is set),
+// apps can extend it with Register()
+var DefaultHandleRegistry = NewHandleRegistry(HandCashHandleResolver(), RelayXHandleResolver())
+
+// NewHandleRegistry will return a registry with the resolvers (invalid resolvers are skipped)
+func NewHandleRegistry(resolvers ...*HandleResolver) *HandleRegistry {
+	r := &HandleRegistry{}
+	for _, resolver := range resolvers {
+		_ = r.Register(resolver)
+	}
+	return r
+}
+
+// Register will add the resolver (a resolver with the same name is replaced)
+func (r *HandleRegistry) Register(resolver *HandleResolver) error {
+	if resolver == nil || len(resolver.Name) == 0 || resolver.Resolve == nil {
+		return ErrHandleResolverInvalid
+	} else if len(resolver.Prefix) == 0 && resolver.Pattern == nil {
+		return fmt.Errorf("%w: %s requires a prefix or a pattern", ErrHandleResolverInvalid, resolver.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for index, existing := range r.resolvers {
+		if existing.Name == resolver.Name {
+			r.resolvers[index] = resolver
+			return nil
+		}
+	}
+	r.resolvers = append(r.resolvers, resolver)
+	return nil
+}
+
+// Remove will remove the resolver by name
+func (r *HandleRegistry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for index, existing := range r.resolvers {
+		if existing.Name == name {
+			r.resolvers = append(r.resolvers[:index], r.resolvers[index+1:]...)
+			return
+		}
+	}
+}
+
+// Find will return the first resolver matching the handle (nil if not found)
+func (r *HandleRegistry) Find(handle string) *HandleResolver {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, resolver := range r.resolvers {
+		if resolver.Match(handle) {
+			return resolver
+		}
+	}
+	return nil
+}
+
+// Convert will convert the handle into a paymail address, the handle is returned
+// unchanged if no resolver matches (IE: it is already a paymail address)
+func (r *HandleRegistry) Convert(ctx context.Context, client *Client, handle string, isBeta bool) (string, error) {
+	resolver := r.Find(handle)
+	if resolver == nil {
+		return handle, nil
+	}
+	return resolver.Resolve(ctx, client, handle, isBeta)
+}
+
+// ConvertHandle will convert a handle into a paymail address using the handle registry of the client
+// (remote lookups are supported), see: ConvertHandle()
+func (c *Client) ConvertHandle(handle string, isBeta bool) (string, error) {
+	return c.ConvertHandleContext(context.Background(), handle, isBeta)
+}
+
+// ConvertHandleContext is the same as ConvertHandle() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request (remote lookups)
+func (c *Client) ConvertHandleContext(ctx context.Context, handle string,
+	isBeta bool) (paymailAddress string, err error) {
+	paymailAddress, err = c.handleRegistry().Convert(ctx, c, handle, isBeta)
+	return
+}
+
+// handleRegistry will return the handle registry of the client (or the default)
+func (c *Client) handleRegistry() *HandleRegistry {
+	if c.options.handleRegistry != nil {
+		return c.options.handleRegistry
+	}
+	return DefaultHandleRegistry
+}
@@ -0,0 +1,194 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockHandleLookup will mock the remote handle lookup response
+func mockHandleLookup(statusCode int, body string) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, testServerURL+"handles/mrz",
+		httpmock.NewStringResponder(statusCode, body),
+	)
+}
+
+// TestHandleResolver_Match will test the method Match()
+func TestHandleResolver_Match(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name     string
+		resolver *HandleResolver
+		handle   string
+		expected bool
+	}{
+		{"prefix", HandCashHandleResolver(), "$mrz", true},
+		{"missing prefix", HandCashHandleResolver(), "mrz", false},
+		{"prefix & pattern", RelayXHandleResolver(), "1mrz", true},
+		{"bitcoin address", RelayXHandleResolver(), "1PN7K19Jmj7QQCpUg37WHpSRUw5gKhJVRa", false},
+		{"paymail address", RelayXHandleResolver(), "1mrz@" + testDomain, false},
+		{"pattern only", &HandleResolver{Pattern: regexp.MustCompile(`^@\w+$`)}, "@mrz", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.resolver.Match(test.handle))
+		})
+	}
+}
+
+// TestHandleRegistry will test the methods of the HandleRegistry
+func TestHandleRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid resolvers", func(t *testing.T) {
+		registry := NewHandleRegistry()
+		assert.ErrorIs(t, registry.Register(nil), ErrHandleResolverInvalid)
+		assert.ErrorIs(t, registry.Register(&HandleResolver{Prefix: "~"}), ErrHandleResolverInvalid)
+		assert.ErrorIs(t, registry.Register(
+			StaticHandleResolver("no-match", "", nil, testDomain, ""),
+		), ErrHandleResolverInvalid)
+		assert.Nil(t, registry.Find("~mrz"))
+	})
+
+	t.Run("register, replace & remove", func(t *testing.T) {
+		registry := NewHandleRegistry(HandCashHandleResolver())
+		require.NoError(t, registry.Register(StaticHandleResolver("custom", "~", nil, testDomain, "")))
+
+		paymailAddress, err := registry.Convert(context.Background(), nil, "~MrZ", false)
+		require.NoError(t, err)
+		assert.Equal(t, "mrz@"+testDomain, paymailAddress)
+
+		// Replace the resolver (same name)
+		require.NoError(t, registry.Register(StaticHandleResolver("custom", "~", nil, "example.com", "")))
+		paymailAddress, err = registry.Convert(context.Background(), nil, "~mrz", false)
+		require.NoError(t, err)
+		assert.Equal(t, "mrz@example.com", paymailAddress)
+
+		// Remove the resolver (the handle is not converted)
+		registry.Remove("custom")
+		assert.Nil(t, registry.Find("~mrz"))
+		paymailAddress, err = registry.Convert(context.Background(), nil, "~mrz", false)
+		require.NoError(t, err)
+		assert.Equal(t, "~mrz", paymailAddress)
+	})
+
+	t.Run("first match wins", func(t *testing.T) {
+		registry := NewHandleRegistry(
+			StaticHandleResolver("first", "$", nil, "first.com", ""),
+			StaticHandleResolver("second", "$", nil, "second.com", ""),
+		)
+		assert.Equal(t, "first", registry.Find("$mrz").Name)
+	})
+
+	t.Run("static resolver removes the prefix once", func(t *testing.T) {
+		registry := NewHandleRegistry(StaticHandleResolver("tilde", "~", nil, testDomain, ""))
+		paymailAddress, err := registry.Convert(context.Background(), nil, "~a~b", false)
+		require.NoError(t, err)
+		assert.Equal(t, "a~b@"+testDomain, paymailAddress)
+	})
+
+	t.Run("default resolvers remove the prefix once", func(t *testing.T) {
+		paymailAddress, err := DefaultHandleRegistry.Convert(context.Background(), nil, "1a1b", false)
+		require.NoError(t, err)
+		assert.Equal(t, "a1b@relayx.io", paymailAddress)
+		paymailAddress, err = DefaultHandleRegistry.Convert(context.Background(), nil, "$a$B", true)
+		require.NoError(t, err)
+		assert.Equal(t, "a$b@beta.handcash.io", paymailAddress)
+	})
+
+	t.Run("remote resolver without a client", func(t *testing.T) {
+		registry := NewHandleRegistry(RemoteHandleResolver("remote", "@", nil, testServerURL+"handles/{handle}"))
+		_, err := registry.Convert(context.Background(), nil, "@mrz", false)
+		assert.ErrorIs(t, err, ErrHandleNotResolved)
+	})
+}
+
+// TestClient_ConvertHandle will test the method ConvertHandle()
+func TestClient_ConvertHandle(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	registry := NewHandleRegistry(
+		HandCashHandleResolver(),
+		RemoteHandleResolver("remote", "@", nil, testServerURL+"handles/{handle}"),
+	)
+	client := newTestClient(t, WithHandleRegistry(registry))
+
+	t.Run("static resolver", func(t *testing.T) {
+		paymailAddress, err := client.ConvertHandle("$mrz", true)
+		require.NoError(t, err)
+		assert.Equal(t, "mrz@beta.handcash.io", paymailAddress)
+	})
+
+	t.Run("remote resolver", func(t *testing.T) {
+		mockHandleLookup(http.StatusOK, `{"paymail": "`+testAlias+`@`+testDomain+`"}`)
+		paymailAddress, err := client.ConvertHandle("@mrz", false)
+		require.NoError(t, err)
+		assert.Equal(t, testAlias+"@"+testDomain, paymailAddress)
+	})
+
+	t.Run("remote resolver - missing paymail", func(t *testing.T) {
+		mockHandleLookup(http.StatusOK, `{}`)
+		_, err := client.ConvertHandle("@mrz", false)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+		assert.ErrorIs(t, err, ErrHandleNotResolved)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepHandleLookup, providerErr.Step)
+	})
+
+	t.Run("remote resolver - not found", func(t *testing.T) {
+		mockHandleLookup(http.StatusNotFound, `{"code":"not-found","message":"handle not found"}`)
+		_, err := client.ConvertHandle("@mrz", false)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+	})
+
+	t.Run("not a handle", func(t *testing.T) {
+		paymailAddress, err := client.ConvertHandle(testAlias+"@"+testDomain, false)
+		require.NoError(t, err)
+		assert.Equal(t, testAlias+"@"+testDomain, paymailAddress)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockHandleLookup(http.StatusOK, `{"paymail": "`+testAlias+`@`+testDomain+`"}`)
+		_, err := client.ConvertHandleContext(canceledContext(), "@mrz", false)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("default registry", func(t *testing.T) {
+		defaultClient := newTestClient(t)
+		paymailAddress, err := defaultClient.ConvertHandle("1mrz", false)
+		require.NoError(t, err)
+		assert.Equal(t, "mrz@relayx.io", paymailAddress)
+	})
+}
+
+// ExampleHandleRegistry_Register example using the method Register()
+//
+// See more examples in /examples/
+func ExampleHandleRegistry_Register() {
+	registry := NewHandleRegistry(HandCashHandleResolver())
+	_ = registry.Register(StaticHandleResolver("example", "~", nil, "example.com", ""))
+	paymailAddress, _ := registry.Convert(context.Background(), nil, "~MrZ", false)
+	fmt.Println(paymailAddress)
+	// Output:mrz@example.com
+}
+
+// BenchmarkHandleRegistry_Convert benchmarks the method Convert()
+func BenchmarkHandleRegistry_Convert(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		_, _ = DefaultHandleRegistry.Convert(ctx, nil, "1mrz", false)
+	}
+}
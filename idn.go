@@ -0,0 +1,193 @@
+package paymail
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mrz1836/go-sanitize"
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+Internationalized (IDN) paymail addresses (IE: müller@bücher.de)
+
+- Domains are converted to punycode (IE: bücher.de = xn--bcher-kva.de) using the IDNA lookup profile,
+  the punycode form is used for DNS, urls & comparisons (see: SanitizeDomain())
+- Aliases stay in unicode, normalised to NFC & lowercase, only letters, marks & digits
+  (plus the ASCII characters: - _ . +) are allowed (see: NormalizeAlias())
+- Aliases & domain labels mixing scripts (IE: a cyrillic "а" in a latin "pаymail") are rejected as
+  confusable, the CJK combinations of UTS #39 (highly restrictive) are allowed (see: IsConfusable())
+- The display form (unicode domain) is returned by DisplayPaymail()
+*/
+
+var (
+	// ErrInvalidAlias is when the alias is empty or contains characters that are not allowed
+	ErrInvalidAlias = errors.New("invalid alias")
+
+	// ErrConfusable is when the alias or a domain label mixes scripts (IE: latin & cyrillic)
+	ErrConfusable = errors.New("confusable characters (mixed scripts)")
+)
+
+// allowedScriptSets are the script combinations that are not confusable (UTS #39, highly restrictive)
+var allowedScriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// NormalizeAlias will return the alias normalised to NFC & lowercase
+//
+// An error is returned if the alias is empty, has characters that are not allowed or is confusable
+func NormalizeAlias(alias string) (string, error) {
+	alias = norm.NFC.String(strings.ToLower(strings.TrimSpace(alias)))
+	if len(alias) == 0 {
+		return "", fmt.Errorf("%w: alias is empty", ErrInvalidAlias)
+	}
+	for _, r := range alias {
+		if !isAliasRune(r) {
+			return "", fmt.Errorf("%w: character %q is not allowed", ErrInvalidAlias, r)
+		}
+	}
+	if IsConfusable(alias) {
+		return "", fmt.Errorf("%w: %s", ErrConfusable, alias)
+	}
+	return alias, nil
+}
+
+// ToASCIIDomain will convert the (IDN) domain to punycode (IE: bücher.de = xn--bcher-kva.de)
+//
+// An error is returned if the domain is invalid or a label is confusable
+func ToASCIIDomain(domain string) (string, error) {
+	asciiDomain, err := idna.Lookup.ToASCII(strings.TrimSpace(domain))
+	if err != nil {
+		return "", err
+	}
+
+	// Check the labels in the unicode form (punycode can hide the confusable characters)
+	unicodeDomain, err := idna.Display.ToUnicode(asciiDomain)
+	if err != nil {
+		return "", err
+	}
+	for _, label := range strings.Split(unicodeDomain, ".") {
+		if IsConfusable(label) {
+			return "", fmt.Errorf("%w: %s", ErrConfusable, label)
+		}
+	}
+	return asciiDomain, nil
+}
+
+// ToUnicodeDomain will convert the (punycode) domain to unicode for display (IE: xn--bcher-kva.de = bücher.de)
+func ToUnicodeDomain(domain string) (string, error) {
+	return idna.Display.ToUnicode(strings.TrimSpace(domain))
+}
+
+// SanitizeDomain will return the sanitized domain (lowercase, no www.), IDN domains are converted to punycode
+func SanitizeDomain(domain string) (string, error) {
+	if isIDN(domain) {
+		var err error
+		if domain, err = ToASCIIDomain(strings.Map(domainRune, domain)); err != nil {
+			return "", err
+		}
+	}
+	return sanitize.Domain(domain, false, true)
+}
+
+// DisplayPaymail will return the display form of the (sanitized) paymail address, the domain
+// is converted to unicode (IE: müller@xn--bcher-kva.de = müller@bücher.de)
+func DisplayPaymail(paymailAddress string) string {
+	at := strings.LastIndex(paymailAddress, "@")
+	if at < 0 {
+		return paymailAddress
+	}
+	domain, err := ToUnicodeDomain(paymailAddress[at+1:])
+	if err != nil {
+		return paymailAddress
+	}
+	return paymailAddress[:at+1] + domain
+}
+
+// IsConfusable will return true if the value mixes scripts (IE: latin & cyrillic), the
+// characters of the common & inherited scripts (digits, punctuation, marks) are ignored
+//
+// Values written in a single (non-latin) script are not confusable
+func IsConfusable(value string) bool {
+
+	// Collect the scripts of the characters
+	scripts := make(map[string]bool)
+	for _, r := range value {
+		if r < utf8.RuneSelf {
+			if unicode.IsLetter(r) {
+				scripts["Latin"] = true
+			}
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+				scripts[name] = true
+				break
+			}
+		}
+	}
+	if len(scripts) <= 1 {
+		return false
+	}
+
+	// Allowed combinations (IE: japanese)
+	for _, set := range allowedScriptSets {
+		found := 0
+		for _, name := range set {
+			if scripts[name] {
+				found++
+			}
+		}
+		if found == len(scripts) {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeAlias will return the alias (NFC & lowercase) without the characters that are not allowed
+func sanitizeAlias(alias string) string {
+	return strings.Map(func(r rune) rune {
+		if isAliasRune(r) {
+			return r
+		}
+		return -1
+	}, norm.NFC.String(strings.ToLower(alias)))
+}
+
+// isAliasRune will return true if the character is allowed in an alias
+func isAliasRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || strings.ContainsRune("-_.+", r)
+	}
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+// domainRune will remove the characters that are not allowed in an (IDN) domain
+func domainRune(r rune) rune {
+	if r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) {
+		return r
+	}
+	return -1
+}
+
+// isIDN will return true if the value has non-ASCII characters or punycode labels
+func isIDN(value string) bool {
+	return !isASCII(value) || strings.Contains(strings.ToLower(value), "xn--")
+}
+
+// isASCII will return true if the value only has ASCII characters
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package paymail
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeAlias will test the method NormalizeAlias()
+func TestNormalizeAlias(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input         string
+		expected      string
+		expectedError error
+	}{
+		{"mrz", "mrz", nil},
+		{" MrZ ", "mrz", nil},
+		{"Müller", "müller", nil},
+		{"müller", "müller", nil},
+		{"田中", "田中", nil},
+		{"たなか田中", "たなか田中", nil},
+		{"ivan.иванов", "", ErrConfusable},
+		{"p\u0430ypal", "", ErrConfusable},
+		{"", "", ErrInvalidAlias},
+		{"mr z", "", ErrInvalidAlias},
+		{"mrz!", "", ErrInvalidAlias},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			alias, err := NormalizeAlias(test.input)
+			if test.expectedError != nil {
+				assert.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, alias)
+		})
+	}
+}
+
+// TestToASCIIDomain will test the method ToASCIIDomain()
+func TestToASCIIDomain(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input         string
+		expected      string
+		expectedError bool
+	}{
+		{"bücher.de", "xn--bcher-kva.de", false},
+		{"Bücher.DE", "xn--bcher-kva.de", false},
+		{"xn--bcher-kva.de", "xn--bcher-kva.de", false},
+		{"例え.jp", "xn--r8jz45g.jp", false},
+		{testDomain, testDomain, false},
+		{"p\u0430ypal.com", "", true},
+		{"xn--pypal-4ve.com", "", true},
+		{"bad domain.com", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			domain, err := ToASCIIDomain(test.input)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, domain)
+		})
+	}
+}
+
+// TestSanitizeDomain will test the method SanitizeDomain()
+func TestSanitizeDomain(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input    string
+		expected string
+	}{
+		{"WWW.Test.com", testDomain},
+		{"www.bücher.de", "xn--bcher-kva.de"},
+		{"XN--BCHER-KVA.de", "xn--bcher-kva.de"},
+	}
+	for _, test := range tests {
+		domain, err := SanitizeDomain(test.input)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, domain)
+	}
+
+	t.Run("confusable domain", func(t *testing.T) {
+		_, err := SanitizeDomain("p\u0430ypal.com")
+		assert.ErrorIs(t, err, ErrConfusable)
+	})
+}
+
+// TestDisplayPaymail will test the method DisplayPaymail()
+func TestDisplayPaymail(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input    string
+		expected string
+	}{
+		{"müller@xn--bcher-kva.de", "müller@bücher.de"},
+		{"mrz@" + testDomain, "mrz@" + testDomain},
+		{"mrz", "mrz"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, DisplayPaymail(test.input))
+	}
+}
+
+// TestIsConfusable will test the method IsConfusable()
+func TestIsConfusable(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input    string
+		expected bool
+	}{
+		{"paypal", false},
+		{"иванов", false},
+		{"田中", false},
+		{"tanaka田中たなか", false},
+		{"김철수kim", false},
+		{"müller-1337", false},
+		{"p\u0430ypal", true},
+		{"ελλάδαgreece", true},
+		{"", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, IsConfusable(test.input), test.input)
+	}
+}
+
+// ExampleDisplayPaymail example using the method DisplayPaymail()
+//
+// See more examples in /examples/
+func ExampleDisplayPaymail() {
+	_, _, address := SanitizePaymail("Müller@Bücher.de")
+	fmt.Println(address + " = " + DisplayPaymail(address))
+	// Output:müller@xn--bcher-kva.de = müller@bücher.de
+}
+
+// BenchmarkSanitizePaymail_IDN benchmarks the method SanitizePaymail() with an IDN paymail
+func BenchmarkSanitizePaymail_IDN(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _, _ = SanitizePaymail("müller@bücher.de")
+	}
+}
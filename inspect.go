@@ -0,0 +1,376 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InspectStatus is the status of a single check in Inspect()
+type InspectStatus string
+
+// Statuses used in InspectCheck.Status (and InspectReport.Status)
+const (
+	InspectStatusFail InspectStatus = "fail" // The check failed (not conformant)
+	InspectStatusPass InspectStatus = "pass" // The check passed
+	InspectStatusSkip InspectStatus = "skip" // The check was skipped (disabled, not supported or a previous check failed)
+	InspectStatusWarn InspectStatus = "warn" // The check passed, but not following a recommendation
+)
+
+// Check names used in InspectCheck.Name (in the order they are run)
+const (
+	InspectCheckSRV           = "srv"            // Host discovery
+	InspectCheckSRVValidation = "srv_validation" // Host discovery record values & resolution
+	InspectCheckDNSSEC        = "dnssec"         // DNSSEC on the domain
+	InspectCheckTLS           = "tls"            // TLS certificate on the SRV target
+	InspectCheckCapabilities  = "capabilities"   // Capability discovery & required capabilities
+	InspectCheckBRFCs         = "brfcs"          // Capabilities are known BRFC specifications
+	InspectCheckPKI           = "pki"            // Public key infrastructure (requires an address)
+	InspectCheckVerifyPubKey  = "verify_pubkey"  // Verify public key owner (requires an address)
+	InspectCheckPublicProfile = "public_profile" // Public profile (requires an address)
+)
+
+// inspectSpecs are the specification urls for each check
+var inspectSpecs = map[string]string{
+	InspectCheckBRFCs:         "http://bsvalias.org/01-brfc-specifications.html",
+	InspectCheckCapabilities:  "http://bsvalias.org/02-02-capability-discovery.html",
+	InspectCheckDNSSEC:        "http://bsvalias.org/02-01-host-discovery.html",
+	InspectCheckPKI:           "http://bsvalias.org/03-public-key-infrastructure.html",
+	InspectCheckPublicProfile: "https://github.com/bitcoin-sv-specs/brfc-paymail/pull/7/files",
+	InspectCheckSRV:           "http://bsvalias.org/02-01-host-discovery.html",
+	InspectCheckSRVValidation: "http://bsvalias.org/02-01-host-discovery.html",
+	InspectCheckTLS:           "http://bsvalias.org/02-01-host-discovery.html",
+	InspectCheckVerifyPubKey:  "http://bsvalias.org/05-verify-public-key-owner.html",
+}
+
+// brfcIDPattern matches a BRFC ID (used as a capability key)
+var brfcIDPattern = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// InspectRequest is the request for the Inspect() method
+type InspectRequest struct {
+	SkipDNSSEC bool // Skip the DNSSEC check (requires a reachable name server)
+	SkipTLS    bool // Skip the TLS check
+}
+
+// InspectCheck is the result of a single check in Inspect()
+type InspectCheck struct {
+	Duration time.Duration `json:"duration"`        // How long the check took
+	Message  string        `json:"message"`         // Details of the result
+	Name     string        `json:"name"`            // Name of the check
+	Specs    string        `json:"specs,omitempty"` // Specification the check is based on
+	Status   InspectStatus `json:"status"`          // pass, warn, fail or skip
+}
+
+// InspectReport is the report returned from Inspect()
+type InspectReport struct {
+	Address      string                `json:"address,omitempty"`      // The sanitized paymail address (if an address was inspected)
+	Capabilities *CapabilitiesResponse `json:"capabilities,omitempty"` // Capabilities discovered for the host
+	CheckTime    time.Time             `json:"check_time"`             // When the inspection started
+	Checks       []*InspectCheck       `json:"checks"`                 // Result of each check
+	Domain       string                `json:"domain"`                 // Domain of the provider
+	SRV          *net.SRV              `json:"srv,omitempty"`          // The discovered host (target & port)
+	Status       InspectStatus         `json:"status"`                 // fail if any check failed, warn if any check warned, otherwise pass
+}
+
+// Inspect will run a full conformance check of a paymail provider against the bsvalias specs
+// and the known BRFC specifications, given a domain (IE: domain.com) or an address (IE: alias@domain.com)
+//
+// Checks that require an alias (pki, verify_pubkey, public_profile) are skipped for a domain.
+// Errors are only returned for an invalid target, everything else is reported as a check.
+//
+// Specs: http://bsvalias.org/
+func (c *Client) Inspect(target string, request *InspectRequest) (*InspectReport, error) {
+	return c.InspectContext(context.Background(), target, request)
+}
+
+// InspectContext is the same as Inspect() but accepts a context
+// that is used for cancellation and deadlines on every check
+func (c *Client) InspectContext(ctx context.Context, target string,
+	request *InspectRequest) (report *InspectReport, err error) {
+
+	// Default to running all checks
+	if request == nil {
+		request = &InspectRequest{}
+	}
+
+	// Start the report
+	report = &InspectReport{CheckTime: time.Now()}
+
+	// Validate the target (an address or a domain)
+	var address Address
+	target = strings.TrimSpace(target)
+	if strings.Contains(target, "@") || !strings.Contains(target, ".") {
+		if address, err = ParseAddress(target); err != nil {
+			report = nil
+			return
+		}
+		report.Address = address.String()
+		report.Domain = address.Domain()
+	} else {
+		report.Domain = strings.ToLower(target)
+		if err = ValidateDomain(report.Domain); err != nil {
+			report = nil
+			return
+		}
+	}
+
+	// Host discovery
+	start := time.Now()
+	records, srvErr := c.GetSRVRecordsContext(ctx, DefaultServiceName, DefaultProtocol, report.Domain)
+	if srvErr != nil {
+		report.add(InspectCheckSRV, start, InspectStatusFail, srvErr.Error())
+		report.skip("srv failed", InspectCheckSRVValidation, InspectCheckDNSSEC, InspectCheckTLS,
+			InspectCheckCapabilities, InspectCheckBRFCs, InspectCheckPKI, InspectCheckVerifyPubKey, InspectCheckPublicProfile)
+		return
+	}
+	report.SRV = records[0]
+	report.add(InspectCheckSRV, start, InspectStatusPass, fmt.Sprintf(
+		"found %d record(s), using %s:%d", len(records), report.SRV.Target, report.SRV.Port,
+	))
+
+	// Host discovery record values (any port, priority & weight are allowed, but the defaults are recommended)
+	start = time.Now()
+	var validateErr error
+	for _, record := range records {
+		if validateErr = c.ValidateSRVRecords(ctx, []*net.SRV{record}, record.Port); validateErr != nil {
+			break
+		}
+	}
+	if validateErr != nil {
+		report.add(InspectCheckSRVValidation, start, InspectStatusFail, validateErr.Error())
+	} else if validateErr = c.ValidateSRVRecord(ctx, report.SRV, 0, 0, 0); validateErr != nil {
+		report.add(InspectCheckSRVValidation, start, InspectStatusWarn, validateErr.Error())
+	} else {
+		report.add(InspectCheckSRVValidation, start, InspectStatusPass, "srv record uses the recommended values")
+	}
+
+	// DNSSEC is recommended (required if chain validation is enabled)
+	start = time.Now()
+	if request.SkipDNSSEC {
+		report.add(InspectCheckDNSSEC, start, InspectStatusSkip, "disabled")
+	} else {
+		dnsResult := c.CheckDNSSECContext(ctx, report.Domain)
+		switch {
+		case dnsResult.Chain != nil && !dnsResult.Chain.Valid:
+			report.add(InspectCheckDNSSEC, start, InspectStatusFail, "chain of trust is invalid: "+dnsResult.Chain.ErrorMessage)
+		case dnsResult.DNSSEC:
+			report.add(InspectCheckDNSSEC, start, InspectStatusPass, "dnssec is enabled")
+		case len(dnsResult.ErrorMessage) > 0:
+			report.add(InspectCheckDNSSEC, start, InspectStatusWarn, dnsResult.ErrorMessage)
+		default:
+			report.add(InspectCheckDNSSEC, start, InspectStatusWarn, "dnssec is not enabled")
+		}
+	}
+
+	// TLS certificate on the host
+	start = time.Now()
+	if request.SkipTLS {
+		report.add(InspectCheckTLS, start, InspectStatusSkip, "disabled")
+	} else {
+		tlsReport, tlsErr := c.CheckTLSContext(ctx, report.SRV.Target, int(report.SRV.Port))
+		report.inspectTLS(start, tlsReport, tlsErr)
+	}
+
+	// Capability discovery
+	start = time.Now()
+	if report.Capabilities, err = c.GetCapabilitiesContext(
+		ctx, report.SRV.Target, int(report.SRV.Port),
+	); err != nil {
+		report.add(InspectCheckCapabilities, start, InspectStatusFail, err.Error())
+		report.skip("capabilities failed", InspectCheckBRFCs, InspectCheckPKI, InspectCheckVerifyPubKey, InspectCheckPublicProfile)
+		err = nil
+		return
+	}
+	capabilities := report.Capabilities.CapabilitiesPayload
+	switch {
+	case !capabilities.Has(BRFCPki, BRFCPkiAlternate):
+		report.add(InspectCheckCapabilities, start, InspectStatusFail, "missing required capability: "+BRFCPki)
+	case !capabilities.Has(BRFCPaymentDestination, BRFCBasicAddressResolution):
+		report.add(InspectCheckCapabilities, start, InspectStatusFail, "missing required capability: "+BRFCPaymentDestination)
+	case capabilities.BsvAlias != DefaultBsvAliasVersion:
+		report.add(InspectCheckCapabilities, start, InspectStatusWarn, fmt.Sprintf(
+			"bsvalias version %s does not match %s", capabilities.BsvAlias, DefaultBsvAliasVersion,
+		))
+	default:
+		report.add(InspectCheckCapabilities, start, InspectStatusPass, fmt.Sprintf(
+			"found %d capabilities", len(capabilities.Capabilities),
+		))
+	}
+
+	// Capabilities should be known BRFC specifications
+	start = time.Now()
+	if unknown := c.unknownCapabilities(&capabilities); len(unknown) > 0 {
+		report.add(InspectCheckBRFCs, start, InspectStatusWarn, "unknown capabilities: "+strings.Join(unknown, ", "))
+	} else {
+		report.add(InspectCheckBRFCs, start, InspectStatusPass, "all capabilities are known brfc specifications")
+	}
+
+	// The remaining checks require an alias
+	if address.IsZero() {
+		report.skip("requires an address", InspectCheckPKI, InspectCheckVerifyPubKey, InspectCheckPublicProfile)
+		return
+	}
+
+	// Public key infrastructure
+	start = time.Now()
+	var pki *PKIResponse
+	if pkiURL := capabilities.GetString(BRFCPki, BRFCPkiAlternate); len(pkiURL) == 0 {
+		report.add(InspectCheckPKI, start, InspectStatusFail, "missing capability: "+BRFCPki)
+	} else if pki, err = c.GetPKIContext(ctx, pkiURL, address.Alias(), address.Domain()); err != nil {
+		report.add(InspectCheckPKI, start, InspectStatusFail, err.Error())
+		pki = nil
+	} else if pki.BsvAlias != DefaultBsvAliasVersion {
+		report.add(InspectCheckPKI, start, InspectStatusWarn, fmt.Sprintf(
+			"bsvalias version %s does not match %s", pki.BsvAlias, DefaultBsvAliasVersion,
+		))
+	} else {
+		report.add(InspectCheckPKI, start, InspectStatusPass, "found pubkey: "+pki.PubKey)
+	}
+	err = nil
+
+	// Verify public key owner (optional capability)
+	start = time.Now()
+	var verification *VerificationResponse
+	if verifyURL := capabilities.GetString(BRFCVerifyPublicKeyOwner, ""); len(verifyURL) == 0 {
+		report.add(InspectCheckVerifyPubKey, start, InspectStatusSkip, "capability not supported")
+	} else if pki == nil {
+		report.add(InspectCheckVerifyPubKey, start, InspectStatusSkip, "pki failed")
+	} else if verification, err = c.VerifyPubKeyContext(
+		ctx, verifyURL, address.Alias(), address.Domain(), pki.PubKey,
+	); err != nil {
+		report.add(InspectCheckVerifyPubKey, start, InspectStatusFail, err.Error())
+	} else if !verification.Match {
+		report.add(InspectCheckVerifyPubKey, start, InspectStatusFail, "pubkey from pki does not match")
+	} else {
+		report.add(InspectCheckVerifyPubKey, start, InspectStatusPass, "pubkey from pki matches")
+	}
+	err = nil
+
+	// Public profile (optional capability)
+	start = time.Now()
+	var profile *PublicProfileResponse
+	if profileURL := capabilities.GetString(BRFCPublicProfile, ""); len(profileURL) == 0 {
+		report.add(InspectCheckPublicProfile, start, InspectStatusSkip, "capability not supported")
+	} else if profile, err = c.GetPublicProfileContext(ctx, profileURL, address.Alias(), address.Domain()); err != nil {
+		report.add(InspectCheckPublicProfile, start, InspectStatusFail, err.Error())
+	} else if len(profile.Name) == 0 {
+		report.add(InspectCheckPublicProfile, start, InspectStatusWarn, "public profile is missing a name")
+	} else {
+		report.add(InspectCheckPublicProfile, start, InspectStatusPass, "found name: "+profile.Name)
+	}
+	err = nil
+
+	return
+}
+
+// inspectTLS will add the TLS check from the CheckTLS() report
+func (r *InspectReport) inspectTLS(start time.Time, tlsReport *TLSReport, err error) {
+	if err != nil {
+		r.add(InspectCheckTLS, start, InspectStatusFail, err.Error())
+		return
+	}
+
+	// Collect the problems from each ip address
+	var problems, warnings []string
+	for _, result := range tlsReport.Results {
+		switch result.Status {
+		case TLSStatusOK:
+		case TLSStatusWarning:
+			warnings = append(warnings, fmt.Sprintf("%s: certificate expires in %d days", result.IP, result.DaysToExpiry))
+		default:
+			problems = append(problems, result.IP+": "+result.Error)
+		}
+	}
+
+	// Set the status
+	switch {
+	case !tlsReport.Valid:
+		r.add(InspectCheckTLS, start, InspectStatusFail, strings.Join(problems, "; "))
+	case len(warnings) > 0:
+		r.add(InspectCheckTLS, start, InspectStatusWarn, strings.Join(warnings, "; "))
+	default:
+		r.add(InspectCheckTLS, start, InspectStatusPass, fmt.Sprintf(
+			"valid certificate on %d ip address(es)", len(tlsReport.Results),
+		))
+	}
+}
+
+// unknownCapabilities will return the capabilities that are not known BRFC specifications
+func (c *Client) unknownCapabilities(capabilities *CapabilitiesPayload) (unknown []string) {
+	for key := range capabilities.Capabilities {
+		known := false
+		for _, spec := range c.options.brfcSpecs {
+			if spec.ID == key || (len(spec.Alias) > 0 && spec.Alias == key) {
+				known = true
+				break
+			}
+		}
+
+		// Aliases that are not in the specs are allowed, only unknown BRFC IDs are reported
+		if !known && brfcIDPattern.MatchString(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return
+}
+
+// add will add a check to the report and update the overall status
+func (r *InspectReport) add(name string, start time.Time, status InspectStatus, message string) {
+	r.Checks = append(r.Checks, &InspectCheck{
+		Duration: time.Since(start),
+		Message:  message,
+		Name:     name,
+		Specs:    inspectSpecs[name],
+		Status:   status,
+	})
+
+	// Fail > warn > pass (skipped checks do not change the status)
+	switch {
+	case status == InspectStatusFail:
+		r.Status = InspectStatusFail
+	case status == InspectStatusWarn && r.Status != InspectStatusFail:
+		r.Status = InspectStatusWarn
+	case status == InspectStatusPass && len(r.Status) == 0:
+		r.Status = InspectStatusPass
+	}
+}
+
+// skip will add the checks as skipped
+func (r *InspectReport) skip(reason string, names ...string) {
+	for _, name := range names {
+		r.add(name, time.Now(), InspectStatusSkip, reason)
+	}
+}
+
+// Count will return the number of checks with the given status
+func (r *InspectReport) Count(status InspectStatus) (count int) {
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return
+}
+
+// String will return a human-readable report
+func (r *InspectReport) String() string {
+	var b strings.Builder
+	name := r.Domain
+	if len(r.Address) > 0 {
+		name = r.Address
+	}
+	_, _ = fmt.Fprintf(&b, "paymail inspection: %s (%s)\n", name, r.CheckTime.UTC().Format(time.RFC3339))
+	for _, check := range r.Checks {
+		_, _ = fmt.Fprintf(&b, "  [%-4s] %-15s %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+	}
+	_, _ = fmt.Fprintf(&b, "result: %s (%d passed, %d warnings, %d failed, %d skipped)\n",
+		strings.ToUpper(string(r.Status)), r.Count(InspectStatusPass), r.Count(InspectStatusWarn),
+		r.Count(InspectStatusFail), r.Count(InspectStatusSkip),
+	)
+	return b.String()
+}
@@ -0,0 +1,289 @@
+package paymail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail/tester"
+)
+
+// inspectTestProvider is a local paymail provider (httptest) for testing Inspect()
+type inspectTestProvider struct {
+	capabilities map[string]interface{} // Capabilities returned from the well-known url
+	match        bool                   // Result of the verify pubkey request
+	port         int                    // Port of the server
+	server       *httptest.Server       // The TLS server (certificate is issued for example.com)
+}
+
+// newInspectTestProvider will start a local provider with all the capabilities
+func newInspectTestProvider(t testing.TB) *inspectTestProvider {
+	p := &inspectTestProvider{match: true}
+	p.server, p.port = newTLSTestServer(t)
+	p.server.Config.Handler = http.HandlerFunc(p.handle)
+
+	baseURL := "https://example.com:" + strconv.Itoa(p.port)
+	p.capabilities = map[string]interface{}{
+		BRFCPki:                  baseURL + "/id/{alias}@{domain.tld}",
+		BRFCPaymentDestination:   baseURL + "/address/{alias}@{domain.tld}",
+		BRFCVerifyPublicKeyOwner: baseURL + "/verify/{alias}@{domain.tld}/{pubkey}",
+		BRFCPublicProfile:        baseURL + "/profile/{alias}@{domain.tld}",
+	}
+	return p
+}
+
+// handle will handle the paymail requests
+func (p *inspectTestProvider) handle(w http.ResponseWriter, req *http.Request) {
+	handle := testAlias + "@" + testDomain
+	var response interface{}
+	switch {
+	case req.URL.Path == "/.well-known/"+DefaultServiceName:
+		response = CapabilitiesPayload{BsvAlias: DefaultBsvAliasVersion, Capabilities: p.capabilities}
+	case req.URL.Path == "/id/"+handle:
+		response = PKIPayload{BsvAlias: DefaultBsvAliasVersion, Handle: handle, PubKey: testPubKey}
+	case strings.HasPrefix(req.URL.Path, "/verify/"+handle+"/"):
+		response = VerificationPayload{BsvAlias: DefaultBsvAliasVersion, Handle: handle, Match: p.match, PubKey: testPubKey}
+	case req.URL.Path == "/profile/"+handle:
+		response = PublicProfilePayload{Avatar: "https://example.com/avatar.png", Name: "MrZ"}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// newInspectTestClient will return a client that resolves the test domain to the local provider (via example.com)
+func newInspectTestClient(t testing.TB, p *inspectTestProvider, opts ...ClientOps) ClientInterface {
+	client, err := NewClient(append([]ClientOps{WithTLSRootCAs(tlsTestRootCAs(p.server))}, opts...)...)
+	require.NoError(t, err)
+
+	// Send all the HTTP requests to the local provider
+	httpClient := p.server.Client()
+	transport := httpClient.Transport.(*http.Transport) //nolint:forcetypeassert // httptest always uses an *http.Transport
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, p.server.Listener.Addr().String())
+	}
+	client.WithCustomHTTPClient(resty.NewWithClient(httpClient))
+
+	// Resolve example.com to the local provider
+	local := []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}
+	client.WithCustomResolver(tester.NewCustomResolver(
+		client.GetResolver(),
+		map[string][]string{
+			"example.com": {"127.0.0.1"},
+		},
+		map[string][]*net.SRV{
+			DefaultServiceName + DefaultProtocol + testDomain: {
+				{Target: "example.com.", Port: uint16(p.port), Priority: 10, Weight: 10},
+			},
+			DefaultServiceName + DefaultProtocol + "unavailable.com": {{Target: "."}},
+		},
+		map[string][]net.IPAddr{
+			"example.com": local,
+		},
+	))
+	return client
+}
+
+// checkStatuses will return the status of each check by name
+func checkStatuses(report *InspectReport) map[string]InspectStatus {
+	statuses := make(map[string]InspectStatus)
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+// TestClient_Inspect will test the method Inspect()
+func TestClient_Inspect(t *testing.T) {
+	t.Parallel()
+
+	t.Run("conformant address", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		assert.Equal(t, testAlias+"@"+testDomain, report.Address)
+		assert.Equal(t, testDomain, report.Domain)
+		assert.Equal(t, "example.com", report.SRV.Target)
+		require.NotNil(t, report.Capabilities)
+		assert.Len(t, report.Checks, 9)
+
+		// The httptest port is not the recommended port (443)
+		assert.Equal(t, map[string]InspectStatus{
+			InspectCheckSRV:           InspectStatusPass,
+			InspectCheckSRVValidation: InspectStatusWarn,
+			InspectCheckDNSSEC:        InspectStatusSkip,
+			InspectCheckTLS:           InspectStatusPass,
+			InspectCheckCapabilities:  InspectStatusPass,
+			InspectCheckBRFCs:         InspectStatusPass,
+			InspectCheckPKI:           InspectStatusPass,
+			InspectCheckVerifyPubKey:  InspectStatusPass,
+			InspectCheckPublicProfile: InspectStatusPass,
+		}, checkStatuses(report))
+		assert.Equal(t, InspectStatusWarn, report.Status)
+		assert.Equal(t, 7, report.Count(InspectStatusPass))
+		assert.Equal(t, InspectCheckSRV, report.Checks[0].Name)
+		assert.NotEmpty(t, report.Checks[0].Specs)
+	})
+
+	t.Run("domain only", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect(testDomain, &InspectRequest{SkipDNSSEC: true, SkipTLS: true})
+		require.NoError(t, err)
+		assert.Equal(t, "", report.Address)
+		statuses := checkStatuses(report)
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckTLS])
+		assert.Equal(t, InspectStatusPass, statuses[InspectCheckCapabilities])
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckPKI])
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckVerifyPubKey])
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckPublicProfile])
+	})
+
+	t.Run("missing required capability", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		delete(p.capabilities, BRFCPaymentDestination)
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		assert.Equal(t, InspectStatusFail, report.Status)
+		assert.Equal(t, InspectStatusFail, checkStatuses(report)[InspectCheckCapabilities])
+	})
+
+	t.Run("unknown brfc and optional capabilities", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		delete(p.capabilities, BRFCVerifyPublicKeyOwner)
+		delete(p.capabilities, BRFCPublicProfile)
+		p.capabilities["aaaaaaaaaaaa"] = true
+		p.capabilities["customAlias"] = true
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		statuses := checkStatuses(report)
+		assert.Equal(t, InspectStatusWarn, statuses[InspectCheckBRFCs])
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckVerifyPubKey])
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckPublicProfile])
+		assert.Equal(t, "unknown capabilities: aaaaaaaaaaaa", report.Checks[5].Message)
+	})
+
+	t.Run("pubkey does not match", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		p.match = false
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		assert.Equal(t, InspectStatusFail, report.Status)
+		assert.Equal(t, InspectStatusFail, checkStatuses(report)[InspectCheckVerifyPubKey])
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect("unknown@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		statuses := checkStatuses(report)
+		assert.Equal(t, InspectStatusFail, statuses[InspectCheckPKI])
+		assert.Equal(t, InspectStatusSkip, statuses[InspectCheckVerifyPubKey])
+		assert.Equal(t, InspectStatusFail, statuses[InspectCheckPublicProfile])
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		client := newInspectTestClient(t, p, WithTLSRootCAs(nil))
+
+		report, err := client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		assert.Equal(t, InspectStatusFail, report.Status)
+		assert.Equal(t, InspectStatusFail, checkStatuses(report)[InspectCheckTLS])
+	})
+
+	t.Run("service not available", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		client := newInspectTestClient(t, p)
+
+		report, err := client.Inspect("unavailable.com", nil)
+		require.NoError(t, err)
+		assert.Equal(t, InspectStatusFail, report.Status)
+		assert.Len(t, report.Checks, 9)
+		assert.Equal(t, 8, report.Count(InspectStatusSkip))
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		p := newInspectTestProvider(t)
+		defer p.server.Close()
+		client := newInspectTestClient(t, p)
+
+		var tests = []string{"", "invalid@", "domain", "not a domain.com"}
+		for _, target := range tests {
+			report, err := client.Inspect(target, nil)
+			require.Error(t, err, target)
+			assert.Nil(t, report)
+		}
+	})
+}
+
+// TestInspectReport_String will test the method String()
+func TestInspectReport_String(t *testing.T) {
+	t.Parallel()
+
+	report := &InspectReport{Address: testAlias + "@" + testDomain, Domain: testDomain}
+	report.add(InspectCheckSRV, report.CheckTime, InspectStatusPass, "found 1 record(s)")
+	report.add(InspectCheckDNSSEC, report.CheckTime, InspectStatusWarn, "dnssec is not enabled")
+	report.skip("requires an address", InspectCheckPKI)
+
+	output := report.String()
+	assert.Contains(t, output, "paymail inspection: "+testAlias+"@"+testDomain)
+	assert.Contains(t, output, "[PASS] srv")
+	assert.Contains(t, output, "[WARN] dnssec")
+	assert.Contains(t, output, "[SKIP] pki")
+	assert.Contains(t, output, "result: WARN (1 passed, 1 warnings, 0 failed, 1 skipped)")
+}
+
+// ExampleClient_Inspect example using Inspect()
+//
+// See more examples in /examples/
+func ExampleClient_Inspect() {
+	p := newInspectTestProvider(&testing.T{})
+	defer p.server.Close()
+	client := newInspectTestClient(&testing.T{}, p)
+
+	report, _ := client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+	fmt.Printf("status: %s, passed: %d, failed: %d", report.Status, report.Count(InspectStatusPass), report.Count(InspectStatusFail))
+	// Output:status: warn, passed: 7, failed: 0
+}
+
+// BenchmarkClient_Inspect benchmarks the method Inspect()
+func BenchmarkClient_Inspect(b *testing.B) {
+	p := newInspectTestProvider(b)
+	defer p.server.Close()
+	client := newInspectTestClient(b, p)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.Inspect(testAlias+"@"+testDomain, &InspectRequest{SkipDNSSEC: true})
+	}
+}
@@ -11,20 +11,80 @@ import (
 // ClientInterface is the Paymail client interface
 type ClientInterface interface {
 	CheckDNSSEC(domain string) (result *DNSCheckResult)
+	CheckDNSSECChain(domain string) (result *DNSSECChainResult)
+	CheckDNSSECChainContext(ctx context.Context, domain string) (result *DNSSECChainResult)
+	CheckDNSSECContext(ctx context.Context, domain string) (result *DNSCheckResult)
 	CheckSSL(host string) (valid bool, err error)
+	CheckSSLContext(ctx context.Context, host string) (valid bool, err error)
+	CheckTLS(host string, port int) (report *TLSReport, err error)
+	CheckTLSContext(ctx context.Context, host string, port int) (report *TLSReport, err error)
+	ConvertHandle(handle string, isBeta bool) (paymailAddress string, err error)
+	ConvertHandleContext(ctx context.Context, handle string, isBeta bool) (paymailAddress string, err error)
 	GetBRFCs() []*BRFCSpec
 	GetCapabilities(target string, port int) (response *CapabilitiesResponse, err error)
+	GetCapabilitiesContext(ctx context.Context, target string, port int) (response *CapabilitiesResponse, err error)
 	GetOptions() *ClientOptions
 	GetP2PPaymentDestination(p2pURL, alias, domain string, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PPaymentDestinationContext(ctx context.Context, p2pURL, alias, domain string, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PPaymentDestinationForAddress(ctx context.Context, p2pURL string, address Address, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PTokenPaymentDestination(p2pURL, alias, domain string, tokenRequest *TokenPaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PTokenPaymentDestinationContext(ctx context.Context, p2pURL, alias, domain string, tokenRequest *TokenPaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PTokenPaymentDestinationForAddress(ctx context.Context, p2pURL string, address Address, tokenRequest *TokenPaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetPaymentApproval(approvalURL, alias, domain, id string) (response *PaymentApprovalResponse, err error)
+	GetPaymentApprovalContext(ctx context.Context, approvalURL, alias, domain, id string) (response *PaymentApprovalResponse, err error)
+	GetPaymentApprovalForAddress(ctx context.Context, approvalURL string, address Address, id string) (response *PaymentApprovalResponse, err error)
+	GetPikeOutputs(outputsURL, alias, domain string, outputsRequest *PikeOutputsRequest) (response *PaymentDestinationResponse, err error)
+	GetPikeOutputsContext(ctx context.Context, outputsURL, alias, domain string, outputsRequest *PikeOutputsRequest) (response *PaymentDestinationResponse, err error)
+	GetPikeOutputsForAddress(ctx context.Context, outputsURL string, address Address, outputsRequest *PikeOutputsRequest) (response *PaymentDestinationResponse, err error)
 	GetPKI(pkiURL, alias, domain string) (response *PKIResponse, err error)
+	GetPKIContext(ctx context.Context, pkiURL, alias, domain string) (response *PKIResponse, err error)
+	GetPKIForAddress(ctx context.Context, pkiURL string, address Address) (response *PKIResponse, err error)
 	GetPublicProfile(publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error)
+	GetPublicProfileContext(ctx context.Context, publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error)
+	GetPublicProfileForAddress(ctx context.Context, publicProfileURL string, address Address) (response *PublicProfileResponse, err error)
 	GetResolver() interfaces.DNSResolver
+	GetSFPAssetInformation(assetURL, alias, domain string) (response *SFPAssetResponse, err error)
+	GetSFPAssetInformationContext(ctx context.Context, assetURL, alias, domain string) (response *SFPAssetResponse, err error)
+	GetSFPAssetInformationForAddress(ctx context.Context, assetURL string, address Address) (response *SFPAssetResponse, err error)
 	GetSRVRecord(service, protocol, domainName string) (srv *net.SRV, err error)
+	GetSRVRecordContext(ctx context.Context, service, protocol, domainName string) (srv *net.SRV, err error)
+	GetSRVRecords(service, protocol, domainName string) (records []*net.SRV, err error)
+	GetSRVRecordsContext(ctx context.Context, service, protocol, domainName string) (records []*net.SRV, err error)
 	GetUserAgent() string
+	Inspect(target string, request *InspectRequest) (report *InspectReport, err error)
+	InspectContext(ctx context.Context, target string, request *InspectRequest) (report *InspectReport, err error)
+	InvalidateCapabilities(ctx context.Context, target string, port int)
+	InvalidateSRVRecord(ctx context.Context, service, protocol, domainName string)
+	Resolve(paymailAddress string, request *ResolveRequest) (result *ResolveResult, err error)
 	ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
+	ResolveAddressContext(ctx context.Context, resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
+	ResolveAddressForAddress(ctx context.Context, resolutionURL string, address Address, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
+	ResolveContext(ctx context.Context, paymailAddress string, request *ResolveRequest) (result *ResolveResult, err error)
+	ResolvePayToURI(uri string) (*ResolveResult, error)
+	ResolvePayToURIContext(ctx context.Context, uri string) (*ResolveResult, error)
+	SendP2PPayment(p2pURL, alias, domain string, destination *PaymentDestinationPayload, payment *P2PPayment) (*P2PTransactionResponse, error)
+	SendP2PPaymentContext(ctx context.Context, p2pURL, alias, domain string, destination *PaymentDestinationPayload, payment *P2PPayment) (response *P2PTransactionResponse, err error)
+	SendP2PPaymentForAddress(ctx context.Context, p2pURL string, address Address, destination *PaymentDestinationPayload, payment *P2PPayment) (response *P2PTransactionResponse, err error)
 	SendP2PTransaction(p2pURL, alias, domain string, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
+	SendP2PTransactionContext(ctx context.Context, p2pURL, alias, domain string, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
+	SendP2PTransactionForAddress(ctx context.Context, p2pURL string, address Address, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
+	SendPikeContact(inviteURL, alias, domain string, contact *PikeContactPayload) (response *PikeContactResponse, err error)
+	SendPikeContactContext(ctx context.Context, inviteURL, alias, domain string, contact *PikeContactPayload) (response *PikeContactResponse, err error)
+	SendPikeContactForAddress(ctx context.Context, inviteURL string, address Address, contact *PikeContactPayload) (response *PikeContactResponse, err error)
+	SFPAuthoriseAction(authoriseURL, alias, domain string, authoriseRequest *SFPAuthoriseRequest) (response *SFPAuthoriseResponse, err error)
+	SFPAuthoriseActionContext(ctx context.Context, authoriseURL, alias, domain string, authoriseRequest *SFPAuthoriseRequest) (response *SFPAuthoriseResponse, err error)
+	SFPAuthoriseActionForAddress(ctx context.Context, authoriseURL string, address Address, authoriseRequest *SFPAuthoriseRequest) (response *SFPAuthoriseResponse, err error)
+	SFPBuildAction(buildURL, alias, domain string, buildRequest *SFPBuildRequest) (response *SFPBuildResponse, err error)
+	SFPBuildActionContext(ctx context.Context, buildURL, alias, domain string, buildRequest *SFPBuildRequest) (response *SFPBuildResponse, err error)
+	SFPBuildActionForAddress(ctx context.Context, buildURL string, address Address, buildRequest *SFPBuildRequest) (response *SFPBuildResponse, err error)
+	SubmitPaymentApproval(approvalURL, alias, domain string, senderRequest *SenderRequest) (response *PaymentApprovalResponse, err error)
+	SubmitPaymentApprovalContext(ctx context.Context, approvalURL, alias, domain string, senderRequest *SenderRequest) (response *PaymentApprovalResponse, err error)
+	SubmitPaymentApprovalForAddress(ctx context.Context, approvalURL string, address Address, senderRequest *SenderRequest) (response *PaymentApprovalResponse, err error)
 	ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, priority, weight uint16) error
+	ValidateSRVRecords(ctx context.Context, records []*net.SRV, port uint16) error
 	VerifyPubKey(verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error)
+	VerifyPubKeyContext(ctx context.Context, verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error)
+	VerifyPubKeyForAddress(ctx context.Context, verifyURL string, address Address, pubKey string) (response *VerificationResponse, err error)
 	WithCustomHTTPClient(client *resty.Client) ClientInterface
 	WithCustomResolver(resolver interfaces.DNSResolver) ClientInterface
 }
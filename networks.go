@@ -1,5 +1,16 @@
 package paymail
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/libsv/go-bk/base58"
+	"github.com/libsv/go-bk/crypto"
+)
+
 // Network an alias of the bitcoin networks.
 type Network byte
 
@@ -10,32 +21,124 @@ const (
 	Testnet
 	// STN bitcoin stress test network.
 	STN
+	// Regtest bitcoin regression test network (local development).
+	Regtest
+)
+
+// Address versions (P2PKH) of the networks
+const (
+	MainnetAddressVersion byte = 0x00 // Addresses starting with "1"
+	TestnetAddressVersion byte = 0x6f // Addresses starting with "m" or "n" (testnet, STN & regtest)
+)
+
+var (
+	// ErrInvalidNetwork is when the name or url suffix of a custom network is invalid
+	ErrInvalidNetwork = errors.New("invalid network")
+
+	// ErrNetworkExists is when the name or url suffix of a custom network is already registered
+	ErrNetworkExists = errors.New("network already exists")
 )
 
+// networkParams are the parameters of a network
+type networkParams struct {
+	addressVersion byte   // Version byte of the P2PKH addresses
+	name           string // Name of the network
+	urlSuffix      string // Suffix of the well-known url (IE: /.well-known/bsvalias-testnet)
+}
+
+// networks are the known networks (custom networks are added by RegisterNetwork())
+var (
+	networks = map[Network]*networkParams{
+		Mainnet: {addressVersion: MainnetAddressVersion, name: "mainnet", urlSuffix: ""},
+		Testnet: {addressVersion: TestnetAddressVersion, name: "testnet", urlSuffix: "-testnet"},
+		STN:     {addressVersion: TestnetAddressVersion, name: "STN", urlSuffix: "-stn"},
+		Regtest: {addressVersion: TestnetAddressVersion, name: "regtest", urlSuffix: "-regtest"},
+	}
+	networksMu sync.RWMutex
+)
+
+// urlSuffixPattern is the format of the url suffix of a custom network (IE: -mynet)
+var urlSuffixPattern = regexp.MustCompile(`^-[a-z0-9-]+$`)
+
+// RegisterNetwork will register a custom network (IE: a private test network) with its own
+// well-known url suffix (IE: "-mynet" for /.well-known/bsvalias-mynet) and P2PKH address version
+//
+// The name & url suffix must be unique, the returned network can be used with WithNetwork()
+func RegisterNetwork(name, urlSuffix string, addressVersion byte) (Network, error) {
+
+	// Check the name & suffix
+	if len(strings.TrimSpace(name)) == 0 {
+		return 0, fmt.Errorf("%w: missing name", ErrInvalidNetwork)
+	} else if !urlSuffixPattern.MatchString(urlSuffix) {
+		return 0, fmt.Errorf("%w: url suffix must match %s: %s", ErrInvalidNetwork, urlSuffixPattern.String(), urlSuffix)
+	}
+
+	networksMu.Lock()
+	defer networksMu.Unlock()
+
+	// Check that the network is unique
+	for _, params := range networks {
+		if strings.EqualFold(params.name, name) {
+			return 0, fmt.Errorf("%w: %s", ErrNetworkExists, name)
+		} else if params.urlSuffix == urlSuffix {
+			return 0, fmt.Errorf("%w: url suffix %s", ErrNetworkExists, urlSuffix)
+		}
+	}
+
+	// Add the network with the first free value (the byte values are limited)
+	for i := 0; i <= 0xff; i++ {
+		if n := Network(i); networks[n] == nil {
+			networks[n] = &networkParams{addressVersion: addressVersion, name: name, urlSuffix: urlSuffix}
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: too many networks", ErrInvalidNetwork)
+}
+
+// NetworkFromString will return the network for the name (case-insensitive)
+func NetworkFromString(name string) (Network, bool) {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	for n, params := range networks {
+		if strings.EqualFold(params.name, name) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// params will return the parameters of the network (nil if not recognized)
+func (n Network) params() *networkParams {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	return networks[n]
+}
+
 // String representation of the network.
 func (n Network) String() string {
-	switch n {
-	case Mainnet:
-		return "mainnet"
-	case Testnet:
-		return "testnet"
-	case STN:
-		return "STN"
-	default:
-		return "not recognized"
+	if params := n.params(); params != nil {
+		return params.name
 	}
+	return "not recognized"
 }
 
 // URLSuffix the conventional URL suffix for the network.
 func (n Network) URLSuffix() string {
-	switch n {
-	case Testnet:
-		return "-testnet"
-	case STN:
-		return "-stn"
-	case Mainnet:
-		return ""
-	default:
-		return ""
+	if params := n.params(); params != nil {
+		return params.urlSuffix
+	}
+	return ""
+}
+
+// AddressVersion the version byte of the P2PKH addresses for the network (mainnet if not recognized).
+func (n Network) AddressVersion() byte {
+	if params := n.params(); params != nil {
+		return params.addressVersion
 	}
+	return MainnetAddressVersion
+}
+
+// AddressFromPubKey will return the P2PKH address of the public key (serialised) for the network
+func (n Network) AddressFromPubKey(pubKey []byte) string {
+	return base58.CheckEncode(crypto.Hash160(pubKey), n.AddressVersion())
 }
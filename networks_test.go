@@ -1,17 +1,30 @@
 package paymail
 
 import (
+	"encoding/hex"
+	"fmt"
 	"testing"
 
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2/bscript"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// unregisterNetwork will remove a custom network (registered by a test)
+func unregisterNetwork(n Network) {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+	delete(networks, n)
+}
+
 // TestNetwork_String will test the method String()
 func TestNetwork_String(t *testing.T) {
 	t.Run("valid networks", func(t *testing.T) {
 		assert.Equal(t, "mainnet", Mainnet.String())
 		assert.Equal(t, "testnet", Testnet.String())
 		assert.Equal(t, "STN", STN.String())
+		assert.Equal(t, "regtest", Regtest.String())
 	})
 	t.Run("invalid network", func(t *testing.T) {
 		b := Network(8)
@@ -25,9 +38,130 @@ func TestNetwork_PaymailURLSuffix(t *testing.T) {
 		assert.Equal(t, "", Mainnet.URLSuffix())
 		assert.Equal(t, "-testnet", Testnet.URLSuffix())
 		assert.Equal(t, "-stn", STN.URLSuffix())
+		assert.Equal(t, "-regtest", Regtest.URLSuffix())
 	})
 	t.Run("invalid network", func(t *testing.T) {
 		b := new(Network)
 		assert.Equal(t, "", b.URLSuffix())
 	})
 }
+
+// TestNetwork_AddressFromPubKey will test the methods AddressVersion() and AddressFromPubKey()
+func TestNetwork_AddressFromPubKey(t *testing.T) {
+	t.Parallel()
+
+	pubKey, err := hex.DecodeString(testPubKey)
+	require.NoError(t, err)
+
+	t.Run("mainnet", func(t *testing.T) {
+		expected, err := bitcoin.GetAddressFromPubKeyString(testPubKey, true)
+		require.NoError(t, err)
+		assert.Equal(t, MainnetAddressVersion, Mainnet.AddressVersion())
+		assert.Equal(t, expected.AddressString, Mainnet.AddressFromPubKey(pubKey))
+	})
+
+	t.Run("test networks", func(t *testing.T) {
+		expected, err := bscript.NewAddressFromPublicKeyString(testPubKey, false)
+		require.NoError(t, err)
+		for _, network := range []Network{Testnet, STN, Regtest} {
+			assert.Equal(t, TestnetAddressVersion, network.AddressVersion())
+			assert.Equal(t, expected.AddressString, network.AddressFromPubKey(pubKey), network.String())
+		}
+	})
+
+	t.Run("invalid network defaults to mainnet", func(t *testing.T) {
+		assert.Equal(t, Mainnet.AddressFromPubKey(pubKey), Network(8).AddressFromPubKey(pubKey))
+	})
+}
+
+// TestRegisterNetwork will test the method RegisterNetwork()
+func TestRegisterNetwork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid network", func(t *testing.T) {
+		n, err := RegisterNetwork("register-net", "-register-net", 0x1c)
+		require.NoError(t, err)
+		t.Cleanup(func() { unregisterNetwork(n) })
+		assert.Equal(t, "register-net", n.String())
+		assert.Equal(t, "-register-net", n.URLSuffix())
+		assert.Equal(t, byte(0x1c), n.AddressVersion())
+
+		found, ok := NetworkFromString("REGISTER-NET")
+		assert.True(t, ok)
+		assert.Equal(t, n, found)
+
+		// Duplicates
+		_, err = RegisterNetwork("register-net", "-other-net", 0x1c)
+		assert.ErrorIs(t, err, ErrNetworkExists)
+		_, err = RegisterNetwork("other-net", "-register-net", 0x1c)
+		assert.ErrorIs(t, err, ErrNetworkExists)
+		_, err = RegisterNetwork("Regtest", "-my-regtest", TestnetAddressVersion)
+		assert.ErrorIs(t, err, ErrNetworkExists)
+	})
+
+	var tests = []struct {
+		name      string
+		network   string
+		urlSuffix string
+	}{
+		{"missing name", "", "-missing-name"},
+		{"missing suffix", "missing-suffix", ""},
+		{"suffix without dash", "no-dash", "nodash"},
+		{"suffix with path", "path", "-path/id"},
+		{"suffix with uppercase", "uppercase", "-Upper"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := RegisterNetwork(test.network, test.urlSuffix, TestnetAddressVersion)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrInvalidNetwork)
+		})
+	}
+}
+
+// TestNetworkFromString will test the method NetworkFromString()
+func TestNetworkFromString(t *testing.T) {
+	t.Parallel()
+
+	for _, network := range []Network{Mainnet, Testnet, STN, Regtest} {
+		found, ok := NetworkFromString(network.String())
+		assert.True(t, ok)
+		assert.Equal(t, network, found)
+	}
+
+	_, ok := NetworkFromString("unknown")
+	assert.False(t, ok)
+}
+
+// TestRegisterNetwork_firstFreeValue will test that RegisterNetwork() uses the first free value
+func TestRegisterNetwork_firstFreeValue(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - the network values depend on the other registrations)
+
+	first, err := RegisterNetwork("first-free-net", "-first-free-net", TestnetAddressVersion)
+	require.NoError(t, err)
+	unregisterNetwork(first)
+	assert.Equal(t, "not recognized", first.String())
+
+	again, err := RegisterNetwork("first-free-again-net", "-first-free-again-net", TestnetAddressVersion)
+	require.NoError(t, err)
+	t.Cleanup(func() { unregisterNetwork(again) })
+	assert.Equal(t, first, again)
+}
+
+// ExampleRegisterNetwork example using RegisterNetwork()
+func ExampleRegisterNetwork() {
+	network, err := RegisterNetwork("example-net", "-example-net", TestnetAddressVersion)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("capabilities: /.well-known/%s%s", DefaultServiceName, network.URLSuffix())
+	// Output:capabilities: /.well-known/bsvalias-example-net
+}
+
+// BenchmarkNetwork_URLSuffix benchmarks the method URLSuffix()
+func BenchmarkNetwork_URLSuffix(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = Regtest.URLSuffix()
+	}
+}
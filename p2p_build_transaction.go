@@ -0,0 +1,229 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+)
+
+// ErrSignerCannotSignInputs is when the signer of a P2PPayment cannot sign the transaction inputs
+// (IE: a remote signer, see: NewRemoteSigner()), use a TransactionSigner and the other signer as MetaDataSigner
+var ErrSignerCannotSignInputs = errors.New("signer cannot sign transaction inputs (not a TransactionSigner)")
+
+// UTXOSource returns the unspent outputs used to fund a P2P transaction
+type UTXOSource interface {
+	// UTXOs will return unspent outputs worth (ideally) at least the deficit in satoshis,
+	// bt.ErrNoUTXO is returned when there are no more outputs
+	UTXOs(ctx context.Context, deficit uint64) ([]*bt.UTXO, error)
+}
+
+// UTXOSourceFunc is an adapter to use a function as a UTXOSource
+type UTXOSourceFunc func(ctx context.Context, deficit uint64) ([]*bt.UTXO, error)
+
+// UTXOs will call the function
+func (f UTXOSourceFunc) UTXOs(ctx context.Context, deficit uint64) ([]*bt.UTXO, error) {
+	return f(ctx, deficit)
+}
+
+// staticUTXOSource hands out a fixed list of unspent outputs (in order)
+type staticUTXOSource struct {
+	mu    sync.Mutex
+	utxos []*bt.UTXO
+}
+
+// NewStaticUTXOSource will return a UTXOSource that hands out the unspent outputs in order,
+// every output is only used once
+func NewStaticUTXOSource(utxos ...*bt.UTXO) UTXOSource {
+	return &staticUTXOSource{utxos: utxos}
+}
+
+// UTXOs will return the next outputs until the deficit is covered
+func (s *staticUTXOSource) UTXOs(_ context.Context, deficit uint64) (utxos []*bt.UTXO, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.utxos) == 0 {
+		err = bt.ErrNoUTXO
+		return
+	}
+	var total uint64
+	for len(s.utxos) > 0 && total < deficit {
+		total += s.utxos[0].Satoshis
+		utxos = append(utxos, s.utxos[0])
+		s.utxos = s.utxos[1:]
+	}
+	return
+}
+
+// TransactionSigner signs the inputs of a P2P transaction (bt.UnlockerGetter)
+// and the tx id for the P2PMetaData (Signer)
+type TransactionSigner interface {
+	bt.UnlockerGetter
+	Signer
+}
+
+// P2PPayment is the information needed to build a P2P transaction for a payment destination
+//
+// The Signer must be a TransactionSigner (NewPrivateKeySigner() or NewWIFSigner()) to sign the inputs,
+// a Signer that only signs messages (NewRemoteSigner()) can be used as the MetaDataSigner
+type P2PPayment struct {
+	ChangeAddress  string       // Address for the change (default: address of the signer's pubkey)
+	FeeQuote       *bt.FeeQuote // Fees for the transaction (default: bt.NewFeeQuote())
+	MetaDataSigner Signer       // Signs the tx id (default: Signer), IE: the sender's paymail key in an HSM
+	Note           string       // A human-readable bit of information about the payment
+	Sender         string       // The paymail of the sender (alias@domain.tld)
+	Signer         Signer       // Signs the inputs & the tx id, must be a TransactionSigner (required)
+	UTXOs          UTXOSource   // Unspent outputs to fund the transaction (required)
+}
+
+// BuildP2PTransaction will build a signed transaction paying every output of the payment destination,
+// funded by the UTXO source (with change), with the tx id signed in the metadata
+//
+// Specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html
+func BuildP2PTransaction(ctx context.Context, destination *PaymentDestinationPayload,
+	payment *P2PPayment) (transaction *P2PTransaction, err error) {
+
+	// Basic requirements
+	if destination == nil || len(destination.Outputs) == 0 {
+		err = errors.New("payment destination is missing outputs")
+		return
+	} else if len(destination.Reference) == 0 {
+		err = errors.New("payment destination is missing a reference")
+		return
+	} else if payment == nil || payment.Signer == nil {
+		err = errors.New("missing signer")
+		return
+	} else if payment.UTXOs == nil {
+		err = errors.New("missing utxo source")
+		return
+	}
+
+	// The signer must sign the inputs (checked before any utxo is taken from the source)
+	inputSigner, ok := payment.Signer.(TransactionSigner)
+	if !ok {
+		err = ErrSignerCannotSignInputs
+		return
+	}
+
+	// Add the outputs of the destination
+	tx := bt.NewTx()
+	for _, output := range destination.Outputs {
+		var script *bscript.Script
+		if script, err = bscript.NewFromHexString(output.Script); err != nil {
+			err = fmt.Errorf("invalid output script %s: %w", output.Script, err)
+			return
+		}
+		tx.AddOutput(&bt.Output{LockingScript: script, Satoshis: output.Satoshis})
+	}
+
+	// Fund the transaction
+	feeQuote := payment.FeeQuote
+	if feeQuote == nil {
+		feeQuote = bt.NewFeeQuote()
+	}
+	if err = tx.Fund(ctx, feeQuote, payment.UTXOs.UTXOs); err != nil {
+		return
+	}
+
+	// Add the change (default is the signer's address)
+	var pubKey string
+	if pubKey, err = payment.Signer.PubKey(ctx); err != nil {
+		return
+	}
+	changeAddress := payment.ChangeAddress
+	if len(changeAddress) == 0 {
+		var address *bscript.Address
+		if address, err = bitcoin.GetAddressFromPubKeyString(pubKey, true); err != nil {
+			return
+		}
+		changeAddress = address.AddressString
+	}
+	if err = tx.ChangeToAddress(changeAddress, feeQuote); err != nil {
+		return
+	}
+
+	// Sign the inputs
+	if err = tx.FillAllInputs(ctx, inputSigner); err != nil {
+		return
+	}
+
+	// Sign the tx id
+	transaction = &P2PTransaction{
+		Hex:       tx.String(),
+		MetaData:  &P2PMetaData{Note: payment.Note, Sender: payment.Sender},
+		Reference: destination.Reference,
+	}
+	metaDataSigner := payment.MetaDataSigner
+	if metaDataSigner == nil {
+		metaDataSigner = payment.Signer
+	}
+	if err = SignP2PMetaData(ctx, metaDataSigner, transaction.MetaData, tx.TxID()); err != nil {
+		transaction = nil
+	}
+	return
+}
+
+// SignP2PMetaData will set the pubkey & the signature of the tx id in the metadata using the signer
+//
+// Specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html
+func SignP2PMetaData(ctx context.Context, signer Signer, metaData *P2PMetaData, txID string) (err error) {
+	if signer == nil {
+		err = errors.New("missing signer")
+		return
+	} else if metaData == nil {
+		err = errors.New("missing metadata")
+		return
+	}
+	if metaData.PubKey, err = signer.PubKey(ctx); err != nil {
+		return
+	}
+	metaData.Signature, err = signer.SignMessage(ctx, txID)
+	return
+}
+
+// SendP2PPayment will build the transaction for the payment destination (see: BuildP2PTransaction())
+// and submit it to the paymail provider
+//
+// Specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html
+func (c *Client) SendP2PPayment(p2pURL, alias, domain string, destination *PaymentDestinationPayload,
+	payment *P2PPayment) (*P2PTransactionResponse, error) {
+	return c.SendP2PPaymentContext(context.Background(), p2pURL, alias, domain, destination, payment)
+}
+
+// SendP2PPaymentContext is the same as SendP2PPayment() but accepts a context
+// that is used for cancellation and deadlines on the UTXO source, signer & HTTP request
+func (c *Client) SendP2PPaymentContext(ctx context.Context, p2pURL, alias, domain string,
+	destination *PaymentDestinationPayload, payment *P2PPayment) (response *P2PTransactionResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.SendP2PPaymentForAddress(ctx, p2pURL, address, destination, payment)
+}
+
+// SendP2PPaymentForAddress is the same as SendP2PPaymentContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) SendP2PPaymentForAddress(ctx context.Context, p2pURL string, address Address,
+	destination *PaymentDestinationPayload, payment *P2PPayment) (response *P2PTransactionResponse, err error) {
+
+	// Require a paymail address (before any utxo is taken from the source)
+	if address.IsZero() {
+		err = ErrMissingAddress
+		return
+	}
+
+	// Build the transaction
+	var transaction *P2PTransaction
+	if transaction, err = BuildP2PTransaction(ctx, destination, payment); err != nil {
+		return
+	}
+
+	// Send the transaction
+	return c.SendP2PTransactionForAddress(ctx, p2pURL, address, transaction)
+}
@@ -0,0 +1,276 @@
+package paymail
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/jarcoal/httpmock"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDestKey    = "e83385af76b2b1997326b567461fb73dd9c27eab9e1e86d26779f4650c5f2b75"
+	testPrevTxID   = "f3ddfabf7a7a84cfa20016e61df24dff32953d4023a3002cb5a98d6da4ef9bf1"
+	testPrivateKey = "54035dd4c7dda99ac473905a3d82f7864322b49bab1ff441cc457183b9bd8abd"
+	testReference  = "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7"
+)
+
+// testDestAddress will return the address of the payment destination
+func testDestAddress() string {
+	address, _ := bitcoin.GetAddressFromPrivateKeyString(testDestKey, true)
+	return address
+}
+
+// testUTXO will return a utxo of the test private key
+func testUTXO(satoshis uint64, vout uint32) *bt.UTXO {
+	address, _ := bitcoin.GetAddressFromPrivateKeyString(testPrivateKey, true)
+	lockingScript, _ := bscript.NewP2PKHFromAddress(address)
+	txID, _ := hex.DecodeString(testPrevTxID)
+	return &bt.UTXO{LockingScript: lockingScript, Satoshis: satoshis, TxID: txID, Vout: vout}
+}
+
+// newTestPayment will return a payment funded by a single utxo of the test private key
+func newTestPayment(t *testing.T, satoshis ...uint64) *P2PPayment {
+	signer, err := NewPrivateKeySigner(testPrivateKey)
+	require.NoError(t, err)
+
+	var utxos []*bt.UTXO
+	for i, amount := range satoshis {
+		utxos = append(utxos, testUTXO(amount, uint32(i)))
+	}
+	return &P2PPayment{
+		Note:   "test payment",
+		Sender: "satchmo@" + testDomain,
+		Signer: signer,
+		UTXOs:  NewStaticUTXOSource(utxos...),
+	}
+}
+
+// newTestDestination will return a payment destination with a single output
+func newTestDestination(t *testing.T, satoshis uint64) *PaymentDestinationPayload {
+	script, err := bitcoin.ScriptFromAddress(testDestAddress())
+	require.NoError(t, err)
+	return &PaymentDestinationPayload{
+		Outputs:   []*PaymentOutput{{Address: testDestAddress(), Satoshis: satoshis, Script: script}},
+		Reference: testReference,
+	}
+}
+
+// TestBuildP2PTransaction will test the method BuildP2PTransaction()
+func TestBuildP2PTransaction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid transaction", func(t *testing.T) {
+		payment := newTestPayment(t, 10000)
+		destination := newTestDestination(t, 1000)
+		transaction, err := BuildP2PTransaction(context.Background(), destination, payment)
+		require.NoError(t, err)
+		require.NotNil(t, transaction)
+		assert.Equal(t, destination.Reference, transaction.Reference)
+		assert.Equal(t, "test payment", transaction.MetaData.Note)
+		assert.Equal(t, "satchmo@"+testDomain, transaction.MetaData.Sender)
+
+		// Outputs: destination & change
+		tx, err := bt.NewTxFromString(transaction.Hex)
+		require.NoError(t, err)
+		require.Len(t, tx.Outputs, 2)
+		assert.Equal(t, destination.Outputs[0].Script, tx.Outputs[0].LockingScript.String())
+		assert.Equal(t, uint64(1000), tx.Outputs[0].Satoshis)
+		assert.Greater(t, tx.Outputs[1].Satoshis, uint64(8000))
+		assert.Less(t, tx.Outputs[1].Satoshis, uint64(9000))
+
+		// Inputs are signed
+		require.Len(t, tx.Inputs, 1)
+		assert.NotEmpty(t, tx.Inputs[0].UnlockingScript.String())
+
+		// Signature of the tx id matches the pubkey (compressed)
+		address, err := bitcoin.GetAddressFromPubKeyString(transaction.MetaData.PubKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, bitcoin.VerifyMessage(address.AddressString, transaction.MetaData.Signature, tx.TxID()))
+	})
+
+	t.Run("several utxos and custom change address", func(t *testing.T) {
+		payment := newTestPayment(t, 600, 600, 600, 600)
+		payment.ChangeAddress = testDestAddress()
+		transaction, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1500), payment)
+		require.NoError(t, err)
+
+		tx, err := bt.NewTxFromString(transaction.Hex)
+		require.NoError(t, err)
+		assert.Len(t, tx.Inputs, 3)
+		require.Len(t, tx.Outputs, 2)
+		assert.Equal(t, tx.Outputs[0].LockingScript.String(), tx.Outputs[1].LockingScript.String())
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		_, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), newTestPayment(t, 500))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, bt.ErrInsufficientFunds)
+	})
+
+	t.Run("utxo source error", func(t *testing.T) {
+		payment := newTestPayment(t)
+		payment.UTXOs = UTXOSourceFunc(func(context.Context, uint64) ([]*bt.UTXO, error) {
+			return nil, errors.New("source is down")
+		})
+		_, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), payment)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "source is down")
+	})
+
+	t.Run("invalid output script", func(t *testing.T) {
+		destination := newTestDestination(t, 1000)
+		destination.Outputs[0].Script = "invalid"
+		_, err := BuildP2PTransaction(context.Background(), destination, newTestPayment(t, 10000))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid change address", func(t *testing.T) {
+		payment := newTestPayment(t, 10000)
+		payment.ChangeAddress = "invalid"
+		_, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), payment)
+		require.Error(t, err)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		destination := newTestDestination(t, 1000)
+		_, err := BuildP2PTransaction(context.Background(), nil, newTestPayment(t, 10000))
+		require.Error(t, err)
+		_, err = BuildP2PTransaction(context.Background(), &PaymentDestinationPayload{Outputs: destination.Outputs}, newTestPayment(t, 10000))
+		require.Error(t, err)
+		_, err = BuildP2PTransaction(context.Background(), destination, nil)
+		require.Error(t, err)
+		_, err = BuildP2PTransaction(context.Background(), destination, &P2PPayment{Signer: newTestPayment(t).Signer})
+		require.Error(t, err)
+	})
+
+	t.Run("signer cannot sign the inputs", func(t *testing.T) {
+		signer, err := NewRemoteSigner(newTestSigningService(t).URL, "test", nil)
+		require.NoError(t, err)
+		payment := newTestPayment(t, 10000)
+		payment.Signer = signer
+		_, err = BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), payment)
+		require.ErrorIs(t, err, ErrSignerCannotSignInputs)
+
+		// The utxos were not taken from the source
+		utxos, err := payment.UTXOs.UTXOs(context.Background(), 10000)
+		require.NoError(t, err)
+		assert.Len(t, utxos, 1)
+	})
+
+	t.Run("remote signer for the metadata", func(t *testing.T) {
+		signer, err := NewRemoteSigner(newTestSigningService(t).URL, "test", nil)
+		require.NoError(t, err)
+		payment := newTestPayment(t, 10000)
+		payment.MetaDataSigner = signer
+		transaction, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), payment)
+		require.NoError(t, err)
+		pubKey, err := bitcoin.PubKeyFromPrivateKeyString(testPrivateKey, true)
+		require.NoError(t, err)
+		assert.Equal(t, pubKey, transaction.MetaData.PubKey)
+		assert.NotEmpty(t, transaction.MetaData.Signature)
+	})
+}
+
+// TestNewStaticUTXOSource will test the method NewStaticUTXOSource()
+func TestNewStaticUTXOSource(t *testing.T) {
+	t.Parallel()
+
+	source := NewStaticUTXOSource(&bt.UTXO{Satoshis: 100}, &bt.UTXO{Satoshis: 200}, &bt.UTXO{Satoshis: 300})
+	utxos, err := source.UTXOs(context.Background(), 250)
+	require.NoError(t, err)
+	assert.Len(t, utxos, 2)
+
+	utxos, err = source.UTXOs(context.Background(), 1000)
+	require.NoError(t, err)
+	assert.Len(t, utxos, 1)
+
+	_, err = source.UTXOs(context.Background(), 1)
+	assert.ErrorIs(t, err, bt.ErrNoUTXO)
+}
+
+// TestClient_SendP2PPayment will test the method SendP2PPayment()
+func TestClient_SendP2PPayment(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+
+	t.Run("valid payment", func(t *testing.T) {
+		var sent string
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testAlias+"@"+testDomain,
+			func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				sent = string(body)
+				return httpmock.NewStringResponse(http.StatusOK, `{"note":"test payment","txid":"`+testPrevTxID+`"}`), nil
+			},
+		)
+
+		response, err := client.SendP2PPayment(
+			testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain,
+			newTestDestination(t, 1000), newTestPayment(t, 10000),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, testPrevTxID, response.TxID)
+		assert.Contains(t, sent, `"reference":"`+testReference+`"`)
+	})
+
+	t.Run("build error", func(t *testing.T) {
+		response, err := client.SendP2PPayment(
+			testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain,
+			newTestDestination(t, 1000), newTestPayment(t, 100),
+		)
+		require.Error(t, err)
+		assert.Nil(t, response)
+	})
+}
+
+// ExampleBuildP2PTransaction example using BuildP2PTransaction()
+func ExampleBuildP2PTransaction() {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+
+	// The payment destination (from GetP2PPaymentDestination())
+	script, _ := bitcoin.ScriptFromAddress(testDestAddress())
+	destination := &PaymentDestinationPayload{
+		Outputs:   []*PaymentOutput{{Satoshis: 1000, Script: script}},
+		Reference: testReference,
+	}
+
+	// Build the transaction
+	transaction, err := BuildP2PTransaction(context.Background(), destination, &P2PPayment{
+		Signer: signer,
+		UTXOs:  NewStaticUTXOSource(testUTXO(10000, 0)),
+	})
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("transaction built for reference: %s", transaction.Reference)
+	// Output:transaction built for reference: z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7
+}
+
+// BenchmarkBuildP2PTransaction benchmarks the method BuildP2PTransaction()
+func BenchmarkBuildP2PTransaction(b *testing.B) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	script, _ := bitcoin.ScriptFromAddress(testDestAddress())
+	destination := &PaymentDestinationPayload{
+		Outputs:   []*PaymentOutput{{Satoshis: 1000, Script: script}},
+		Reference: testReference,
+	}
+	for i := 0; i < b.N; i++ {
+		_, _ = BuildP2PTransaction(context.Background(), destination, &P2PPayment{
+			Signer: signer,
+			UTXOs:  NewStaticUTXOSource(testUTXO(10000, 0)),
+		})
+	}
+}
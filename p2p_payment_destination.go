@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,7 +20,8 @@ Example:
 
 // PaymentRequest is the request body for the P2P payment request
 type PaymentRequest struct {
-	Satoshis uint64 `json:"satoshis"` // The amount, in Satoshis, that the sender intends to transfer to the receiver
+	ApprovalID string `json:"approvalId,omitempty"` // ID of the approved payment, if the receiver requires approvals (see: SubmitPaymentApproval())
+	Satoshis   uint64 `json:"satoshis"`             // The amount, in Satoshis, that the sender intends to transfer to the receiver
 }
 
 // PaymentDestinationResponse is the response from the GetP2PPaymentDestination() request
@@ -52,6 +54,26 @@ type PaymentOutput struct {
 //
 // Specs: https://docs.moneybutton.com/docs/paymail-07-p2p-payment-destination.html
 func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
+	paymentRequest *PaymentRequest) (*PaymentDestinationResponse, error) {
+	return c.GetP2PPaymentDestinationContext(context.Background(), p2pURL, alias, domain, paymentRequest)
+}
+
+// GetP2PPaymentDestinationContext is the same as GetP2PPaymentDestination() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetP2PPaymentDestinationContext(ctx context.Context, p2pURL, alias, domain string,
+	paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetP2PPaymentDestinationForAddress(ctx, p2pURL, address, paymentRequest)
+}
+
+// GetP2PPaymentDestinationForAddress is the same as GetP2PPaymentDestinationContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetP2PPaymentDestinationForAddress(ctx context.Context, p2pURL string, address Address,
 	paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error) {
 
 	// Require a valid url
@@ -67,63 +89,60 @@ func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 	} else if paymentRequest.Satoshis == 0 {
 		err = errors.New("satoshis is required")
 		return
-	} else if len(alias) == 0 {
-		err = errors.New("missing alias")
-		return
-	} else if len(domain) == 0 {
-		err = errors.New("missing domain")
+	}
+
+	// Require a paymail address
+	if address.IsZero() {
+		err = ErrMissingAddress
 		return
 	}
 
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/api/rawtx/{alias}@{domain.tld}
 	// https://<host-discovery-target>/api/p2p-payment-destination/{alias}@{domain.tld}
-	reqURL := replaceAliasDomain(p2pURL, alias, domain)
+	reqURL := replaceAddress(p2pURL, address)
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, paymentRequest); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, paymentRequest); err != nil {
+		err = newRequestError(StepP2PDestination, reqURL, err)
 		return
 	}
 
+	return newPaymentDestinationResponse(StepP2PDestination, reqURL, resp, true)
+}
+
+// newPaymentDestinationResponse will check & decode the response of the payment destination requests
+//
+// If requireAddress is false, outputs that are not P2PKH (IE: token scripts) are returned without an address
+func newPaymentDestinationResponse(step, reqURL string, resp StandardResponse,
+	requireAddress bool) (response *PaymentDestinationResponse, err error) {
+
 	// Start the response
 	response = &PaymentDestinationResponse{StandardResponse: resp}
 
 	// Test the status code
 	if response.StatusCode != http.StatusOK &&
 		response.StatusCode != http.StatusNotModified {
-
-		// Paymail address not found?
-		if response.StatusCode == http.StatusNotFound {
-			err = errors.New("paymail address not found")
-		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return
-			}
-			err = fmt.Errorf(
-				"bad response from paymail provider: code %d, message: %s",
-				response.StatusCode, serverError.Message,
-			)
-		}
-
+		err = newResponseError(step, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
 	// Decode the body of the response
 	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(step, reqURL, response.StatusCode, err)
 		return
 	}
 
 	// Check for a reference number
 	if len(response.Reference) == 0 {
-		err = errors.New("missing a returned reference value")
+		err = newInvalidResponseError(step, reqURL, response.StatusCode, errors.New("missing a returned reference value"))
 		return
 	}
 
 	// No outputs?
 	if len(response.Outputs) == 0 {
-		err = errors.New("missing a returned output")
+		err = newInvalidResponseError(step, reqURL, response.StatusCode, errors.New("missing a returned output"))
 		return
 	}
 
@@ -132,16 +151,19 @@ func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 
 		// No script returned
 		if len(out.Script) == 0 {
-			err = fmt.Errorf("script was missing from output: %d", index)
+			err = newInvalidResponseError(step, reqURL, response.StatusCode, fmt.Errorf("script was missing from output: %d", index))
 			return
 		}
 
 		// Extract the address
-		if response.Outputs[index].Address, err = bitcoin.GetAddressFromScript(
-			out.Script,
-		); err != nil {
-			return
+		var address string
+		if address, err = bitcoin.GetAddressFromScript(out.Script); err != nil {
+			if requireAddress {
+				return
+			}
+			err = nil
 		}
+		response.Outputs[index].Address = address
 	}
 
 	return
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -35,6 +36,23 @@ func TestClient_GetP2PPaymentDestination(t *testing.T) {
 		assert.Equal(t, uint64(100), destination.Outputs[0].Satoshis)
 	})
 
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockP2PPaymentDestination(http.StatusOK)
+
+		destination, err := client.GetP2PPaymentDestinationContext(
+			canceledContext(),
+			testServerURL+"p2p-payment-destination/{alias}@{domain.tld}",
+			testAlias,
+			testDomain,
+			&PaymentRequest{Satoshis: 100},
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, destination)
+	})
+
 	t.Run("successful response - status not modified", func(t *testing.T) {
 		client := newTestClient(t)
 
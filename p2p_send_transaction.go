@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,9 +25,11 @@ Example:
 
 // P2PTransaction is the request body for the P2P transaction request
 type P2PTransaction struct {
-	Hex       string       `json:"hex"`       // The raw transaction, encoded as a hexadecimal string
-	MetaData  *P2PMetaData `json:"metadata"`  // An object containing data associated with the transaction
-	Reference string       `json:"reference"` // Reference for the payment (from previous P2P Destination request)
+	Beef        string       `json:"beef,omitempty"` // The transaction with its ancestors & merkle proofs (BEEF), if the receiver supports it
+	DecodedBeef *DecodedBEEF `json:"-"`              // The verified BEEF envelope (set by the server when a beef is received)
+	Hex         string       `json:"hex"`            // The raw transaction, encoded as a hexadecimal string
+	MetaData    *P2PMetaData `json:"metadata"`       // An object containing data associated with the transaction
+	Reference   string       `json:"reference"`      // Reference for the payment (from previous P2P Destination request)
 }
 
 // P2PMetaData is an object containing data associated with the P2P transaction
@@ -51,19 +54,42 @@ type P2PTransactionPayload struct {
 
 // SendP2PTransaction will submit a transaction hex string (tx_hex) to a paymail provider
 //
+// A BEEF envelope (transaction.Beef) can be sent to a provider with the BRFCBeefTransaction capability,
+// the hex of the subject transaction is then set from the envelope (if missing)
+//
 // Specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html
 func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
+	transaction *P2PTransaction) (*P2PTransactionResponse, error) {
+	return c.SendP2PTransactionContext(context.Background(), p2pURL, alias, domain, transaction)
+}
+
+// SendP2PTransactionContext is the same as SendP2PTransaction() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) SendP2PTransactionContext(ctx context.Context, p2pURL, alias, domain string,
+	transaction *P2PTransaction) (response *P2PTransactionResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.SendP2PTransactionForAddress(ctx, p2pURL, address, transaction)
+}
+
+// SendP2PTransactionForAddress is the same as SendP2PTransactionContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) SendP2PTransactionForAddress(ctx context.Context, p2pURL string, address Address,
 	transaction *P2PTransaction) (response *P2PTransactionResponse, err error) {
 
 	// Require a valid url
 	if len(p2pURL) == 0 || !strings.Contains(p2pURL, "https://") {
 		err = fmt.Errorf("invalid url: %s", p2pURL)
 		return
-	} else if len(alias) == 0 {
-		err = errors.New("missing alias")
-		return
-	} else if len(domain) == 0 {
-		err = errors.New("missing domain")
+	}
+
+	// Require a paymail address
+	if address.IsZero() {
+		err = ErrMissingAddress
 		return
 	}
 
@@ -71,22 +97,32 @@ func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 	if transaction == nil {
 		err = errors.New("transaction cannot be nil")
 		return
-	} else if len(transaction.Hex) == 0 {
-		err = errors.New("hex is required")
+	} else if len(transaction.Hex) == 0 && len(transaction.Beef) == 0 {
+		err = errors.New("hex or beef is required")
 		return
 	} else if len(transaction.Reference) == 0 {
 		err = errors.New("reference is required")
 		return
 	}
 
+	// Set the hex from the BEEF envelope (for providers without BEEF support)
+	if len(transaction.Hex) == 0 {
+		var beef *DecodedBEEF
+		if beef, err = DecodeBEEF(transaction.Beef); err != nil {
+			return
+		}
+		transaction.Hex = beef.SubjectTx().String()
+	}
+
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/api/rawtx/{alias}@{domain.tld}
 	// https://<host-discovery-target>/api/receive-transaction/{alias}@{domain.tld}
-	reqURL := replaceAliasDomain(p2pURL, alias, domain)
+	reqURL := replaceAddress(p2pURL, address)
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, transaction); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, transaction); err != nil {
+		err = newRequestError(StepP2PSendTransaction, reqURL, err)
 		return
 	}
 
@@ -95,33 +131,19 @@ func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 
 	// Test the status code
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-
-		// Paymail address not found?
-		if response.StatusCode == http.StatusNotFound {
-			err = errors.New("paymail address not found")
-		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return
-			}
-			if len(serverError.Message) == 0 {
-				err = fmt.Errorf("bad response from paymail provider: code %d, body: %s", response.StatusCode, string(resp.Body))
-			} else {
-				err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
-			}
-		}
-
+		err = newResponseError(StepP2PSendTransaction, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
 	// Decode the body of the response
 	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(StepP2PSendTransaction, reqURL, response.StatusCode, err)
 		return
 	}
 
 	// Check for a TX ID
 	if len(response.TxID) == 0 {
-		err = errors.New("missing a returned txid")
+		err = newInvalidResponseError(StepP2PSendTransaction, reqURL, response.StatusCode, errors.New("missing a returned txid"))
 		return
 	}
 
@@ -1,6 +1,8 @@
 package paymail
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -97,6 +99,38 @@ func BenchmarkClient_SendP2PTransaction(b *testing.B) {
 	}
 }
 
+// TestClient_SendP2PTransactionContextCanceled will test the method SendP2PTransactionContext()
+func TestClient_SendP2PTransactionContextCanceled(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	// Create a client with options
+	client := newTestClient(t)
+
+	// Create mock response
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"note":"test note","txid":"f3ddfabf7a7a84cfa20016e61df24dff32953d4023a3002cb5a98d6da4ef9bf1"}`,
+		),
+	)
+
+	// Raw TX
+	rawTransaction := &P2PTransaction{
+		Hex:       "some-raw-hex",
+		MetaData:  &P2PMetaData{Note: "test note", Sender: "someone@" + testDomain},
+		Reference: "1234567",
+	}
+
+	// Fire the request
+	transaction, err := client.SendP2PTransactionContext(
+		canceledContext(), testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain, rawTransaction,
+	)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, transaction)
+}
+
 // TestClient_SendP2PTransactionStatusNotModified will test the method SendP2PTransaction()
 func TestClient_SendP2PTransactionStatusNotModified(t *testing.T) {
 	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
@@ -444,3 +478,49 @@ func TestClient_SendP2PTransactionStatusMissingTxID(t *testing.T) {
 	require.NotNil(t, transaction)
 	assert.Equal(t, 0, len(transaction.TxID))
 }
+
+// TestClient_SendP2PTransactionBEEF will test the method SendP2PTransaction() with a BEEF envelope
+func TestClient_SendP2PTransactionBEEF(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	p2pURL := testServerURL + "receive-transaction/{alias}@{domain.tld}"
+
+	t.Run("hex is set from the envelope", func(t *testing.T) {
+		var sent *P2PTransaction
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testAlias+"@"+testDomain,
+			func(req *http.Request) (*http.Response, error) {
+				sent = new(P2PTransaction)
+				if err := json.NewDecoder(req.Body).Decode(sent); err != nil {
+					return nil, err
+				}
+				return httpmock.NewStringResponse(http.StatusOK, `{"txid":"`+testPrevTxID+`"}`), nil
+			},
+		)
+
+		beef, _ := newTestBEEF(t)
+		_, err := client.SendP2PTransaction(p2pURL, testAlias, testDomain, &P2PTransaction{
+			Beef:      beef.Hex(),
+			MetaData:  &P2PMetaData{},
+			Reference: testReference,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, sent)
+		assert.Equal(t, beef.Hex(), sent.Beef)
+		assert.Equal(t, beef.SubjectTx().String(), sent.Hex)
+	})
+
+	t.Run("invalid envelope", func(t *testing.T) {
+		_, err := client.SendP2PTransaction(p2pURL, testAlias, testDomain, &P2PTransaction{
+			Beef:      "0100beef",
+			Reference: testReference,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("missing hex & beef", func(t *testing.T) {
+		_, err := client.SendP2PTransaction(p2pURL, testAlias, testDomain, &P2PTransaction{Reference: testReference})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,100 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Known token schemes (the receiver can support any scheme)
+const (
+	TokenSchemeBSV20 = "bsv-20" // BSV-20 tokens (1Sat Ordinals)
+	TokenSchemeRun   = "run"    // Run tokens
+	TokenSchemeSTAS  = "STAS"   // STAS tokens
+)
+
+/*
+Example:
+{
+  "amount": 1000,
+  "scheme": "STAS",
+  "tokenId": "1a2b3c..."
+}
+*/
+
+// TokenPaymentRequest is the request body for the P2P payment destination with tokens request
+type TokenPaymentRequest struct {
+	Amount     uint64 `json:"amount"`               // The amount of tokens that the sender intends to transfer to the receiver
+	ApprovalID string `json:"approvalId,omitempty"` // ID of the approved payment, if the receiver requires approvals (see: SubmitPaymentApproval())
+	Scheme     string `json:"scheme"`               // The token protocol (IE: STAS, bsv-20, run)
+	TokenID    string `json:"tokenId"`              // The ID of the token (IE: the symbol, or the genesis outpoint/txid)
+}
+
+// GetP2PTokenPaymentDestination will return a list of outputs for a P2P transaction sending tokens,
+// the outputs can be token scripts (the output address is only set for P2PKH outputs)
+//
+// Specs: https://docs.moneybutton.com/docs/paymail/paymail-11-p2p-payment-destination-tokens.html
+func (c *Client) GetP2PTokenPaymentDestination(p2pURL, alias, domain string,
+	tokenRequest *TokenPaymentRequest) (*PaymentDestinationResponse, error) {
+	return c.GetP2PTokenPaymentDestinationContext(context.Background(), p2pURL, alias, domain, tokenRequest)
+}
+
+// GetP2PTokenPaymentDestinationContext is the same as GetP2PTokenPaymentDestination() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetP2PTokenPaymentDestinationContext(ctx context.Context, p2pURL, alias, domain string,
+	tokenRequest *TokenPaymentRequest) (response *PaymentDestinationResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetP2PTokenPaymentDestinationForAddress(ctx, p2pURL, address, tokenRequest)
+}
+
+// GetP2PTokenPaymentDestinationForAddress is the same as GetP2PTokenPaymentDestinationContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetP2PTokenPaymentDestinationForAddress(ctx context.Context, p2pURL string, address Address,
+	tokenRequest *TokenPaymentRequest) (response *PaymentDestinationResponse, err error) {
+
+	// Require a valid url
+	if len(p2pURL) == 0 || !strings.Contains(p2pURL, "https://") {
+		err = fmt.Errorf("invalid url: %s", p2pURL)
+		return
+	}
+
+	// Basic requirements for request
+	if tokenRequest == nil {
+		err = errors.New("tokenRequest cannot be nil")
+		return
+	} else if tokenRequest.Amount == 0 {
+		err = errors.New("amount is required")
+		return
+	} else if len(tokenRequest.Scheme) == 0 {
+		err = errors.New("scheme is required")
+		return
+	} else if len(tokenRequest.TokenID) == 0 {
+		err = errors.New("tokenId is required")
+		return
+	}
+
+	// Require a paymail address
+	if address.IsZero() {
+		err = ErrMissingAddress
+		return
+	}
+
+	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
+	// https://<host-discovery-target>/api/p2p-payment-destination-token/{alias}@{domain.tld}
+	reqURL := replaceAddress(p2pURL, address)
+
+	// Fire the POST request
+	var resp StandardResponse
+	if resp, err = c.postRequest(ctx, reqURL, tokenRequest); err != nil {
+		err = newRequestError(StepP2PTokenDestination, reqURL, err)
+		return
+	}
+
+	return newPaymentDestinationResponse(StepP2PTokenDestination, reqURL, resp, false)
+}
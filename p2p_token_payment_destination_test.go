@@ -0,0 +1,153 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTokenID     = "e9a4d4e0c8b2d7a1f3c6b5a4d3c2b1a0e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4"
+	testTokenScript = "006a0474657374"
+)
+
+// mockP2PTokenPaymentDestination will mock the token destination (P2PKH & token output)
+func mockP2PTokenPaymentDestination(statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"p2p-payment-destination-token/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			statusCode,
+			`{"outputs": [{"script": "76a9143e2d1d795f8acaa7957045cc59376177eb04a3c588ac","satoshis": 1},{"script": "`+testTokenScript+`","satoshis": 0}],"reference": "`+testReference+`"}`,
+		),
+	)
+}
+
+// testTokenRequest will return a basic token request
+func testTokenRequest() *TokenPaymentRequest {
+	return &TokenPaymentRequest{Amount: 10, Scheme: TokenSchemeSTAS, TokenID: testTokenID}
+}
+
+// TestClient_GetP2PTokenPaymentDestination will test the method GetP2PTokenPaymentDestination()
+func TestClient_GetP2PTokenPaymentDestination(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	tokenURL := testServerURL + "p2p-payment-destination-token/{alias}@{domain.tld}"
+
+	t.Run("successful response", func(t *testing.T) {
+		mockP2PTokenPaymentDestination(http.StatusOK)
+		destination, err := client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, testTokenRequest())
+		require.NoError(t, err)
+		require.Len(t, destination.Outputs, 2)
+		assert.Equal(t, testReference, destination.Reference)
+
+		// Only the P2PKH output has an address
+		assert.NotEmpty(t, destination.Outputs[0].Address)
+		assert.Empty(t, destination.Outputs[1].Address)
+		assert.Equal(t, testTokenScript, destination.Outputs[1].Script)
+	})
+
+	t.Run("token fields are sent", func(t *testing.T) {
+		var sent string
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"p2p-payment-destination-token/"+testAlias+"@"+testDomain,
+			func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				sent = string(body)
+				return httpmock.NewStringResponse(http.StatusOK, `{"outputs":[{"script":"`+testTokenScript+`"}],"reference":"`+testReference+`"}`), nil
+			},
+		)
+		_, err := client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, testTokenRequest())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"amount":10,"scheme":"STAS","tokenId":"`+testTokenID+`"}`, sent)
+	})
+
+	t.Run("bad response", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"p2p-payment-destination-token/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"outputs":[],"reference":"`+testReference+`"}`),
+		)
+		_, err := client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, testTokenRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepP2PTokenDestination, providerErr.Step)
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"p2p-payment-destination-token/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"paymail not found"}`),
+		)
+		_, err := client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, testTokenRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockP2PTokenPaymentDestination(http.StatusOK)
+		destination, err := client.GetP2PTokenPaymentDestinationContext(canceledContext(), tokenURL, testAlias, testDomain, testTokenRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, destination)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.GetP2PTokenPaymentDestination("", testAlias, testDomain, testTokenRequest())
+		require.Error(t, err)
+		_, err = client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, nil)
+		require.Error(t, err)
+		_, err = client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, &TokenPaymentRequest{Scheme: TokenSchemeSTAS, TokenID: testTokenID})
+		require.Error(t, err)
+		_, err = client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, &TokenPaymentRequest{Amount: 1, TokenID: testTokenID})
+		require.Error(t, err)
+		_, err = client.GetP2PTokenPaymentDestination(tokenURL, testAlias, testDomain, &TokenPaymentRequest{Amount: 1, Scheme: TokenSchemeSTAS})
+		require.Error(t, err)
+		_, err = client.GetP2PTokenPaymentDestination(tokenURL, "", testDomain, testTokenRequest())
+		require.Error(t, err)
+		_, err = client.GetP2PTokenPaymentDestination(tokenURL, testAlias, "", testTokenRequest())
+		require.Error(t, err)
+	})
+}
+
+// ExampleClient_GetP2PTokenPaymentDestination example using GetP2PTokenPaymentDestination()
+func ExampleClient_GetP2PTokenPaymentDestination() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockP2PTokenPaymentDestination(http.StatusOK)
+
+	// Fire the request
+	destination, err := client.GetP2PTokenPaymentDestination(
+		testServerURL+"p2p-payment-destination-token/{alias}@{domain.tld}",
+		testAlias,
+		testDomain,
+		&TokenPaymentRequest{Amount: 10, Scheme: TokenSchemeSTAS, TokenID: testTokenID},
+	)
+	if err != nil {
+		fmt.Printf("error occurred in GetP2PTokenPaymentDestination: %s", err.Error())
+		return
+	}
+	fmt.Printf("token destination outputs: %d", len(destination.Outputs))
+	// Output:token destination outputs: 2
+}
+
+// BenchmarkClient_GetP2PTokenPaymentDestination benchmarks the method GetP2PTokenPaymentDestination()
+func BenchmarkClient_GetP2PTokenPaymentDestination(b *testing.B) {
+	client := newTestClient(nil)
+	mockP2PTokenPaymentDestination(http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetP2PTokenPaymentDestination(
+			testServerURL+"p2p-payment-destination-token/{alias}@{domain.tld}",
+			testAlias, testDomain, testTokenRequest(),
+		)
+	}
+}
@@ -19,6 +19,9 @@ const (
 	testServerURL = "https://" + testDomain + "/api/v1/" + DefaultServiceName + "/"
 )
 
+// testPaymail is the paymail address of the test alias & domain
+var testPaymail = MustParseAddress(testAlias + "@" + testDomain)
+
 // TestVersion will test the method Version()
 func TestVersion(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,133 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PayToScheme is the URI scheme of the PayTo protocol prefix (IE: payto:alias@domain.tld?amount=1000)
+//
+// Specs: http://bsvalias.org/04-04-payto-protocol-prefix.html
+const PayToScheme = "payto"
+
+// PayTo query parameters
+const (
+	PayToParamAmount  = "amount"  // Amount in Satoshis
+	PayToParamPurpose = "purpose" // Human-readable description of the purpose of the payment
+)
+
+// PayToURI is a payment request using the PayTo protocol prefix
+//
+// Example: payto:mrz@moneybutton.com?amount=1000&purpose=coffee
+type PayToURI struct {
+	Address string `json:"address"`           // The sanitized paymail address (alias@domain.tld)
+	Alias   string `json:"alias"`             // Alias of the paymail
+	Amount  uint64 `json:"amount,omitempty"`  // Amount in Satoshis (0 if not set)
+	Domain  string `json:"domain"`            // Domain of the paymail
+	Purpose string `json:"purpose,omitempty"` // Human-readable description of the purpose of the payment
+}
+
+// NewPayToURI will return a PayTo URI for the paymail address (or $handle / 1handle)
+//
+// Specs: http://bsvalias.org/04-04-payto-protocol-prefix.html
+func NewPayToURI(paymailAddress string, amount uint64, purpose string) (*PayToURI, error) {
+	address, err := ParseAddress(paymailAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &PayToURI{
+		Address: address.String(),
+		Alias:   address.Alias(),
+		Amount:  amount,
+		Domain:  address.Domain(),
+		Purpose: purpose,
+	}, nil
+}
+
+// ParsePayToURI will parse & validate a PayTo URI (IE: payto:alias@domain.tld?amount=1000&purpose=coffee)
+//
+// The scheme is case-insensitive, "payto://" is also accepted and handles ($handle / 1handle) are converted.
+// Unknown query parameters are ignored.
+//
+// Specs: http://bsvalias.org/04-04-payto-protocol-prefix.html
+func ParsePayToURI(uri string) (*PayToURI, error) {
+
+	// Check the scheme
+	uri = strings.TrimSpace(uri)
+	scheme, rest, found := strings.Cut(uri, ":")
+	if !found || !strings.EqualFold(scheme, PayToScheme) {
+		return nil, fmt.Errorf("invalid payto uri: missing %s: scheme", PayToScheme)
+	}
+	rest = strings.TrimPrefix(rest, "//")
+
+	// Split the target & the query
+	target, rawQuery, _ := strings.Cut(rest, "?")
+	target, err := url.PathUnescape(strings.TrimSuffix(target, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid payto uri: %w", err)
+	}
+	var query url.Values
+	if query, err = url.ParseQuery(rawQuery); err != nil {
+		return nil, fmt.Errorf("invalid payto uri: %w", err)
+	}
+
+	// Validate the paymail
+	var amount uint64
+	if value := query.Get(PayToParamAmount); len(value) > 0 {
+		if amount, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid payto uri: amount must be in satoshis: %s", value)
+		}
+	}
+	var payTo *PayToURI
+	if payTo, err = NewPayToURI(target, amount, query.Get(PayToParamPurpose)); err != nil {
+		return nil, fmt.Errorf("invalid payto uri: %w", err)
+	}
+	return payTo, nil
+}
+
+// String will return the canonical PayTo URI (payto:alias@domain.tld?amount=1000&purpose=coffee)
+func (p *PayToURI) String() string {
+	query := url.Values{}
+	if p.Amount > 0 {
+		query.Set(PayToParamAmount, strconv.FormatUint(p.Amount, 10))
+	}
+	if len(p.Purpose) > 0 {
+		query.Set(PayToParamPurpose, p.Purpose)
+	}
+	uri := PayToScheme + ":" + p.Address
+	if len(query) > 0 {
+		uri += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	return uri
+}
+
+// ResolvePayToURI will parse the PayTo URI and resolve it into a P2P payment destination
+// for the amount of the URI (see: Resolve())
+//
+// Specs: http://bsvalias.org/04-04-payto-protocol-prefix.html
+func (c *Client) ResolvePayToURI(uri string) (*ResolveResult, error) {
+	return c.ResolvePayToURIContext(context.Background(), uri)
+}
+
+// ResolvePayToURIContext is the same as ResolvePayToURI() but accepts a context
+// that is used for cancellation and deadlines on every step
+func (c *Client) ResolvePayToURIContext(ctx context.Context, uri string) (*ResolveResult, error) {
+
+	// Parse the uri (an amount is required for the payment destination)
+	payTo, err := ParsePayToURI(uri)
+	if err != nil {
+		return nil, err
+	} else if payTo.Amount == 0 {
+		return nil, errors.New("payto uri is missing an amount")
+	}
+
+	// Resolve the payment destination
+	return c.ResolveContext(ctx, payTo.Address, &ResolveRequest{
+		Operation:      ResolveOperationPaymentDestination,
+		PaymentRequest: &PaymentRequest{Satoshis: payTo.Amount},
+	})
+}
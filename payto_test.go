@@ -0,0 +1,144 @@
+package paymail
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePayToURI will test the method ParsePayToURI()
+func TestParsePayToURI(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		uri             string
+		expectedAddress string
+		expectedAmount  uint64
+		expectedPurpose string
+		expectedError   bool
+	}{
+		{"payto:mrz@test.com", "mrz@test.com", 0, "", false},
+		{"payto:mrz@test.com?amount=1000", "mrz@test.com", 1000, "", false},
+		{"payto:mrz@test.com?amount=1000&purpose=coffee%20and%20cake", "mrz@test.com", 1000, "coffee and cake", false},
+		{"payto:mrz@test.com?purpose=coffee+and+cake", "mrz@test.com", 0, "coffee and cake", false},
+		{"PAYTO:MrZ@Test.com", "mrz@test.com", 0, "", false},
+		{"payto://mrz@test.com/?amount=5", "mrz@test.com", 5, "", false},
+		{"payto:mrz%40test.com", "mrz@test.com", 0, "", false},
+		{" payto:mrz@test.com?amount=1&unknown=1 ", "mrz@test.com", 1, "", false},
+		{"payto:$mrz", "mrz@handcash.io", 0, "", false},
+		{"payto:mrz@test.com?amount=1.5", "", 0, "", true},
+		{"payto:mrz@test.com?amount=-1", "", 0, "", true},
+		{"payto:mrz@test.com?amount=%zz", "", 0, "", true},
+		{"payto:invalid", "", 0, "", true},
+		{"payto:", "", 0, "", true},
+		{"bitcoin:mrz@test.com", "", 0, "", true},
+		{"mrz@test.com", "", 0, "", true},
+		{"", "", 0, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.uri, func(t *testing.T) {
+			payTo, err := ParsePayToURI(test.uri)
+			if test.expectedError {
+				require.Error(t, err)
+				assert.Nil(t, payTo)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedAddress, payTo.Address)
+			assert.Equal(t, test.expectedAmount, payTo.Amount)
+			assert.Equal(t, test.expectedPurpose, payTo.Purpose)
+		})
+	}
+}
+
+// TestPayToURI_String will test the method String()
+func TestPayToURI_String(t *testing.T) {
+	t.Parallel()
+
+	t.Run("canonical uri", func(t *testing.T) {
+		payTo, err := NewPayToURI("MrZ@Test.com", 1000, "coffee & cake")
+		require.NoError(t, err)
+		assert.Equal(t, "payto:mrz@test.com?amount=1000&purpose=coffee%20%26%20cake", payTo.String())
+
+		// Parses back to the same values
+		parsed, err := ParsePayToURI(payTo.String())
+		require.NoError(t, err)
+		assert.Equal(t, payTo, parsed)
+	})
+
+	t.Run("no amount or purpose", func(t *testing.T) {
+		payTo, err := NewPayToURI("mrz@test.com", 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, "payto:mrz@test.com", payTo.String())
+	})
+
+	t.Run("invalid paymail", func(t *testing.T) {
+		payTo, err := NewPayToURI("invalid", 1000, "")
+		require.Error(t, err)
+		assert.Nil(t, payTo)
+	})
+}
+
+// TestClient_ResolvePayToURI will test the method ResolvePayToURI()
+func TestClient_ResolvePayToURI(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("payment destination", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockP2PPaymentDestination(http.StatusOK)
+		mockResolveCapabilities()
+
+		result, err := client.ResolvePayToURI("payto:" + testAlias + "@" + testDomain + "?amount=100")
+		require.NoError(t, err)
+		require.NotNil(t, result.PaymentDestination)
+		assert.Equal(t, testAlias+"@"+testDomain, result.Address)
+		assert.Equal(t, uint64(100), result.PaymentDestination.Outputs[0].Satoshis)
+	})
+
+	t.Run("missing amount", func(t *testing.T) {
+		client := newTestClient(t)
+		result, err := client.ResolvePayToURI("payto:" + testAlias + "@" + testDomain)
+		require.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid uri", func(t *testing.T) {
+		client := newTestClient(t)
+		result, err := client.ResolvePayToURI("invalid")
+		require.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+// ExampleParsePayToURI example using ParsePayToURI()
+func ExampleParsePayToURI() {
+	payTo, err := ParsePayToURI("payto:MrZ@moneybutton.com?amount=1000&purpose=coffee")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("pay %d satoshis to %s for %s", payTo.Amount, payTo.Address, payTo.Purpose)
+	// Output:pay 1000 satoshis to mrz@moneybutton.com for coffee
+}
+
+// ExampleNewPayToURI example using NewPayToURI()
+func ExampleNewPayToURI() {
+	payTo, err := NewPayToURI("mrz@moneybutton.com", 1000, "coffee")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("uri: %s", payTo.String())
+	// Output:uri: payto:mrz@moneybutton.com?amount=1000&purpose=coffee
+}
+
+// BenchmarkParsePayToURI benchmarks the method ParsePayToURI()
+func BenchmarkParsePayToURI(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = ParsePayToURI("payto:mrz@moneybutton.com?amount=1000&purpose=coffee")
+	}
+}
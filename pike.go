@@ -0,0 +1,272 @@
+package paymail
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/libsv/go-bk/bec"
+)
+
+/*
+Example (capability):
+{
+  "8c4ed5ef8ace": {
+    "invite": "https://example.com/api/v1/bsvalias/contact/invite/{alias}@{domain.tld}",
+    "outputs": "https://example.com/api/v1/bsvalias/pike/outputs/{alias}@{domain.tld}"
+  }
+}
+*/
+
+// PikeCapability is the capability of PIKE (paymail identity key exchange), the urls of the
+// contact invite & the outputs requests
+type PikeCapability struct {
+	Invite  string `json:"invite"`  // The url for the contact invite (request)
+	Outputs string `json:"outputs"` // The url for the outputs request (Type-42 derived outputs)
+}
+
+/*
+Example (contact):
+{
+  "fullName": "Satoshi Nakamoto",
+  "paymail": "satoshi@moneybutton.com",
+  "pubKey": "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"
+}
+*/
+
+// PikeContactResponse is the response from the SendPikeContact() request
+type PikeContactResponse struct {
+	StandardResponse
+	PikeContactPayload
+}
+
+// PikeContactPayload is the contact exchanged with PIKE, the sender sends its own contact
+// and the receiver returns its contact (with the identity key of the receiver)
+type PikeContactPayload struct {
+	FullName string `json:"fullName,omitempty"` // The name of the contact (for display)
+	Paymail  string `json:"paymail"`            // The paymail of the contact (alias@domain.tld)
+	PubKey   string `json:"pubKey"`             // The identity key of the contact (compressed, hex)
+}
+
+/*
+Example (outputs request):
+{
+  "senderPaymail": "satoshi@moneybutton.com",
+  "amount": 1000
+}
+*/
+
+// PikeOutputsRequest is the request body for the PIKE outputs request
+type PikeOutputsRequest struct {
+	Amount        uint64 `json:"amount"`        // The amount, in Satoshis, that the sender intends to transfer
+	SenderPaymail string `json:"senderPaymail"` // The paymail of the sender (must be a contact of the receiver)
+}
+
+// GetPikeCapability will return the PIKE capability (invite & outputs urls)
+//
+// Returns nil if the capability is not found (or is not an object)
+func (c *CapabilitiesPayload) GetPikeCapability() *PikeCapability {
+	ok, val := c.getValue(BRFCPike, "")
+	if !ok {
+		return nil
+	}
+
+	// The capability is an object (decoded or set by a server)
+	switch capability := val.(type) {
+	case *PikeCapability:
+		return capability
+	case map[string]string:
+		return &PikeCapability{Invite: capability["invite"], Outputs: capability["outputs"]}
+	case map[string]interface{}:
+		pike := &PikeCapability{}
+		pike.Invite, _ = capability["invite"].(string)
+		pike.Outputs, _ = capability["outputs"].(string)
+		return pike
+	}
+	return nil
+}
+
+// SendPikeContact will send the contact of the sender (invite) to the receiver (alias@domain), the
+// receiver stores the contact and returns its own contact (the identity keys are exchanged)
+//
+// The inviteURL is the "invite" url of GetPikeCapability()
+func (c *Client) SendPikeContact(inviteURL, alias, domain string,
+	contact *PikeContactPayload) (*PikeContactResponse, error) {
+	return c.SendPikeContactContext(context.Background(), inviteURL, alias, domain, contact)
+}
+
+// SendPikeContactContext is the same as SendPikeContact() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) SendPikeContactContext(ctx context.Context, inviteURL, alias, domain string,
+	contact *PikeContactPayload) (response *PikeContactResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.SendPikeContactForAddress(ctx, inviteURL, address, contact)
+}
+
+// SendPikeContactForAddress is the same as SendPikeContactContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) SendPikeContactForAddress(ctx context.Context, inviteURL string, address Address,
+	contact *PikeContactPayload) (response *PikeContactResponse, err error) {
+
+	// Require a valid url & paymail
+	if err = validateServiceAddress(inviteURL, address); err != nil {
+		return
+	}
+
+	// Basic requirements for request
+	if err = contact.Validate(); err != nil {
+		return
+	}
+
+	// Fire the POST request
+	reqURL := replaceAddress(inviteURL, address)
+	var resp StandardResponse
+	if resp, err = c.postRequest(ctx, reqURL, contact); err != nil {
+		err = newRequestError(StepPikeInvite, reqURL, err)
+		return
+	}
+
+	// Decode the response
+	response = &PikeContactResponse{StandardResponse: resp}
+	if err = decodeServiceResponse(StepPikeInvite, reqURL, resp, response); err != nil {
+		return
+	}
+
+	// Check the contact of the receiver
+	if err = response.PikeContactPayload.Validate(); err != nil {
+		err = newInvalidResponseError(StepPikeInvite, reqURL, response.StatusCode, err)
+	}
+	return
+}
+
+// GetPikeOutputs will return the outputs (Type-42 derived from the identity keys) for a payment
+// from the sender (a contact of the receiver) to the receiver (alias@domain)
+//
+// The outputsURL is the "outputs" url of GetPikeCapability(), the transaction is sent with
+// SendP2PTransaction() using the returned reference
+func (c *Client) GetPikeOutputs(outputsURL, alias, domain string,
+	outputsRequest *PikeOutputsRequest) (*PaymentDestinationResponse, error) {
+	return c.GetPikeOutputsContext(context.Background(), outputsURL, alias, domain, outputsRequest)
+}
+
+// GetPikeOutputsContext is the same as GetPikeOutputs() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetPikeOutputsContext(ctx context.Context, outputsURL, alias, domain string,
+	outputsRequest *PikeOutputsRequest) (response *PaymentDestinationResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetPikeOutputsForAddress(ctx, outputsURL, address, outputsRequest)
+}
+
+// GetPikeOutputsForAddress is the same as GetPikeOutputsContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetPikeOutputsForAddress(ctx context.Context, outputsURL string, address Address,
+	outputsRequest *PikeOutputsRequest) (response *PaymentDestinationResponse, err error) {
+
+	// Require a valid url & paymail
+	if err = validateServiceAddress(outputsURL, address); err != nil {
+		return
+	}
+
+	// Basic requirements for request
+	if err = outputsRequest.Validate(); err != nil {
+		return
+	}
+
+	// Fire the POST request
+	reqURL := replaceAddress(outputsURL, address)
+	var resp StandardResponse
+	if resp, err = c.postRequest(ctx, reqURL, outputsRequest); err != nil {
+		err = newRequestError(StepPikeOutputs, reqURL, err)
+		return
+	}
+
+	return newPaymentDestinationResponse(StepPikeOutputs, reqURL, resp, true)
+}
+
+// Validate will check the required fields of the contact
+func (p *PikeContactPayload) Validate() error {
+	if p == nil {
+		return errors.New("contact cannot be nil")
+	} else if err := ValidatePaymail(p.Paymail); err != nil {
+		return fmt.Errorf("invalid paymail: %w", err)
+	} else if _, err = parsePubKey(p.PubKey); err != nil {
+		return fmt.Errorf("invalid pubKey: %w", err)
+	}
+	return nil
+}
+
+// Validate will check the required fields of the outputs request
+func (r *PikeOutputsRequest) Validate() error {
+	if r == nil {
+		return errors.New("outputsRequest cannot be nil")
+	} else if r.Amount == 0 {
+		return errors.New("amount is required")
+	} else if err := ValidatePaymail(r.SenderPaymail); err != nil {
+		return fmt.Errorf("invalid senderPaymail: %w", err)
+	}
+	return nil
+}
+
+// PikeSharedSecret will return the shared secret (ECDH point) of the identity keys, the
+// sender (private key & the pubKey of the receiver) and the receiver (private key & the
+// pubKey of the sender) compute the same secret
+func PikeSharedSecret(privateKey *bec.PrivateKey, publicKey *bec.PublicKey) *bec.PublicKey {
+	x, y := bec.S256().ScalarMult(publicKey.X, publicKey.Y, privateKey.D.Bytes())
+	return &bec.PublicKey{Curve: bec.S256(), X: x, Y: y}
+}
+
+// DeriveType42PublicKey will derive the child public key of the publicKey (IE: the identity key of the receiver)
+// for the invoice number, the sender derives the keys of the outputs without the private key of the receiver
+//
+// Specs: https://github.com/bitcoin-sv/BRCs/blob/master/key-derivation/0042.md
+func DeriveType42PublicKey(publicKey, sharedSecret *bec.PublicKey, invoiceNumber string) *bec.PublicKey {
+	tweak := type42Tweak(sharedSecret, invoiceNumber)
+	x, y := bec.S256().ScalarBaseMult(tweak.Bytes())
+	x, y = bec.S256().Add(publicKey.X, publicKey.Y, x, y)
+	return &bec.PublicKey{Curve: bec.S256(), X: x, Y: y}
+}
+
+// DeriveType42PrivateKey will derive the child private key of the privateKey (IE: the identity key of the receiver)
+// for the invoice number, the public key is the same as DeriveType42PublicKey()
+//
+// Specs: https://github.com/bitcoin-sv/BRCs/blob/master/key-derivation/0042.md
+func DeriveType42PrivateKey(privateKey *bec.PrivateKey, sharedSecret *bec.PublicKey,
+	invoiceNumber string) *bec.PrivateKey {
+	d := new(big.Int).Add(privateKey.D, type42Tweak(sharedSecret, invoiceNumber))
+	d.Mod(d, bec.S256().N)
+	key, _ := bec.PrivKeyFromBytes(bec.S256(), d.Bytes())
+	return key
+}
+
+// type42Tweak will return the HMAC (key: shared secret, message: invoice number) as a scalar
+func type42Tweak(sharedSecret *bec.PublicKey, invoiceNumber string) *big.Int {
+	mac := hmac.New(sha256.New, sharedSecret.SerialiseCompressed())
+	_, _ = mac.Write([]byte(invoiceNumber))
+	return new(big.Int).Mod(new(big.Int).SetBytes(mac.Sum(nil)), bec.S256().N)
+}
+
+// parsePubKey will parse the public key (compressed, hex)
+func parsePubKey(pubKey string) (*bec.PublicKey, error) {
+	if len(pubKey) != PubKeyLength {
+		return nil, fmt.Errorf("pubKey must be %d characters", PubKeyLength)
+	}
+	b, err := hex.DecodeString(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return bec.ParsePubKey(b, bec.S256())
+}
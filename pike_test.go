@@ -0,0 +1,289 @@
+package paymail
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/libsv/go-bk/bec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPikeSender    = "satoshi@" + testDomain
+	testPikeSenderKey = "ed9e3a2d2b6e7e0a2a3f1cbd2e05e7c8b4b0e3b9c7a1f6d5e4c3b2a1f0e9d8c7"
+)
+
+// mockPikeInvite will mock the contact invite response
+func mockPikeInvite(statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"contact/invite/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			statusCode,
+			`{"fullName": "Test User","paymail": "`+testAlias+`@`+testDomain+`","pubKey": "`+testPubKey+`"}`,
+		),
+	)
+}
+
+// mockPikeOutputs will mock the outputs response
+func mockPikeOutputs(statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"pike/outputs/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			statusCode,
+			`{"outputs": [{"script": "`+testOutput+`","satoshis": 1000}],"reference": "`+testReference+`"}`,
+		),
+	)
+}
+
+// testPikeContact will return the contact of the sender
+func testPikeContact() *PikeContactPayload {
+	return &PikeContactPayload{FullName: "Satoshi Nakamoto", Paymail: testPikeSender, PubKey: testPubKey}
+}
+
+// TestCapabilitiesPayload_GetPikeCapability will test the method GetPikeCapability()
+func TestCapabilitiesPayload_GetPikeCapability(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name     string
+		value    interface{}
+		expected *PikeCapability
+	}{
+		{"decoded object", map[string]interface{}{"invite": "https://a/invite", "outputs": "https://a/outputs"}, &PikeCapability{Invite: "https://a/invite", Outputs: "https://a/outputs"}},
+		{"string map", map[string]string{"invite": "https://a/invite"}, &PikeCapability{Invite: "https://a/invite"}},
+		{"capability struct", &PikeCapability{Outputs: "https://a/outputs"}, &PikeCapability{Outputs: "https://a/outputs"}},
+		{"invalid type", "https://a/invite", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			capabilities := &CapabilitiesPayload{Capabilities: map[string]interface{}{BRFCPike: test.value}}
+			assert.Equal(t, test.expected, capabilities.GetPikeCapability())
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		capabilities := &CapabilitiesPayload{Capabilities: map[string]interface{}{BRFCPki: "https://a/id"}}
+		assert.Nil(t, capabilities.GetPikeCapability())
+	})
+}
+
+// TestClient_SendPikeContact will test the method SendPikeContact()
+func TestClient_SendPikeContact(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	inviteURL := testServerURL + "contact/invite/{alias}@{domain.tld}"
+
+	t.Run("successful response", func(t *testing.T) {
+		mockPikeInvite(http.StatusOK)
+		contact, err := client.SendPikeContact(inviteURL, testAlias, testDomain, testPikeContact())
+		require.NoError(t, err)
+		require.NotNil(t, contact)
+		assert.Equal(t, http.StatusOK, contact.StatusCode)
+		assert.Equal(t, testAlias+"@"+testDomain, contact.Paymail)
+		assert.Equal(t, testPubKey, contact.PubKey)
+	})
+
+	t.Run("invalid contact returned", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"contact/invite/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"paymail": "`+testAlias+`@`+testDomain+`","pubKey": "02ff"}`),
+		)
+		_, err := client.SendPikeContact(inviteURL, testAlias, testDomain, testPikeContact())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepPikeInvite, providerErr.Step)
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"contact/invite/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"paymail not found"}`),
+		)
+		_, err := client.SendPikeContact(inviteURL, testAlias, testDomain, testPikeContact())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockPikeInvite(http.StatusOK)
+		contact, err := client.SendPikeContactContext(canceledContext(), inviteURL, testAlias, testDomain, testPikeContact())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, contact)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.SendPikeContact("", testAlias, testDomain, testPikeContact())
+		require.Error(t, err)
+		_, err = client.SendPikeContact(inviteURL, "", testDomain, testPikeContact())
+		require.Error(t, err)
+		_, err = client.SendPikeContact(inviteURL, testAlias, "", testPikeContact())
+		require.Error(t, err)
+		_, err = client.SendPikeContact(inviteURL, testAlias, testDomain, nil)
+		require.Error(t, err)
+		_, err = client.SendPikeContact(inviteURL, testAlias, testDomain, &PikeContactPayload{Paymail: "invalid", PubKey: testPubKey})
+		require.Error(t, err)
+		_, err = client.SendPikeContact(inviteURL, testAlias, testDomain, &PikeContactPayload{Paymail: testPikeSender, PubKey: "invalid"})
+		require.Error(t, err)
+	})
+}
+
+// TestClient_GetPikeOutputs will test the method GetPikeOutputs()
+func TestClient_GetPikeOutputs(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	outputsURL := testServerURL + "pike/outputs/{alias}@{domain.tld}"
+	outputsRequest := &PikeOutputsRequest{Amount: 1000, SenderPaymail: testPikeSender}
+
+	t.Run("successful response", func(t *testing.T) {
+		mockPikeOutputs(http.StatusOK)
+		destination, err := client.GetPikeOutputs(outputsURL, testAlias, testDomain, outputsRequest)
+		require.NoError(t, err)
+		require.NotNil(t, destination)
+		assert.Equal(t, testReference, destination.Reference)
+		require.Len(t, destination.Outputs, 1)
+		assert.Equal(t, testOutput, destination.Outputs[0].Script)
+		assert.NotEmpty(t, destination.Outputs[0].Address)
+	})
+
+	t.Run("contact not found", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"pike/outputs/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code":"contact-not-found","message":"contact not found"}`),
+		)
+		_, err := client.GetPikeOutputs(outputsURL, testAlias, testDomain, outputsRequest)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrContactNotFound)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepPikeOutputs, providerErr.Step)
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"pike/outputs/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"outputs": [{"script": "`+testOutput+`"}]}`),
+		)
+		_, err := client.GetPikeOutputs(outputsURL, testAlias, testDomain, outputsRequest)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockPikeOutputs(http.StatusOK)
+		destination, err := client.GetPikeOutputsContext(canceledContext(), outputsURL, testAlias, testDomain, outputsRequest)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, destination)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.GetPikeOutputs("http://"+testDomain+"/pike", testAlias, testDomain, outputsRequest)
+		require.Error(t, err)
+		_, err = client.GetPikeOutputs(outputsURL, testAlias, testDomain, nil)
+		require.Error(t, err)
+		_, err = client.GetPikeOutputs(outputsURL, testAlias, testDomain, &PikeOutputsRequest{SenderPaymail: testPikeSender})
+		require.Error(t, err)
+		_, err = client.GetPikeOutputs(outputsURL, testAlias, testDomain, &PikeOutputsRequest{Amount: 1000})
+		require.Error(t, err)
+	})
+}
+
+// TestDeriveType42PublicKey will test the method DeriveType42PublicKey()
+func TestDeriveType42PublicKey(t *testing.T) {
+	t.Parallel()
+
+	sender := mustPrivateKey(t, testPikeSenderKey)
+	receiver := mustPrivateKey(t, testPrivateKey)
+
+	t.Run("shared secret is symmetric", func(t *testing.T) {
+		assert.True(t, PikeSharedSecret(sender, receiver.PubKey()).IsEqual(PikeSharedSecret(receiver, sender.PubKey())))
+	})
+
+	t.Run("derived keys match", func(t *testing.T) {
+		// The sender derives the public key, the receiver derives the private key
+		publicKey := DeriveType42PublicKey(receiver.PubKey(), PikeSharedSecret(sender, receiver.PubKey()), "2-pike-1")
+		privateKey := DeriveType42PrivateKey(receiver, PikeSharedSecret(receiver, sender.PubKey()), "2-pike-1")
+		assert.True(t, publicKey.IsEqual(privateKey.PubKey()))
+		assert.False(t, publicKey.IsEqual(receiver.PubKey()))
+	})
+
+	t.Run("invoice numbers derive different keys", func(t *testing.T) {
+		sharedSecret := PikeSharedSecret(sender, receiver.PubKey())
+		first := DeriveType42PublicKey(receiver.PubKey(), sharedSecret, "2-pike-1")
+		second := DeriveType42PublicKey(receiver.PubKey(), sharedSecret, "2-pike-2")
+		assert.False(t, first.IsEqual(second))
+	})
+}
+
+// mustPrivateKey will parse the private key (hex)
+func mustPrivateKey(t *testing.T, privateKey string) *bec.PrivateKey {
+	b, err := hex.DecodeString(privateKey)
+	require.NoError(t, err)
+	key, _ := bec.PrivKeyFromBytes(bec.S256(), b)
+	return key
+}
+
+// ExampleClient_SendPikeContact example using SendPikeContact()
+func ExampleClient_SendPikeContact() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockPikeInvite(http.StatusOK)
+
+	// Fire the request
+	contact, err := client.SendPikeContact(
+		testServerURL+"contact/invite/{alias}@{domain.tld}",
+		testAlias,
+		testDomain,
+		testPikeContact(),
+	)
+	if err != nil {
+		fmt.Printf("error occurred in SendPikeContact: %s", err.Error())
+		return
+	}
+	fmt.Printf("added contact: %s", contact.Paymail)
+	// Output:added contact: mrz@test.com
+}
+
+// ExampleClient_GetPikeOutputs example using GetPikeOutputs()
+func ExampleClient_GetPikeOutputs() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockPikeOutputs(http.StatusOK)
+
+	// Fire the request
+	destination, err := client.GetPikeOutputs(
+		testServerURL+"pike/outputs/{alias}@{domain.tld}",
+		testAlias,
+		testDomain,
+		&PikeOutputsRequest{Amount: 1000, SenderPaymail: testPikeSender},
+	)
+	if err != nil {
+		fmt.Printf("error occurred in GetPikeOutputs: %s", err.Error())
+		return
+	}
+	fmt.Printf("found %d output(s), reference: %s", len(destination.Outputs), destination.Reference)
+	// Output:found 1 output(s), reference: z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7
+}
+
+// BenchmarkDeriveType42PublicKey benchmarks the method DeriveType42PublicKey()
+func BenchmarkDeriveType42PublicKey(b *testing.B) {
+	key, _ := bec.NewPrivateKey(bec.S256())
+	sharedSecret := PikeSharedSecret(key, key.PubKey())
+	for i := 0; i < b.N; i++ {
+		_ = DeriveType42PublicKey(key.PubKey(), sharedSecret, "2-pike-1")
+	}
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -32,7 +33,25 @@ type PKIPayload struct {
 // GetPKI will return a valid PKI response for a given alias@domain.tld
 //
 // Specs: http://bsvalias.org/03-public-key-infrastructure.html
-func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, err error) {
+func (c *Client) GetPKI(pkiURL, alias, domain string) (*PKIResponse, error) {
+	return c.GetPKIContext(context.Background(), pkiURL, alias, domain)
+}
+
+// GetPKIContext is the same as GetPKI() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetPKIContext(ctx context.Context, pkiURL, alias, domain string) (response *PKIResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetPKIForAddress(ctx, pkiURL, address)
+}
+
+// GetPKIForAddress is the same as GetPKIContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetPKIForAddress(ctx context.Context, pkiURL string, address Address) (response *PKIResponse, err error) {
 
 	// Require a valid url
 	if len(pkiURL) == 0 || !strings.Contains(pkiURL, "https://") {
@@ -40,22 +59,20 @@ func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, er
 		return
 	}
 
-	// Basic requirements for the request
-	if len(alias) == 0 {
-		err = fmt.Errorf("missing alias")
-		return
-	} else if len(domain) == 0 {
-		err = fmt.Errorf("missing domain")
+	// Require a paymail address
+	if address.IsZero() {
+		err = ErrMissingAddress
 		return
 	}
 
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/{alias}@{domain.tld}/id
-	reqURL := replaceAliasDomain(pkiURL, alias, domain)
+	reqURL := replaceAddress(pkiURL, address)
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
+		err = newRequestError(StepPKI, reqURL, err)
 		return
 	}
 
@@ -64,36 +81,33 @@ func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, er
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newResponseError(StepPKI, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
 	// Decode the body of the response
 	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(StepPKI, reqURL, response.StatusCode, err)
 		return
 	}
 
 	// Invalid version detected
 	if len(response.BsvAlias) == 0 {
-		err = fmt.Errorf("missing bsvalias version")
+		err = newInvalidResponseError(StepPKI, reqURL, response.StatusCode, fmt.Errorf("missing bsvalias version"))
 		return
 	}
 
 	// Check basic requirements (handle should match our alias@domain.tld)
-	if response.Handle != alias+"@"+domain {
-		err = fmt.Errorf("pki response handle %s does not match paymail address: %s", response.Handle, alias+"@"+domain)
+	if response.Handle != address.String() {
+		err = newInvalidResponseError(StepPKI, reqURL, response.StatusCode, fmt.Errorf("pki response handle %s does not match paymail address: %s", response.Handle, address.String()))
 		return
 	}
 
 	// Check the PubKey length
 	if len(response.PubKey) == 0 {
-		err = fmt.Errorf("pki response is missing a PubKey value")
+		err = newInvalidResponseError(StepPKI, reqURL, response.StatusCode, fmt.Errorf("pki response is missing a PubKey value"))
 	} else if len(response.PubKey) != PubKeyLength {
-		err = fmt.Errorf("returned pubkey is not the required length of %d, got: %d", PubKeyLength, len(response.PubKey))
+		err = newInvalidResponseError(StepPKI, reqURL, response.StatusCode, fmt.Errorf("returned pubkey is not the required length of %d, got: %d", PubKeyLength, len(response.PubKey)))
 	}
 
 	return
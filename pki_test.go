@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -28,6 +29,17 @@ func TestClient_GetPKI(t *testing.T) {
 		assert.Equal(t, "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10", pki.PubKey)
 	})
 
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPKI(http.StatusOK)
+
+		pki, err := client.GetPKIContext(canceledContext(), testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, pki)
+	})
+
 	t.Run("successful response - status not modified", func(t *testing.T) {
 		client := newTestClient(t)
 
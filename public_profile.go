@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -30,7 +31,25 @@ type PublicProfilePayload struct {
 // GetPublicProfile will return a valid public profile
 //
 // Specs: https://github.com/bitcoin-sv-specs/brfc-paymail/pull/7/files
-func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error) {
+func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (*PublicProfileResponse, error) {
+	return c.GetPublicProfileContext(context.Background(), publicProfileURL, alias, domain)
+}
+
+// GetPublicProfileContext is the same as GetPublicProfile() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetPublicProfileContext(ctx context.Context, publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetPublicProfileForAddress(ctx, publicProfileURL, address)
+}
+
+// GetPublicProfileForAddress is the same as GetPublicProfileContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetPublicProfileForAddress(ctx context.Context, publicProfileURL string, address Address) (response *PublicProfileResponse, err error) {
 
 	// Require a valid url
 	if len(publicProfileURL) == 0 || !strings.Contains(publicProfileURL, "https://") {
@@ -39,21 +58,19 @@ func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (respo
 	}
 
 	// Basic requirements for request
-	if len(alias) == 0 {
-		err = fmt.Errorf("missing alias")
-		return
-	} else if len(domain) == 0 {
-		err = fmt.Errorf("missing domain")
+	if address.IsZero() {
+		err = ErrMissingAddress
 		return
 	}
 
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/public-profile/{alias}@{domain.tld}
-	reqURL := replaceAliasDomain(publicProfileURL, alias, domain)
+	reqURL := replaceAddress(publicProfileURL, address)
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
+		err = newRequestError(StepPublicProfile, reqURL, err)
 		return
 	}
 
@@ -62,16 +79,14 @@ func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (respo
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newResponseError(StepPublicProfile, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
 	// Decode the body of the response
-	err = json.Unmarshal(resp.Body, &response)
+	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(StepPublicProfile, reqURL, response.StatusCode, err)
+	}
 
 	return
 }
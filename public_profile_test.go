@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -27,6 +28,19 @@ func TestClient_GetPublicProfile(t *testing.T) {
 		assert.Equal(t, testAvatar, profile.Avatar)
 	})
 
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPublicProfile(http.StatusOK)
+
+		profile, err := client.GetPublicProfileContext(
+			canceledContext(), testServerURL+"public-profile/{alias}@{domain.tld}", testAlias, testDomain,
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, profile)
+	})
+
 	t.Run("successful response - status not modified", func(t *testing.T) {
 		client := newTestClient(t)
 
@@ -0,0 +1,184 @@
+package paymail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Payment approval statuses
+const (
+	ApprovalStatusApproved = "approved" // The receiver approved the payment (a destination can be requested)
+	ApprovalStatusPending  = "pending"  // The receiver did not decide yet (poll again later)
+	ApprovalStatusRejected = "rejected" // The receiver rejected the payment
+)
+
+/*
+Example (response):
+{
+  "id": "4b5a1e8f2c9d4e7a",
+  "status": "approved",
+  "amount": 1000100
+}
+*/
+
+// PaymentApprovalResponse is the response from the SubmitPaymentApproval() & GetPaymentApproval() requests
+type PaymentApprovalResponse struct {
+	StandardResponse
+	PaymentApprovalPayload
+}
+
+// PaymentApprovalPayload is the payload from the response
+//
+// Once approved, the ID is sent with the payment destination request (see: PaymentRequest.ApprovalID)
+type PaymentApprovalPayload struct {
+	Amount uint64 `json:"amount,omitempty"` // The approved amount, in Satoshis (0 is any amount)
+	ID     string `json:"id"`               // Unique ID of the approval, created by the receiver
+	Reason string `json:"reason,omitempty"` // Human-readable reason for the status (IE: why it was rejected)
+	Status string `json:"status"`           // Status of the approval (pending, approved or rejected)
+}
+
+// IsApproved will return true if the receiver approved the payment
+func (p *PaymentApprovalPayload) IsApproved() bool {
+	return p.Status == ApprovalStatusApproved
+}
+
+// IsPending will return true if the receiver did not decide yet
+func (p *PaymentApprovalPayload) IsPending() bool {
+	return p.Status == ApprovalStatusPending
+}
+
+// IsRejected will return true if the receiver rejected the payment
+func (p *PaymentApprovalPayload) IsRejected() bool {
+	return p.Status == ApprovalStatusRejected
+}
+
+// SubmitPaymentApproval will submit the payment intent (sender request) to the receiver for approval,
+// use GetPaymentApproval() to poll the status until it is approved or rejected
+//
+// The approval url is the BRFCReceiverApprovals capability (IE: https://<host>/api/v1/bsvalias/approvals/{alias}@{domain.tld})
+//
+// Specs: http://bsvalias.org/04-03-receiver-approvals.html
+func (c *Client) SubmitPaymentApproval(approvalURL, alias, domain string,
+	senderRequest *SenderRequest) (*PaymentApprovalResponse, error) {
+	return c.SubmitPaymentApprovalContext(context.Background(), approvalURL, alias, domain, senderRequest)
+}
+
+// SubmitPaymentApprovalContext is the same as SubmitPaymentApproval() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) SubmitPaymentApprovalContext(ctx context.Context, approvalURL, alias, domain string,
+	senderRequest *SenderRequest) (response *PaymentApprovalResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.SubmitPaymentApprovalForAddress(ctx, approvalURL, address, senderRequest)
+}
+
+// SubmitPaymentApprovalForAddress is the same as SubmitPaymentApprovalContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) SubmitPaymentApprovalForAddress(ctx context.Context, approvalURL string, address Address,
+	senderRequest *SenderRequest) (response *PaymentApprovalResponse, err error) {
+
+	// Require a valid url & paymail
+	if err = validateServiceAddress(approvalURL, address); err != nil {
+		return
+	}
+
+	// Basic requirements for request
+	if senderRequest == nil {
+		err = errors.New("senderRequest cannot be nil")
+		return
+	} else if err = senderRequest.validate(); err != nil {
+		return
+	}
+
+	// Fire the POST request
+	reqURL := replaceAddress(approvalURL, address)
+	var resp StandardResponse
+	if resp, err = c.postRequest(ctx, reqURL, senderRequest); err != nil {
+		err = newRequestError(StepReceiverApprovals, reqURL, err)
+		return
+	}
+
+	return newPaymentApprovalResponse(reqURL, resp)
+}
+
+// GetPaymentApproval will return the status of a payment approval (from SubmitPaymentApproval())
+//
+// The approval url is the BRFCReceiverApprovals capability, the status is requested at {approval url}/{id}
+//
+// Specs: http://bsvalias.org/04-03-receiver-approvals.html
+func (c *Client) GetPaymentApproval(approvalURL, alias, domain string, id string) (*PaymentApprovalResponse, error) {
+	return c.GetPaymentApprovalContext(context.Background(), approvalURL, alias, domain, id)
+}
+
+// GetPaymentApprovalContext is the same as GetPaymentApproval() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetPaymentApprovalContext(ctx context.Context, approvalURL, alias, domain string,
+	id string) (response *PaymentApprovalResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetPaymentApprovalForAddress(ctx, approvalURL, address, id)
+}
+
+// GetPaymentApprovalForAddress is the same as GetPaymentApprovalContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetPaymentApprovalForAddress(ctx context.Context, approvalURL string, address Address,
+	id string) (response *PaymentApprovalResponse, err error) {
+
+	// Require a valid url, paymail & id
+	if err = validateServiceAddress(approvalURL, address); err != nil {
+		return
+	} else if len(id) == 0 {
+		err = errors.New("missing approval id")
+		return
+	}
+
+	// Fire the GET request
+	reqURL := strings.TrimSuffix(replaceAddress(approvalURL, address), "/") + "/" + url.PathEscape(id)
+	var resp StandardResponse
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
+		err = newRequestError(StepReceiverApprovals, reqURL, err)
+		return
+	}
+
+	return newPaymentApprovalResponse(reqURL, resp)
+}
+
+// newPaymentApprovalResponse will check & decode the response of the approval requests
+func newPaymentApprovalResponse(reqURL string, resp StandardResponse) (response *PaymentApprovalResponse, err error) {
+
+	// Start the response
+	response = &PaymentApprovalResponse{StandardResponse: resp}
+
+	// Test the status code
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		err = newResponseError(StepReceiverApprovals, reqURL, response.StatusCode, resp.Body)
+		return
+	}
+
+	// Decode the body of the response
+	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(StepReceiverApprovals, reqURL, response.StatusCode, err)
+		return
+	}
+
+	// Check the id & status
+	if len(response.ID) == 0 {
+		err = newInvalidResponseError(StepReceiverApprovals, reqURL, response.StatusCode, errors.New("missing a returned approval id"))
+	} else if !response.IsApproved() && !response.IsPending() && !response.IsRejected() {
+		err = newInvalidResponseError(StepReceiverApprovals, reqURL, response.StatusCode, fmt.Errorf("unknown approval status: %s", response.Status))
+	}
+	return
+}
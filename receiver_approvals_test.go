@@ -0,0 +1,171 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testApprovalID = "4b5a1e8f2c9d4e7a"
+
+// mockPaymentApproval will mock the approval routes (submit & status)
+func mockPaymentApproval(statusCode int, body string) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"approvals/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(statusCode, body),
+	)
+	httpmock.RegisterResponder(http.MethodGet, testServerURL+"approvals/"+testAlias+"@"+testDomain+"/"+testApprovalID,
+		httpmock.NewStringResponder(statusCode, body),
+	)
+}
+
+// testSenderRequest will return a basic sender request
+func testSenderRequest() *SenderRequest {
+	return &SenderRequest{
+		Amount:       1000,
+		Dt:           "2020-04-09T16:08:06.419Z",
+		SenderHandle: "satchmo@" + testDomain,
+	}
+}
+
+// TestClient_SubmitPaymentApproval will test the method SubmitPaymentApproval()
+func TestClient_SubmitPaymentApproval(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	approvalURL := testServerURL + "approvals/{alias}@{domain.tld}"
+
+	t.Run("pending approval", func(t *testing.T) {
+		mockPaymentApproval(http.StatusCreated, `{"id":"`+testApprovalID+`","status":"pending","amount":1000}`)
+		response, err := client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, testSenderRequest())
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, testApprovalID, response.ID)
+		assert.Equal(t, uint64(1000), response.Amount)
+		assert.True(t, response.IsPending())
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		mockPaymentApproval(http.StatusNotFound, `{"code":"not-found","message":"paymail not found"}`)
+		response, err := client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, testSenderRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		mockPaymentApproval(http.StatusCreated, `{"status":"pending"}`)
+		_, err := client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, testSenderRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		mockPaymentApproval(http.StatusCreated, `{"id":"`+testApprovalID+`","status":"maybe"}`)
+		_, err := client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, testSenderRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		mockPaymentApproval(http.StatusCreated, `{"id":`)
+		_, err := client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, testSenderRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockPaymentApproval(http.StatusCreated, `{"id":"`+testApprovalID+`","status":"pending"}`)
+		response, err := client.SubmitPaymentApprovalContext(canceledContext(), approvalURL, testAlias, testDomain, testSenderRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, response)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.SubmitPaymentApproval("http://"+testDomain, testAlias, testDomain, testSenderRequest())
+		require.Error(t, err)
+		_, err = client.SubmitPaymentApproval(approvalURL, "", testDomain, testSenderRequest())
+		require.Error(t, err)
+		_, err = client.SubmitPaymentApproval(approvalURL, testAlias, "", testSenderRequest())
+		require.Error(t, err)
+		_, err = client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, nil)
+		require.Error(t, err)
+		_, err = client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, &SenderRequest{Dt: "dt"})
+		require.Error(t, err)
+	})
+}
+
+// TestClient_GetPaymentApproval will test the method GetPaymentApproval()
+func TestClient_GetPaymentApproval(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	approvalURL := testServerURL + "approvals/{alias}@{domain.tld}"
+
+	t.Run("approved", func(t *testing.T) {
+		mockPaymentApproval(http.StatusOK, `{"id":"`+testApprovalID+`","status":"approved"}`)
+		response, err := client.GetPaymentApproval(approvalURL, testAlias, testDomain, testApprovalID)
+		require.NoError(t, err)
+		assert.True(t, response.IsApproved())
+		assert.False(t, response.IsRejected())
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		mockPaymentApproval(http.StatusOK, `{"id":"`+testApprovalID+`","status":"rejected","reason":"unknown sender"}`)
+		response, err := client.GetPaymentApproval(approvalURL, testAlias, testDomain, testApprovalID)
+		require.NoError(t, err)
+		assert.True(t, response.IsRejected())
+		assert.Equal(t, "unknown sender", response.Reason)
+	})
+
+	t.Run("approval not found", func(t *testing.T) {
+		mockPaymentApproval(http.StatusNotFound, `{"code":"approval-not-found","message":"payment approval not found"}`)
+		_, err := client.GetPaymentApproval(approvalURL, testAlias, testDomain, testApprovalID)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrApprovalNotFound)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.GetPaymentApproval(approvalURL, testAlias, testDomain, "")
+		require.Error(t, err)
+		_, err = client.GetPaymentApproval("", testAlias, testDomain, testApprovalID)
+		require.Error(t, err)
+	})
+}
+
+// ExampleClient_GetPaymentApproval example using GetPaymentApproval()
+func ExampleClient_GetPaymentApproval() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockPaymentApproval(http.StatusOK, `{"id":"`+testApprovalID+`","status":"approved"}`)
+
+	// Poll the status of the approval (from SubmitPaymentApproval())
+	approval, err := client.GetPaymentApproval(
+		testServerURL+"approvals/{alias}@{domain.tld}", testAlias, testDomain, testApprovalID,
+	)
+	if err != nil {
+		fmt.Printf("error occurred in GetPaymentApproval: %s", err.Error())
+		return
+	}
+	fmt.Printf("payment %s is %s", approval.ID, approval.Status)
+	// Output:payment 4b5a1e8f2c9d4e7a is approved
+}
+
+// BenchmarkClient_SubmitPaymentApproval benchmarks the method SubmitPaymentApproval()
+func BenchmarkClient_SubmitPaymentApproval(b *testing.B) {
+	client := newTestClient(nil)
+	mockPaymentApproval(http.StatusCreated, `{"id":"`+testApprovalID+`","status":"pending"}`)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.SubmitPaymentApproval(
+			testServerURL+"approvals/{alias}@{domain.tld}", testAlias, testDomain, testSenderRequest(),
+		)
+	}
+}
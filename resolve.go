@@ -0,0 +1,219 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ResolveOperation is the operation to perform once host & capability discovery is complete
+type ResolveOperation string
+
+// Operations supported by Resolve()
+const (
+	ResolveOperationNone               ResolveOperation = "none"                // Only run host & capability discovery
+	ResolveOperationPKI                ResolveOperation = "pki"                 // Get the PKI (pubkey) of the paymail
+	ResolveOperationPublicProfile      ResolveOperation = "public_profile"      // Get the public profile (name & avatar)
+	ResolveOperationAddressResolution  ResolveOperation = "address_resolution"  // Basic address resolution (requires a SenderRequest)
+	ResolveOperationPaymentDestination ResolveOperation = "payment_destination" // P2P payment destination (requires a PaymentRequest)
+	ResolveOperationAssetInformation   ResolveOperation = "asset_information"   // SFP asset information (the paymail is the asset)
+)
+
+// Resolve step names used in ResolveResult.Steps
+const (
+	ResolveStepCapabilities = StepCapabilities // Capability discovery
+	ResolveStepSRV          = StepSRV          // Host discovery
+)
+
+// ResolveRequest is the request for the Resolve() method
+type ResolveRequest struct {
+	Operation      ResolveOperation // The operation to perform after discovery
+	PaymentRequest *PaymentRequest  // Required for ResolveOperationPaymentDestination
+	SenderRequest  *SenderRequest   // Required for ResolveOperationAddressResolution
+}
+
+// ResolveStep is the timing information for a single step in Resolve()
+type ResolveStep struct {
+	Duration time.Duration `json:"duration"` // How long the step took
+	Name     string        `json:"name"`     // Name of the step (srv, capabilities, or the operation)
+}
+
+// ResolveResult is the result of the Resolve() method
+//
+// Only the response for the requested operation will be set
+type ResolveResult struct {
+	Address            string                      `json:"address"`                       // The sanitized paymail address (alias@domain.tld)
+	Alias              string                      `json:"alias"`                         // Alias of the paymail
+	AssetInformation   *SFPAssetResponse           `json:"asset_information,omitempty"`   // Result of ResolveOperationAssetInformation
+	Capabilities       *CapabilitiesResponse       `json:"capabilities"`                  // Capabilities discovered for the host
+	Domain             string                      `json:"domain"`                        // Domain of the paymail
+	PaymentDestination *PaymentDestinationResponse `json:"payment_destination,omitempty"` // Result of ResolveOperationPaymentDestination
+	PKI                *PKIResponse                `json:"pki,omitempty"`                 // Result of ResolveOperationPKI
+	PublicProfile      *PublicProfileResponse      `json:"public_profile,omitempty"`      // Result of ResolveOperationPublicProfile
+	Resolution         *ResolutionResponse         `json:"resolution,omitempty"`          // Result of ResolveOperationAddressResolution
+	SRV                *net.SRV                    `json:"srv"`                           // The discovered host (target & port)
+	Steps              []*ResolveStep              `json:"steps"`                         // Timing of each step
+}
+
+// Resolve will take a paymail address (or handle) and run host discovery, capability discovery
+// and then the requested operation, returning everything in one result
+//
+// Specs: http://bsvalias.org/02-01-host-discovery.html
+func (c *Client) Resolve(paymailAddress string, request *ResolveRequest) (*ResolveResult, error) {
+	return c.ResolveContext(context.Background(), paymailAddress, request)
+}
+
+// ResolveContext is the same as Resolve() but accepts a context
+// that is used for cancellation and deadlines on every step
+func (c *Client) ResolveContext(ctx context.Context, paymailAddress string,
+	request *ResolveRequest) (result *ResolveResult, err error) {
+
+	// Basic requirements for the request
+	if request == nil {
+		err = errors.New("resolve request cannot be nil")
+		return
+	}
+
+	// Default to only running discovery
+	operation := request.Operation
+	if len(operation) == 0 {
+		operation = ResolveOperationNone
+	}
+
+	// Convert the handle (if any) using the handle registry of the client
+	if paymailAddress, err = c.ConvertHandleContext(ctx, paymailAddress, false); err != nil {
+		return
+	}
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = parseAddress(paymailAddress); err != nil {
+		return
+	}
+
+	// Start the result
+	result = &ResolveResult{
+		Address: address.String(),
+		Alias:   address.Alias(),
+		Domain:  address.Domain(),
+	}
+
+	// Host discovery (all the records if failover is enabled)
+	start := time.Now()
+	var records []*net.SRV
+	if records, err = c.GetSRVRecordsContext(
+		ctx, DefaultServiceName, DefaultProtocol, result.Domain,
+	); err != nil {
+		return
+	}
+	if !c.options.srvFailover {
+		records = records[:1]
+	}
+	result.addStep(ResolveStepSRV, start)
+
+	// Capability discovery (try the next record if the capabilities fail)
+	start = time.Now()
+	for _, record := range records {
+		result.SRV = record
+		if result.Capabilities, err = c.GetCapabilitiesContext(
+			ctx, record.Target, int(record.Port),
+		); err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		if len(records) > 1 {
+			err = fmt.Errorf("capabilities failed on all %d srv targets for %s: %w", len(records), result.Domain, err)
+		}
+		return
+	}
+	result.addStep(ResolveStepCapabilities, start)
+
+	// Run the requested operation
+	start = time.Now()
+	switch operation {
+	case ResolveOperationNone:
+		return
+	case ResolveOperationPKI:
+		var pkiURL string
+		if pkiURL, err = result.capabilityURL(BRFCPki, BRFCPkiAlternate); err != nil {
+			return
+		}
+		if result.PKI, err = c.GetPKIContext(
+			ctx, pkiURL, result.Alias, result.Domain,
+		); err != nil {
+			return
+		}
+	case ResolveOperationPublicProfile:
+		var profileURL string
+		if profileURL, err = result.capabilityURL(BRFCPublicProfile, ""); err != nil {
+			return
+		}
+		if result.PublicProfile, err = c.GetPublicProfileContext(
+			ctx, profileURL, result.Alias, result.Domain,
+		); err != nil {
+			return
+		}
+	case ResolveOperationAddressResolution:
+		var resolutionURL string
+		if resolutionURL, err = result.capabilityURL(BRFCPaymentDestination, BRFCBasicAddressResolution); err != nil {
+			return
+		}
+		if result.Resolution, err = c.ResolveAddressContext(
+			ctx, resolutionURL, result.Alias, result.Domain, request.SenderRequest,
+		); err != nil {
+			return
+		}
+	case ResolveOperationPaymentDestination:
+		var p2pURL string
+		if p2pURL, err = result.capabilityURL(BRFCP2PPaymentDestination, ""); err != nil {
+			return
+		}
+		if result.PaymentDestination, err = c.GetP2PPaymentDestinationContext(
+			ctx, p2pURL, result.Alias, result.Domain, request.PaymentRequest,
+		); err != nil {
+			return
+		}
+	case ResolveOperationAssetInformation:
+		var assetURL string
+		if assetURL, err = result.capabilityURL(BRFCSFPAssetInformation, ""); err != nil {
+			return
+		}
+		if result.AssetInformation, err = c.GetSFPAssetInformationContext(
+			ctx, assetURL, result.Alias, result.Domain,
+		); err != nil {
+			return
+		}
+	default:
+		err = fmt.Errorf("unknown resolve operation: %s", operation)
+		return
+	}
+	result.addStep(string(operation), start)
+
+	return
+}
+
+// addStep will add the timing of a step to the result
+func (r *ResolveResult) addStep(name string, start time.Time) {
+	r.Steps = append(r.Steps, &ResolveStep{
+		Duration: time.Since(start),
+		Name:     name,
+	})
+}
+
+// capabilityURL will return the url for the capability (or alternate) or an error if not found
+func (r *ResolveResult) capabilityURL(brfcID, alternateID string) (string, error) {
+	if found, val := r.Capabilities.getValue(brfcID, alternateID); found {
+		if capabilityURL, ok := val.(string); ok && len(capabilityURL) > 0 {
+			return capabilityURL, nil
+		}
+	}
+	return "", &ProviderError{
+		Err:     ErrNotSupported,
+		Message: fmt.Sprintf("paymail provider for %s is missing capability: %s", r.Domain, brfcID),
+		Step:    StepCapabilities,
+		URL:     r.Domain,
+	}
+}
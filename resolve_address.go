@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,7 +27,27 @@ type ResolutionPayload struct {
 // ResolveAddress will return a hex-encoded Bitcoin script if successful
 //
 // Specs: http://bsvalias.org/04-01-basic-address-resolution.html
-func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
+func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (*ResolutionResponse, error) {
+	return c.ResolveAddressContext(context.Background(), resolutionURL, alias, domain, senderRequest)
+}
+
+// ResolveAddressContext is the same as ResolveAddress() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) ResolveAddressContext(ctx context.Context, resolutionURL, alias, domain string,
+	senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.ResolveAddressForAddress(ctx, resolutionURL, address, senderRequest)
+}
+
+// ResolveAddressForAddress is the same as ResolveAddressContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) ResolveAddressForAddress(ctx context.Context, resolutionURL string, address Address,
+	senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
 
 	// Require a valid url
 	if len(resolutionURL) == 0 || !strings.Contains(resolutionURL, "https://") {
@@ -34,12 +55,9 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 		return
 	}
 
-	// Basic requirements for the request
-	if len(alias) == 0 {
-		err = errors.New("missing alias")
-		return
-	} else if len(domain) == 0 {
-		err = errors.New("missing domain")
+	// Require a paymail address
+	if address.IsZero() {
+		err = ErrMissingAddress
 		return
 	}
 
@@ -57,11 +75,12 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/{alias}@{domain.tld}/payment-destination
-	reqURL := replaceAliasDomain(resolutionURL, alias, domain)
+	reqURL := replaceAddress(resolutionURL, address)
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, senderRequest); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, senderRequest); err != nil {
+		err = newRequestError(StepAddressResolution, reqURL, err)
 		return
 	}
 
@@ -70,29 +89,19 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 	// Test the status code
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-
-		// Paymail address not found?
-		if response.StatusCode == http.StatusNotFound {
-			err = errors.New("paymail address not found")
-		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return
-			}
-			err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
-		}
-
+		err = newResponseError(StepAddressResolution, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
 	// Decode the body of the response
 	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(StepAddressResolution, reqURL, response.StatusCode, err)
 		return
 	}
 
 	// Check for an output
 	if len(response.Output) == 0 {
-		err = errors.New("missing an output value")
+		err = newInvalidResponseError(StepAddressResolution, reqURL, response.StatusCode, errors.New("missing an output value"))
 		return
 	}
 
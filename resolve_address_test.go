@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -36,6 +37,25 @@ func TestClient_ResolveAddress(t *testing.T) {
 		assert.Equal(t, testOutput, resolution.Output)
 	})
 
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockResolveAddress(http.StatusOK)
+
+		senderRequest := &SenderRequest{
+			Dt:           time.Now().UTC().Format(time.RFC3339),
+			SenderHandle: testAlias + "@" + testDomain,
+			SenderName:   testName,
+		}
+
+		resolution, err := client.ResolveAddressContext(
+			canceledContext(), testServerURL+"address/{alias}@{domain.tld}", testAlias, testDomain, senderRequest,
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, resolution)
+	})
+
 	t.Run("successful response - status not modified", func(t *testing.T) {
 		client := newTestClient(t)
 
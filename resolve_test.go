@@ -0,0 +1,322 @@
+package paymail
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_Resolve will test the method Resolve()
+func TestClient_Resolve(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("discovery only", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, testAlias, result.Alias)
+		assert.Equal(t, testDomain, result.Domain)
+		assert.Equal(t, testAlias+"@"+testDomain, result.Address)
+		assert.Equal(t, "www."+testDomain, result.SRV.Target)
+		assert.Equal(t, true, result.Capabilities.Has(BRFCPki, BRFCPkiAlternate))
+		require.Equal(t, 2, len(result.Steps))
+		assert.Equal(t, ResolveStepSRV, result.Steps[0].Name)
+		assert.Equal(t, ResolveStepCapabilities, result.Steps[1].Name)
+		assert.Nil(t, result.PKI)
+	})
+
+	t.Run("pki", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPKI(http.StatusOK)
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: ResolveOperationPKI})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.NotNil(t, result.PKI)
+		assert.Equal(t, testPubKey, result.PKI.PubKey)
+		require.Equal(t, 3, len(result.Steps))
+		assert.Equal(t, string(ResolveOperationPKI), result.Steps[2].Name)
+	})
+
+	t.Run("public profile", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPublicProfile(http.StatusOK)
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: ResolveOperationPublicProfile})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.NotNil(t, result.PublicProfile)
+		assert.Equal(t, testName, result.PublicProfile.Name)
+		assert.Equal(t, testAvatar, result.PublicProfile.Avatar)
+	})
+
+	t.Run("address resolution", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockResolveAddress(http.StatusOK)
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{
+			Operation: ResolveOperationAddressResolution,
+			SenderRequest: &SenderRequest{
+				Dt:           time.Now().UTC().Format(time.RFC3339),
+				SenderHandle: testAlias + "@" + testDomain,
+				SenderName:   testName,
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.NotNil(t, result.Resolution)
+		assert.Equal(t, testAddress, result.Resolution.Address)
+		assert.Equal(t, testOutput, result.Resolution.Output)
+	})
+
+	t.Run("payment destination", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockP2PPaymentDestination(http.StatusOK)
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{
+			Operation:      ResolveOperationPaymentDestination,
+			PaymentRequest: &PaymentRequest{Satoshis: 100},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.NotNil(t, result.PaymentDestination)
+		assert.NotEqual(t, 0, len(result.PaymentDestination.Reference))
+		assert.Equal(t, uint64(100), result.PaymentDestination.Outputs[0].Satoshis)
+	})
+
+	t.Run("asset information", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockSFPAssetInformation(http.StatusOK)
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: ResolveOperationAssetInformation})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.NotNil(t, result.AssetInformation)
+		assert.Equal(t, testTokenID, result.AssetInformation.TokenID)
+	})
+
+	t.Run("nil request", func(t *testing.T) {
+		client := newTestClient(t)
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, nil)
+		require.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid paymail", func(t *testing.T) {
+		client := newTestClient(t)
+
+		result, err := client.Resolve("invalid-paymail", &ResolveRequest{})
+		require.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		mockResolveCapabilities()
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: "unknown"})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.NotNil(t, result.Capabilities)
+	})
+
+	t.Run("missing capability", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, "https://www."+testDomain+":443/.well-known/"+DefaultServiceName,
+			httpmock.NewStringResponder(
+				http.StatusOK,
+				`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {
+"6745385c3fc0": false,"pki": "`+testServerURL+`id/{alias}@{domain.tld}"}}`,
+			),
+		)
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{
+			Operation:      ResolveOperationPaymentDestination,
+			PaymentRequest: &PaymentRequest{Satoshis: 100},
+		})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Nil(t, result.PaymentDestination)
+
+		result, err = client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: ResolveOperationPublicProfile})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Nil(t, result.PublicProfile)
+	})
+
+	t.Run("capabilities failed", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, "https://www."+testDomain+":443/.well-known/"+DefaultServiceName,
+			httpmock.NewErrorResponder(fmt.Errorf("error in request")),
+		)
+
+		result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: ResolveOperationPKI})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Nil(t, result.Capabilities)
+		assert.Equal(t, 1, len(result.Steps))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		mockResolveCapabilities()
+
+		result, err := client.ResolveContext(canceledContext(), testAlias+"@"+testDomain, &ResolveRequest{})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Nil(t, result.Capabilities)
+	})
+}
+
+// TestClient_ResolveFailover will test the failover discovery mode of Resolve()
+func TestClient_ResolveFailover(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	paymailAddress := testAlias + "@" + testFailoverDomain
+
+	t.Run("uses the next srv target", func(t *testing.T) {
+		client := newTestClient(t, WithSRVFailover())
+
+		mockFailoverCapabilities(http.StatusInternalServerError)
+
+		result, err := client.Resolve(paymailAddress, &ResolveRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, "backup."+testFailoverDomain, result.SRV.Target)
+		assert.Equal(t, true, result.Capabilities.Has(BRFCPki, BRFCPkiAlternate))
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("first target is used if valid", func(t *testing.T) {
+		client := newTestClient(t, WithSRVFailover())
+
+		mockFailoverCapabilities(http.StatusOK)
+
+		result, err := client.Resolve(paymailAddress, &ResolveRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "primary."+testFailoverDomain, result.SRV.Target)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockFailoverCapabilities(http.StatusInternalServerError)
+
+		result, err := client.Resolve(paymailAddress, &ResolveRequest{})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, "primary."+testFailoverDomain, result.SRV.Target)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("all targets fail", func(t *testing.T) {
+		client := newTestClient(t, WithSRVFailover())
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, "https://primary."+testFailoverDomain+":443/.well-known/"+DefaultServiceName,
+			httpmock.NewErrorResponder(fmt.Errorf("error in request")),
+		)
+		httpmock.RegisterResponder(http.MethodGet, "https://backup."+testFailoverDomain+":443/.well-known/"+DefaultServiceName,
+			httpmock.NewErrorResponder(fmt.Errorf("error in request")),
+		)
+
+		result, err := client.Resolve(paymailAddress, &ResolveRequest{})
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Contains(t, err.Error(), "all 2 srv targets")
+		assert.Equal(t, 1, len(result.Steps))
+	})
+}
+
+// mockFailoverCapabilities is used for mocking the capabilities of the failover domain
+// (the primary target returns the status code, the backup target is always valid)
+func mockFailoverCapabilities(primaryStatusCode int) {
+	httpmock.Reset()
+	body := `{"` + DefaultServiceName + `": "` + DefaultBsvAliasVersion + `","capabilities": {
+"pki": "` + testServerURL + `id/{alias}@{domain.tld}"}}`
+	httpmock.RegisterResponder(http.MethodGet, "https://primary."+testFailoverDomain+":443/.well-known/"+DefaultServiceName,
+		httpmock.NewStringResponder(primaryStatusCode, body),
+	)
+	httpmock.RegisterResponder(http.MethodGet, "https://backup."+testFailoverDomain+":443/.well-known/"+DefaultServiceName,
+		httpmock.NewStringResponder(http.StatusOK, body),
+	)
+}
+
+// mockResolveCapabilities is used for mocking the capabilities of the discovered host (www.test.com)
+//
+// This does not reset the mock, so it can be combined with the other mocks
+func mockResolveCapabilities() {
+	httpmock.RegisterResponder(http.MethodGet, "https://www."+testDomain+":443/.well-known/"+DefaultServiceName,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {
+"6745385c3fc0": false,
+"pki": "`+testServerURL+`id/{alias}@{domain.tld}",
+"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}",
+"`+BRFCPublicProfile+`": "`+testServerURL+`public-profile/{alias}@{domain.tld}",
+"`+BRFCSFPAssetInformation+`": "`+testServerURL+`asset/{alias}@{domain.tld}",
+"`+BRFCP2PPaymentDestination+`": "`+testServerURL+`p2p-payment-destination/{alias}@{domain.tld}"}}`,
+		),
+	)
+}
+
+// ExampleClient_Resolve example using Resolve()
+//
+// See more examples in /examples/
+func ExampleClient_Resolve() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockGetPKI(http.StatusOK)
+	mockResolveCapabilities()
+
+	// Discover the host & capabilities, then get the PKI
+	result, err := client.Resolve(testAlias+"@"+testDomain, &ResolveRequest{Operation: ResolveOperationPKI})
+	if err != nil {
+		fmt.Printf("error occurred in Resolve: %s", err.Error())
+		return
+	}
+	fmt.Printf("found pubkey: %s via host: %s", result.PKI.PubKey, result.SRV.Target)
+	// Output:found pubkey: 02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10 via host: www.test.com
+}
+
+// BenchmarkClient_Resolve benchmarks the method Resolve()
+func BenchmarkClient_Resolve(b *testing.B) {
+	client := newTestClient(nil)
+	mockGetPKI(http.StatusOK)
+	mockResolveCapabilities()
+	request := &ResolveRequest{Operation: ResolveOperationPKI}
+	for i := 0; i < b.N; i++ {
+		_, _ = client.Resolve(testAlias+"@"+testDomain, request)
+	}
+}
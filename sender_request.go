@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/bitcoinschema/go-bitcoin/v2"
@@ -23,6 +24,7 @@ Example:
 // This is required to make a basic resolution request, and Dt and SenderHandle are required
 type SenderRequest struct {
 	Amount       uint64 `json:"amount,omitempty"`     // The amount, in Satoshis, that the sender intends to transfer to the receiver
+	ApprovalID   string `json:"approvalId,omitempty"` // ID of the approved payment, if the receiver requires approvals (not signed)
 	Dt           string `json:"dt"`                   // (required) ISO-8601 formatted timestamp; see notes
 	Purpose      string `json:"purpose,omitempty"`    // Human-readable description of the purpose of the payment
 	SenderHandle string `json:"senderHandle"`         // (required) Sender's paymail handle
@@ -44,7 +46,7 @@ func (s *SenderRequest) Verify(keyAddress, signature string) error {
 	}
 
 	// Concatenate & verify the message
-	return bitcoin.VerifyMessage(keyAddress, signature, fmt.Sprintf("%s%d%s%s", s.SenderHandle, s.Amount, s.Dt, s.Purpose))
+	return bitcoin.VerifyMessage(keyAddress, signature, s.Message())
 }
 
 // Sign will sign the given components in the ResolveAddress() request
@@ -57,16 +59,42 @@ func (s *SenderRequest) Sign(privateKey string) (string, error) {
 	// Basic checks before trying to sign the request
 	if len(privateKey) == 0 {
 		return "", fmt.Errorf("missing private key")
-	} else if len(s.Dt) == 0 {
-		return "", fmt.Errorf("missing dt")
-	} else if len(s.SenderHandle) == 0 {
-		return "", fmt.Errorf("missing senderHandle")
+	} else if err := s.validate(); err != nil {
+		return "", err
 	}
 
 	// Concatenate & sign message
-	return bitcoin.SignMessage(
-		privateKey,
-		fmt.Sprintf("%s%d%s%s", s.SenderHandle, s.Amount, s.Dt, s.Purpose),
-		false,
-	)
+	return bitcoin.SignMessage(privateKey, s.Message(), false)
+}
+
+// SignWithSigner is the same as Sign() but uses a Signer (IE: HSM, KMS or a signing service)
+// instead of a private key
+//
+// The signature references the compressed key of the signer (the pubkey returned by PKI)
+func (s *SenderRequest) SignWithSigner(ctx context.Context, signer Signer) (string, error) {
+
+	// Basic checks before trying to sign the request
+	if signer == nil {
+		return "", fmt.Errorf("missing signer")
+	} else if err := s.validate(); err != nil {
+		return "", err
+	}
+
+	// Concatenate & sign message
+	return signer.SignMessage(ctx, s.Message())
+}
+
+// validate will check the required fields for signing
+func (s *SenderRequest) validate() error {
+	if len(s.Dt) == 0 {
+		return fmt.Errorf("missing dt")
+	} else if len(s.SenderHandle) == 0 {
+		return fmt.Errorf("missing senderHandle")
+	}
+	return nil
+}
+
+// Message will return the message that is signed (senderHandle + amount + dt + purpose)
+func (s *SenderRequest) Message() string {
+	return fmt.Sprintf("%s%d%s%s", s.SenderHandle, s.Amount, s.Dt, s.Purpose)
 }
@@ -8,9 +8,32 @@ import (
 	"github.com/tonicpow/go-paymail"
 )
 
+// CapabilityOps allow functional options to be supplied
+// that add optional capabilities.
+type CapabilityOps func(c *paymail.CapabilitiesPayload)
+
+// WithPayToCapability will advertise support for the PayTo protocol prefix (payto:alias@domain.tld)
+//
+// Specs: http://bsvalias.org/04-04-payto-protocol-prefix.html
+func WithPayToCapability() CapabilityOps {
+	return func(c *paymail.CapabilitiesPayload) {
+		c.Capabilities[paymail.BRFCPayToProtocolPrefix] = true
+	}
+}
+
+// WithPikeCapability will advertise support for PIKE (paymail identity key exchange)
+func WithPikeCapability() CapabilityOps {
+	return func(c *paymail.CapabilitiesPayload) {
+		c.Capabilities[paymail.BRFCPike] = map[string]interface{}{
+			"invite":  PikeInvitePath,
+			"outputs": PikeOutputsPath,
+		}
+	}
+}
+
 // GenericCapabilities will make generic capabilities
-func GenericCapabilities(bsvAliasVersion string, senderValidation bool) *paymail.CapabilitiesPayload {
-	return &paymail.CapabilitiesPayload{
+func GenericCapabilities(bsvAliasVersion string, senderValidation bool, opts ...CapabilityOps) *paymail.CapabilitiesPayload {
+	c := &paymail.CapabilitiesPayload{
 		BsvAlias: bsvAliasVersion,
 		Capabilities: map[string]interface{}{
 			paymail.BRFCPaymentDestination:   "/address/{alias}@{domain.tld}",
@@ -20,11 +43,15 @@ func GenericCapabilities(bsvAliasVersion string, senderValidation bool) *paymail
 			paymail.BRFCVerifyPublicKeyOwner: "/verify-pubkey/{alias}@{domain.tld}/{pubkey}",
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // P2PCapabilities will make generic capabilities & add additional p2p capabilities
-func P2PCapabilities(bsvAliasVersion string, senderValidation bool) *paymail.CapabilitiesPayload {
-	c := GenericCapabilities(bsvAliasVersion, senderValidation)
+func P2PCapabilities(bsvAliasVersion string, senderValidation bool, opts ...CapabilityOps) *paymail.CapabilitiesPayload {
+	c := GenericCapabilities(bsvAliasVersion, senderValidation, opts...)
 	c.Capabilities[paymail.BRFCP2PTransactions] = "/receive-transaction/{alias}@{domain.tld}"
 	c.Capabilities[paymail.BRFCP2PPaymentDestination] = "/p2p-payment-destination/{alias}@{domain.tld}"
 	return c
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +33,14 @@ func TestGenericCapabilities(t *testing.T) {
 		require.NotNil(t, c)
 		assert.Equal(t, true, c.Capabilities[paymail.BRFCSenderValidation])
 	})
+
+	t.Run("payto capability", func(t *testing.T) {
+		c := GenericCapabilities("test", false, WithPayToCapability())
+		require.NotNil(t, c)
+		assert.Equal(t, 6, len(c.Capabilities))
+		assert.Equal(t, true, c.Capabilities[paymail.BRFCPayToProtocolPrefix])
+		assert.NotContains(t, GenericCapabilities("test", false).Capabilities, paymail.BRFCPayToProtocolPrefix)
+	})
 }
 
 // TestP2PCapabilities will test the method P2PCapabilities()
@@ -61,4 +72,48 @@ func TestP2PCapabilities(t *testing.T) {
 		assert.NotEmpty(t, c.Capabilities[paymail.BRFCP2PTransactions])
 		assert.NotEmpty(t, c.Capabilities[paymail.BRFCP2PPaymentDestination])
 	})
+
+	t.Run("payto capability", func(t *testing.T) {
+		c := P2PCapabilities("", true, WithPayToCapability())
+		require.NotNil(t, c)
+		assert.Equal(t, 8, len(c.Capabilities))
+		assert.Equal(t, true, c.Capabilities[paymail.BRFCPayToProtocolPrefix])
+	})
+}
+
+// TestConfiguration_showCapabilities will test the method showCapabilities()
+func TestConfiguration_showCapabilities(t *testing.T) {
+	t.Parallel()
+
+	t.Run("mainnet well-known route", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"))
+		require.NoError(t, err)
+		handler := Handlers(c)
+
+		req := httptest.NewRequest(http.MethodGet, "http://test.com/.well-known/bsvalias", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("network well-known route", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithNetwork(paymail.Regtest))
+		require.NoError(t, err)
+		assert.Equal(t, paymail.Regtest, c.Network)
+		handler := Handlers(c)
+
+		req := httptest.NewRequest(http.MethodGet, "http://test.com/.well-known/bsvalias-regtest", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		capabilities := &paymail.CapabilitiesPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), capabilities))
+		assert.True(t, capabilities.Has(paymail.BRFCPki, ""))
+
+		// The mainnet route is not served
+		req = httptest.NewRequest(http.MethodGet, "http://test.com/.well-known/bsvalias", nil)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
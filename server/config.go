@@ -1,9 +1,11 @@
 package server
 
 import (
+	"context"
 	"strings"
 	"time"
 
+	"github.com/libsv/go-bk/bec"
 	"github.com/mrz1836/go-sanitize"
 	"github.com/tonicpow/go-paymail"
 )
@@ -12,18 +14,31 @@ import (
 type Configuration struct {
 	APIVersion                       string                       `json:"api_version"`
 	BasicRoutes                      *basicRoutes                 `json:"basic_routes"`
+	BeefEnabled                      bool                         `json:"beef_enabled"`
 	BSVAliasVersion                  string                       `json:"bsv_alias_version"`
 	Capabilities                     *paymail.CapabilitiesPayload `json:"capabilities"`
 	PaymailDomains                   []*Domain                    `json:"paymail_domains"`
+	Network                          paymail.Network              `json:"network"`
 	PaymailDomainsValidationDisabled bool                         `json:"paymail_domains_validation_disabled"`
+	PayToEnabled                     bool                         `json:"payto_enabled"`
+	PikeEnabled                      bool                         `json:"pike_enabled"`
 	Port                             int                          `json:"port"`
 	Prefix                           string                       `json:"prefix"`
+	ReceiverApprovalsEnabled         bool                         `json:"receiver_approvals_enabled"`
+	ReceiverApprovalsRequired        bool                         `json:"receiver_approvals_required"`
+	ReplayProtectionEnabled          bool                         `json:"replay_protection_enabled"`
+	ReplayStore                      ReplayStore                  `json:"-"`
+	ReplayTransactionTTL             time.Duration                `json:"replay_transaction_ttl"`
 	SenderValidationEnabled          bool                         `json:"sender_validation_enabled"`
+	SFPEnabled                       bool                         `json:"sfp_enabled"`
 	ServiceName                      string                       `json:"service_name"`
 	Timeout                          time.Duration                `json:"timeout"`
+	TimestampValidator               *paymail.TimestampValidator  `json:"-"`
+	TokenDestinationsEnabled         bool                         `json:"token_destinations_enabled"`
 
 	// private
-	actions PaymailServiceProvider
+	actions      PaymailServiceProvider
+	senderPubKey func(ctx context.Context, senderPaymailAddress string) (*bec.PublicKey, error) // Default: getSenderPubKey()
 }
 
 // Domain is the Paymail Domain information
@@ -74,9 +89,7 @@ func (c *Configuration) IsAllowedDomain(domain string) (success bool) {
 
 	// Sanitize the domain (standard)
 	var err error
-	if domain, err = sanitize.Domain(
-		domain, false, true,
-	); err != nil {
+	if domain, err = paymail.SanitizeDomain(domain); err != nil {
 		// todo: log the error? This should rarely occur
 		return
 	}
@@ -100,11 +113,8 @@ func (c *Configuration) AddDomain(domain string) (err error) {
 		return ErrDomainMissing
 	}
 
-	// Sanitize and standardize
-	domain, err = sanitize.Domain(
-		domain, false, true,
-	)
-	if err != nil {
+	// Sanitize and standardize (IDN domains are converted to punycode)
+	if domain, err = paymail.SanitizeDomain(domain); err != nil {
 		return
 	}
 
@@ -124,10 +134,22 @@ func (c *Configuration) EnrichCapabilities(domain string) *paymail.CapabilitiesP
 		BsvAlias:     c.Capabilities.BsvAlias,
 		Capabilities: make(map[string]interface{}),
 	}
+	serviceURL := GenerateServiceURL(c.Prefix, domain, c.APIVersion, c.ServiceName)
 	for key, val := range c.Capabilities.Capabilities {
-		if w, ok := val.(string); ok {
-			capabilities.Capabilities[key] = GenerateServiceURL(c.Prefix, domain, c.APIVersion, c.ServiceName) + w
-		} else {
+		switch w := val.(type) {
+		case string:
+			capabilities.Capabilities[key] = serviceURL + w
+		case map[string]interface{}: // Objects of urls (IE: PIKE)
+			urls := make(map[string]interface{}, len(w))
+			for name, path := range w {
+				if p, ok := path.(string); ok {
+					urls[name] = serviceURL + p
+				} else {
+					urls[name] = path
+				}
+			}
+			capabilities.Capabilities[key] = urls
+		default:
 			capabilities.Capabilities[key] = val
 		}
 	}
@@ -177,6 +199,58 @@ func NewConfig(serviceProvider PaymailServiceProvider, opts ...ConfigOps) (*Conf
 		return nil, err
 	}
 
+	// Advertise the PayTo protocol prefix
+	if config.PayToEnabled {
+		WithPayToCapability()(config.Capabilities)
+	}
+
+	// Receiver approvals require the optional interface (and the capability)
+	if config.ReceiverApprovalsEnabled {
+		if _, ok := serviceProvider.(PaymentApprovalProvider); !ok {
+			return nil, ErrApprovalProviderMissing
+		}
+		config.Capabilities.Capabilities[paymail.BRFCReceiverApprovals] = ReceiverApprovalsPath
+	}
+
+	// Token destinations require the optional interface (and the capability)
+	if config.TokenDestinationsEnabled {
+		if _, ok := serviceProvider.(TokenDestinationProvider); !ok {
+			return nil, ErrTokenProviderMissing
+		}
+		config.Capabilities.Capabilities[paymail.BRFCP2PPaymentDestinationWithToken] = P2PTokenDestinationPath
+	}
+
+	// BEEF requires the block headers (to verify the merkle roots) and the capability flag
+	if config.BeefEnabled {
+		if _, ok := serviceProvider.(paymail.BlockHeadersProvider); !ok {
+			return nil, ErrBlockHeadersProviderMissing
+		}
+		config.Capabilities.Capabilities[paymail.BRFCBeefTransaction] = true
+	}
+
+	// PIKE requires the optional interface (and the capability)
+	if config.PikeEnabled {
+		if _, ok := serviceProvider.(PikeProvider); !ok {
+			return nil, ErrPikeProviderMissing
+		}
+		WithPikeCapability()(config.Capabilities)
+	}
+
+	// SFP requires the optional interface (and the capabilities)
+	if config.SFPEnabled {
+		if _, ok := serviceProvider.(SFPProvider); !ok {
+			return nil, ErrSFPProviderMissing
+		}
+		config.Capabilities.Capabilities[paymail.BRFCSFPAssetInformation] = SFPAssetInformationPath
+		config.Capabilities.Capabilities[paymail.BRFCSFPAuthoriseAction] = SFPAuthorisePath
+		config.Capabilities.Capabilities[paymail.BRFCSFPBuildAction] = SFPBuildPath
+	}
+
+	// Replay protection uses the in-memory store by default (same clock as the timestamp validator)
+	if config.ReplayProtectionEnabled && config.ReplayStore == nil {
+		config.ReplayStore = NewMemoryReplayStore(config.TimestampValidator.Now)
+	}
+
 	// Set the service provider
 	config.actions = serviceProvider
 
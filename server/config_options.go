@@ -22,9 +22,21 @@ func defaultConfigOptions() *Configuration {
 		PaymailDomainsValidationDisabled: false,
 		Port:                             DefaultServerPort,
 		Prefix:                           DefaultPrefix,
+		ReplayTransactionTTL:             DefaultReplayTransactionTTL,
 		SenderValidationEnabled:          DefaultSenderValidation,
 		ServiceName:                      paymail.DefaultServiceName,
 		Timeout:                          DefaultTimeout,
+		TimestampValidator:               paymail.NewTimestampValidator(),
+	}
+}
+
+// WithTimestampValidator will overwrite the validator used for the "dt" of the sender requests
+// (IE: a custom clock or a larger skew for slow clients)
+func WithTimestampValidator(validator *paymail.TimestampValidator) ConfigOps {
+	return func(c *Configuration) {
+		if validator != nil {
+			c.TimestampValidator = validator
+		}
 	}
 }
 
@@ -89,6 +101,87 @@ func WithSenderValidation() ConfigOps {
 	}
 }
 
+// WithPayTo will advertise the PayTo protocol prefix capability (payto:alias@domain.tld)
+func WithPayTo() ConfigOps {
+	return func(c *Configuration) {
+		c.PayToEnabled = true
+	}
+}
+
+// WithReplayProtection will reject the requests that were already processed: signed sender requests
+// (same sender & signed content) for the skew window of the timestamp validator, and P2P transactions
+// (same txid & reference) for the ReplayTransactionTTL (see: WithReplayTransactionTTL())
+//
+// The store is optional (default: NewMemoryReplayStore()), use a shared store when running multiple servers
+func WithReplayProtection(store ReplayStore) ConfigOps {
+	return func(c *Configuration) {
+		c.ReplayProtectionEnabled = true
+		if store != nil {
+			c.ReplayStore = store
+		}
+	}
+}
+
+// WithReplayTransactionTTL will set how long the received transactions are remembered (replay protection)
+func WithReplayTransactionTTL(ttl time.Duration) ConfigOps {
+	return func(c *Configuration) {
+		if ttl > 0 {
+			c.ReplayTransactionTTL = ttl
+		}
+	}
+}
+
+// WithReceiverApprovals will enable receiver approvals (the service provider must implement PaymentApprovalProvider)
+//
+// If required, a payment destination is only issued for an approved payment (approvalId in the request),
+// otherwise the approval is only checked when the sender includes one
+func WithReceiverApprovals(required bool) ConfigOps {
+	return func(c *Configuration) {
+		c.ReceiverApprovalsEnabled = true
+		c.ReceiverApprovalsRequired = required
+	}
+}
+
+// WithP2PTokenDestinations will enable the P2P payment destination with tokens
+// (the service provider must implement TokenDestinationProvider)
+func WithP2PTokenDestinations() ConfigOps {
+	return func(c *Configuration) {
+		c.TokenDestinationsEnabled = true
+	}
+}
+
+// WithBEEF will enable receiving transactions as BEEF envelopes (SPV), the merkle roots are verified
+// with the service provider (it must implement paymail.BlockHeadersProvider)
+func WithBEEF() ConfigOps {
+	return func(c *Configuration) {
+		c.BeefEnabled = true
+	}
+}
+
+// WithNetwork will set the network of the server, the capabilities are served
+// on the well-known url of the network (IE: /.well-known/bsvalias-testnet)
+func WithNetwork(network paymail.Network) ConfigOps {
+	return func(c *Configuration) {
+		c.Network = network
+	}
+}
+
+// WithPike will enable PIKE (paymail identity key exchange), the contact invite & outputs requests
+// (the service provider must implement PikeProvider)
+func WithPike() ConfigOps {
+	return func(c *Configuration) {
+		c.PikeEnabled = true
+	}
+}
+
+// WithSFP will enable the SFP asset information, build & authorise actions
+// (the service provider must implement SFPProvider)
+func WithSFP() ConfigOps {
+	return func(c *Configuration) {
+		c.SFPEnabled = true
+	}
+}
+
 // WithDomain will add the domain if not found
 func WithDomain(domain string) ConfigOps {
 	return func(c *Configuration) {
@@ -165,6 +165,15 @@ func TestConfiguration_IsAllowedDomain(t *testing.T) {
 		assert.Equal(t, true, success)
 	})
 
+	t.Run("IDN domains are converted to punycode", func(t *testing.T) {
+		c := testConfig(t, "Bücher.de")
+		require.NotNil(t, c)
+
+		assert.Equal(t, "xn--bcher-kva.de", c.PaymailDomains[0].Name)
+		assert.Equal(t, true, c.IsAllowedDomain("www.bücher.de"))
+		assert.Equal(t, true, c.IsAllowedDomain("xn--bcher-kva.de"))
+	})
+
 	t.Run("domain validation on", func(t *testing.T) {
 		c := testConfig(t, "WwW.Test.Com")
 		c.PaymailDomainsValidationDisabled = false
@@ -256,6 +265,22 @@ func TestConfiguration_EnrichCapabilities(t *testing.T) {
 		capabilities = c.EnrichCapabilities(testDomain)
 		assert.Equal(t, 5, len(capabilities.Capabilities))
 	})
+
+	t.Run("object of urls", func(t *testing.T) {
+		testDomain := "test.com"
+		c := testConfig(t, testDomain)
+		require.NotNil(t, c)
+		WithPikeCapability()(c.Capabilities)
+
+		capabilities := c.EnrichCapabilities(testDomain)
+		assert.Equal(t, map[string]interface{}{
+			"invite":  "https://" + testDomain + "/v1/bsvalias/contact/invite/{alias}@{domain.tld}",
+			"outputs": "https://" + testDomain + "/v1/bsvalias/pike/outputs/{alias}@{domain.tld}",
+		}, capabilities.Capabilities[paymail.BRFCPike])
+
+		// The configuration is not changed
+		assert.Equal(t, PikeInvitePath, c.Capabilities.GetPikeCapability().Invite)
+	})
 }
 
 // TestGenerateServiceURL will test the method GenerateServiceURL()
@@ -377,6 +402,19 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 7, len(c.Capabilities.Capabilities))
 	})
 
+	t.Run("with payto", func(t *testing.T) {
+		c, err := NewConfig(
+			new(mockServiceProvider),
+			WithPayTo(),
+			WithDomain("test.com"),
+			WithP2PCapabilities(),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.True(t, c.PayToEnabled)
+		assert.Equal(t, true, c.EnrichCapabilities("test.com").Capabilities[paymail.BRFCPayToProtocolPrefix])
+	})
+
 	t.Run("with custom capabilities", func(t *testing.T) {
 		c, err := NewConfig(
 			new(mockServiceProvider),
@@ -8,11 +8,12 @@ import (
 
 // Server default values
 const (
-	DefaultAPIVersion       = "v1"             // Version of API
-	DefaultPrefix           = "https://"       // Paymail specs require SSL
-	DefaultSenderValidation = false            // If true, it requires extra sender validation
-	DefaultServerPort       = 3000             // Port for the server
-	DefaultTimeout          = 15 * time.Second // Default timeouts
+	DefaultAPIVersion           = "v1"             // Version of API
+	DefaultPrefix               = "https://"       // Paymail specs require SSL
+	DefaultReplayTransactionTTL = 24 * time.Hour   // How long the received transactions are remembered (replay protection)
+	DefaultSenderValidation     = false            // If true, it requires extra sender validation
+	DefaultServerPort           = 3000             // Port for the server
+	DefaultTimeout              = 15 * time.Second // Default timeouts
 )
 
 // basicRoutes is the configuration for basic server routes
@@ -25,12 +26,19 @@ type basicRoutes struct {
 
 // RequestMetadata is the struct with extra metadata
 type RequestMetadata struct {
-	Alias              string                  `json:"alias,omitempty"`               // Alias of the paymail
-	Domain             string                  `json:"domain,omitempty"`              // Domain of the request
-	IPAddress          string                  `json:"ip_address,omitempty"`          // IP address of the requesting user
-	Note               string                  `json:"note,omitempty"`                // Generic note field used for extra information
-	PaymentDestination *paymail.PaymentRequest `json:"payment_destination,omitempty"` // Information from the P2P Payment Destination request
-	RequestURI         string                  `json:"request_uri,omitempty"`         // Full requesting URL path
-	ResolveAddress     *paymail.SenderRequest  `json:"resolve_address,omitempty"`     // Information from the Resolve Address request
-	UserAgent          string                  `json:"user_agent,omitempty"`          // User agent of the requesting user
+	Address            paymail.Address                 `json:"address"`                       // The paymail address (alias@domain.tld, validated)
+	Alias              string                          `json:"alias,omitempty"`               // Alias of the paymail
+	Domain             string                          `json:"domain,omitempty"`              // Domain of the request
+	IPAddress          string                          `json:"ip_address,omitempty"`          // IP address of the requesting user
+	Note               string                          `json:"note,omitempty"`                // Generic note field used for extra information
+	PaymentApproval    *paymail.PaymentApprovalPayload `json:"payment_approval,omitempty"`    // The approved payment (if receiver approvals are enabled)
+	PaymentDestination *paymail.PaymentRequest         `json:"payment_destination,omitempty"` // Information from the P2P Payment Destination request
+	PikeContact        *paymail.PikeContactPayload     `json:"pike_contact,omitempty"`        // Information from the PIKE contact invite
+	PikeOutputs        *paymail.PikeOutputsRequest     `json:"pike_outputs,omitempty"`        // Information from the PIKE outputs request
+	RequestURI         string                          `json:"request_uri,omitempty"`         // Full requesting URL path
+	SFPAuthorise       *paymail.SFPAuthoriseRequest    `json:"sfp_authorise,omitempty"`       // Information from the SFP authorise request
+	SFPBuild           *paymail.SFPBuildRequest        `json:"sfp_build,omitempty"`           // Information from the SFP build request
+	ResolveAddress     *paymail.SenderRequest          `json:"resolve_address,omitempty"`     // Information from the Resolve Address request
+	TokenDestination   *paymail.TokenPaymentRequest    `json:"token_destination,omitempty"`   // Information from the P2P Payment Destination with tokens request
+	UserAgent          string                          `json:"user_agent,omitempty"`          // User agent of the requesting user
 }
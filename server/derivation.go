@@ -0,0 +1,295 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/tonicpow/go-paymail"
+)
+
+// Address derivation default values
+const (
+	DefaultDerivationChain = bitcoin.DefaultExternalChain // Chain for the derived addresses (m/0/n)
+	DefaultGapLimit        = 20                           // Max unused addresses in a row (BIP-44 gap limit)
+)
+
+// GapLimitPolicy is what happens when the gap limit is reached (no address in the window was used)
+type GapLimitPolicy uint8
+
+// Gap limit policies
+const (
+	GapLimitReject GapLimitPolicy = iota // Return ErrGapLimitReached (default, addresses are never reused)
+	GapLimitReuse                        // Reuse the unused addresses of the window (oldest first)
+)
+
+var (
+	// ErrGapLimitReached is the error for deriving more unused addresses in a row than the gap limit
+	ErrGapLimitReached = errors.New("gap limit reached, no address can be derived until one is used")
+
+	// ErrIndexStoreNil is the error for having a nil index store
+	ErrIndexStoreNil = errors.New("index store is nil")
+
+	// ErrXPubMissing is the error for a missing xPub
+	ErrXPubMissing = errors.New("xpub is missing")
+)
+
+// IndexStore stores the derivation indexes for each key (IE: paymail address or xPub)
+//
+// Implementations must be safe for concurrent use, the IndexState methods can be used
+// to apply the gap limit (see: NewMemoryIndexStore())
+type IndexStore interface {
+	// ReserveIndex will return the next index to derive for the key, the policy is applied
+	// when the next index would be past the gap limit after the last used index
+	ReserveIndex(ctx context.Context, key string, gapLimit uint32, policy GapLimitPolicy) (uint32, error)
+
+	// MarkIndexUsed will record that the index of the key received a payment
+	MarkIndexUsed(ctx context.Context, key string, index uint32) error
+}
+
+// IndexState is the derivation state of a key
+type IndexState struct {
+	NextIndex uint32 `json:"next_index"` // Next index to derive
+	UsedCount uint32 `json:"used_count"` // Highest used index + 1 (0 if none were used)
+}
+
+// Reserve will return the next index and move the state forward
+//
+// Indexes never go past the gap limit, so a wallet scanning with the same gap limit finds every payment.
+// When the limit is reached, GapLimitReject returns ErrGapLimitReached and GapLimitReuse starts
+// again from the oldest unused index of the window (the same address is handed out again)
+func (s *IndexState) Reserve(gapLimit uint32, policy GapLimitPolicy) (index uint32, err error) {
+	if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+	if s.NextIndex < s.UsedCount {
+		s.NextIndex = s.UsedCount
+	}
+	if s.NextIndex >= s.UsedCount+gapLimit {
+		if policy != GapLimitReuse {
+			err = ErrGapLimitReached
+			return
+		}
+		s.NextIndex = s.UsedCount
+	}
+	index = s.NextIndex
+	s.NextIndex++
+	return
+}
+
+// MarkUsed will record that the index received a payment
+func (s *IndexState) MarkUsed(index uint32) {
+	if index >= s.UsedCount {
+		s.UsedCount = index + 1
+	}
+}
+
+// memoryIndexStore is an in-memory IndexStore
+type memoryIndexStore struct {
+	mu     sync.Mutex
+	states map[string]*IndexState
+}
+
+// NewMemoryIndexStore will return an in-memory IndexStore (state is lost on restart)
+func NewMemoryIndexStore() IndexStore {
+	return &memoryIndexStore{states: make(map[string]*IndexState)}
+}
+
+// ReserveIndex will return the next index to derive for the key
+func (m *memoryIndexStore) ReserveIndex(_ context.Context, key string, gapLimit uint32,
+	policy GapLimitPolicy) (uint32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state(key).Reserve(gapLimit, policy)
+}
+
+// MarkIndexUsed will record that the index of the key received a payment
+func (m *memoryIndexStore) MarkIndexUsed(_ context.Context, key string, index uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state(key).MarkUsed(index)
+	return nil
+}
+
+// state will return the state for the key (created if missing, lock must be held)
+func (m *memoryIndexStore) state(key string) *IndexState {
+	state, ok := m.states[key]
+	if !ok {
+		state = &IndexState{}
+		m.states[key] = state
+	}
+	return state
+}
+
+// DerivedAddress is an address derived from an xPub
+type DerivedAddress struct {
+	Address   string `json:"address"`             // Derived address
+	Chain     uint32 `json:"chain"`               // Chain of the address (m/chain/index)
+	Index     uint32 `json:"index"`               // Index of the address (m/chain/index)
+	Key       string `json:"key"`                 // Key in the index store
+	Reference string `json:"reference,omitempty"` // Reference of the payment destination
+	Script    string `json:"script"`              // P2PKH output script (hex)
+}
+
+// AddressDeriverOps allow functional options to be supplied
+// that overwrite default options.
+type AddressDeriverOps func(d *AddressDeriver)
+
+// WithDerivationChain will set the chain for the derived addresses (default: 0)
+func WithDerivationChain(chain uint32) AddressDeriverOps {
+	return func(d *AddressDeriver) {
+		d.chain = chain
+	}
+}
+
+// WithDerivationNetwork will set the network of the derived addresses (default: mainnet)
+func WithDerivationNetwork(network paymail.Network) AddressDeriverOps {
+	return func(d *AddressDeriver) {
+		d.network = network
+	}
+}
+
+// WithGapLimit will set the max number of unused addresses in a row (default: 20)
+func WithGapLimit(gapLimit uint32) AddressDeriverOps {
+	return func(d *AddressDeriver) {
+		if gapLimit > 0 {
+			d.gapLimit = gapLimit
+		}
+	}
+}
+
+// WithGapLimitPolicy will set what happens when the gap limit is reached (default: GapLimitReject)
+//
+// The address resolution & P2P payment destination routes are not authenticated: with GapLimitReject,
+// a gap limit of unpaid requests (from anyone) stops the paymail from receiving payments until
+// an address is used. GapLimitReuse keeps the paymail receiving, but hands out the same addresses again.
+// A larger gap limit (see: WithGapLimit()) needs more requests, but the wallet must scan with the same limit.
+func WithGapLimitPolicy(policy GapLimitPolicy) AddressDeriverOps {
+	return func(d *AddressDeriver) {
+		d.gapPolicy = policy
+	}
+}
+
+// AddressDeriver derives a new address from an xPub for every address resolution
+// or P2P payment destination, so a paymail does not reuse the same address
+// (see: WithGapLimitPolicy() for when the gap limit is reached)
+type AddressDeriver struct {
+	chain     uint32          // Chain for the derived addresses
+	gapLimit  uint32          // Max unused addresses in a row
+	gapPolicy GapLimitPolicy  // What happens when the gap limit is reached
+	network   paymail.Network // Network of the derived addresses
+	store     IndexStore      // Store for the indexes
+}
+
+// NewAddressDeriver will return an AddressDeriver using the index store
+func NewAddressDeriver(store IndexStore, opts ...AddressDeriverOps) (*AddressDeriver, error) {
+	if store == nil {
+		return nil, ErrIndexStoreNil
+	}
+	d := &AddressDeriver{
+		chain:    DefaultDerivationChain,
+		gapLimit: DefaultGapLimit,
+		store:    store,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, nil
+}
+
+// DeriveAddress will derive the next address of the xPub (the index is tracked by key)
+func (d *AddressDeriver) DeriveAddress(ctx context.Context, key, xPub string) (*DerivedAddress, error) {
+
+	// Parse the xPub
+	if len(xPub) == 0 {
+		return nil, ErrXPubMissing
+	}
+	hdKey, err := bitcoin.GetHDKeyFromExtendedPublicKey(xPub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve the index
+	derived := &DerivedAddress{Chain: d.chain, Key: key}
+	if derived.Index, err = d.store.ReserveIndex(ctx, key, d.gapLimit, d.gapPolicy); err != nil {
+		return nil, err
+	}
+
+	// Derive the address & script
+	if hdKey, err = bitcoin.GetHDKeyByPath(hdKey, d.chain, derived.Index); err != nil {
+		return nil, err
+	}
+	var pubKey *bec.PublicKey
+	if pubKey, err = hdKey.ECPubKey(); err != nil {
+		return nil, err
+	}
+	derived.Address = d.network.AddressFromPubKey(pubKey.SerialiseCompressed())
+	var script *bscript.Script
+	if script, err = bscript.NewP2PKHFromPubKeyEC(pubKey); err != nil {
+		return nil, err
+	}
+	derived.Script = script.String()
+
+	return derived, nil
+}
+
+// MarkUsed will record that the derived address received a payment
+func (d *AddressDeriver) MarkUsed(ctx context.Context, derived *DerivedAddress) error {
+	return d.store.MarkIndexUsed(ctx, derived.Key, derived.Index)
+}
+
+// ResolutionPayload will return the address resolution response using the next address of the xPub
+//
+// The output is signed if a signer (PKI key) is given (sender validation)
+func (d *AddressDeriver) ResolutionPayload(ctx context.Context, key, xPub string,
+	signer paymail.Signer) (*paymail.ResolutionPayload, *DerivedAddress, error) {
+
+	// Derive the next address
+	derived, err := d.DeriveAddress(ctx, key, xPub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sign the output if a signer is given
+	response := &paymail.ResolutionPayload{Address: derived.Address, Output: derived.Script}
+	if signer != nil {
+		if response.Signature, err = signer.SignMessage(ctx, response.Output); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return response, derived, nil
+}
+
+// PaymentDestinationPayload will return the P2P payment destination response using the
+// next address of the xPub, with a new random reference
+func (d *AddressDeriver) PaymentDestinationPayload(ctx context.Context, key, xPub string,
+	satoshis uint64) (*paymail.PaymentDestinationPayload, *DerivedAddress, error) {
+
+	// Derive the next address
+	derived, err := d.DeriveAddress(ctx, key, xPub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Generate a unique reference
+	reference := make([]byte, 16)
+	if _, err = rand.Read(reference); err != nil {
+		return nil, nil, err
+	}
+	derived.Reference = hex.EncodeToString(reference)
+
+	return &paymail.PaymentDestinationPayload{
+		Outputs: []*paymail.PaymentOutput{{
+			Address:  derived.Address,
+			Satoshis: satoshis,
+			Script:   derived.Script,
+		}},
+		Reference: derived.Reference,
+	}, derived, nil
+}
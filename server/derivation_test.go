@@ -0,0 +1,428 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+const testKey = "mrz@test.com"
+
+// newTestXPub will return a new xPub (and its xPriv)
+func newTestXPub(t *testing.T) (xPub, xPriv string) {
+	hdKey, err := bitcoin.GenerateHDKey(bitcoin.RecommendedSeedLength)
+	require.NoError(t, err)
+	xPub, err = bitcoin.GetExtendedPublicKey(hdKey)
+	require.NoError(t, err)
+	return xPub, hdKey.String()
+}
+
+// newTestDeriver will return an address deriver with an in-memory store
+func newTestDeriver(t *testing.T, opts ...AddressDeriverOps) *AddressDeriver {
+	d, err := NewAddressDeriver(NewMemoryIndexStore(), opts...)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	return d
+}
+
+// TestIndexState_Reserve will test the methods Reserve() and MarkUsed()
+func TestIndexState_Reserve(t *testing.T) {
+	t.Parallel()
+
+	t.Run("indexes are handed out in order", func(t *testing.T) {
+		state := &IndexState{}
+		for i := uint32(0); i < 3; i++ {
+			index, err := state.Reserve(5, GapLimitReject)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+		}
+	})
+
+	t.Run("gap limit reached", func(t *testing.T) {
+		state := &IndexState{}
+		for i := uint32(0); i < 3; i++ {
+			index, err := state.Reserve(3, GapLimitReject)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+		}
+		_, err := state.Reserve(3, GapLimitReject)
+		require.ErrorIs(t, err, ErrGapLimitReached)
+		assert.Equal(t, uint32(3), state.NextIndex)
+
+		// Using an index moves the window forward (indexes are never handed out twice)
+		state.MarkUsed(1)
+		for i := uint32(3); i < 5; i++ {
+			index, err := state.Reserve(3, GapLimitReject)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+		}
+		_, err = state.Reserve(3, GapLimitReject)
+		require.ErrorIs(t, err, ErrGapLimitReached)
+	})
+
+	t.Run("gap limit reached with reuse", func(t *testing.T) {
+		state := &IndexState{}
+		for i := uint32(0); i < 3; i++ {
+			index, err := state.Reserve(3, GapLimitReuse)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+		}
+
+		// The window starts again from the oldest unused index
+		for i := uint32(0); i < 3; i++ {
+			index, err := state.Reserve(3, GapLimitReuse)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+		}
+
+		// Using an index moves the window forward
+		state.MarkUsed(1)
+		for _, expected := range []uint32{3, 4, 2, 3} {
+			index, err := state.Reserve(3, GapLimitReuse)
+			require.NoError(t, err)
+			assert.Equal(t, expected, index)
+		}
+	})
+
+	t.Run("older used index does not move the window back", func(t *testing.T) {
+		state := &IndexState{}
+		state.MarkUsed(10)
+		state.MarkUsed(2)
+		assert.Equal(t, uint32(11), state.UsedCount)
+		index, err := state.Reserve(0, GapLimitReject)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(11), index)
+	})
+
+	t.Run("zero gap limit uses the default", func(t *testing.T) {
+		state := &IndexState{}
+		for i := uint32(0); i < DefaultGapLimit; i++ {
+			index, err := state.Reserve(0, GapLimitReject)
+			require.NoError(t, err)
+			assert.Equal(t, i, index)
+		}
+		_, err := state.Reserve(0, GapLimitReject)
+		require.ErrorIs(t, err, ErrGapLimitReached)
+	})
+}
+
+// TestNewAddressDeriver will test the method NewAddressDeriver()
+func TestNewAddressDeriver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil store", func(t *testing.T) {
+		d, err := NewAddressDeriver(nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrIndexStoreNil)
+		assert.Nil(t, d)
+	})
+
+	t.Run("default options", func(t *testing.T) {
+		d := newTestDeriver(t)
+		assert.Equal(t, uint32(DefaultDerivationChain), d.chain)
+		assert.Equal(t, uint32(DefaultGapLimit), d.gapLimit)
+		assert.Equal(t, GapLimitReject, d.gapPolicy)
+	})
+
+	t.Run("custom options", func(t *testing.T) {
+		d := newTestDeriver(t, WithDerivationChain(1), WithGapLimit(5), WithGapLimitPolicy(GapLimitReuse))
+		assert.Equal(t, uint32(1), d.chain)
+		assert.Equal(t, uint32(5), d.gapLimit)
+		assert.Equal(t, GapLimitReuse, d.gapPolicy)
+	})
+
+	t.Run("zero gap limit is ignored", func(t *testing.T) {
+		d := newTestDeriver(t, WithGapLimit(0))
+		assert.Equal(t, uint32(DefaultGapLimit), d.gapLimit)
+	})
+}
+
+// TestAddressDeriver_DeriveAddress will test the method DeriveAddress()
+func TestAddressDeriver_DeriveAddress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("matches the xpriv derivation", func(t *testing.T) {
+		xPub, xPriv := newTestXPub(t)
+		d := newTestDeriver(t)
+		hdKey, err := bitcoin.GenerateHDKeyFromString(xPriv)
+		require.NoError(t, err)
+
+		for i := uint32(0); i < 3; i++ {
+			derived, err := d.DeriveAddress(context.Background(), testKey, xPub)
+			require.NoError(t, err)
+			assert.Equal(t, i, derived.Index)
+			assert.Equal(t, testKey, derived.Key)
+
+			child, err := bitcoin.GetHDKeyByPath(hdKey, DefaultDerivationChain, i)
+			require.NoError(t, err)
+			address, err := bitcoin.GetAddressStringFromHDKey(child)
+			require.NoError(t, err)
+			assert.Equal(t, address, derived.Address)
+
+			script, err := bitcoin.ScriptFromAddress(address)
+			require.NoError(t, err)
+			assert.Equal(t, script, derived.Script)
+		}
+	})
+
+	t.Run("network addresses", func(t *testing.T) {
+		xPub, xPriv := newTestXPub(t)
+		d := newTestDeriver(t, WithDerivationNetwork(paymail.Regtest))
+		derived, err := d.DeriveAddress(context.Background(), testKey, xPub)
+		require.NoError(t, err)
+
+		hdKey, err := bitcoin.GenerateHDKeyFromString(xPriv)
+		require.NoError(t, err)
+		child, err := bitcoin.GetHDKeyByPath(hdKey, DefaultDerivationChain, 0)
+		require.NoError(t, err)
+		pubKey, err := child.ECPubKey()
+		require.NoError(t, err)
+		address, err := bscript.NewAddressFromPublicKey(pubKey, false)
+		require.NoError(t, err)
+		assert.Equal(t, address.AddressString, derived.Address)
+
+		// The script is the same on every network
+		script, err := bscript.NewP2PKHFromAddress(address.AddressString)
+		require.NoError(t, err)
+		assert.Equal(t, script.String(), derived.Script)
+	})
+
+	t.Run("keys are tracked separately", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t)
+		_, err := d.DeriveAddress(context.Background(), testKey, xPub)
+		require.NoError(t, err)
+		derived, err := d.DeriveAddress(context.Background(), "satchmo@test.com", xPub)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0), derived.Index)
+	})
+
+	t.Run("used address moves the gap window", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t, WithGapLimit(2))
+		first, err := d.DeriveAddress(context.Background(), testKey, xPub)
+		require.NoError(t, err)
+		_, err = d.DeriveAddress(context.Background(), testKey, xPub)
+		require.NoError(t, err)
+
+		// Gap limit reached
+		_, err = d.DeriveAddress(context.Background(), testKey, xPub)
+		require.ErrorIs(t, err, ErrGapLimitReached)
+
+		require.NoError(t, d.MarkUsed(context.Background(), first))
+		next, err := d.DeriveAddress(context.Background(), testKey, xPub)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), next.Index)
+		assert.NotEqual(t, first.Address, next.Address)
+	})
+
+	t.Run("missing xpub", func(t *testing.T) {
+		d := newTestDeriver(t)
+		_, err := d.DeriveAddress(context.Background(), testKey, "")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrXPubMissing)
+	})
+
+	t.Run("invalid xpub", func(t *testing.T) {
+		d := newTestDeriver(t)
+		_, err := d.DeriveAddress(context.Background(), testKey, "invalid")
+		require.Error(t, err)
+	})
+
+	t.Run("concurrent requests get unique addresses", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t)
+		addresses := make([]string, DefaultGapLimit)
+		var wg sync.WaitGroup
+		for i := range addresses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if derived, err := d.DeriveAddress(context.Background(), testKey, xPub); err == nil {
+					addresses[i] = derived.Address
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		unique := make(map[string]struct{})
+		for _, address := range addresses {
+			require.NotEmpty(t, address)
+			unique[address] = struct{}{}
+		}
+		assert.Len(t, unique, len(addresses))
+	})
+}
+
+// TestAddressDeriver_ResolutionPayload will test the method ResolutionPayload()
+func TestAddressDeriver_ResolutionPayload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without signature", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t)
+		response, derived, err := d.ResolutionPayload(context.Background(), testKey, xPub, nil)
+		require.NoError(t, err)
+		assert.Equal(t, derived.Address, response.Address)
+		assert.Equal(t, derived.Script, response.Output)
+		assert.Empty(t, response.Signature)
+	})
+
+	t.Run("signed output", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t)
+		privateKey, err := bitcoin.CreatePrivateKeyString()
+		require.NoError(t, err)
+		signer, err := paymail.NewPrivateKeySigner(privateKey)
+		require.NoError(t, err)
+
+		response, _, err := d.ResolutionPayload(context.Background(), testKey, xPub, signer)
+		require.NoError(t, err)
+		require.NotEmpty(t, response.Signature)
+
+		address, err := bitcoin.GetAddressFromPrivateKeyString(privateKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, bitcoin.VerifyMessage(address, response.Signature, response.Output))
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t)
+		signer, err := paymail.NewRemoteSigner("http://127.0.0.1:1", "unknown", nil)
+		require.NoError(t, err)
+		_, _, err = d.ResolutionPayload(context.Background(), testKey, xPub, signer)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid xpub", func(t *testing.T) {
+		d := newTestDeriver(t)
+		_, _, err := d.ResolutionPayload(context.Background(), testKey, "invalid", nil)
+		require.Error(t, err)
+	})
+}
+
+// TestAddressDeriver_PaymentDestinationPayload will test the method PaymentDestinationPayload()
+func TestAddressDeriver_PaymentDestinationPayload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid destination", func(t *testing.T) {
+		xPub, _ := newTestXPub(t)
+		d := newTestDeriver(t)
+		response, derived, err := d.PaymentDestinationPayload(context.Background(), testKey, xPub, 1000)
+		require.NoError(t, err)
+		require.Len(t, response.Outputs, 1)
+		assert.Equal(t, derived.Address, response.Outputs[0].Address)
+		assert.Equal(t, derived.Script, response.Outputs[0].Script)
+		assert.Equal(t, uint64(1000), response.Outputs[0].Satoshis)
+		assert.Len(t, response.Reference, 32)
+		assert.Equal(t, response.Reference, derived.Reference)
+
+		next, _, err := d.PaymentDestinationPayload(context.Background(), testKey, xPub, 1000)
+		require.NoError(t, err)
+		assert.NotEqual(t, response.Reference, next.Reference)
+		assert.NotEqual(t, response.Outputs[0].Address, next.Outputs[0].Address)
+	})
+
+	t.Run("invalid xpub", func(t *testing.T) {
+		d := newTestDeriver(t)
+		_, _, err := d.PaymentDestinationPayload(context.Background(), testKey, "invalid", 1000)
+		require.Error(t, err)
+	})
+}
+
+// mockDeriverProvider is a service provider that derives the P2P payment destinations from an xPub
+type mockDeriverProvider struct {
+	mockApprovalProvider
+	deriver *AddressDeriver
+	xPub    string
+}
+
+// CreateP2PDestinationResponse is a demo implementation of this interface
+func (m *mockDeriverProvider) CreateP2PDestinationResponse(ctx context.Context, alias, domain string,
+	satoshis uint64, _ *RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+	payload, _, err := m.deriver.PaymentDestinationPayload(ctx, alias+"@"+domain, m.xPub, satoshis)
+	return payload, err
+}
+
+// TestAddressDeriver_gapLimitRoute will test the gap limit through the P2P payment destination route
+func TestAddressDeriver_gapLimitRoute(t *testing.T) {
+	t.Parallel()
+
+	// requestAddress will return the address of a new payment destination (empty if the request failed)
+	requestAddress := func(t *testing.T, handler http.Handler) (string, *httptest.ResponseRecorder) {
+		w := serveRequest(handler, http.MethodPost, "/p2p-payment-destination/mrz@test.com", `{"satoshis":1000}`)
+		if w.Code != http.StatusOK {
+			return "", w
+		}
+		response := &paymail.PaymentDestinationPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+		require.Len(t, response.Outputs, 1)
+		return response.Outputs[0].Address, w
+	}
+
+	// newHandler will return the handler of a server using the deriver
+	newHandler := func(t *testing.T, opts ...AddressDeriverOps) http.Handler {
+		xPub, _ := newTestXPub(t)
+		provider := &mockDeriverProvider{deriver: newTestDeriver(t, opts...), xPub: xPub}
+		c, err := NewConfig(provider, WithDomain("test.com"), WithP2PCapabilities())
+		require.NoError(t, err)
+		return Handlers(c)
+	}
+
+	t.Run("unpaid requests reach the gap limit", func(t *testing.T) {
+		handler := newHandler(t, WithGapLimit(3))
+		for i := 0; i < 3; i++ {
+			address, w := requestAddress(t, handler)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NotEmpty(t, address)
+		}
+
+		_, w := requestAddress(t, handler)
+		assert.Equal(t, http.StatusExpectationFailed, w.Code)
+		assert.Equal(t, ErrorScript, errorCode(t, w))
+		assert.Contains(t, w.Body.String(), ErrGapLimitReached.Error())
+	})
+
+	t.Run("unpaid requests reuse the addresses", func(t *testing.T) {
+		handler := newHandler(t, WithGapLimit(3), WithGapLimitPolicy(GapLimitReuse))
+		var addresses []string
+		for i := 0; i < 6; i++ {
+			address, w := requestAddress(t, handler)
+			require.Equal(t, http.StatusOK, w.Code)
+			addresses = append(addresses, address)
+		}
+		assert.Equal(t, addresses[:3], addresses[3:])
+	})
+}
+
+// ExampleAddressDeriver_PaymentDestinationPayload example using PaymentDestinationPayload()
+func ExampleAddressDeriver_PaymentDestinationPayload() {
+	deriver, _ := NewAddressDeriver(NewMemoryIndexStore())
+	xPub := "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+	response, _, err := deriver.PaymentDestinationPayload(context.Background(), "mrz@test.com", xPub, 1000)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("pay %d satoshis to %s", response.Outputs[0].Satoshis, response.Outputs[0].Address)
+	// Output:pay 1000 satoshis to 12CL4K2eVqj7hQTix7dM7CVHCkpP17Pry3
+}
+
+// BenchmarkAddressDeriver_DeriveAddress benchmarks the method DeriveAddress()
+func BenchmarkAddressDeriver_DeriveAddress(b *testing.B) {
+	deriver, _ := NewAddressDeriver(NewMemoryIndexStore(), WithGapLimit(uint32(b.N)+1))
+	xPub := "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+	for i := 0; i < b.N; i++ {
+		_, _ = deriver.DeriveAddress(context.Background(), testKey, xPub)
+	}
+}
@@ -9,22 +9,29 @@ import (
 )
 
 // Error codes for server response errors
+//
+// These are the same codes used by the client, use paymail.ErrorForCode() to get the sentinel error
 const (
-	ErrorFindingPaymail      = "error-finding-paymail"
-	ErrorInvalidDt           = "invalid-dt"
-	ErrorInvalidParameter    = "invalid-parameter"
-	ErrorInvalidPubKey       = "invalid-pubkey"
-	ErrorInvalidSenderHandle = "invalid-sender-handle"
-	ErrorInvalidSignature    = "invalid-signature"
-	ErrorMethodNotFound      = "method-405"
-	ErrorMissingHex          = "missing-hex"
-	ErrorMissingReference    = "missing-reference"
-	ErrorMissingSatoshis     = "missing-satoshis"
-	ErrorPaymailNotFound     = "not-found"
-	ErrorRecordingTx         = "error-recording-tx"
-	ErrorRequestNotFound     = "request-404"
-	ErrorScript              = "script-error"
-	ErrorUnknownDomain       = "unknown-domain"
+	ErrorApprovalNotFound    = paymail.ErrorCodeApprovalNotFound
+	ErrorContactNotFound     = paymail.ErrorCodeContactNotFound
+	ErrorFindingPaymail      = paymail.ErrorCodeFindingPaymail
+	ErrorInvalidBeef         = paymail.ErrorCodeInvalidBeef
+	ErrorInvalidDt           = paymail.ErrorCodeInvalidDt
+	ErrorInvalidParameter    = paymail.ErrorCodeInvalidParameter
+	ErrorInvalidPubKey       = paymail.ErrorCodeInvalidPubKey
+	ErrorInvalidSenderHandle = paymail.ErrorCodeInvalidSenderHandle
+	ErrorInvalidSignature    = paymail.ErrorCodeInvalidSignature
+	ErrorMethodNotFound      = paymail.ErrorCodeMethodNotFound
+	ErrorMissingHex          = paymail.ErrorCodeMissingHex
+	ErrorMissingReference    = paymail.ErrorCodeMissingReference
+	ErrorMissingSatoshis     = paymail.ErrorCodeMissingSatoshis
+	ErrorPaymailNotFound     = paymail.ErrorCodePaymailNotFound
+	ErrorPaymentNotApproved  = paymail.ErrorCodePaymentNotApproved
+	ErrorRecordingTx         = paymail.ErrorCodeRecordingTx
+	ErrorReplayedRequest     = paymail.ErrorCodeReplayedRequest
+	ErrorRequestNotFound     = paymail.ErrorCodeRequestNotFound
+	ErrorScript              = paymail.ErrorCodeScript
+	ErrorUnknownDomain       = paymail.ErrorCodeUnknownDomain
 )
 
 var (
@@ -45,6 +52,26 @@ var (
 
 	// ErrBsvAliasMissing is when the bsv alias version is missing
 	ErrBsvAliasMissing = errors.New("missing bsv alias version")
+
+	// ErrApprovalProviderMissing is when receiver approvals are enabled, but the
+	// service provider does not implement the PaymentApprovalProvider interface
+	ErrApprovalProviderMissing = errors.New("service provider does not support receiver approvals")
+
+	// ErrTokenProviderMissing is when token destinations are enabled, but the
+	// service provider does not implement the TokenDestinationProvider interface
+	ErrTokenProviderMissing = errors.New("service provider does not support token destinations")
+
+	// ErrPikeProviderMissing is when PIKE is enabled, but the
+	// service provider does not implement the PikeProvider interface
+	ErrPikeProviderMissing = errors.New("service provider does not support pike")
+
+	// ErrSFPProviderMissing is when SFP is enabled, but the
+	// service provider does not implement the SFPProvider interface
+	ErrSFPProviderMissing = errors.New("service provider does not support sfp")
+
+	// ErrBlockHeadersProviderMissing is when BEEF is enabled, but the
+	// service provider does not implement the paymail.BlockHeadersProvider interface
+	ErrBlockHeadersProviderMissing = errors.New("service provider does not support block headers")
 )
 
 // ErrorResponse is a standard way to return errors to the client
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonicpow/go-paymail"
 )
 
 // TestErrorResponse will test the method ErrorResponse()
@@ -15,3 +19,37 @@ func TestErrorResponse(t *testing.T) {
 		// todo: actually test the error response
 	})
 }
+
+// TestErrorCodes will test that the server error codes map onto the client errors
+func TestErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		code     string
+		expected error
+	}{
+		{ErrorApprovalNotFound, paymail.ErrApprovalNotFound},
+		{ErrorContactNotFound, paymail.ErrContactNotFound},
+		{ErrorFindingPaymail, paymail.ErrProviderFailure},
+		{ErrorInvalidBeef, paymail.ErrInvalidTransaction},
+		{ErrorInvalidDt, paymail.ErrInvalidDt},
+		{ErrorInvalidParameter, paymail.ErrInvalidParameter},
+		{ErrorInvalidPubKey, paymail.ErrInvalidPubKey},
+		{ErrorInvalidSenderHandle, paymail.ErrInvalidSenderHandle},
+		{ErrorInvalidSignature, paymail.ErrInvalidSignature},
+		{ErrorMethodNotFound, paymail.ErrNotSupported},
+		{ErrorMissingHex, paymail.ErrInvalidParameter},
+		{ErrorMissingReference, paymail.ErrInvalidParameter},
+		{ErrorMissingSatoshis, paymail.ErrInvalidParameter},
+		{ErrorPaymailNotFound, paymail.ErrPaymailNotFound},
+		{ErrorPaymentNotApproved, paymail.ErrPaymentNotApproved},
+		{ErrorRecordingTx, paymail.ErrProviderFailure},
+		{ErrorReplayedRequest, paymail.ErrReplayedRequest},
+		{ErrorRequestNotFound, paymail.ErrNotSupported},
+		{ErrorScript, paymail.ErrInvalidTransaction},
+		{ErrorUnknownDomain, paymail.ErrUnknownDomain},
+	}
+	for _, test := range tests {
+		assert.True(t, errors.Is(paymail.ErrorForCode(test.code), test.expected), "code: %s", test.code)
+	}
+}
@@ -34,3 +34,97 @@ type PaymailServiceProvider interface {
 		metaData *RequestMetadata,
 	) (*paymail.P2PTransactionPayload, error)
 }
+
+// PaymentApprovalProvider is an optional interface for the PaymailServiceProvider to support
+// receiver approvals (see: WithReceiverApprovals())
+//
+// The receiver approves or rejects the submitted payments in its own backend (IE: a wallet app),
+// the status is then polled by the sender before requesting a payment destination
+//
+// Specs: http://bsvalias.org/04-03-receiver-approvals.html
+type PaymentApprovalProvider interface {
+	CreatePaymentApproval(
+		ctx context.Context,
+		alias, domain string,
+		senderRequest *paymail.SenderRequest,
+		metaData *RequestMetadata,
+	) (*paymail.PaymentApprovalPayload, error)
+
+	// GetPaymentApproval returns nil if the approval was not found (for the alias & domain)
+	GetPaymentApproval(
+		ctx context.Context,
+		alias, domain, id string,
+		metaData *RequestMetadata,
+	) (*paymail.PaymentApprovalPayload, error)
+}
+
+// TokenDestinationProvider is an optional interface for the PaymailServiceProvider to receive
+// tokens (IE: STAS or bsv-20), see: WithP2PTokenDestinations()
+//
+// The outputs can be token scripts or P2PKH outputs (depending on the scheme), the transaction
+// is received by RecordTransaction() using the returned reference
+//
+// Specs: https://docs.moneybutton.com/docs/paymail/paymail-11-p2p-payment-destination-tokens.html
+type TokenDestinationProvider interface {
+	CreateP2PTokenDestinationResponse(
+		ctx context.Context,
+		alias, domain string,
+		tokenRequest *paymail.TokenPaymentRequest,
+		metaData *RequestMetadata,
+	) (*paymail.PaymentDestinationPayload, error)
+}
+
+// PikeProvider is an optional interface for the PaymailServiceProvider to exchange identity keys
+// with contacts (PIKE), see: WithPike()
+//
+// The outputs are derived (Type-42) from the identity key of the paymail & the shared secret with the
+// identity key of the contact (see: paymail.PikeSharedSecret() & paymail.DeriveType42PublicKey()),
+// the transaction is received by RecordTransaction() using the returned reference
+type PikeProvider interface {
+	// AddContact stores the contact (of the sender) & returns the contact of the paymail (the identity key)
+	AddContact(
+		ctx context.Context,
+		alias, domain string,
+		contact *paymail.PikeContactPayload,
+		metaData *RequestMetadata,
+	) (*paymail.PikeContactPayload, error)
+
+	// CreatePikeOutputs returns nil if the sender is not a contact of the paymail
+	CreatePikeOutputs(
+		ctx context.Context,
+		alias, domain string,
+		outputsRequest *paymail.PikeOutputsRequest,
+		metaData *RequestMetadata,
+	) (*paymail.PaymentDestinationPayload, error)
+}
+
+// SFPProvider is an optional interface for the PaymailServiceProvider to issue & transfer
+// tokenised assets with the Simplified Fungible token Protocol (see: WithSFP())
+//
+// The paymail of the build & authorise requests is the owner of the tokens, the provider
+// must authenticate the owner (IE: with the request headers in the metadata) before building
+// or authorising an action
+//
+// Specs: https://docs.moneybutton.com/docs/sfp/paymail-08-asset-information.html
+type SFPProvider interface {
+	// GetAssetInformation returns nil if the asset was not found (the paymail is the asset)
+	GetAssetInformation(
+		ctx context.Context,
+		alias, domain string,
+		metaData *RequestMetadata,
+	) (*paymail.SFPAssetPayload, error)
+
+	AuthoriseSFPAction(
+		ctx context.Context,
+		alias, domain string,
+		authoriseRequest *paymail.SFPAuthoriseRequest,
+		metaData *RequestMetadata,
+	) (*paymail.SFPAuthorisePayload, error)
+
+	BuildSFPAction(
+		ctx context.Context,
+		alias, domain string,
+		buildRequest *paymail.SFPBuildRequest,
+		metaData *RequestMetadata,
+	) (*paymail.SFPBuildPayload, error)
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/server"
+)
+
+// Errors returned for the payment approvals
+var (
+	ErrApprovalDecided  = errors.New("payment approval was already decided")
+	ErrApprovalNotFound = errors.New("payment approval not found")
+)
+
+// Approval is a payment submitted to a paymail for approval (receiver approvals)
+type Approval struct {
+	Address   string                 `json:"address"`    // Paymail address (alias@domain.tld)
+	Amount    uint64                 `json:"amount"`     // Amount of the payment (0 is any amount)
+	CreatedAt time.Time              `json:"created_at"` // When the approval was submitted
+	ID        string                 `json:"id"`         // Unique id of the approval
+	Reason    string                 `json:"reason"`     // Reason for the status (IE: why it was rejected)
+	Sender    *paymail.SenderRequest `json:"sender"`     // The submitted sender request
+	Status    string                 `json:"status"`     // Status (pending, approved or rejected)
+	UpdatedAt time.Time              `json:"updated_at"` // When the status was last changed
+}
+
+// ApprovalHandler is called when a payment is submitted for approval, it can decide right away
+// (IE: auto-approve small amounts) by setting the Status & Reason of the approval
+//
+// The approval is pending unless the handler changes the status, and is not created if an error is returned
+type ApprovalHandler func(ctx context.Context, approval *Approval) error
+
+// WithApprovalHandler will set the handler that is called when a payment is submitted for approval
+func WithApprovalHandler(handler ApprovalHandler) ProviderOps {
+	return func(p *Provider) {
+		p.approvalHandler = handler
+	}
+}
+
+// CreatePaymentApproval will create a pending approval for the payment (server.PaymentApprovalProvider)
+func (p *Provider) CreatePaymentApproval(ctx context.Context, alias, domain string,
+	senderRequest *paymail.SenderRequest, _ *server.RequestMetadata) (*paymail.PaymentApprovalPayload, error) {
+
+	// Check the paymail
+	key := normalize(alias) + "@" + normalize(domain)
+	p.mu.RLock()
+	_, ok := p.paymails[key]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPaymailNotFound, key)
+	}
+
+	// Create the approval
+	copied := *senderRequest
+	approval := &Approval{
+		Address:   key,
+		Amount:    senderRequest.Amount,
+		CreatedAt: time.Now().UTC(),
+		Sender:    &copied,
+		Status:    paymail.ApprovalStatusPending,
+	}
+	approval.UpdatedAt = approval.CreatedAt
+	var err error
+	if approval.ID, err = randomHex(16); err != nil {
+		return nil, err
+	}
+
+	// Let the handler decide (auto-approve, notify the receiver...)
+	if p.approvalHandler != nil {
+		if err = p.approvalHandler(ctx, approval); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the approval
+	p.mu.Lock()
+	p.approvals[approval.ID] = approval
+	payload := newApprovalPayload(approval)
+	p.mu.Unlock()
+	return payload, nil
+}
+
+// GetPaymentApproval will return the approval for the paymail (or nil if not found)
+func (p *Provider) GetPaymentApproval(_ context.Context, alias, domain, id string,
+	_ *server.RequestMetadata) (*paymail.PaymentApprovalPayload, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	approval, ok := p.approvals[id]
+	if !ok || approval.Address != normalize(alias)+"@"+normalize(domain) {
+		return nil, nil
+	}
+	return newApprovalPayload(approval), nil
+}
+
+// ApprovePayment will approve the pending payment
+func (p *Provider) ApprovePayment(id string) error {
+	return p.decidePayment(id, paymail.ApprovalStatusApproved, "")
+}
+
+// RejectPayment will reject the pending payment (the reason is returned to the sender)
+func (p *Provider) RejectPayment(id, reason string) error {
+	return p.decidePayment(id, paymail.ApprovalStatusRejected, reason)
+}
+
+// Approval will return a copy of the approval (or nil if not found)
+func (p *Provider) Approval(id string) *Approval {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	approval, ok := p.approvals[id]
+	if !ok {
+		return nil
+	}
+	copied := *approval
+	return &copied
+}
+
+// Approvals will return a copy of all the approvals (sorted by created time)
+func (p *Provider) Approvals() (approvals []*Approval) {
+	p.mu.RLock()
+	for _, approval := range p.approvals {
+		copied := *approval
+		approvals = append(approvals, &copied)
+	}
+	p.mu.RUnlock()
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].CreatedAt.Before(approvals[j].CreatedAt)
+	})
+	return
+}
+
+// decidePayment will set the status of the pending approval
+func (p *Provider) decidePayment(id, status, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	approval, ok := p.approvals[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	} else if approval.Status != paymail.ApprovalStatusPending {
+		return fmt.Errorf("%w: %s is %s", ErrApprovalDecided, id, approval.Status)
+	}
+	approval.Reason = reason
+	approval.Status = status
+	approval.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// newApprovalPayload will return the response for the approval (lock must be held)
+func newApprovalPayload(approval *Approval) *paymail.PaymentApprovalPayload {
+	return &paymail.PaymentApprovalPayload{
+		Amount: approval.Amount,
+		ID:     approval.ID,
+		Reason: approval.Reason,
+		Status: approval.Status,
+	}
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/server"
+)
+
+// Ensure the provider implements the optional interface
+var _ server.PaymentApprovalProvider = (*Provider)(nil)
+
+// testApprovalRequest will return a basic sender request
+func testApprovalRequest(amount uint64) *paymail.SenderRequest {
+	return &paymail.SenderRequest{Amount: amount, Dt: "2020-04-09T16:08:06.419Z", SenderHandle: "satchmo@" + testDomain}
+}
+
+// TestProvider_CreatePaymentApproval will test the method CreatePaymentApproval()
+func TestProvider_CreatePaymentApproval(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pending until decided", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		approval, err := p.CreatePaymentApproval(context.Background(), testAlias, testDomain, testApprovalRequest(1000), nil)
+		require.NoError(t, err)
+		assert.True(t, approval.IsPending())
+		assert.Equal(t, uint64(1000), approval.Amount)
+
+		require.NoError(t, p.ApprovePayment(approval.ID))
+		approval, err = p.GetPaymentApproval(context.Background(), testAlias, testDomain, approval.ID, nil)
+		require.NoError(t, err)
+		assert.True(t, approval.IsApproved())
+
+		// Decided only once
+		err = p.RejectPayment(approval.ID, "too late")
+		assert.ErrorIs(t, err, ErrApprovalDecided)
+		require.Len(t, p.Approvals(), 1)
+		assert.Equal(t, "satchmo@"+testDomain, p.Approval(approval.ID).Sender.SenderHandle)
+	})
+
+	t.Run("rejected with a reason", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		approval, err := p.CreatePaymentApproval(context.Background(), testAlias, testDomain, testApprovalRequest(0), nil)
+		require.NoError(t, err)
+		require.NoError(t, p.RejectPayment(approval.ID, "unknown sender"))
+		approval, err = p.GetPaymentApproval(context.Background(), testAlias, testDomain, approval.ID, nil)
+		require.NoError(t, err)
+		assert.True(t, approval.IsRejected())
+		assert.Equal(t, "unknown sender", approval.Reason)
+	})
+
+	t.Run("approval handler", func(t *testing.T) {
+		p, _ := newTestProvider(t, WithApprovalHandler(func(_ context.Context, approval *Approval) error {
+			if approval.Amount > 5000 {
+				return errors.New("amount is too high")
+			} else if approval.Amount <= 1000 {
+				approval.Status = paymail.ApprovalStatusApproved
+			}
+			return nil
+		}))
+		approval, err := p.CreatePaymentApproval(context.Background(), testAlias, testDomain, testApprovalRequest(500), nil)
+		require.NoError(t, err)
+		assert.True(t, approval.IsApproved())
+		approval, err = p.CreatePaymentApproval(context.Background(), testAlias, testDomain, testApprovalRequest(2000), nil)
+		require.NoError(t, err)
+		assert.True(t, approval.IsPending())
+		_, err = p.CreatePaymentApproval(context.Background(), testAlias, testDomain, testApprovalRequest(9000), nil)
+		require.Error(t, err)
+		assert.Len(t, p.Approvals(), 2)
+	})
+
+	t.Run("unknown paymail or approval", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		_, err := p.CreatePaymentApproval(context.Background(), "unknown", testDomain, testApprovalRequest(0), nil)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+		assert.ErrorIs(t, p.ApprovePayment("unknown"), ErrApprovalNotFound)
+		assert.Nil(t, p.Approval("unknown"))
+
+		// Only returned for the paymail of the approval
+		approval, err := p.CreatePaymentApproval(context.Background(), testAlias, testDomain, testApprovalRequest(0), nil)
+		require.NoError(t, err)
+		found, err := p.GetPaymentApproval(context.Background(), "satchmo", testDomain, approval.ID, nil)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tonicpow/go-paymail"
+)
+
+// ErrBlockHeadersMissing is when a merkle root is checked, but no block headers were set (see: WithBlockHeaders())
+var ErrBlockHeadersMissing = errors.New("block headers provider is not set")
+
+// WithBlockHeaders will set the block headers that are used to verify the merkle roots of
+// the received BEEF envelopes (see: server.WithBEEF())
+func WithBlockHeaders(headers paymail.BlockHeadersProvider) ProviderOps {
+	return func(p *Provider) {
+		p.blockHeaders = headers
+	}
+}
+
+// IsValidRootForHeight will check the merkle root with the block headers (paymail.BlockHeadersProvider)
+func (p *Provider) IsValidRootForHeight(ctx context.Context, merkleRoot string, blockHeight uint64) (bool, error) {
+	if p.blockHeaders == nil {
+		return false, ErrBlockHeadersMissing
+	}
+	return p.blockHeaders.IsValidRootForHeight(ctx, merkleRoot, blockHeight)
+}
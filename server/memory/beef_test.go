@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// Ensure the provider implements the optional interface
+var _ paymail.BlockHeadersProvider = (*Provider)(nil)
+
+// testHeaders is a block headers provider with a single merkle root
+type testHeaders struct{}
+
+// IsValidRootForHeight is a test implementation of this interface
+func (h *testHeaders) IsValidRootForHeight(_ context.Context, merkleRoot string, blockHeight uint64) (bool, error) {
+	return merkleRoot == "root" && blockHeight == 100, nil
+}
+
+// TestProvider_IsValidRootForHeight will test the method IsValidRootForHeight()
+func TestProvider_IsValidRootForHeight(t *testing.T) {
+	t.Parallel()
+
+	t.Run("block headers are used", func(t *testing.T) {
+		p, _ := newTestProvider(t, WithBlockHeaders(&testHeaders{}))
+		valid, err := p.IsValidRootForHeight(context.Background(), "root", 100)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		valid, err = p.IsValidRootForHeight(context.Background(), "root", 101)
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("missing block headers", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		valid, err := p.IsValidRootForHeight(context.Background(), "root", 100)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBlockHeadersMissing)
+		assert.False(t, valid)
+	})
+}
@@ -0,0 +1,642 @@
+/*
+Package memory is a reference in-memory implementation of server.PaymailServiceProvider
+
+Every paymail has its own HD key (xPriv): the PKI key is derived from the internal chain (m/1/0)
+and each address resolution or P2P destination uses the next address on the external chain (m/0/n),
+derived by a server.AddressDeriver (see: WithAddressDeriver()).
+Received transactions are checked against the P2P destination and recorded by reference,
+unpaid P2P destinations expire (see: WithDestinationTTL()).
+The PKI key can be replaced by an external paymail.Signer (see: SetSigner()).
+Payments submitted for approval (receiver approvals) are pending until ApprovePayment() or RejectPayment().
+BEEF envelopes are verified with the block headers set by WithBlockHeaders().
+The payment addresses are for mainnet, unless another network is set by WithNetwork().
+
+The provider is safe for concurrent use. It can be used as a test double, or embedded as a base
+for a persistent backend (load with AddPaymail(), save with Paymails() & Transactions(), or use
+WithTransactionHandler() to broadcast & persist received transactions).
+*/
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bt/v2"
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/server"
+)
+
+// Derivation paths for the keys of each paymail
+const (
+	PaymentChain = bitcoin.DefaultExternalChain // Chain for the payment addresses (m/0/n)
+	PKIChain     = bitcoin.DefaultInternalChain // Chain for the PKI key (m/1/0)
+	PKIIndex     = 0                            // Index for the PKI key (m/1/0)
+)
+
+// Destination expiry default values
+const (
+	DefaultDestinationTTL    = 24 * time.Hour // How long an unpaid P2P destination can be paid
+	destinationSweepInterval = 256            // Remove the expired destinations every n created destinations
+)
+
+// Errors returned by the provider
+var (
+	ErrDomainMissing      = errors.New("domain is not registered")
+	ErrPaymailExists      = errors.New("paymail already exists")
+	ErrPaymailNotFound    = errors.New("paymail not found")
+	ErrReferenceNotFound  = errors.New("payment destination reference not found")
+	ErrReferenceUsed      = errors.New("payment destination reference was already used")
+	ErrTransactionInvalid = errors.New("transaction does not pay the payment destination")
+)
+
+// Paymail is a paymail stored in the provider
+type Paymail struct {
+	Alias     string    `json:"alias"`      // Alias of the paymail
+	Avatar    string    `json:"avatar"`     // Avatar url (public profile)
+	CreatedAt time.Time `json:"created_at"` // When the paymail was added
+	Domain    string    `json:"domain"`     // Domain of the paymail
+	ID        string    `json:"id"`         // Unique id of the paymail
+	Name      string    `json:"name"`       // Name (public profile)
+	NextIndex uint32    `json:"next_index"` // Next index on the payment chain (highest derived index + 1)
+	PubKey    string    `json:"pubkey"`     // PKI public key (hex)
+	XPriv     string    `json:"-"`          // HD key of the paymail
+
+	hdKey       *bip32.ExtendedKey // Parsed HD key
+	lastAddress string             // Last payment address derived
+	privateKey  string             // PKI private key (hex), empty if an external signer is set
+	signer      paymail.Signer     // Signer for the PKI key
+	xPub        string             // HD public key (for the payment addresses)
+}
+
+// Destination is a P2P payment destination that was created by the provider
+type Destination struct {
+	Address   string                   `json:"address"`    // Paymail address (alias@domain.tld)
+	CreatedAt time.Time                `json:"created_at"` // When the destination was created
+	Outputs   []*paymail.PaymentOutput `json:"outputs"`    // Outputs of the destination
+	Reference string                   `json:"reference"`  // Unique reference
+	Satoshis  uint64                   `json:"satoshis"`   // Requested satoshis
+
+	derived *server.DerivedAddress // Derived payment address (marked as used when paid)
+}
+
+// copy will return a copy of the destination (the outputs are copied)
+func (d *Destination) copy() *Destination {
+	copied := *d
+	copied.Outputs = make([]*paymail.PaymentOutput, 0, len(d.Outputs))
+	for _, output := range d.Outputs {
+		copiedOutput := *output
+		copied.Outputs = append(copied.Outputs, &copiedOutput)
+	}
+	return &copied
+}
+
+// Transaction is a transaction received for a payment destination
+type Transaction struct {
+	Address    string                  `json:"address"`     // Paymail address (alias@domain.tld)
+	MetaData   *paymail.P2PMetaData    `json:"metadata"`    // Metadata sent with the transaction
+	ReceivedAt time.Time               `json:"received_at"` // When the transaction was received
+	Reference  string                  `json:"reference"`   // Reference of the payment destination
+	Hex        string                  `json:"hex"`         // Raw transaction
+	TxID       string                  `json:"txid"`        // ID of the transaction
+	P2P        *paymail.P2PTransaction `json:"-"`           // The transaction as it was received
+}
+
+// copy will return a copy of the transaction (the metadata is copied, the decoded BEEF is shared)
+func (t *Transaction) copy() *Transaction {
+	copied := *t
+	if t.MetaData != nil {
+		metaData := *t.MetaData
+		copied.MetaData = &metaData
+	}
+	if t.P2P != nil {
+		p2pTx := *t.P2P
+		p2pTx.MetaData = copied.MetaData
+		copied.P2P = &p2pTx
+	}
+	return &copied
+}
+
+// TransactionHandler is called before a transaction is recorded (IE: broadcast or persist),
+// the transaction is not recorded if an error is returned
+type TransactionHandler func(ctx context.Context, transaction *Transaction) error
+
+// ProviderOps allow functional options to be supplied
+// that overwrite default options.
+type ProviderOps func(p *Provider)
+
+// WithAddressDeriver will set the deriver of the payment addresses (IE: a persistent index store,
+// another gap limit or policy), the chain & network of the deriver are used
+//
+// The default deriver uses an in-memory index store and reuses the unused addresses when the gap limit
+// is reached (server.GapLimitReuse), the payments of basic address resolutions are never reported
+func WithAddressDeriver(deriver *server.AddressDeriver) ProviderOps {
+	return func(p *Provider) {
+		if deriver != nil {
+			p.deriver = deriver
+		}
+	}
+}
+
+// WithDestinationTTL will set how long an unpaid P2P destination can be paid (default: 24 hours),
+// expired destinations are removed
+func WithDestinationTTL(ttl time.Duration) ProviderOps {
+	return func(p *Provider) {
+		if ttl > 0 {
+			p.destinationTTL = ttl
+		}
+	}
+}
+
+// WithDomains will register the domains
+func WithDomains(domains ...string) ProviderOps {
+	return func(p *Provider) {
+		for _, domain := range domains {
+			p.domains[normalize(domain)] = struct{}{}
+		}
+	}
+}
+
+// WithNetwork will set the network of the payment addresses (default: mainnet)
+func WithNetwork(network paymail.Network) ProviderOps {
+	return func(p *Provider) {
+		p.network = network
+	}
+}
+
+// WithTransactionHandler will set the handler that is called before a transaction is recorded
+func WithTransactionHandler(handler TransactionHandler) ProviderOps {
+	return func(p *Provider) {
+		p.transactionHandler = handler
+	}
+}
+
+// Provider is an in-memory server.PaymailServiceProvider
+type Provider struct {
+	approvalHandler    ApprovalHandler              // Called when a payment is submitted for approval
+	approvals          map[string]*Approval         // id -> approval
+	blockHeaders       paymail.BlockHeadersProvider // Verifies the merkle roots of BEEF envelopes
+	created            int                          // Number of destinations created (for the sweep)
+	deriver            *server.AddressDeriver       // Derives the payment addresses
+	destinationTTL     time.Duration                // How long an unpaid destination can be paid
+	destinations       map[string]*Destination      // reference -> destination
+	domains            map[string]struct{}          // Registered domains
+	mu                 sync.RWMutex                 // Protects all the maps
+	network            paymail.Network              // Network of the payment addresses
+	paymails           map[string]*Paymail          // alias@domain -> paymail
+	transactionHandler TransactionHandler           // Called before recording a transaction
+	transactions       map[string]*Transaction      // reference -> transaction
+}
+
+// NewProvider will return an empty provider
+func NewProvider(opts ...ProviderOps) *Provider {
+	p := &Provider{
+		approvals:      make(map[string]*Approval),
+		destinationTTL: DefaultDestinationTTL,
+		destinations:   make(map[string]*Destination),
+		domains:        make(map[string]struct{}),
+		paymails:       make(map[string]*Paymail),
+		transactions:   make(map[string]*Transaction),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// Default deriver (the in-memory store is never nil)
+	if p.deriver == nil {
+		p.deriver, _ = server.NewAddressDeriver(
+			server.NewMemoryIndexStore(),
+			server.WithDerivationChain(PaymentChain),
+			server.WithDerivationNetwork(p.network),
+			server.WithGapLimitPolicy(server.GapLimitReuse),
+		)
+	}
+	return p
+}
+
+// AddDomain will register the domain (paymails can only be added for registered domains)
+func (p *Provider) AddDomain(domain string) {
+	p.mu.Lock()
+	p.domains[normalize(domain)] = struct{}{}
+	p.mu.Unlock()
+}
+
+// Domains will return the registered domains (sorted)
+func (p *Provider) Domains() (domains []string) {
+	p.mu.RLock()
+	for domain := range p.domains {
+		domains = append(domains, domain)
+	}
+	p.mu.RUnlock()
+	sort.Strings(domains)
+	return
+}
+
+// AddPaymail will add a paymail (alias@domain) with the given HD key (xPriv),
+// a new HD key is generated if the xPriv is empty
+func (p *Provider) AddPaymail(alias, domain, name, avatar, xPriv string) (*Paymail, error) {
+
+	// Sanitize & validate the address
+	alias, domain = normalize(alias), normalize(domain)
+	if err := paymail.ValidatePaymail(alias + "@" + domain); err != nil {
+		return nil, err
+	}
+
+	// Create the paymail
+	record := &Paymail{
+		Alias:     alias,
+		Avatar:    avatar,
+		CreatedAt: time.Now().UTC(),
+		Domain:    domain,
+		Name:      name,
+	}
+	var err error
+	if len(xPriv) == 0 {
+		record.hdKey, err = bitcoin.GenerateHDKey(bitcoin.RecommendedSeedLength)
+	} else {
+		record.hdKey, err = bitcoin.GenerateHDKeyFromString(xPriv)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpriv: %w", err)
+	} else if !record.hdKey.IsPrivate() {
+		return nil, errors.New("invalid xpriv: key is not private")
+	}
+	record.XPriv = record.hdKey.String()
+	if record.xPub, err = bitcoin.GetExtendedPublicKey(record.hdKey); err != nil {
+		return nil, err
+	}
+	if record.ID, err = randomHex(16); err != nil {
+		return nil, err
+	}
+
+	// Derive the PKI key
+	var pkiKey *bip32.ExtendedKey
+	if pkiKey, err = bitcoin.GetHDKeyByPath(record.hdKey, PKIChain, PKIIndex); err != nil {
+		return nil, err
+	}
+	if record.privateKey, err = bitcoin.GetPrivateKeyStringFromHDKey(pkiKey); err != nil {
+		return nil, err
+	}
+	if record.signer, err = paymail.NewPrivateKeySigner(record.privateKey); err != nil {
+		return nil, err
+	}
+	if record.PubKey, err = record.signer.PubKey(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// Add the paymail
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.domains[domain]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDomainMissing, domain)
+	}
+	key := alias + "@" + domain
+	if _, ok := p.paymails[key]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrPaymailExists, key)
+	}
+	p.paymails[key] = record
+	copied := *record
+	return &copied, nil
+}
+
+// DeletePaymail will remove the paymail
+func (p *Provider) DeletePaymail(alias, domain string) error {
+	key := normalize(alias) + "@" + normalize(domain)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.paymails[key]; !ok {
+		return fmt.Errorf("%w: %s", ErrPaymailNotFound, key)
+	}
+	delete(p.paymails, key)
+	return nil
+}
+
+// UpdateProfile will update the public profile (name & avatar) of the paymail
+func (p *Provider) UpdateProfile(alias, domain, name, avatar string) error {
+	key := normalize(alias) + "@" + normalize(domain)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	record, ok := p.paymails[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPaymailNotFound, key)
+	}
+	record.Name = name
+	record.Avatar = avatar
+	return nil
+}
+
+// SetSigner will use the signer (IE: HSM, KMS or a signing service) for the PKI key of the paymail,
+// the PKI private key is no longer kept in memory
+func (p *Provider) SetSigner(ctx context.Context, alias, domain string, signer paymail.Signer) error {
+	if signer == nil {
+		return errors.New("missing signer")
+	}
+	pubKey, err := signer.PubKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	key := normalize(alias) + "@" + normalize(domain)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	record, ok := p.paymails[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPaymailNotFound, key)
+	}
+	record.PubKey = pubKey
+	record.privateKey = ""
+	record.signer = signer
+	return nil
+}
+
+// Paymails will return a copy of all the paymails (sorted by address)
+func (p *Provider) Paymails() (paymails []*Paymail) {
+	p.mu.RLock()
+	for _, record := range p.paymails {
+		copied := *record
+		paymails = append(paymails, &copied)
+	}
+	p.mu.RUnlock()
+	sort.Slice(paymails, func(i, j int) bool {
+		return paymails[i].Alias+"@"+paymails[i].Domain < paymails[j].Alias+"@"+paymails[j].Domain
+	})
+	return
+}
+
+// Destination will return a copy of the payment destination for the reference (or nil if not found)
+func (p *Provider) Destination(reference string) *Destination {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	destination, ok := p.destinations[reference]
+	if !ok {
+		return nil
+	}
+	return destination.copy()
+}
+
+// Transaction will return a copy of the transaction for the reference (or nil if not found)
+func (p *Provider) Transaction(reference string) *Transaction {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	transaction, ok := p.transactions[reference]
+	if !ok {
+		return nil
+	}
+	return transaction.copy()
+}
+
+// Transactions will return a copy of all the received transactions (sorted by received time)
+func (p *Provider) Transactions() (transactions []*Transaction) {
+	p.mu.RLock()
+	for _, transaction := range p.transactions {
+		transactions = append(transactions, transaction.copy())
+	}
+	p.mu.RUnlock()
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].ReceivedAt.Before(transactions[j].ReceivedAt)
+	})
+	return
+}
+
+// GetPaymailByAlias will return the paymail (or nil if not found)
+//
+// LastAddress is the last payment address derived for the paymail
+func (p *Provider) GetPaymailByAlias(_ context.Context, alias, domain string,
+	_ *server.RequestMetadata) (*paymail.AddressInformation, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	record, ok := p.paymails[normalize(alias)+"@"+normalize(domain)]
+	if !ok {
+		return nil, nil
+	}
+	return &paymail.AddressInformation{
+		Alias:       record.Alias,
+		Avatar:      record.Avatar,
+		Domain:      record.Domain,
+		ID:          record.ID,
+		LastAddress: record.lastAddress,
+		Name:        record.Name,
+		PrivateKey:  record.privateKey,
+		PubKey:      record.PubKey,
+	}, nil
+}
+
+// CreateAddressResolutionResponse will return the output script of the next payment address,
+// signed with the PKI key if sender validation is enabled
+func (p *Provider) CreateAddressResolutionResponse(ctx context.Context, alias, domain string,
+	senderValidation bool, _ *server.RequestMetadata) (*paymail.ResolutionPayload, error) {
+
+	// Derive the next address
+	derived, signer, err := p.nextAddress(ctx, alias, domain)
+	if err != nil {
+		return nil, err
+	}
+	response := &paymail.ResolutionPayload{Address: derived.Address, Output: derived.Script}
+
+	// Sign the output if sender validation is enabled
+	if senderValidation {
+		if response.Signature, err = signer.SignMessage(ctx, response.Output); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// CreateP2PDestinationResponse will return a single output (to the next payment address)
+// with a unique reference, the destination expires if it is not paid (see: WithDestinationTTL())
+func (p *Provider) CreateP2PDestinationResponse(ctx context.Context, alias, domain string,
+	satoshis uint64, _ *server.RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+
+	// Derive the next address
+	derived, _, err := p.nextAddress(ctx, alias, domain)
+	if err != nil {
+		return nil, err
+	}
+	output := &paymail.PaymentOutput{Address: derived.Address, Satoshis: satoshis, Script: derived.Script}
+
+	// Create the destination with a unique reference
+	destination := &Destination{
+		Address:   normalize(alias) + "@" + normalize(domain),
+		CreatedAt: time.Now().UTC(),
+		Outputs:   []*paymail.PaymentOutput{output},
+		Satoshis:  satoshis,
+		derived:   derived,
+	}
+	if destination.Reference, err = randomHex(16); err != nil {
+		return nil, err
+	}
+
+	// Store the destination & remove the expired destinations from time to time
+	p.mu.Lock()
+	p.destinations[destination.Reference] = destination
+	if p.created++; p.created%destinationSweepInterval == 0 {
+		for reference, existing := range p.destinations {
+			if p.isExpired(existing) {
+				delete(p.destinations, reference)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	copied := *output
+	return &paymail.PaymentDestinationPayload{
+		Outputs:   []*paymail.PaymentOutput{&copied},
+		Reference: destination.Reference,
+	}, nil
+}
+
+// RecordTransaction will check that the transaction pays the destination of the reference,
+// call the transaction handler (if set) and record the transaction by reference
+//
+// Sending the same transaction again for a reference is allowed (the same response is returned)
+func (p *Provider) RecordTransaction(ctx context.Context, p2pTx *paymail.P2PTransaction,
+	metaData *server.RequestMetadata) (*paymail.P2PTransactionPayload, error) {
+
+	// Parse the transaction
+	tx, err := bt.NewTxFromString(p2pTx.Hex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the destination (an expired destination can no longer be paid)
+	p.mu.RLock()
+	destination, ok := p.destinations[p2pTx.Reference]
+	existing := p.transactions[p2pTx.Reference]
+	p.mu.RUnlock()
+	if !ok || (existing == nil && p.isExpired(destination)) {
+		return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, p2pTx.Reference)
+	} else if metaData != nil && len(metaData.Alias) > 0 &&
+		normalize(metaData.Alias)+"@"+normalize(metaData.Domain) != destination.Address {
+		return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, p2pTx.Reference)
+	} else if existing != nil {
+		if existing.TxID != tx.TxID() {
+			return nil, fmt.Errorf("%w: %s", ErrReferenceUsed, p2pTx.Reference)
+		}
+		return newTransactionPayload(existing), nil
+	}
+
+	// Check that every output of the destination is paid
+	for _, output := range destination.Outputs {
+		if !paysOutput(tx, output) {
+			return nil, fmt.Errorf("%w: missing output %s", ErrTransactionInvalid, output.Script)
+		}
+	}
+
+	// Create the transaction
+	transaction := &Transaction{
+		Address:    destination.Address,
+		Hex:        p2pTx.Hex,
+		MetaData:   p2pTx.MetaData,
+		P2P:        p2pTx,
+		ReceivedAt: time.Now().UTC(),
+		Reference:  p2pTx.Reference,
+		TxID:       tx.TxID(),
+	}
+
+	// Handle the transaction (broadcast, persist...)
+	if p.transactionHandler != nil {
+		if err = p.transactionHandler(ctx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	// The address was used (moves the gap limit window)
+	if destination.derived != nil {
+		if err = p.deriver.MarkUsed(ctx, destination.derived); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the transaction (unless another request recorded it first)
+	p.mu.Lock()
+	if existing = p.transactions[p2pTx.Reference]; existing == nil {
+		p.transactions[p2pTx.Reference] = transaction
+	}
+	p.mu.Unlock()
+	if existing != nil && existing.TxID != transaction.TxID {
+		return nil, fmt.Errorf("%w: %s", ErrReferenceUsed, p2pTx.Reference)
+	}
+
+	return newTransactionPayload(transaction), nil
+}
+
+// nextAddress will derive the next payment address & script for the paymail (and return its PKI signer)
+func (p *Provider) nextAddress(ctx context.Context, alias, domain string) (derived *server.DerivedAddress,
+	signer paymail.Signer, err error) {
+	key := normalize(alias) + "@" + normalize(domain)
+
+	// Find the paymail
+	p.mu.RLock()
+	record, ok := p.paymails[key]
+	var xPub string
+	if ok {
+		xPub = record.xPub
+	}
+	p.mu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrPaymailNotFound, key)
+		return
+	}
+
+	// Derive the address on the payment chain
+	if derived, err = p.deriver.DeriveAddress(ctx, key, xPub); err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	if derived.Index >= record.NextIndex {
+		record.NextIndex = derived.Index + 1
+	}
+	record.lastAddress = derived.Address
+	signer = record.signer
+	p.mu.Unlock()
+	return
+}
+
+// isExpired will return true if the destination can no longer be paid
+func (p *Provider) isExpired(destination *Destination) bool {
+	return time.Since(destination.CreatedAt) > p.destinationTTL
+}
+
+// paysOutput will return true if the transaction has an output with the script & at least the satoshis
+func paysOutput(tx *bt.Tx, output *paymail.PaymentOutput) bool {
+	for _, txOutput := range tx.Outputs {
+		if txOutput.LockingScript != nil && txOutput.LockingScript.String() == output.Script &&
+			txOutput.Satoshis >= output.Satoshis {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransactionPayload will return the response for the transaction
+func newTransactionPayload(transaction *Transaction) *paymail.P2PTransactionPayload {
+	payload := &paymail.P2PTransactionPayload{TxID: transaction.TxID}
+	if transaction.MetaData != nil {
+		payload.Note = transaction.MetaData.Note
+	}
+	return payload
+}
+
+// normalize will lowercase & trim the value (alias or domain)
+func normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// randomHex will return a random hex string of the given number of bytes
+func randomHex(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,508 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/server"
+)
+
+const (
+	testAlias  = "mrz"
+	testDomain = "test.com"
+)
+
+// Ensure the provider implements the interface
+var _ server.PaymailServiceProvider = (*Provider)(nil)
+
+// newTestProvider will return a provider with a single paymail
+func newTestProvider(t *testing.T, opts ...ProviderOps) (*Provider, *Paymail) {
+	p := NewProvider(append([]ProviderOps{WithDomains(testDomain)}, opts...)...)
+	record, err := p.AddPaymail(testAlias, testDomain, "MrZ", "https://github.com/mrz1836.png", "")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	return p, record
+}
+
+// newTestTransaction will return a hex transaction paying the destination
+func newTestTransaction(t *testing.T, destination *paymail.PaymentDestinationPayload) string {
+	tx := bt.NewTx()
+	for _, output := range destination.Outputs {
+		require.NoError(t, tx.PayToAddress(output.Address, output.Satoshis))
+	}
+	return tx.String()
+}
+
+// TestProvider_AddPaymail will test the method AddPaymail()
+func TestProvider_AddPaymail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid paymail", func(t *testing.T) {
+		p, record := newTestProvider(t)
+		assert.Equal(t, testAlias, record.Alias)
+		assert.Equal(t, testDomain, record.Domain)
+		assert.Len(t, record.PubKey, paymail.PubKeyLength)
+		assert.NotEmpty(t, record.XPriv)
+		assert.NotEmpty(t, record.ID)
+		require.Len(t, p.Paymails(), 1)
+	})
+
+	t.Run("given xpriv is used", func(t *testing.T) {
+		_, record := newTestProvider(t)
+		p := NewProvider(WithDomains(testDomain))
+		added, err := p.AddPaymail("Satchmo", " TEST.com ", "", "", record.XPriv)
+		require.NoError(t, err)
+		assert.Equal(t, "satchmo", added.Alias)
+		assert.Equal(t, record.PubKey, added.PubKey)
+	})
+
+	t.Run("domain not registered", func(t *testing.T) {
+		p := NewProvider()
+		_, err := p.AddPaymail(testAlias, testDomain, "", "", "")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrDomainMissing)
+
+		p.AddDomain(testDomain)
+		_, err = p.AddPaymail(testAlias, testDomain, "", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{testDomain}, p.Domains())
+	})
+
+	t.Run("duplicate paymail", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		_, err := p.AddPaymail(testAlias, testDomain, "", "", "")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailExists)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		p := NewProvider(WithDomains(testDomain))
+		_, err := p.AddPaymail("", testDomain, "", "", "")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid xpriv", func(t *testing.T) {
+		p := NewProvider(WithDomains(testDomain))
+		_, err := p.AddPaymail(testAlias, testDomain, "", "", "invalid")
+		require.Error(t, err)
+	})
+
+	t.Run("public key is not allowed", func(t *testing.T) {
+		_, record := newTestProvider(t)
+		hdKey, err := bitcoin.GenerateHDKeyFromString(record.XPriv)
+		require.NoError(t, err)
+		xPub, err := bitcoin.GetExtendedPublicKey(hdKey)
+		require.NoError(t, err)
+
+		p := NewProvider(WithDomains(testDomain))
+		_, err = p.AddPaymail(testAlias, testDomain, "", "", xPub)
+		require.Error(t, err)
+	})
+}
+
+// TestProvider_UpdateProfile will test the methods UpdateProfile() and DeletePaymail()
+func TestProvider_UpdateProfile(t *testing.T) {
+	t.Parallel()
+
+	p, _ := newTestProvider(t)
+	require.NoError(t, p.UpdateProfile(testAlias, testDomain, "Satchmo", "https://example.com/a.png"))
+	info, err := p.GetPaymailByAlias(context.Background(), testAlias, testDomain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Satchmo", info.Name)
+	assert.Equal(t, "https://example.com/a.png", info.Avatar)
+
+	require.NoError(t, p.DeletePaymail(testAlias, testDomain))
+	info, err = p.GetPaymailByAlias(context.Background(), testAlias, testDomain, nil)
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	assert.ErrorIs(t, p.UpdateProfile(testAlias, testDomain, "", ""), ErrPaymailNotFound)
+	assert.ErrorIs(t, p.DeletePaymail(testAlias, testDomain), ErrPaymailNotFound)
+}
+
+// TestProvider_GetPaymailByAlias will test the method GetPaymailByAlias()
+func TestProvider_GetPaymailByAlias(t *testing.T) {
+	t.Parallel()
+
+	p, record := newTestProvider(t)
+	info, err := p.GetPaymailByAlias(context.Background(), "MRZ", "Test.com", nil)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, record.ID, info.ID)
+	assert.Equal(t, record.PubKey, info.PubKey)
+	assert.Equal(t, "MrZ", info.Name)
+
+	// The private key matches the pubkey
+	pubKey, err := bitcoin.PubKeyFromPrivateKeyString(info.PrivateKey, true)
+	require.NoError(t, err)
+	assert.Equal(t, info.PubKey, pubKey)
+
+	info, err = p.GetPaymailByAlias(context.Background(), "unknown", testDomain, nil)
+	require.NoError(t, err)
+	assert.Nil(t, info)
+}
+
+// TestProvider_CreateAddressResolutionResponse will test the method CreateAddressResolutionResponse()
+func TestProvider_CreateAddressResolutionResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("new address every time", func(t *testing.T) {
+		p, record := newTestProvider(t)
+		first, err := p.CreateAddressResolutionResponse(context.Background(), testAlias, testDomain, false, nil)
+		require.NoError(t, err)
+		assert.NotEmpty(t, first.Output)
+		assert.Empty(t, first.Signature)
+
+		// First address of the payment chain (m/0/0)
+		hdKey, err := bitcoin.GenerateHDKeyFromString(record.XPriv)
+		require.NoError(t, err)
+		child, err := bitcoin.GetHDKeyByPath(hdKey, PaymentChain, 0)
+		require.NoError(t, err)
+		expected, err := bitcoin.GetAddressStringFromHDKey(child)
+		require.NoError(t, err)
+		assert.Equal(t, expected, first.Address)
+
+		second, err := p.CreateAddressResolutionResponse(context.Background(), testAlias, testDomain, false, nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Address, second.Address)
+
+		info, err := p.GetPaymailByAlias(context.Background(), testAlias, testDomain, nil)
+		require.NoError(t, err)
+		assert.Equal(t, second.Address, info.LastAddress)
+		assert.Equal(t, uint32(2), p.Paymails()[0].NextIndex)
+	})
+
+	t.Run("network addresses", func(t *testing.T) {
+		p, _ := newTestProvider(t, WithNetwork(paymail.Regtest))
+		response, err := p.CreateAddressResolutionResponse(context.Background(), testAlias, testDomain, false, nil)
+		require.NoError(t, err)
+		assert.Contains(t, "mn", response.Address[:1]) // Testnet address version
+		script, err := bscript.NewP2PKHFromAddress(response.Address)
+		require.NoError(t, err)
+		assert.Equal(t, script.String(), response.Output)
+	})
+
+	t.Run("sender validation", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		response, err := p.CreateAddressResolutionResponse(context.Background(), testAlias, testDomain, true, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, response.Signature)
+
+		// Signed with the PKI key
+		info, err := p.GetPaymailByAlias(context.Background(), testAlias, testDomain, nil)
+		require.NoError(t, err)
+		address, err := bitcoin.GetAddressFromPubKeyString(info.PubKey, true)
+		require.NoError(t, err)
+		require.NoError(t, bitcoin.VerifyMessage(address.AddressString, response.Signature, response.Output))
+	})
+
+	t.Run("external signer", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		privateKey, err := bitcoin.CreatePrivateKeyString()
+		require.NoError(t, err)
+		keySigner, err := paymail.NewPrivateKeySigner(privateKey)
+		require.NoError(t, err)
+
+		// Fake signing service
+		signingService := httptest.NewServer(paymail.NewSignerHandler(map[string]paymail.Signer{"pki": keySigner}))
+		defer signingService.Close()
+		signer, err := paymail.NewRemoteSigner(signingService.URL, "pki", nil)
+		require.NoError(t, err)
+		require.NoError(t, p.SetSigner(context.Background(), testAlias, testDomain, signer))
+
+		info, err := p.GetPaymailByAlias(context.Background(), testAlias, testDomain, nil)
+		require.NoError(t, err)
+		assert.Empty(t, info.PrivateKey)
+		pubKey, err := keySigner.PubKey(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, pubKey, info.PubKey)
+
+		response, err := p.CreateAddressResolutionResponse(context.Background(), testAlias, testDomain, true, nil)
+		require.NoError(t, err)
+		address, err := bitcoin.GetAddressFromPrivateKeyString(privateKey, true)
+		require.NoError(t, err)
+		require.NoError(t, bitcoin.VerifyMessage(address, response.Signature, response.Output))
+
+		// Errors
+		assert.Error(t, p.SetSigner(context.Background(), testAlias, testDomain, nil))
+		assert.ErrorIs(t, p.SetSigner(context.Background(), "unknown", testDomain, signer), ErrPaymailNotFound)
+		unknown, err := paymail.NewRemoteSigner(signingService.URL, "unknown", nil)
+		require.NoError(t, err)
+		assert.Error(t, p.SetSigner(context.Background(), testAlias, testDomain, unknown))
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		_, err := p.CreateAddressResolutionResponse(context.Background(), "unknown", testDomain, false, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+	})
+}
+
+// TestProvider_RecordTransaction will test the methods CreateP2PDestinationResponse() and RecordTransaction()
+func TestProvider_RecordTransaction(t *testing.T) {
+	t.Parallel()
+
+	metaData := &server.RequestMetadata{Alias: testAlias, Domain: testDomain}
+
+	t.Run("valid transaction", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		require.Len(t, destination.Outputs, 1)
+		assert.Equal(t, uint64(1000), destination.Outputs[0].Satoshis)
+		require.NotNil(t, p.Destination(destination.Reference))
+
+		txHex := newTestTransaction(t, destination)
+		response, err := p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex:       txHex,
+			MetaData:  &paymail.P2PMetaData{Note: "test payment"},
+			Reference: destination.Reference,
+		}, metaData)
+		require.NoError(t, err)
+		assert.Equal(t, "test payment", response.Note)
+
+		transaction := p.Transaction(destination.Reference)
+		require.NotNil(t, transaction)
+		assert.Equal(t, response.TxID, transaction.TxID)
+		assert.Equal(t, testAlias+"@"+testDomain, transaction.Address)
+		require.Len(t, p.Transactions(), 1)
+
+		// The same transaction can be sent again
+		again, err := p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: txHex, Reference: destination.Reference,
+		}, metaData)
+		require.NoError(t, err)
+		assert.Equal(t, response.TxID, again.TxID)
+		require.Len(t, p.Transactions(), 1)
+	})
+
+	t.Run("reference already used", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.NoError(t, err)
+
+		// Pay more to the same output (different tx)
+		destination.Outputs[0].Satoshis = 2000
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrReferenceUsed)
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: "unknown",
+		}, metaData)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+
+	t.Run("reference of another paymail", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		_, err := p.AddPaymail("satchmo", testDomain, "", "", "")
+		require.NoError(t, err)
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), "satchmo", testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+
+	t.Run("not enough satoshis", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		reference := destination.Reference
+		destination.Outputs[0].Satoshis = 999
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: reference,
+		}, metaData)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTransactionInvalid)
+		assert.Nil(t, p.Transaction(reference))
+	})
+
+	t.Run("invalid hex", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		_, err := p.RecordTransaction(context.Background(), &paymail.P2PTransaction{Hex: "invalid"}, metaData)
+		require.Error(t, err)
+	})
+
+	t.Run("transaction handler", func(t *testing.T) {
+		var handled []*Transaction
+		p, _ := newTestProvider(t, WithTransactionHandler(func(_ context.Context, tx *Transaction) error {
+			handled = append(handled, tx)
+			if len(handled) > 1 {
+				return errors.New("broadcast failed")
+			}
+			return nil
+		}))
+
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.NoError(t, err)
+		require.Len(t, handled, 1)
+
+		destination, err = p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.Error(t, err)
+		assert.Nil(t, p.Transaction(destination.Reference))
+	})
+
+	t.Run("expired destination", func(t *testing.T) {
+		p, _ := newTestProvider(t, WithDestinationTTL(time.Hour))
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		p.destinations[destination.Reference].CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+
+	t.Run("expired destinations are removed", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		expired, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		p.destinations[expired.Reference].CreatedAt = time.Now().UTC().Add(-2 * DefaultDestinationTTL)
+
+		// The expired destinations are removed on the sweep interval
+		p.created = destinationSweepInterval - 1
+		valid, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		assert.Nil(t, p.Destination(expired.Reference))
+		assert.NotNil(t, p.Destination(valid.Reference))
+	})
+
+	t.Run("paid address moves the gap limit window", func(t *testing.T) {
+		deriver, err := server.NewAddressDeriver(server.NewMemoryIndexStore(), server.WithGapLimit(1))
+		require.NoError(t, err)
+		p, _ := newTestProvider(t, WithAddressDeriver(deriver))
+
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.ErrorIs(t, err, server.ErrGapLimitReached)
+
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex: newTestTransaction(t, destination), Reference: destination.Reference,
+		}, metaData)
+		require.NoError(t, err)
+
+		next, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, destination.Outputs[0].Address, next.Outputs[0].Address)
+	})
+
+	t.Run("returned records are copies", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		destination, err := p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+		require.NoError(t, err)
+		_, err = p.RecordTransaction(context.Background(), &paymail.P2PTransaction{
+			Hex:       newTestTransaction(t, destination),
+			MetaData:  &paymail.P2PMetaData{Note: "test payment"},
+			Reference: destination.Reference,
+		}, metaData)
+		require.NoError(t, err)
+
+		p.Destination(destination.Reference).Outputs[0].Satoshis = 1
+		assert.Equal(t, uint64(1000), p.Destination(destination.Reference).Outputs[0].Satoshis)
+
+		p.Transaction(destination.Reference).MetaData.Note = "changed"
+		p.Transactions()[0].TxID = "changed"
+		transaction := p.Transaction(destination.Reference)
+		assert.Equal(t, "test payment", transaction.MetaData.Note)
+		assert.Equal(t, "test payment", transaction.P2P.MetaData.Note)
+		assert.NotEqual(t, "changed", transaction.TxID)
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		p, _ := newTestProvider(t)
+		_, err := p.CreateP2PDestinationResponse(context.Background(), "unknown", testDomain, 1000, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+	})
+}
+
+// TestProvider_Concurrency will test the provider under concurrent use
+func TestProvider_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	p, _ := newTestProvider(t)
+	var wg sync.WaitGroup
+	addresses := make([]string, 20)
+	for i := range addresses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := p.CreateAddressResolutionResponse(context.Background(), testAlias, testDomain, false, nil)
+			if err == nil {
+				addresses[i] = response.Address
+			}
+			_, _ = p.GetPaymailByAlias(context.Background(), testAlias, testDomain, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	// Every address is unique
+	unique := make(map[string]struct{})
+	for _, address := range addresses {
+		require.NotEmpty(t, address)
+		unique[address] = struct{}{}
+	}
+	assert.Len(t, unique, len(addresses))
+}
+
+// ExampleNewProvider example using NewProvider()
+func ExampleNewProvider() {
+	p := NewProvider(WithDomains(testDomain))
+	record, err := p.AddPaymail(testAlias, testDomain, "MrZ", "", "")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+
+	// Use the provider for the server configuration
+	config, _ := server.NewConfig(p, server.WithDomain(testDomain), server.WithP2PCapabilities())
+	fmt.Printf("paymail %s@%s served by %s", record.Alias, record.Domain, config.PaymailDomains[0].Name)
+	// Output:paymail mrz@test.com served by test.com
+}
+
+// BenchmarkProvider_CreateP2PDestinationResponse benchmarks the method CreateP2PDestinationResponse()
+func BenchmarkProvider_CreateP2PDestinationResponse(b *testing.B) {
+	p := NewProvider(WithDomains(testDomain))
+	_, _ = p.AddPaymail(testAlias, testDomain, "", "", "")
+	for i := 0; i < b.N; i++ {
+		_, _ = p.CreateP2PDestinationResponse(context.Background(), testAlias, testDomain, 1000, nil)
+	}
+}
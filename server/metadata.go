@@ -4,11 +4,16 @@ import (
 	"net/http"
 
 	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/tonicpow/go-paymail"
 )
 
 // CreateMetadata will create the base metadata using the request
+//
+// The Address is only set if the alias & domain are a valid paymail address
 func CreateMetadata(req *http.Request, alias, domain, optionalNote string) *RequestMetadata {
+	address, _ := paymail.NewAddress(alias, domain)
 	return &RequestMetadata{
+		Address:    address,
 		Alias:      alias,
 		Domain:     domain,
 		IPAddress:  apirouter.GetClientIPAddress(req),
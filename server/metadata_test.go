@@ -17,6 +17,7 @@ func TestCreateMetadata(t *testing.T) {
 		assert.NotNil(t, md)
 		assert.Equal(t, "tester", md.Alias)
 		assert.Equal(t, "test.com", md.Domain)
+		assert.Equal(t, "tester@test.com", md.Address.String())
 		assert.Equal(t, "optional", md.Note)
 		assert.Equal(t, "", md.UserAgent)
 		assert.Equal(t, "", md.RequestURI)
@@ -25,5 +26,12 @@ func TestCreateMetadata(t *testing.T) {
 		assert.Nil(t, md.PaymentDestination)
 	})
 
+	t.Run("invalid paymail address", func(t *testing.T) {
+		md := CreateMetadata(new(http.Request), "tester", "", "")
+		assert.NotNil(t, md)
+		assert.Equal(t, "tester", md.Alias)
+		assert.True(t, md.Address.IsZero())
+	})
+
 	// todo: add more tests on parsing request for IP, user agent etc
 }
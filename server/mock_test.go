@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"errors"
 
+	"github.com/libsv/go-bt/v2"
 	"github.com/tonicpow/go-paymail"
 )
 
@@ -40,3 +42,167 @@ func (m *mockServiceProvider) RecordTransaction(_ context.Context,
 	// Record the tx into your datastore layer
 	return nil, nil
 }
+
+// Mock implementation of a service provider with receiver approvals
+type mockApprovalProvider struct {
+	mockServiceProvider
+	approvals map[string]*paymail.PaymentApprovalPayload
+}
+
+// GetPaymailByAlias is a demo implementation of this interface
+func (m *mockApprovalProvider) GetPaymailByAlias(_ context.Context, alias, domain string,
+	_ *RequestMetadata) (*paymail.AddressInformation, error) {
+	return &paymail.AddressInformation{Alias: alias, Domain: domain}, nil
+}
+
+// CreateAddressResolutionResponse is a demo implementation of this interface
+func (m *mockApprovalProvider) CreateAddressResolutionResponse(_ context.Context, _, _ string,
+	_ bool, _ *RequestMetadata) (*paymail.ResolutionPayload, error) {
+	return &paymail.ResolutionPayload{Output: "76a9143e2d1d795f8acaa7957045cc59376177eb04a3c588ac"}, nil
+}
+
+// CreateP2PDestinationResponse is a demo implementation of this interface
+func (m *mockApprovalProvider) CreateP2PDestinationResponse(_ context.Context, _, _ string,
+	satoshis uint64, _ *RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+	return &paymail.PaymentDestinationPayload{
+		Outputs:   []*paymail.PaymentOutput{{Satoshis: satoshis, Script: "76a9143e2d1d795f8acaa7957045cc59376177eb04a3c588ac"}},
+		Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+	}, nil
+}
+
+// CreatePaymentApproval is a demo implementation of this interface
+func (m *mockApprovalProvider) CreatePaymentApproval(_ context.Context, _, _ string,
+	senderRequest *paymail.SenderRequest, _ *RequestMetadata) (*paymail.PaymentApprovalPayload, error) {
+	return &paymail.PaymentApprovalPayload{
+		Amount: senderRequest.Amount,
+		ID:     "pending",
+		Status: paymail.ApprovalStatusPending,
+	}, nil
+}
+
+// GetPaymentApproval is a demo implementation of this interface
+func (m *mockApprovalProvider) GetPaymentApproval(_ context.Context, _, _, id string,
+	_ *RequestMetadata) (*paymail.PaymentApprovalPayload, error) {
+	return m.approvals[id], nil
+}
+
+// Mock implementation of a service provider with token destinations
+type mockTokenProvider struct {
+	mockApprovalProvider
+}
+
+// CreateP2PTokenDestinationResponse is a demo implementation of this interface
+func (m *mockTokenProvider) CreateP2PTokenDestinationResponse(_ context.Context, _, _ string,
+	tokenRequest *paymail.TokenPaymentRequest, _ *RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+	if tokenRequest.Scheme != paymail.TokenSchemeSTAS {
+		return nil, errors.New("unsupported scheme: " + tokenRequest.Scheme)
+	}
+	return &paymail.PaymentDestinationPayload{
+		Outputs:   []*paymail.PaymentOutput{{Satoshis: tokenRequest.Amount, Script: "76a9143e2d1d795f8acaa7957045cc59376177eb04a3c588ac"}},
+		Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+	}, nil
+}
+
+// Mock implementation of a service provider with SFP
+type mockSFPProvider struct {
+	mockApprovalProvider
+}
+
+// GetAssetInformation is a demo implementation of this interface
+func (m *mockSFPProvider) GetAssetInformation(_ context.Context, alias, domain string,
+	_ *RequestMetadata) (*paymail.SFPAssetPayload, error) {
+	if alias != "usdt" {
+		return nil, nil
+	}
+	return &paymail.SFPAssetPayload{
+		Asset:   alias + "@" + domain,
+		Name:    "Test Token",
+		Symbol:  "USDT",
+		TokenID: "e9a4d4e0c8b2d7a1f3c6b5a4d3c2b1a0e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4",
+	}, nil
+}
+
+// AuthoriseSFPAction is a demo implementation of this interface
+func (m *mockSFPProvider) AuthoriseSFPAction(_ context.Context, _, _ string,
+	authoriseRequest *paymail.SFPAuthoriseRequest, _ *RequestMetadata) (*paymail.SFPAuthorisePayload, error) {
+	if authoriseRequest.Reference != "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7" {
+		return nil, errors.New("unknown reference: " + authoriseRequest.Reference)
+	}
+	return &paymail.SFPAuthorisePayload{
+		Hex:       authoriseRequest.Hex,
+		Reference: authoriseRequest.Reference,
+		TxID:      "9e8f6f3e2a0e83b5a2b7ab5b1f7e8b6c0c1c7e4d7b7a0c0f9e8d7c6b5a4f3e2d",
+	}, nil
+}
+
+// BuildSFPAction is a demo implementation of this interface
+func (m *mockSFPProvider) BuildSFPAction(_ context.Context, _, _ string,
+	buildRequest *paymail.SFPBuildRequest, _ *RequestMetadata) (*paymail.SFPBuildPayload, error) {
+	if buildRequest.Action != paymail.SFPActionTransfer {
+		return nil, errors.New("unsupported action: " + buildRequest.Action)
+	}
+	return &paymail.SFPBuildPayload{
+		Hex:       "0100000001ffff",
+		Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+	}, nil
+}
+
+// Mock implementation of a service provider with block headers (BEEF)
+type mockBeefProvider struct {
+	mockApprovalProvider
+	recorded *paymail.P2PTransaction
+	roots    map[string]uint64
+}
+
+// IsValidRootForHeight is a demo implementation of this interface
+func (m *mockBeefProvider) IsValidRootForHeight(_ context.Context, merkleRoot string, blockHeight uint64) (bool, error) {
+	height, ok := m.roots[merkleRoot]
+	return ok && height == blockHeight, nil
+}
+
+// RecordTransaction is a demo implementation of this interface
+func (m *mockBeefProvider) RecordTransaction(_ context.Context,
+	p2pTx *paymail.P2PTransaction, _ *RequestMetadata) (*paymail.P2PTransactionPayload, error) {
+	m.recorded = p2pTx
+	tx, err := bt.NewTxFromString(p2pTx.Hex)
+	if err != nil {
+		return nil, err
+	}
+	return &paymail.P2PTransactionPayload{Note: p2pTx.MetaData.Note, TxID: tx.TxID()}, nil
+}
+
+// Mock implementation of a service provider with PIKE (contacts)
+type mockPikeProvider struct {
+	mockApprovalProvider
+}
+
+// AddContact is a demo implementation of this interface
+func (m *mockPikeProvider) AddContact(_ context.Context, alias, domain string,
+	contact *paymail.PikeContactPayload, _ *RequestMetadata) (*paymail.PikeContactPayload, error) {
+	if contact.FullName == "blocked" {
+		return nil, errors.New("contact is blocked")
+	}
+	return &paymail.PikeContactPayload{
+		FullName: "Test User",
+		Paymail:  alias + "@" + domain,
+		PubKey:   "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10",
+	}, nil
+}
+
+// CreatePikeOutputs is a demo implementation of this interface
+func (m *mockPikeProvider) CreatePikeOutputs(_ context.Context, _, _ string,
+	outputsRequest *paymail.PikeOutputsRequest, _ *RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+	switch outputsRequest.SenderPaymail {
+	case "satoshi@test.com":
+		return &paymail.PaymentDestinationPayload{
+			Outputs: []*paymail.PaymentOutput{{
+				Satoshis: outputsRequest.Amount,
+				Script:   "76a9147f11c8f67a2781df0400ebfb1f31b4c72a780b9d88ac",
+			}},
+			Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+		}, nil
+	case "error@test.com":
+		return nil, errors.New("failed to derive the outputs")
+	}
+	return nil, nil
+}
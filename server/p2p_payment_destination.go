@@ -12,6 +12,7 @@ import (
 Incoming Data Object Example:
 {
   "satoshis": 1000100,
+  "approvalId": "APPROVAL-ID-IF-REQUIRED-IN-CONFIG"
 }
 */
 
@@ -36,7 +37,8 @@ func (c *Configuration) p2pDestination(w http.ResponseWriter, req *http.Request,
 
 	// Start the PaymentRequest
 	paymentRequest := &paymail.PaymentRequest{
-		Satoshis: params.GetUint64("satoshis"),
+		ApprovalID: params.GetString("approvalId"),
+		Satoshis:   params.GetUint64("satoshis"),
 	}
 
 	// Did we get some satoshis?
@@ -59,6 +61,11 @@ func (c *Configuration) p2pDestination(w http.ResponseWriter, req *http.Request,
 		return
 	}
 
+	// Check the payment approval (if receiver approvals are enabled)
+	if !c.checkPaymentApproval(w, req, alias, domain, paymentRequest.ApprovalID, paymentRequest.Satoshis, md) {
+		return
+	}
+
 	// Create the response
 	var response *paymail.PaymentDestinationPayload
 	if response, err = c.actions.CreateP2PDestinationResponse(
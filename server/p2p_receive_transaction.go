@@ -13,6 +13,7 @@ import (
 /*
 Incoming Data Object Example:
 {
+  "beef": "0100beef01fe636d0c0007021400fe507c0c7aa754cef1f7889d5fd395cf1f785dd7de98eed895dbedfe4e5bc70d1502ac4e1...",
   "hex": "01000000012adda020db81f2155ebba69e7.........154888ac00000000",
   "metadata": {
 	"sender": "someone@example.tld",
@@ -35,6 +36,7 @@ func (c *Configuration) p2pReceiveTx(w http.ResponseWriter, req *http.Request, _
 
 	// Start the P2PTransaction
 	p2pTransaction := &paymail.P2PTransaction{
+		Beef:      params.GetString("beef"),
 		Hex:       params.GetString("hex"),
 		MetaData:  &paymail.P2PMetaData{},
 		Reference: params.GetString("reference"),
@@ -61,7 +63,7 @@ func (c *Configuration) p2pReceiveTx(w http.ResponseWriter, req *http.Request, _
 	}
 
 	// Check for required fields
-	if len(p2pTransaction.Hex) == 0 {
+	if len(p2pTransaction.Hex) == 0 && (len(p2pTransaction.Beef) == 0 || !c.BeefEnabled) {
 		ErrorResponse(w, req, ErrorMissingHex, "missing parameter: hex", http.StatusBadRequest)
 		return
 	} else if len(p2pTransaction.Reference) == 0 {
@@ -69,6 +71,13 @@ func (c *Configuration) p2pReceiveTx(w http.ResponseWriter, req *http.Request, _
 		return
 	}
 
+	// Verify the BEEF envelope (SPV), the subject transaction is the P2P transaction
+	if len(p2pTransaction.Beef) > 0 && c.BeefEnabled {
+		if !c.verifyBeef(w, req, p2pTransaction) {
+			return
+		}
+	}
+
 	// Convert the raw tx into a transaction
 	transaction, err := bitcoin.TxFromHex(p2pTransaction.Hex)
 	if err != nil {
@@ -122,10 +131,17 @@ func (c *Configuration) p2pReceiveTx(w http.ResponseWriter, req *http.Request, _
 		return
 	}
 
+	// Reject the transaction if it was already received (replay protection)
+	replayKey := replayTransactionKey(response.TxID, p2pTransaction.Reference)
+	if !c.checkReplay(w, req, replayKey, c.ReplayTransactionTTL) {
+		return
+	}
+
 	// Record the transaction (verify, save, broadcast...)
 	if response, err = c.actions.RecordTransaction(
 		req.Context(), p2pTransaction, md,
 	); err != nil {
+		c.forgetReplay(req.Context(), replayKey) // Not recorded, the sender can try again
 		ErrorResponse(w, req, ErrorRecordingTx, err.Error(), http.StatusExpectationFailed)
 		return
 	}
@@ -133,3 +149,38 @@ func (c *Configuration) p2pReceiveTx(w http.ResponseWriter, req *http.Request, _
 	// Return the response
 	apirouter.ReturnResponse(w, req, http.StatusOK, response)
 }
+
+// verifyBeef will decode & verify the BEEF envelope of the P2P transaction (and set the hex & decoded envelope)
+//
+// Specs: https://bsv.brc.dev/transactions/0062
+func (c *Configuration) verifyBeef(w http.ResponseWriter, req *http.Request, p2pTransaction *paymail.P2PTransaction) bool {
+
+	// Decode the envelope
+	beef, err := paymail.DecodeBEEF(p2pTransaction.Beef)
+	if err != nil {
+		ErrorResponse(w, req, ErrorInvalidBeef, "invalid parameter: beef: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	// The hex (if given) must be the subject transaction
+	subjectHex := beef.SubjectTx().String()
+	if len(p2pTransaction.Hex) > 0 && p2pTransaction.Hex != subjectHex {
+		ErrorResponse(w, req, ErrorInvalidBeef, "invalid parameter: hex does not match the beef transaction", http.StatusBadRequest)
+		return false
+	}
+
+	// Verify the ancestors & merkle roots (NewConfig() checks the provider, a hand-built configuration might not)
+	provider, ok := c.actions.(paymail.BlockHeadersProvider)
+	if !ok {
+		ErrorResponse(w, req, ErrorInvalidBeef, ErrBlockHeadersProviderMissing.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if err = beef.Verify(req.Context(), provider); err != nil {
+		ErrorResponse(w, req, ErrorInvalidBeef, "invalid beef: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	p2pTransaction.DecodedBeef = beef
+	p2pTransaction.Hex = subjectHex
+	return true
+}
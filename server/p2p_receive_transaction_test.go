@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// testBeefPrivateKey is the key of the output spent by the BEEF subject transaction
+const testBeefPrivateKey = "54035dd4c7dda99ac473905a3d82f7864322b49bab1ff441cc457183b9bd8abd"
+
+// newTestBeef will return an envelope with a mined parent and the subject transaction spending it,
+// and the provider with the merkle root of the parent
+func newTestBeef(t *testing.T) (*paymail.DecodedBEEF, *mockBeefProvider) {
+
+	// The parent is mined (the input is not checked)
+	address, err := bitcoin.GetAddressFromPrivateKeyString(testBeefPrivateKey, true)
+	require.NoError(t, err)
+	lockingScript, err := bscript.NewP2PKHFromAddress(address)
+	require.NoError(t, err)
+	parent := bt.NewTx()
+	require.NoError(t, parent.From("f3ddfabf7a7a84cfa20016e61df24dff32953d4023a3002cb5a98d6da4ef9bf1", 0, lockingScript.String(), 20000))
+	parent.AddOutput(&bt.Output{LockingScript: lockingScript, Satoshis: 10000})
+
+	// The subject transaction spends the parent
+	signer, err := paymail.NewPrivateKeySigner(testBeefPrivateKey)
+	require.NoError(t, err)
+	transaction, err := paymail.BuildP2PTransaction(context.Background(), &paymail.PaymentDestinationPayload{
+		Outputs:   []*paymail.PaymentOutput{{Satoshis: 1000, Script: lockingScript.String()}},
+		Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+	}, &paymail.P2PPayment{
+		Signer: signer,
+		UTXOs: paymail.NewStaticUTXOSource(&bt.UTXO{
+			LockingScript: lockingScript, Satoshis: 10000, TxID: parent.TxIDBytes(), Vout: 0,
+		}),
+	})
+	require.NoError(t, err)
+	subject, err := bt.NewTxFromString(transaction.Hex)
+	require.NoError(t, err)
+
+	// The merkle path of the parent
+	bump := &paymail.BUMP{BlockHeight: 800000, Path: [][]*paymail.BUMPLeaf{{
+		{Hash: parent.TxID(), Offset: 0, TxID: true},
+		{Hash: "b1fea52486ce0c62bb442b530a3f0132b826c74e473d1f2c220bfa78111c5082", Offset: 1},
+	}}}
+	root, err := bump.CalculateMerkleRoot(parent.TxID())
+	require.NoError(t, err)
+
+	return &paymail.DecodedBEEF{
+		BUMPs: []*paymail.BUMP{bump},
+		Transactions: []*paymail.BEEFTransaction{
+			{BUMPIndex: 0, Transaction: parent},
+			{BUMPIndex: -1, Transaction: subject},
+		},
+	}, &mockBeefProvider{roots: map[string]uint64{root: bump.BlockHeight}}
+}
+
+// TestWithBEEF will test the method WithBEEF()
+func TestWithBEEF(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capability is added", func(t *testing.T) {
+		c, err := NewConfig(new(mockBeefProvider), WithDomain("test.com"), WithBEEF())
+		require.NoError(t, err)
+		assert.True(t, c.BeefEnabled)
+		assert.Equal(t, true, c.Capabilities.Capabilities[paymail.BRFCBeefTransaction])
+	})
+
+	t.Run("provider does not support block headers", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithBEEF())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrBlockHeadersProviderMissing)
+		assert.Nil(t, c)
+	})
+}
+
+// TestConfiguration_p2pReceiveTx_beef will test the method p2pReceiveTx() with a BEEF envelope
+func TestConfiguration_p2pReceiveTx_beef(t *testing.T) {
+	t.Parallel()
+
+	// newHandler will return the handler & the provider of the envelope
+	newHandler := func(t *testing.T, opts ...ConfigOps) (http.Handler, *paymail.DecodedBEEF, *mockBeefProvider) {
+		beef, provider := newTestBeef(t)
+		c, err := NewConfig(provider, append([]ConfigOps{WithDomain("test.com"), WithP2PCapabilities()}, opts...)...)
+		require.NoError(t, err)
+		return Handlers(c), beef, provider
+	}
+
+	// body will return the request body
+	body := func(t *testing.T, beefHex, txHex string) string {
+		b, err := json.Marshal(&paymail.P2PTransaction{
+			Beef:      beefHex,
+			Hex:       txHex,
+			MetaData:  &paymail.P2PMetaData{Note: "beef payment"},
+			Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("valid envelope", func(t *testing.T) {
+		handler, beef, provider := newHandler(t, WithBEEF())
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), ""))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		response := &paymail.P2PTransactionPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+		assert.Equal(t, beef.SubjectTx().TxID(), response.TxID)
+
+		// The provider receives the verified envelope
+		require.NotNil(t, provider.recorded)
+		require.NotNil(t, provider.recorded.DecodedBeef)
+		assert.Equal(t, beef.SubjectTx().String(), provider.recorded.Hex)
+		assert.Len(t, provider.recorded.DecodedBeef.Transactions, 2)
+	})
+
+	t.Run("valid envelope & hex", func(t *testing.T) {
+		handler, beef, _ := newHandler(t, WithBEEF())
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), beef.SubjectTx().String()))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("hex does not match", func(t *testing.T) {
+		handler, beef, _ := newHandler(t, WithBEEF())
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), beef.Transactions[0].Transaction.String()))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidBeef, errorCode(t, w))
+	})
+
+	t.Run("invalid envelope", func(t *testing.T) {
+		handler, _, _ := newHandler(t, WithBEEF())
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, "0100beef", ""))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidBeef, errorCode(t, w))
+	})
+
+	t.Run("unknown merkle root", func(t *testing.T) {
+		handler, beef, provider := newHandler(t, WithBEEF())
+		provider.roots = nil
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), ""))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidBeef, errorCode(t, w))
+		assert.Nil(t, provider.recorded)
+	})
+
+	t.Run("provider does not support block headers", func(t *testing.T) {
+		beef, provider := newTestBeef(t)
+		c, err := NewConfig(provider, WithDomain("test.com"), WithP2PCapabilities(), WithBEEF())
+		require.NoError(t, err)
+		c.actions = new(mockServiceProvider) // Hand-built configuration (not checked by NewConfig)
+		w := serveRequest(Handlers(c), http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), ""))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, ErrorInvalidBeef, errorCode(t, w))
+	})
+
+	t.Run("beef is not enabled", func(t *testing.T) {
+		handler, beef, _ := newHandler(t)
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), ""))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorMissingHex, errorCode(t, w))
+	})
+
+	t.Run("beef is ignored if not enabled", func(t *testing.T) {
+		handler, beef, provider := newHandler(t)
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef.Hex(), beef.SubjectTx().String()))
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, provider.recorded)
+		assert.Nil(t, provider.recorded.DecodedBeef)
+	})
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/tonicpow/go-paymail"
+)
+
+// P2PTokenDestinationPath is the capability path for the P2P payment destination with tokens
+const P2PTokenDestinationPath = "/p2p-payment-destination-token/{alias}@{domain.tld}"
+
+/*
+Incoming Data Object Example:
+{
+  "amount": 1000,
+  "scheme": "STAS",
+  "tokenId": "1a2b3c...",
+  "approvalId": "APPROVAL-ID-IF-REQUIRED-IN-CONFIG"
+}
+*/
+
+// p2pTokenDestination will return output script(s) for receiving tokens (used with SendP2PTransaction)
+//
+// Specs: https://docs.moneybutton.com/docs/paymail/paymail-11-p2p-payment-destination-tokens.html
+func (c *Configuration) p2pTokenDestination(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Start the TokenPaymentRequest
+	tokenRequest := &paymail.TokenPaymentRequest{
+		Amount:     params.GetUint64("amount"),
+		ApprovalID: params.GetString("approvalId"),
+		Scheme:     params.GetString("scheme"),
+		TokenID:    params.GetString("tokenId"),
+	}
+
+	// Check for required fields
+	if tokenRequest.Amount == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "missing parameter: amount", http.StatusBadRequest)
+		return
+	} else if len(tokenRequest.Scheme) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "missing parameter: scheme", http.StatusBadRequest)
+		return
+	} else if len(tokenRequest.TokenID) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "missing parameter: tokenId", http.StatusBadRequest)
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.TokenDestination = tokenRequest
+
+	// Get from the data layer
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Check the payment approval (the approved amount is in satoshis, not tokens)
+	if !c.checkPaymentApproval(w, req, alias, domain, tokenRequest.ApprovalID, 0, md) {
+		return
+	}
+
+	// Create the response
+	var response *paymail.PaymentDestinationPayload
+	if response, err = c.actions.(TokenDestinationProvider).CreateP2PTokenDestinationResponse(
+		req.Context(), alias, domain, tokenRequest, md,
+	); err != nil {
+		ErrorResponse(w, req, ErrorScript, "error creating output script(s): "+err.Error(), http.StatusExpectationFailed)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// TestWithP2PTokenDestinations will test the method WithP2PTokenDestinations()
+func TestWithP2PTokenDestinations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capability is added", func(t *testing.T) {
+		c, err := NewConfig(new(mockTokenProvider), WithDomain("test.com"), WithP2PTokenDestinations())
+		require.NoError(t, err)
+		assert.True(t, c.TokenDestinationsEnabled)
+		assert.Equal(t, P2PTokenDestinationPath, c.Capabilities.Capabilities[paymail.BRFCP2PPaymentDestinationWithToken])
+	})
+
+	t.Run("provider does not support tokens", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithP2PTokenDestinations())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTokenProviderMissing)
+		assert.Nil(t, c)
+	})
+}
+
+// TestConfiguration_p2pTokenDestination will test the method p2pTokenDestination()
+func TestConfiguration_p2pTokenDestination(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(new(mockTokenProvider), WithDomain("test.com"), WithP2PCapabilities(), WithP2PTokenDestinations())
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	t.Run("valid request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/p2p-payment-destination-token/mrz@test.com",
+			`{"amount":10,"scheme":"STAS","tokenId":"abc"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		destination := &paymail.PaymentDestinationPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), destination))
+		require.Len(t, destination.Outputs, 1)
+		assert.Equal(t, uint64(10), destination.Outputs[0].Satoshis)
+		assert.NotEmpty(t, destination.Reference)
+	})
+
+	var tests = []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		errorCode    string
+	}{
+		{"missing amount", "mrz@test.com", `{"scheme":"STAS","tokenId":"abc"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"missing scheme", "mrz@test.com", `{"amount":10,"tokenId":"abc"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"missing token id", "mrz@test.com", `{"amount":10,"scheme":"STAS"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"unknown domain", "mrz@unknown.com", `{"amount":10,"scheme":"STAS","tokenId":"abc"}`, http.StatusBadRequest, ErrorUnknownDomain},
+		{"provider error", "mrz@test.com", `{"amount":10,"scheme":"run","tokenId":"abc"}`, http.StatusExpectationFailed, ErrorScript},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(handler, http.MethodPost, "/p2p-payment-destination-token/"+test.path, test.body)
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, test.errorCode, errorCode(t, w))
+		})
+	}
+
+	t.Run("route is not registered if disabled", func(t *testing.T) {
+		disabled, err := NewConfig(new(mockTokenProvider), WithDomain("test.com"), WithBasicRoutes())
+		require.NoError(t, err)
+		w := serveRequest(Handlers(disabled), http.MethodPost, "/p2p-payment-destination-token/mrz@test.com",
+			`{"amount":10,"scheme":"STAS","tokenId":"abc"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/tonicpow/go-paymail"
+)
+
+// Capability paths for PIKE (the capability is an object with both urls)
+const (
+	PikeInvitePath  = "/contact/invite/{alias}@{domain.tld}"
+	PikeOutputsPath = "/pike/outputs/{alias}@{domain.tld}"
+)
+
+/*
+Incoming Data Object Example:
+{
+  "fullName": "Satoshi Nakamoto",
+  "paymail": "satoshi@moneybutton.com",
+  "pubKey": "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"
+}
+*/
+
+// pikeInvite will store the contact of the sender (invite) & return the contact of the paymail
+// (the identity keys are exchanged)
+func (c *Configuration) pikeInvite(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Start the PikeContactPayload
+	contact := &paymail.PikeContactPayload{
+		FullName: params.GetString("fullName"),
+		Paymail:  params.GetString("paymail"),
+		PubKey:   params.GetString("pubKey"),
+	}
+
+	// Check for required fields
+	if err := contact.Validate(); err != nil {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.PikeContact = contact
+
+	// Get from the data layer
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Store the contact
+	var response *paymail.PikeContactPayload
+	if response, err = c.actions.(PikeProvider).AddContact(
+		req.Context(), alias, domain, contact, md,
+	); err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, "error adding contact: "+err.Error(), http.StatusExpectationFailed)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+}
+
+/*
+Incoming Data Object Example:
+{
+  "senderPaymail": "satoshi@moneybutton.com",
+  "amount": 1000
+}
+*/
+
+// pikeOutputs will return the output script(s) derived from the identity keys of the paymail & the
+// sender (a contact), the transaction is received by the P2P receive transaction route
+func (c *Configuration) pikeOutputs(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Start the PikeOutputsRequest
+	outputsRequest := &paymail.PikeOutputsRequest{
+		Amount:        params.GetUint64("amount"),
+		SenderPaymail: params.GetString("senderPaymail"),
+	}
+
+	// Check for required fields
+	if err := outputsRequest.Validate(); err != nil {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.PikeOutputs = outputsRequest
+
+	// Get from the data layer
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Create the response
+	var response *paymail.PaymentDestinationPayload
+	if response, err = c.actions.(PikeProvider).CreatePikeOutputs(
+		req.Context(), alias, domain, outputsRequest, md,
+	); err != nil {
+		ErrorResponse(w, req, ErrorScript, "error creating output script(s): "+err.Error(), http.StatusExpectationFailed)
+		return
+	} else if response == nil {
+		ErrorResponse(w, req, ErrorContactNotFound, "contact not found: "+outputsRequest.SenderPaymail, http.StatusNotFound)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// TestWithPike will test the method WithPike()
+func TestWithPike(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capability is added", func(t *testing.T) {
+		c, err := NewConfig(new(mockPikeProvider), WithDomain("test.com"), WithPike())
+		require.NoError(t, err)
+		assert.True(t, c.PikeEnabled)
+		pike := c.Capabilities.GetPikeCapability()
+		require.NotNil(t, pike)
+		assert.Equal(t, PikeInvitePath, pike.Invite)
+		assert.Equal(t, PikeOutputsPath, pike.Outputs)
+	})
+
+	t.Run("provider does not support pike", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithPike())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPikeProviderMissing)
+		assert.Nil(t, c)
+	})
+}
+
+// TestConfiguration_pikeInvite will test the method pikeInvite()
+func TestConfiguration_pikeInvite(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(new(mockPikeProvider), WithDomain("test.com"), WithPike())
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	const pubKey = "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"
+
+	t.Run("valid request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/contact/invite/mrz@test.com",
+			`{"fullName":"Satoshi","paymail":"satoshi@test.com","pubKey":"`+pubKey+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		contact := &paymail.PikeContactPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), contact))
+		assert.Equal(t, "mrz@test.com", contact.Paymail)
+		assert.Equal(t, pubKey, contact.PubKey)
+	})
+
+	t.Run("IDN paymail", func(t *testing.T) {
+		idn, err := NewConfig(new(mockPikeProvider), WithDomain("bücher.de"), WithPike())
+		require.NoError(t, err)
+		w := serveRequest(Handlers(idn), http.MethodPost, "/contact/invite/M%C3%BCller@B%C3%BCcher.de",
+			`{"paymail":"satoshi@bücher.de","pubKey":"`+pubKey+`"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		contact := &paymail.PikeContactPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), contact))
+		assert.Equal(t, "müller@xn--bcher-kva.de", contact.Paymail)
+
+		// Confusable alias (latin & cyrillic)
+		w = serveRequest(Handlers(idn), http.MethodPost, "/contact/invite/p%D0%B0ypal@b%C3%BCcher.de",
+			`{"paymail":"satoshi@bücher.de","pubKey":"`+pubKey+`"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidParameter, errorCode(t, w))
+	})
+
+	var tests = []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		errorCode    string
+	}{
+		{"missing paymail", "mrz@test.com", `{"pubKey":"` + pubKey + `"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"invalid pubKey", "mrz@test.com", `{"paymail":"satoshi@test.com","pubKey":"02ff"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"unknown domain", "mrz@unknown.com", `{"paymail":"satoshi@test.com","pubKey":"` + pubKey + `"}`, http.StatusBadRequest, ErrorUnknownDomain},
+		{"provider error", "mrz@test.com", `{"fullName":"blocked","paymail":"satoshi@test.com","pubKey":"` + pubKey + `"}`, http.StatusExpectationFailed, ErrorFindingPaymail},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(handler, http.MethodPost, "/contact/invite/"+test.path, test.body)
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, test.errorCode, errorCode(t, w))
+		})
+	}
+
+	t.Run("route is not registered if disabled", func(t *testing.T) {
+		disabled, err := NewConfig(new(mockPikeProvider), WithDomain("test.com"), WithBasicRoutes())
+		require.NoError(t, err)
+		w := serveRequest(Handlers(disabled), http.MethodPost, "/contact/invite/mrz@test.com",
+			`{"paymail":"satoshi@test.com","pubKey":"`+pubKey+`"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// TestConfiguration_pikeOutputs will test the method pikeOutputs()
+func TestConfiguration_pikeOutputs(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(new(mockPikeProvider), WithDomain("test.com"), WithPike())
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	t.Run("valid request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/pike/outputs/mrz@test.com",
+			`{"senderPaymail":"satoshi@test.com","amount":1000}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		destination := &paymail.PaymentDestinationPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), destination))
+		assert.NotEmpty(t, destination.Reference)
+		require.Len(t, destination.Outputs, 1)
+		assert.Equal(t, uint64(1000), destination.Outputs[0].Satoshis)
+	})
+
+	var tests = []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		errorCode    string
+	}{
+		{"missing amount", "mrz@test.com", `{"senderPaymail":"satoshi@test.com"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"invalid sender", "mrz@test.com", `{"senderPaymail":"satoshi","amount":1000}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"unknown domain", "mrz@unknown.com", `{"senderPaymail":"satoshi@test.com","amount":1000}`, http.StatusBadRequest, ErrorUnknownDomain},
+		{"contact not found", "mrz@test.com", `{"senderPaymail":"unknown@test.com","amount":1000}`, http.StatusNotFound, ErrorContactNotFound},
+		{"provider error", "mrz@test.com", `{"senderPaymail":"error@test.com","amount":1000}`, http.StatusExpectationFailed, ErrorScript},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(handler, http.MethodPost, "/pike/outputs/"+test.path, test.body)
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, test.errorCode, errorCode(t, w))
+		})
+	}
+}
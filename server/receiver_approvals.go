@@ -0,0 +1,167 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/tonicpow/go-paymail"
+)
+
+// ReceiverApprovalsPath is the capability path for receiver approvals (the status is at {path}/{id})
+const ReceiverApprovalsPath = "/approvals/{alias}@{domain.tld}"
+
+/*
+Incoming Data Object Example:
+{
+    "senderName": "UserName",
+    "senderHandle": "alias@domain.com",
+    "dt": "2020-04-09T16:08:06.419Z",
+    "amount": 551,
+    "purpose": "message to receiver",
+    "signature": "SIGNATURE-IF-REQUIRED-IN-CONFIG"
+}
+*/
+
+// submitPaymentApproval will submit a payment intent to the receiver for approval,
+// and return the approval (id & status)
+//
+// Specs: http://bsvalias.org/04-03-receiver-approvals.html
+func (c *Configuration) submitPaymentApproval(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Start the SenderRequest
+	senderRequest := &paymail.SenderRequest{
+		Amount:       params.GetUint64("amount"),
+		Dt:           params.GetString("dt"),
+		Purpose:      params.GetString("purpose"),
+		SenderHandle: params.GetString("senderHandle"),
+		SenderName:   params.GetString("senderName"),
+		Signature:    params.GetString("signature"),
+	}
+
+	// Validate the sender request (fields, timestamp & signature)
+	replayKey, ok := c.validateSenderRequest(w, req, senderRequest)
+	if !ok {
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.ResolveAddress = senderRequest
+
+	// Get from the data layer (not approved, the sender can try again)
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		c.forgetReplay(req.Context(), replayKey)
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		c.forgetReplay(req.Context(), replayKey)
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Create the approval
+	var response *paymail.PaymentApprovalPayload
+	if response, err = c.actions.(PaymentApprovalProvider).CreatePaymentApproval(
+		req.Context(), alias, domain, senderRequest, md,
+	); err != nil {
+		c.forgetReplay(req.Context(), replayKey)
+		ErrorResponse(w, req, ErrorFindingPaymail, "error creating payment approval: "+err.Error(), http.StatusExpectationFailed)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusCreated, response)
+}
+
+// showPaymentApproval will return the approval (id & status) for the paymail address
+//
+// Specs: http://bsvalias.org/04-03-receiver-approvals.html
+func (c *Configuration) showPaymentApproval(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+	approvalID := params.GetString("approvalId")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	} else if len(approvalID) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "missing parameter: approvalId", http.StatusBadRequest)
+		return
+	}
+
+	// Get from the data layer
+	approval, err := c.actions.(PaymentApprovalProvider).GetPaymentApproval(
+		req.Context(), alias, domain, approvalID, CreateMetadata(req, alias, domain, ""),
+	)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if approval == nil {
+		ErrorResponse(w, req, ErrorApprovalNotFound, "payment approval not found", http.StatusNotFound)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, approval)
+}
+
+// checkPaymentApproval will check the approval before a payment destination is issued,
+// the approved payment is set in the metadata (the error response is sent if not approved)
+//
+// Skipped if receiver approvals are disabled, or if approvals are optional and no approval id was given
+func (c *Configuration) checkPaymentApproval(w http.ResponseWriter, req *http.Request, alias, domain,
+	approvalID string, satoshis uint64, md *RequestMetadata) bool {
+
+	// Approvals disabled or not required
+	if !c.ReceiverApprovalsEnabled || (len(approvalID) == 0 && !c.ReceiverApprovalsRequired) {
+		return true
+	} else if len(approvalID) == 0 {
+		ErrorResponse(w, req, ErrorPaymentNotApproved, "missing required approvalId", http.StatusForbidden)
+		return false
+	}
+
+	// Get from the data layer
+	approval, err := c.actions.(PaymentApprovalProvider).GetPaymentApproval(req.Context(), alias, domain, approvalID, md)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return false
+	} else if approval == nil {
+		ErrorResponse(w, req, ErrorApprovalNotFound, "payment approval not found", http.StatusNotFound)
+		return false
+	}
+
+	// Must be approved, and not more than the approved amount
+	if !approval.IsApproved() {
+		ErrorResponse(w, req, ErrorPaymentNotApproved, "payment is "+approval.Status, http.StatusForbidden)
+		return false
+	} else if approval.Amount > 0 && satoshis > approval.Amount {
+		ErrorResponse(w, req, ErrorPaymentNotApproved, "amount is more than the approved amount", http.StatusForbidden)
+		return false
+	}
+
+	md.PaymentApproval = approval
+	return true
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// newApprovalsHandler will return the routes of a server with receiver approvals
+func newApprovalsHandler(t *testing.T, required bool) http.Handler {
+	c, err := NewConfig(
+		&mockApprovalProvider{approvals: map[string]*paymail.PaymentApprovalPayload{
+			"approved": {ID: "approved", Status: paymail.ApprovalStatusApproved, Amount: 1000},
+			"pending":  {ID: "pending", Status: paymail.ApprovalStatusPending},
+			"rejected": {ID: "rejected", Status: paymail.ApprovalStatusRejected, Reason: "unknown sender"},
+		}},
+		WithDomain("test.com"),
+		WithP2PCapabilities(),
+		WithReceiverApprovals(required),
+	)
+	require.NoError(t, err)
+	return Handlers(c)
+}
+
+// serveRequest will fire the request and return the recorder
+func serveRequest(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://test.com/v1/bsvalias"+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode will return the code of the error response
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	serverError := &paymail.ServerError{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), serverError))
+	return serverError.Code
+}
+
+// TestWithReceiverApprovals will test the method WithReceiverApprovals()
+func TestWithReceiverApprovals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capability is added", func(t *testing.T) {
+		c, err := NewConfig(new(mockApprovalProvider), WithDomain("test.com"), WithReceiverApprovals(false))
+		require.NoError(t, err)
+		assert.True(t, c.ReceiverApprovalsEnabled)
+		assert.False(t, c.ReceiverApprovalsRequired)
+		assert.Equal(t, ReceiverApprovalsPath, c.Capabilities.Capabilities[paymail.BRFCReceiverApprovals])
+	})
+
+	t.Run("provider does not support approvals", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReceiverApprovals(true))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrApprovalProviderMissing)
+		assert.Nil(t, c)
+	})
+}
+
+// TestConfiguration_submitPaymentApproval will test the method submitPaymentApproval()
+func TestConfiguration_submitPaymentApproval(t *testing.T) {
+	t.Parallel()
+
+	handler := newApprovalsHandler(t, false)
+	dt := time.Now().UTC().Format(time.RFC3339)
+
+	t.Run("pending approval", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/approvals/mrz@test.com",
+			`{"senderHandle":"satchmo@test.com","dt":"`+dt+`","amount":500}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		approval := &paymail.PaymentApprovalPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), approval))
+		assert.True(t, approval.IsPending())
+		assert.Equal(t, uint64(500), approval.Amount)
+	})
+
+	t.Run("invalid sender request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/approvals/mrz@test.com", `{"dt":"`+dt+`"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidSenderHandle, errorCode(t, w))
+	})
+
+	t.Run("unknown domain", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/approvals/mrz@unknown.com",
+			`{"senderHandle":"satchmo@test.com","dt":"`+dt+`"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorUnknownDomain, errorCode(t, w))
+	})
+}
+
+// TestConfiguration_showPaymentApproval will test the method showPaymentApproval()
+func TestConfiguration_showPaymentApproval(t *testing.T) {
+	t.Parallel()
+
+	handler := newApprovalsHandler(t, false)
+
+	t.Run("rejected approval", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodGet, "/approvals/mrz@test.com/rejected", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		approval := &paymail.PaymentApprovalPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), approval))
+		assert.True(t, approval.IsRejected())
+		assert.Equal(t, "unknown sender", approval.Reason)
+	})
+
+	t.Run("approval not found", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodGet, "/approvals/mrz@test.com/unknown", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ErrorApprovalNotFound, errorCode(t, w))
+	})
+}
+
+// TestConfiguration_checkPaymentApproval will test the method checkPaymentApproval()
+func TestConfiguration_checkPaymentApproval(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name         string
+		required     bool
+		body         string
+		expectedCode int
+		errorCode    string
+	}{
+		{"optional - no approval id", false, `{"satoshis":5000}`, http.StatusOK, ""},
+		{"optional - approved", false, `{"satoshis":1000,"approvalId":"approved"}`, http.StatusOK, ""},
+		{"optional - pending", false, `{"satoshis":1000,"approvalId":"pending"}`, http.StatusForbidden, ErrorPaymentNotApproved},
+		{"required - no approval id", true, `{"satoshis":1000}`, http.StatusForbidden, ErrorPaymentNotApproved},
+		{"required - approved", true, `{"satoshis":1000,"approvalId":"approved"}`, http.StatusOK, ""},
+		{"required - more than approved", true, `{"satoshis":1001,"approvalId":"approved"}`, http.StatusForbidden, ErrorPaymentNotApproved},
+		{"required - rejected", true, `{"satoshis":1000,"approvalId":"rejected"}`, http.StatusForbidden, ErrorPaymentNotApproved},
+		{"required - not found", true, `{"satoshis":1000,"approvalId":"unknown"}`, http.StatusNotFound, ErrorApprovalNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(newApprovalsHandler(t, test.required), http.MethodPost,
+				"/p2p-payment-destination/mrz@test.com", test.body)
+			assert.Equal(t, test.expectedCode, w.Code)
+			if len(test.errorCode) > 0 {
+				assert.Equal(t, test.errorCode, errorCode(t, w))
+			}
+		})
+	}
+
+	t.Run("address resolution", func(t *testing.T) {
+		handler := newApprovalsHandler(t, true)
+		dt := time.Now().UTC().Format(time.RFC3339)
+		w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com",
+			`{"senderHandle":"satchmo@test.com","dt":"`+dt+`"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = serveRequest(handler, http.MethodPost, "/address/mrz@test.com",
+			`{"senderHandle":"satchmo@test.com","dt":"`+dt+`","amount":1000,"approvalId":"approved"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
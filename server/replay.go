@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tonicpow/go-paymail"
+)
+
+// Replay protection default values
+const (
+	replayKeySender      = "sender:"      // Prefix for the keys of signed sender requests
+	replayKeyTransaction = "transaction:" // Prefix for the keys of received P2P transactions
+	replaySweepInterval  = 256            // Sweep the expired keys every n stored keys (memory store)
+)
+
+// ReplayStore records the keys of the requests that were already processed
+//
+// Implementations must be safe for concurrent use and must check & store the key atomically,
+// otherwise two copies of the same request can both pass (see: NewMemoryReplayStore())
+type ReplayStore interface {
+	// Forget will remove the key (the request failed, so it can be sent again)
+	Forget(ctx context.Context, key string) error
+
+	// Remember will store the key until it expires, returns false if the key
+	// was already stored and has not expired yet (the request is a replay)
+	Remember(ctx context.Context, key string, expires time.Time) (bool, error)
+}
+
+// memoryReplayStore is an in-memory ReplayStore
+type memoryReplayStore struct {
+	clock  func() time.Time
+	keys   map[string]time.Time
+	mu     sync.Mutex
+	stored int
+}
+
+// NewMemoryReplayStore will return an in-memory ReplayStore (keys are lost on restart)
+//
+// The clock is used to expire the keys (default: time.Now)
+func NewMemoryReplayStore(clock func() time.Time) ReplayStore {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &memoryReplayStore{clock: clock, keys: make(map[string]time.Time)}
+}
+
+// Forget will remove the key
+func (m *memoryReplayStore) Forget(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+	return nil
+}
+
+// Remember will store the key until it expires, returns false if the key was already stored
+func (m *memoryReplayStore) Remember(_ context.Context, key string, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Key was already stored (and has not expired)
+	now := m.clock()
+	if existing, ok := m.keys[key]; ok && existing.After(now) {
+		return false, nil
+	}
+
+	// Store the key & remove the expired keys from time to time
+	m.keys[key] = expires
+	if m.stored++; m.stored%replaySweepInterval == 0 {
+		for k, e := range m.keys {
+			if !e.After(now) {
+				delete(m.keys, k)
+			}
+		}
+	}
+	return true, nil
+}
+
+// replaySenderKey will return the key of a signed sender request, the sender (address of the pubKey)
+// and a hash of the signed content
+//
+// The signature is not part of the key: different encodings of the same signature (or a re-signed
+// copy) are still the same request
+func replaySenderKey(senderAddress string, senderRequest *paymail.SenderRequest) string {
+	hash := sha256.Sum256([]byte(senderRequest.Message()))
+	return replayKeySender + senderAddress + ":" + hex.EncodeToString(hash[:])
+}
+
+// replayTransactionKey will return the key of a received P2P transaction (txid & reference)
+func replayTransactionKey(txID, reference string) string {
+	return replayKeyTransaction + txID + ":" + reference
+}
+
+// replayTTL will return how long the keys of the sender requests are stored,
+// the full skew window of the timestamp validator
+//
+// A timestamp is accepted for (at most) past + future skew, so a replay after the TTL is rejected on the dt
+func (c *Configuration) replayTTL() time.Duration {
+	past, future := c.TimestampValidator.Skew()
+	return past + future
+}
+
+// checkReplay will store the key of the request for the TTL (if replay protection is enabled),
+// the error response is sent if the request was already processed
+func (c *Configuration) checkReplay(w http.ResponseWriter, req *http.Request, key string, ttl time.Duration) bool {
+
+	// Skip if replay protection is disabled
+	if !c.ReplayProtectionEnabled {
+		return true
+	}
+
+	// Replay protection was enabled without a store (IE: the configuration was not made with NewConfig())
+	if c.ReplayStore == nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, "replay protection is missing a replay store", http.StatusInternalServerError)
+		return false
+	}
+
+	// Store the key (atomic check & store)
+	ok, err := c.ReplayStore.Remember(req.Context(), key, c.TimestampValidator.Now().Add(ttl))
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, "replay protection failed: "+err.Error(), http.StatusExpectationFailed)
+		return false
+	} else if !ok {
+		ErrorResponse(w, req, ErrorReplayedRequest, "request was already processed", http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// forgetReplay will remove the key of a request that failed (if replay protection is enabled),
+// so the sender can send it again (an empty key was never stored)
+func (c *Configuration) forgetReplay(ctx context.Context, key string) {
+	if c.ReplayProtectionEnabled && c.ReplayStore != nil && len(key) > 0 {
+		_ = c.ReplayStore.Forget(ctx, key)
+	}
+}
@@ -0,0 +1,501 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bk/bec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// testReplayClock is a clock that can be moved forward in the tests
+type testReplayClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now will return the current time of the clock
+func (c *testReplayClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Add will move the clock forward
+func (c *testReplayClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// mockReplayStore is a ReplayStore that always fails
+type mockReplayStore struct{}
+
+// Forget is a demo implementation of this interface
+func (m *mockReplayStore) Forget(_ context.Context, _ string) error {
+	return errors.New("store is down")
+}
+
+// Remember is a demo implementation of this interface
+func (m *mockReplayStore) Remember(_ context.Context, _ string, _ time.Time) (bool, error) {
+	return false, errors.New("store is down")
+}
+
+// mockRecordFailureProvider is a service provider that fails to record the first transactions
+type mockRecordFailureProvider struct {
+	mockBeefProvider
+	failures int
+}
+
+// RecordTransaction is a demo implementation of this interface
+func (m *mockRecordFailureProvider) RecordTransaction(ctx context.Context,
+	p2pTx *paymail.P2PTransaction, md *RequestMetadata) (*paymail.P2PTransactionPayload, error) {
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("broadcast failed")
+	}
+	return m.mockBeefProvider.RecordTransaction(ctx, p2pTx, md)
+}
+
+// mockResolutionFailureProvider is a service provider that fails to create the first address resolutions
+type mockResolutionFailureProvider struct {
+	mockApprovalProvider
+	failures int
+}
+
+// CreateAddressResolutionResponse is a demo implementation of this interface
+func (m *mockResolutionFailureProvider) CreateAddressResolutionResponse(ctx context.Context, alias, domain string,
+	senderValidation bool, md *RequestMetadata) (*paymail.ResolutionPayload, error) {
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("failed to derive the address")
+	}
+	return m.mockApprovalProvider.CreateAddressResolutionResponse(ctx, alias, domain, senderValidation, md)
+}
+
+// alterSignature will return the signature with other (unused) low bits in the last base64 character,
+// the encoding is different but the decoded signature is the same
+func alterSignature(t *testing.T, signature string) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	last := strings.LastIndexFunc(signature, func(r rune) bool { return r != '=' })
+	require.Positive(t, last)
+	index := strings.IndexByte(alphabet, signature[last])
+	require.GreaterOrEqual(t, index, 0)
+	return signature[:last] + string(alphabet[index^1]) + signature[last+1:]
+}
+
+// TestNewMemoryReplayStore will test the method NewMemoryReplayStore()
+func TestNewMemoryReplayStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("replay is rejected until the key expires", func(t *testing.T) {
+		clock := &testReplayClock{now: time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC)}
+		store := NewMemoryReplayStore(clock.Now)
+		expires := clock.Now().Add(4 * time.Minute)
+
+		ok, err := store.Remember(context.Background(), "key", expires)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = store.Remember(context.Background(), "key", expires)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = store.Remember(context.Background(), "other-key", expires)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		clock.Add(4 * time.Minute)
+		ok, err = store.Remember(context.Background(), "key", clock.Now().Add(4*time.Minute))
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("forgotten key can be stored again", func(t *testing.T) {
+		store := NewMemoryReplayStore(nil)
+		expires := time.Now().Add(time.Minute)
+
+		ok, err := store.Remember(context.Background(), "key", expires)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, store.Forget(context.Background(), "key"))
+		require.NoError(t, store.Forget(context.Background(), "unknown-key"))
+
+		ok, err = store.Remember(context.Background(), "key", expires)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("expired keys are removed", func(t *testing.T) {
+		clock := &testReplayClock{now: time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC)}
+		store := NewMemoryReplayStore(clock.Now).(*memoryReplayStore)
+		for i := 0; i < replaySweepInterval-1; i++ {
+			_, err := store.Remember(context.Background(), strconv.Itoa(i), clock.Now().Add(time.Minute))
+			require.NoError(t, err)
+		}
+		clock.Add(time.Minute)
+		_, err := store.Remember(context.Background(), "last", clock.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Len(t, store.keys, 1)
+	})
+
+	t.Run("only one concurrent request passes", func(t *testing.T) {
+		store := NewMemoryReplayStore(nil)
+		var passed int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, _ := store.Remember(context.Background(), "key", time.Now().Add(time.Minute)); ok {
+					atomic.AddInt32(&passed, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), passed)
+	})
+}
+
+// TestWithReplayProtection will test the method WithReplayProtection()
+func TestWithReplayProtection(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		assert.False(t, c.ReplayProtectionEnabled)
+		assert.Nil(t, c.ReplayStore)
+	})
+
+	t.Run("default memory store", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReplayProtection(nil))
+		require.NoError(t, err)
+		assert.True(t, c.ReplayProtectionEnabled)
+		require.NotNil(t, c.ReplayStore)
+		assert.Equal(t, 2*paymail.DefaultTimestampSkew, c.replayTTL())
+		assert.Equal(t, DefaultReplayTransactionTTL, c.ReplayTransactionTTL)
+	})
+
+	t.Run("custom store", func(t *testing.T) {
+		store := new(mockReplayStore)
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReplayProtection(store))
+		require.NoError(t, err)
+		assert.Equal(t, store, c.ReplayStore)
+	})
+}
+
+// TestWithReplayTransactionTTL will test the method WithReplayTransactionTTL()
+func TestWithReplayTransactionTTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("custom ttl", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReplayTransactionTTL(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, c.ReplayTransactionTTL)
+	})
+
+	t.Run("invalid ttl is ignored", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReplayTransactionTTL(0))
+		require.NoError(t, err)
+		assert.Equal(t, DefaultReplayTransactionTTL, c.ReplayTransactionTTL)
+	})
+}
+
+// Test_replaySenderKey will test the method replaySenderKey()
+func Test_replaySenderKey(t *testing.T) {
+	t.Parallel()
+
+	// sign will return a signed sender request
+	sign := func(t *testing.T, amount uint64) *paymail.SenderRequest {
+		senderRequest := &paymail.SenderRequest{
+			Amount:       amount,
+			Dt:           "2020-04-09T16:08:06.419Z",
+			Purpose:      "pay for the coffee",
+			SenderHandle: "satchmo@test.com",
+			SenderName:   "Satchmo",
+		}
+		var err error
+		senderRequest.Signature, err = bitcoin.SignMessage(testBeefPrivateKey, senderRequest.Message(), true)
+		require.NoError(t, err)
+		return senderRequest
+	}
+
+	address, err := bitcoin.GetAddressFromPrivateKeyString(testBeefPrivateKey, true)
+	require.NoError(t, err)
+
+	t.Run("altered signature is the same request", func(t *testing.T) {
+		senderRequest := sign(t, 1000)
+		altered := *senderRequest
+		altered.Signature = alterSignature(t, senderRequest.Signature)
+		require.NotEqual(t, senderRequest.Signature, altered.Signature)
+		require.NoError(t, altered.Verify(address, altered.Signature))
+
+		assert.Equal(t, replaySenderKey(address, senderRequest), replaySenderKey(address, &altered))
+	})
+
+	t.Run("other content or sender is another request", func(t *testing.T) {
+		senderRequest := sign(t, 1000)
+		assert.NotEqual(t, replaySenderKey(address, senderRequest), replaySenderKey(address, sign(t, 1001)))
+		assert.NotEqual(t, replaySenderKey(address, senderRequest), replaySenderKey("other-address", senderRequest))
+	})
+
+	t.Run("replay with an altered signature is rejected", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReplayProtection(nil))
+		require.NoError(t, err)
+		senderRequest := sign(t, 1000)
+		altered := *senderRequest
+		altered.Signature = alterSignature(t, senderRequest.Signature)
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		assert.True(t, c.checkReplay(httptest.NewRecorder(), req, replaySenderKey(address, senderRequest), c.replayTTL()))
+
+		w := httptest.NewRecorder()
+		assert.False(t, c.checkReplay(w, req, replaySenderKey(address, &altered), c.replayTTL()))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, ErrorReplayedRequest, errorCode(t, w))
+	})
+}
+
+// TestConfiguration_checkReplay will test the method checkReplay()
+func TestConfiguration_checkReplay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		assert.True(t, c.checkReplay(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil), "key", time.Minute))
+		assert.True(t, c.checkReplay(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil), "key", time.Minute))
+	})
+
+	t.Run("replayed sender request", func(t *testing.T) {
+		clock := &testReplayClock{now: time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC)}
+		c, err := NewConfig(
+			new(mockServiceProvider),
+			WithDomain("test.com"),
+			WithTimestampValidator(paymail.NewTimestampValidator(paymail.WithTimestampClock(clock.Now))),
+			WithReplayProtection(nil),
+		)
+		require.NoError(t, err)
+
+		key := replayKeySender + "address:hash"
+		assert.True(t, c.checkReplay(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil), key, c.replayTTL()))
+
+		w := httptest.NewRecorder()
+		assert.False(t, c.checkReplay(w, httptest.NewRequest(http.MethodPost, "/", nil), key, c.replayTTL()))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, ErrorReplayedRequest, errorCode(t, w))
+
+		// The key expires after the skew window (the dt is rejected by then)
+		clock.Add(c.replayTTL())
+		assert.True(t, c.checkReplay(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil), key, c.replayTTL()))
+	})
+
+	t.Run("missing store", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		c.ReplayProtectionEnabled = true
+
+		w := httptest.NewRecorder()
+		assert.False(t, c.checkReplay(w, httptest.NewRequest(http.MethodPost, "/", nil), "key", time.Minute))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, ErrorFindingPaymail, errorCode(t, w))
+		assert.NotPanics(t, func() { c.forgetReplay(context.Background(), "key") })
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithReplayProtection(new(mockReplayStore)))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		assert.False(t, c.checkReplay(w, httptest.NewRequest(http.MethodPost, "/", nil), "key", time.Minute))
+		assert.Equal(t, http.StatusExpectationFailed, w.Code)
+	})
+}
+
+// TestConfiguration_resolveAddress_replay will test the replay protection in resolveAddress()
+func TestConfiguration_resolveAddress_replay(t *testing.T) {
+	t.Parallel()
+
+	// newConfig will return a configuration with sender validation (the sender's pubKey is not fetched)
+	newConfig := func(t *testing.T, provider PaymailServiceProvider) *Configuration {
+		c, err := NewConfig(
+			provider,
+			WithDomain("test.com"),
+			WithSenderValidation(),
+			WithGenericCapabilities(),
+			WithTimestampValidator(paymail.NewTimestampValidator(
+				paymail.WithTimestampClock(func() time.Time { return time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC) }),
+			)),
+			WithReplayProtection(nil),
+		)
+		require.NoError(t, err)
+		c.senderPubKey = func(_ context.Context, _ string) (*bec.PublicKey, error) {
+			pubKey, err := bitcoin.PubKeyFromPrivateKeyString(testBeefPrivateKey, true)
+			if err != nil {
+				return nil, err
+			}
+			return bitcoin.PubKeyFromString(pubKey)
+		}
+		return c
+	}
+
+	// body will return a signed sender request body
+	body := func(t *testing.T) string {
+		senderRequest := &paymail.SenderRequest{
+			Amount:       1000,
+			Dt:           "2020-04-09T16:08:06.419Z",
+			Purpose:      "pay for the coffee",
+			SenderHandle: "satchmo@test.com",
+			SenderName:   "Satchmo",
+		}
+		var err error
+		senderRequest.Signature, err = bitcoin.SignMessage(testBeefPrivateKey, senderRequest.Message(), true)
+		require.NoError(t, err)
+		b, err := json.Marshal(senderRequest)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("replayed sender request is rejected", func(t *testing.T) {
+		handler := Handlers(newConfig(t, new(mockApprovalProvider)))
+		payload := body(t)
+
+		w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com", payload)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = serveRequest(handler, http.MethodPost, "/address/mrz@test.com", payload)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, ErrorReplayedRequest, errorCode(t, w))
+	})
+
+	t.Run("failed resolution can be sent again", func(t *testing.T) {
+		handler := Handlers(newConfig(t, &mockResolutionFailureProvider{failures: 1}))
+		payload := body(t)
+
+		w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com", payload)
+		require.Equal(t, http.StatusExpectationFailed, w.Code)
+		assert.Equal(t, ErrorScript, errorCode(t, w))
+
+		w = serveRequest(handler, http.MethodPost, "/address/mrz@test.com", payload)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serveRequest(handler, http.MethodPost, "/address/mrz@test.com", payload)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("unknown paymail can be sent again", func(t *testing.T) {
+		handler := Handlers(newConfig(t, new(mockServiceProvider)))
+		payload := body(t)
+
+		for i := 0; i < 2; i++ {
+			w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com", payload)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, ErrorPaymailNotFound, errorCode(t, w))
+		}
+	})
+}
+
+// TestConfiguration_p2pReceiveTx_replay will test the replay protection in p2pReceiveTx()
+func TestConfiguration_p2pReceiveTx_replay(t *testing.T) {
+	t.Parallel()
+
+	// body will return a signed request body for the subject transaction (the signature is altered if needed)
+	body := func(t *testing.T, beef *paymail.DecodedBEEF, altered bool) string {
+		subject := beef.SubjectTx()
+		signature, err := bitcoin.SignMessage(testBeefPrivateKey, subject.TxID(), true)
+		require.NoError(t, err)
+		if altered {
+			signature = alterSignature(t, signature)
+		}
+		pubKey, err := bitcoin.PubKeyFromPrivateKeyString(testBeefPrivateKey, true)
+		require.NoError(t, err)
+
+		b, err := json.Marshal(&paymail.P2PTransaction{
+			Hex: subject.String(),
+			MetaData: &paymail.P2PMetaData{
+				Note:      "signed payment",
+				PubKey:    pubKey,
+				Sender:    "satchmo@test.com",
+				Signature: signature,
+			},
+			Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("replayed transaction is rejected", func(t *testing.T) {
+		beef, provider := newTestBeef(t)
+		c, err := NewConfig(provider, WithDomain("test.com"), WithP2PCapabilities(), WithReplayProtection(nil))
+		require.NoError(t, err)
+		handler := Handlers(c)
+		payload := body(t, beef, false)
+
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", payload)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", payload)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, ErrorReplayedRequest, errorCode(t, w))
+	})
+
+	t.Run("replay with an altered signature is rejected", func(t *testing.T) {
+		beef, provider := newTestBeef(t)
+		c, err := NewConfig(provider, WithDomain("test.com"), WithP2PCapabilities(), WithReplayProtection(nil))
+		require.NoError(t, err)
+		handler := Handlers(c)
+
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef, false))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// The altered signature is still valid (same decoded signature)
+		w = serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", body(t, beef, true))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, ErrorReplayedRequest, errorCode(t, w))
+	})
+
+	t.Run("failed transaction can be sent again", func(t *testing.T) {
+		beef, provider := newTestBeef(t)
+		c, err := NewConfig(
+			&mockRecordFailureProvider{mockBeefProvider: *provider, failures: 1},
+			WithDomain("test.com"), WithP2PCapabilities(), WithReplayProtection(nil),
+		)
+		require.NoError(t, err)
+		handler := Handlers(c)
+		payload := body(t, beef, false)
+
+		w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", payload)
+		require.Equal(t, http.StatusExpectationFailed, w.Code)
+		assert.Equal(t, ErrorRecordingTx, errorCode(t, w))
+
+		w = serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", payload)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", payload)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("replay protection disabled", func(t *testing.T) {
+		beef, provider := newTestBeef(t)
+		c, err := NewConfig(provider, WithDomain("test.com"), WithP2PCapabilities())
+		require.NoError(t, err)
+		handler := Handlers(c)
+		payload := body(t, beef, false)
+
+		for i := 0; i < 2; i++ {
+			w := serveRequest(handler, http.MethodPost, "/receive-transaction/mrz@test.com", payload)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+	})
+}
@@ -1,7 +1,7 @@
 package server
 
 import (
-	"net"
+	"context"
 	"net/http"
 	"time"
 
@@ -21,7 +21,8 @@ Incoming Data Object Example:
     "dt": "2020-04-09T16:08:06.419Z",
     "amount": 551,
     "purpose": "message to receiver",
-	"signature": "SIGNATURE-IF-REQUIRED-IN-CONFIG"
+	"signature": "SIGNATURE-IF-REQUIRED-IN-CONFIG",
+    "approvalId": "APPROVAL-ID-IF-REQUIRED-IN-CONFIG"
 }
 */
 
@@ -47,6 +48,7 @@ func (c *Configuration) resolveAddress(w http.ResponseWriter, req *http.Request,
 	// Start the SenderRequest
 	senderRequest := &paymail.SenderRequest{
 		Amount:       params.GetUint64("amount"),
+		ApprovalID:   params.GetString("approvalId"),
 		Dt:           params.GetString("dt"),
 		Purpose:      params.GetString("purpose"),
 		SenderHandle: params.GetString("senderHandle"),
@@ -54,6 +56,58 @@ func (c *Configuration) resolveAddress(w http.ResponseWriter, req *http.Request,
 		Signature:    params.GetString("signature"),
 	}
 
+	// Validate the sender request (fields, timestamp & signature)
+	replayKey, ok := c.validateSenderRequest(w, req, senderRequest)
+	if !ok {
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.ResolveAddress = senderRequest
+
+	// Get from the data layer (not resolved, the sender can try again)
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		c.forgetReplay(req.Context(), replayKey)
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		c.forgetReplay(req.Context(), replayKey)
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Check the payment approval (if receiver approvals are enabled)
+	if !c.checkPaymentApproval(w, req, alias, domain, senderRequest.ApprovalID, senderRequest.Amount, md) {
+		c.forgetReplay(req.Context(), replayKey)
+		return
+	}
+
+	// Get the resolution information
+	var response *paymail.ResolutionPayload
+	if response, err = c.actions.CreateAddressResolutionResponse(
+		req.Context(), alias, domain, c.SenderValidationEnabled, md,
+	); err != nil {
+		c.forgetReplay(req.Context(), replayKey)
+		ErrorResponse(w, req, ErrorScript, "error creating output script: "+err.Error(), http.StatusExpectationFailed)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+}
+
+// validateSenderRequest will check the required fields, the timestamp & the signature (if sender validation
+// is enabled) of the sender request, the error response is sent if the request is invalid
+//
+// A signed request is only accepted once if replay protection is enabled (see: WithReplayProtection()),
+// the stored replay key is returned so the caller can forget it if the request fails afterwards
+//
+// Specs: http://bsvalias.org/04-02-sender-validation.html
+func (c *Configuration) validateSenderRequest(w http.ResponseWriter, req *http.Request,
+	senderRequest *paymail.SenderRequest) (replayKey string, ok bool) {
+
 	// Check for required fields
 	if len(senderRequest.SenderHandle) == 0 {
 		ErrorResponse(w, req, ErrorInvalidSenderHandle, "senderHandle is empty", http.StatusBadRequest)
@@ -64,7 +118,7 @@ func (c *Configuration) resolveAddress(w http.ResponseWriter, req *http.Request,
 	}
 
 	// Validate the timestamp
-	if err := paymail.ValidateTimestamp(senderRequest.Dt); err != nil {
+	if err := c.TimestampValidator.Validate(senderRequest.Dt); err != nil {
 		ErrorResponse(w, req, ErrorInvalidDt, "invalid dt: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -80,7 +134,7 @@ func (c *Configuration) resolveAddress(w http.ResponseWriter, req *http.Request,
 		if len(senderRequest.Signature) > 0 {
 
 			// Get the pubKey from the corresponding sender paymail address
-			senderPubKey, err := getSenderPubKey(senderRequest.SenderHandle)
+			senderPubKey, err := c.getSenderPubKey(req.Context(), senderRequest.SenderHandle)
 			if err != nil {
 				ErrorResponse(w, req, ErrorInvalidSenderHandle, "invalid senderHandle: "+err.Error(), http.StatusBadRequest)
 				return
@@ -98,44 +152,33 @@ func (c *Configuration) resolveAddress(w http.ResponseWriter, req *http.Request,
 				ErrorResponse(w, req, ErrorInvalidSignature, "invalid signature: "+err.Error(), http.StatusBadRequest)
 				return
 			}
+
+			// Reject the signed request if it was already processed (replay protection)
+			key := replaySenderKey(rawAddress.AddressString, senderRequest)
+			if !c.checkReplay(w, req, key, c.replayTTL()) {
+				return
+			}
+			replayKey = key
 		} else {
 			ErrorResponse(w, req, ErrorInvalidSignature, "missing required signature", http.StatusBadRequest)
 			return
 		}
 	}
 
-	// Create the metadata struct
-	md := CreateMetadata(req, alias, domain, "")
-	md.ResolveAddress = senderRequest
+	ok = true
+	return
+}
 
-	// Get from the data layer
-	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
-	if err != nil {
-		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
-		return
-	} else if foundPaymail == nil {
-		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
-		return
+// getSenderPubKey will fetch the pubKey of the sender handle (default: a PKI request, see: getSenderPubKey())
+func (c *Configuration) getSenderPubKey(ctx context.Context, senderPaymailAddress string) (*bec.PublicKey, error) {
+	if c.senderPubKey != nil {
+		return c.senderPubKey(ctx, senderPaymailAddress)
 	}
-
-	// Get the resolution information
-	var response *paymail.ResolutionPayload
-	if response, err = c.actions.CreateAddressResolutionResponse(
-		req.Context(), alias, domain, c.SenderValidationEnabled, md,
-	); err != nil {
-		ErrorResponse(w, req, ErrorScript, "error creating output script: "+err.Error(), http.StatusExpectationFailed)
-		return
-	}
-
-	// Return the response
-	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+	return getSenderPubKey(ctx, senderPaymailAddress)
 }
 
 // getSenderPubKey will fetch the pubKey from a PKI request for the sender handle
-func getSenderPubKey(senderPaymailAddress string) (*bec.PublicKey, error) {
-
-	// Sanitize and break apart
-	alias, domain, _ := paymail.SanitizePaymail(senderPaymailAddress)
+func getSenderPubKey(ctx context.Context, senderPaymailAddress string) (*bec.PublicKey, error) {
 
 	// Load the client
 	client, err := paymail.NewClient(paymail.WithHTTPTimeout(15 * time.Second))
@@ -143,34 +186,14 @@ func getSenderPubKey(senderPaymailAddress string) (*bec.PublicKey, error) {
 		return nil, err
 	}
 
-	// Get the SRV record
-	var srv *net.SRV
-	if srv, err = client.GetSRVRecord(
-		paymail.DefaultServiceName, paymail.DefaultProtocol, domain,
-	); err != nil {
-		return nil, err
-	}
-
-	// Get the capabilities
-	// This is required first to get the corresponding PKI endpoint url
-	var capabilities *paymail.CapabilitiesResponse
-	if capabilities, err = client.GetCapabilities(
-		srv.Target, paymail.DefaultPort,
-	); err != nil {
-		return nil, err
-	}
-
-	// Extract the PKI URL from the capabilities response
-	pkiURL := capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate)
-
-	// Get the actual PKI
-	var pki *paymail.PKIResponse
-	if pki, err = client.GetPKI(
-		pkiURL, alias, domain,
+	// Discover the host & capabilities, then get the PKI
+	var result *paymail.ResolveResult
+	if result, err = client.ResolveContext(
+		ctx, senderPaymailAddress, &paymail.ResolveRequest{Operation: paymail.ResolveOperationPKI},
 	); err != nil {
 		return nil, err
 	}
 
 	// Convert the string pubKey to a bec.PubKey
-	return bitcoin.PubKeyFromString(pki.PubKey)
+	return bitcoin.PubKeyFromString(result.PKI.PubKey)
 }
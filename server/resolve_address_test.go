@@ -1,9 +1,14 @@
 package server
 
 import (
+	"context"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
 )
 
 // Test_getSenderPubKey will test the method getSenderPubKey()
@@ -11,14 +16,79 @@ func Test_getSenderPubKey(t *testing.T) {
 	// todo: this needs proper mocking
 
 	t.Run("error - bad domain", func(t *testing.T) {
-		key, err := getSenderPubKey("bad@domain.com")
+		key, err := getSenderPubKey(context.Background(), "bad@domain.com")
+		require.Error(t, err)
+		require.Nil(t, key)
+	})
+
+	t.Run("error - canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		key, err := getSenderPubKey(ctx, "mrzz@handcash.io")
 		require.Error(t, err)
 		require.Nil(t, key)
 	})
 
 	t.Run("valid - good paymail", func(t *testing.T) {
-		key, err := getSenderPubKey("mrzz@handcash.io")
+		key, err := getSenderPubKey(context.Background(), "mrzz@handcash.io")
 		require.NoError(t, err)
 		require.NotNil(t, key)
 	})
 }
+
+// TestWithTimestampValidator will test the method WithTimestampValidator()
+func TestWithTimestampValidator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default validator", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		require.NotNil(t, c.TimestampValidator)
+		past, future := c.TimestampValidator.Skew()
+		assert.Equal(t, paymail.DefaultTimestampSkew, past)
+		assert.Equal(t, paymail.DefaultTimestampSkew, future)
+	})
+
+	t.Run("nil validator is ignored", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithTimestampValidator(nil))
+		require.NoError(t, err)
+		require.NotNil(t, c.TimestampValidator)
+	})
+}
+
+// TestConfiguration_resolveAddress_timestamp will test the timestamp validation in resolveAddress()
+func TestConfiguration_resolveAddress_timestamp(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(
+		new(mockServiceProvider),
+		WithDomain("test.com"),
+		WithGenericCapabilities(),
+		WithTimestampValidator(paymail.NewTimestampValidator(
+			paymail.WithTimestampClock(func() time.Time { return time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC) }),
+			paymail.WithTimestampSkew(10*time.Minute, time.Minute),
+		)),
+	)
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	t.Run("within the skew (paymail not found)", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com",
+			`{"senderHandle":"satchmo@test.com","dt":"2020-04-09T16:00:06.419"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ErrorPaymailNotFound, errorCode(t, w))
+	})
+
+	t.Run("too far in the past", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com",
+			`{"senderHandle":"satchmo@test.com","dt":"2020-04-09T15:50:06Z"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidDt, errorCode(t, w))
+	})
+
+	t.Run("too far in the future", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/address/mrz@test.com",
+			`{"senderHandle":"satchmo@test.com","dt":"2020-04-09T16:10:06Z"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorInvalidDt, errorCode(t, w))
+	})
+}
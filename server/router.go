@@ -71,9 +71,9 @@ func (c *Configuration) registerBasicRoutes(router *apirouter.Router) {
 // registerPaymailRoutes will register all paymail related routes
 func (c *Configuration) registerPaymailRoutes(router *apirouter.Router) {
 
-	// Capabilities (service discovery)
+	// Capabilities (service discovery), the network suffix is added (IE: /.well-known/bsvalias-testnet)
 	router.HTTPRouter.GET(
-		"/.well-known/"+c.ServiceName,
+		"/.well-known/"+c.ServiceName+c.Network.URLSuffix(),
 		router.Request(c.showCapabilities),
 	)
 
@@ -112,4 +112,52 @@ func (c *Configuration) registerPaymailRoutes(router *apirouter.Router) {
 		"/"+c.APIVersion+"/"+c.ServiceName+"/receive-transaction/:paymailAddress",
 		router.Request(c.p2pReceiveTx),
 	)
+
+	// P2P Destination with tokens request (returns output & reference)
+	if c.TokenDestinationsEnabled {
+		router.HTTPRouter.POST(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/p2p-payment-destination-token/:paymailAddress",
+			router.Request(c.p2pTokenDestination),
+		)
+	}
+
+	// Receiver Approvals (submit a payment for approval & poll the status)
+	if c.ReceiverApprovalsEnabled {
+		router.HTTPRouter.POST(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/approvals/:paymailAddress",
+			router.Request(c.submitPaymentApproval),
+		)
+		router.HTTPRouter.GET(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/approvals/:paymailAddress/:approvalId",
+			router.Request(c.showPaymentApproval),
+		)
+	}
+
+	// PIKE (contact invite & outputs derived from the identity keys)
+	if c.PikeEnabled {
+		router.HTTPRouter.POST(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/contact/invite/:paymailAddress",
+			router.Request(c.pikeInvite),
+		)
+		router.HTTPRouter.POST(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/pike/outputs/:paymailAddress",
+			router.Request(c.pikeOutputs),
+		)
+	}
+
+	// SFP (asset information, build & authorise the token actions)
+	if c.SFPEnabled {
+		router.HTTPRouter.GET(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/asset/:paymailAddress",
+			router.Request(c.sfpAssetInformation),
+		)
+		router.HTTPRouter.POST(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/sfp/authorise/:paymailAddress",
+			router.Request(c.sfpAuthorise),
+		)
+		router.HTTPRouter.POST(
+			"/"+c.APIVersion+"/"+c.ServiceName+"/sfp/build/:paymailAddress",
+			router.Request(c.sfpBuild),
+		)
+	}
 }
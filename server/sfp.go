@@ -0,0 +1,211 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/tonicpow/go-paymail"
+)
+
+// Capability paths for SFP (the asset paymail, or the paymail of the token owner)
+const (
+	SFPAssetInformationPath = "/asset/{alias}@{domain.tld}"
+	SFPAuthorisePath        = "/sfp/authorise/{alias}@{domain.tld}"
+	SFPBuildPath            = "/sfp/build/{alias}@{domain.tld}"
+)
+
+// sfpAssetInformation will return the information of the tokenised asset (the paymail is the asset)
+//
+// Specs: https://docs.moneybutton.com/docs/sfp/paymail-08-asset-information.html
+func (c *Configuration) sfpAssetInformation(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Get from the data layer
+	asset, err := c.actions.(SFPProvider).GetAssetInformation(
+		req.Context(), alias, domain, CreateMetadata(req, alias, domain, ""),
+	)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if asset == nil {
+		ErrorResponse(w, req, ErrorPaymailNotFound, "asset not found", http.StatusNotFound)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, asset)
+}
+
+/*
+Incoming Data Object Example:
+{
+  "action": "transfer",
+  "asset": "usdt@issuer.com",
+  "amount": 100,
+  "outputs": [{"script": "76a914...88ac", "satoshis": 100}],
+  "purpose": "invoice #1234"
+}
+*/
+
+// sfpBuild will build the transaction for the token action of the owner (the paymail)
+//
+// Specs: https://docs.moneybutton.com/docs/sfp/paymail-09-sfp-build.html
+func (c *Configuration) sfpBuild(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Start the SFPBuildRequest
+	buildRequest := &paymail.SFPBuildRequest{
+		Action:  params.GetString("action"),
+		Amount:  params.GetUint64("amount"),
+		Asset:   params.GetString("asset"),
+		Outputs: getOutputs(params.Get("outputs")),
+		Purpose: params.GetString("purpose"),
+	}
+
+	// Check for required fields
+	if err := buildRequest.Validate(); err != nil {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.SFPBuild = buildRequest
+
+	// Get from the data layer
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Build the action
+	var response *paymail.SFPBuildPayload
+	if response, err = c.actions.(SFPProvider).BuildSFPAction(
+		req.Context(), alias, domain, buildRequest, md,
+	); err != nil {
+		ErrorResponse(w, req, ErrorScript, "error building action: "+err.Error(), http.StatusExpectationFailed)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+}
+
+/*
+Incoming Data Object Example:
+{
+  "hex": "01000000...",
+  "reference": "someRefId"
+}
+*/
+
+// sfpAuthorise will authorise (sign the token inputs of) the transaction from the build action
+//
+// Specs: https://docs.moneybutton.com/docs/sfp/paymail-10-sfp-authorise.html
+func (c *Configuration) sfpAuthorise(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the params & paymail address submitted via URL request
+	params := apirouter.GetParams(req)
+	incomingPaymail := params.GetString("paymailAddress")
+
+	// Parse, sanitize and basic validation
+	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
+	if len(paymailAddress) == 0 {
+		ErrorResponse(w, req, ErrorInvalidParameter, "invalid paymail: "+incomingPaymail, http.StatusBadRequest)
+		return
+	} else if !c.IsAllowedDomain(domain) {
+		ErrorResponse(w, req, ErrorUnknownDomain, "domain unknown: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	// Start the SFPAuthoriseRequest
+	authoriseRequest := &paymail.SFPAuthoriseRequest{
+		Hex:       params.GetString("hex"),
+		Reference: params.GetString("reference"),
+	}
+
+	// Check for required fields
+	if len(authoriseRequest.Hex) == 0 {
+		ErrorResponse(w, req, ErrorMissingHex, "missing parameter: hex", http.StatusBadRequest)
+		return
+	} else if len(authoriseRequest.Reference) == 0 {
+		ErrorResponse(w, req, ErrorMissingReference, "missing parameter: reference", http.StatusBadRequest)
+		return
+	}
+
+	// Create the metadata struct
+	md := CreateMetadata(req, alias, domain, "")
+	md.SFPAuthorise = authoriseRequest
+
+	// Get from the data layer
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		ErrorResponse(w, req, ErrorFindingPaymail, err.Error(), http.StatusExpectationFailed)
+		return
+	} else if foundPaymail == nil {
+		ErrorResponse(w, req, ErrorPaymailNotFound, "paymail not found", http.StatusNotFound)
+		return
+	}
+
+	// Authorise the action
+	var response *paymail.SFPAuthorisePayload
+	if response, err = c.actions.(SFPProvider).AuthoriseSFPAction(
+		req.Context(), alias, domain, authoriseRequest, md,
+	); err != nil {
+		ErrorResponse(w, req, ErrorScript, "error authorising action: "+err.Error(), http.StatusExpectationFailed)
+		return
+	}
+
+	// Return the response
+	apirouter.ReturnResponse(w, req, http.StatusOK, response)
+}
+
+// getOutputs will convert the JSON outputs ([{"script": "", "satoshis": 0}]) into payment outputs
+func getOutputs(value interface{}, _ bool) (outputs []*paymail.PaymentOutput) {
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		output, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paymentOutput := &paymail.PaymentOutput{}
+		paymentOutput.Address, _ = output["address"].(string)
+		paymentOutput.Script, _ = output["script"].(string)
+		if satoshis, isNumber := output["satoshis"].(float64); isNumber && satoshis > 0 {
+			paymentOutput.Satoshis = uint64(satoshis)
+		}
+		outputs = append(outputs, paymentOutput)
+	}
+	return
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+)
+
+// TestWithSFP will test the method WithSFP()
+func TestWithSFP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capabilities are added", func(t *testing.T) {
+		c, err := NewConfig(new(mockSFPProvider), WithDomain("test.com"), WithSFP())
+		require.NoError(t, err)
+		assert.True(t, c.SFPEnabled)
+		assert.Equal(t, SFPAssetInformationPath, c.Capabilities.Capabilities[paymail.BRFCSFPAssetInformation])
+		assert.Equal(t, SFPAuthorisePath, c.Capabilities.Capabilities[paymail.BRFCSFPAuthoriseAction])
+		assert.Equal(t, SFPBuildPath, c.Capabilities.Capabilities[paymail.BRFCSFPBuildAction])
+	})
+
+	t.Run("provider does not support sfp", func(t *testing.T) {
+		c, err := NewConfig(new(mockServiceProvider), WithDomain("test.com"), WithSFP())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrSFPProviderMissing)
+		assert.Nil(t, c)
+	})
+}
+
+// TestConfiguration_sfpAssetInformation will test the method sfpAssetInformation()
+func TestConfiguration_sfpAssetInformation(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(new(mockSFPProvider), WithDomain("test.com"), WithSFP())
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	t.Run("valid request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodGet, "/asset/usdt@test.com", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		asset := &paymail.SFPAssetPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), asset))
+		assert.Equal(t, "usdt@test.com", asset.Asset)
+		assert.Equal(t, "USDT", asset.Symbol)
+		assert.NotEmpty(t, asset.TokenID)
+	})
+
+	t.Run("asset not found", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodGet, "/asset/mrz@test.com", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ErrorPaymailNotFound, errorCode(t, w))
+	})
+
+	t.Run("unknown domain", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodGet, "/asset/usdt@unknown.com", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorUnknownDomain, errorCode(t, w))
+	})
+
+	t.Run("route is not registered if disabled", func(t *testing.T) {
+		disabled, err := NewConfig(new(mockSFPProvider), WithDomain("test.com"), WithBasicRoutes())
+		require.NoError(t, err)
+		w := serveRequest(Handlers(disabled), http.MethodGet, "/asset/usdt@test.com", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// TestConfiguration_sfpBuild will test the method sfpBuild()
+func TestConfiguration_sfpBuild(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(new(mockSFPProvider), WithDomain("test.com"), WithSFP())
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	t.Run("valid request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/sfp/build/mrz@test.com",
+			`{"action":"transfer","amount":10,"asset":"usdt@test.com","outputs":[{"script":"006a0474657374","satoshis":10}]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		build := &paymail.SFPBuildPayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), build))
+		assert.NotEmpty(t, build.Hex)
+		assert.NotEmpty(t, build.Reference)
+	})
+
+	var tests = []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		errorCode    string
+	}{
+		{"missing action", "mrz@test.com", `{"amount":10,"asset":"usdt@test.com"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"missing amount", "mrz@test.com", `{"action":"redeem","asset":"usdt@test.com"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"invalid asset", "mrz@test.com", `{"action":"redeem","amount":10,"asset":"usdt"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"transfer without outputs", "mrz@test.com", `{"action":"transfer","amount":10,"asset":"usdt@test.com"}`, http.StatusBadRequest, ErrorInvalidParameter},
+		{"unknown domain", "mrz@unknown.com", `{"action":"redeem","amount":10,"asset":"usdt@test.com"}`, http.StatusBadRequest, ErrorUnknownDomain},
+		{"provider error", "mrz@test.com", `{"action":"redeem","amount":10,"asset":"usdt@test.com"}`, http.StatusExpectationFailed, ErrorScript},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(handler, http.MethodPost, "/sfp/build/"+test.path, test.body)
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, test.errorCode, errorCode(t, w))
+		})
+	}
+}
+
+// TestConfiguration_sfpAuthorise will test the method sfpAuthorise()
+func TestConfiguration_sfpAuthorise(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig(new(mockSFPProvider), WithDomain("test.com"), WithSFP())
+	require.NoError(t, err)
+	handler := Handlers(c)
+
+	t.Run("valid request", func(t *testing.T) {
+		w := serveRequest(handler, http.MethodPost, "/sfp/authorise/mrz@test.com",
+			`{"hex":"0100000001ffff","reference":"z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		authorised := &paymail.SFPAuthorisePayload{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), authorised))
+		assert.NotEmpty(t, authorised.TxID)
+	})
+
+	var tests = []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		errorCode    string
+	}{
+		{"missing hex", "mrz@test.com", `{"reference":"abc"}`, http.StatusBadRequest, ErrorMissingHex},
+		{"missing reference", "mrz@test.com", `{"hex":"0100000001ffff"}`, http.StatusBadRequest, ErrorMissingReference},
+		{"unknown domain", "mrz@unknown.com", `{"hex":"0100000001ffff","reference":"abc"}`, http.StatusBadRequest, ErrorUnknownDomain},
+		{"provider error", "mrz@test.com", `{"hex":"0100000001ffff","reference":"abc"}`, http.StatusExpectationFailed, ErrorScript},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serveRequest(handler, http.MethodPost, "/sfp/authorise/"+test.path, test.body)
+			assert.Equal(t, test.expectedCode, w.Code)
+			assert.Equal(t, test.errorCode, errorCode(t, w))
+		})
+	}
+}
+
+// Test_getOutputs will test the method getOutputs()
+func Test_getOutputs(t *testing.T) {
+	t.Parallel()
+
+	outputs := getOutputs([]interface{}{
+		map[string]interface{}{"script": "006a", "satoshis": float64(10)},
+		map[string]interface{}{"address": "1Kh...", "script": "76a9", "satoshis": float64(-1)},
+		"invalid",
+	}, true)
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "006a", outputs[0].Script)
+	assert.Equal(t, uint64(10), outputs[0].Satoshis)
+	assert.Equal(t, "1Kh...", outputs[1].Address)
+	assert.Equal(t, uint64(0), outputs[1].Satoshis)
+
+	assert.Empty(t, getOutputs(nil, false))
+}
@@ -0,0 +1,289 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SFP actions for the build request
+const (
+	SFPActionRedeem   = "redeem"   // Redeem the tokens with the issuer
+	SFPActionTransfer = "transfer" // Transfer the tokens to the outputs (IE: from GetP2PTokenPaymentDestination())
+)
+
+/*
+Example (asset information):
+{
+  "asset": "usdt@issuer.com",
+  "name": "Tether USD",
+  "symbol": "USDT",
+  "protocol": "SFP",
+  "tokenId": "<genesis txid>",
+  "issuer": "treasury@issuer.com",
+  "supply": 100000000
+}
+*/
+
+// SFPAssetResponse is the response from the GetSFPAssetInformation() request
+type SFPAssetResponse struct {
+	StandardResponse
+	SFPAssetPayload
+}
+
+// SFPAssetPayload is the information of a tokenised asset (the asset is identified by a paymail)
+type SFPAssetPayload struct {
+	Asset       string `json:"asset"`                 // The paymail of the asset (alias@domain.tld)
+	Decimals    uint8  `json:"decimals,omitempty"`    // Number of decimals for displaying the amount
+	Description string `json:"description,omitempty"` // Human-readable description of the asset
+	Image       string `json:"image,omitempty"`       // URL of an image for the asset
+	Issuer      string `json:"issuer,omitempty"`      // The paymail of the issuer
+	Name        string `json:"name"`                  // Name of the asset
+	Protocol    string `json:"protocol,omitempty"`    // Token protocol (IE: SFP, STAS)
+	Supply      uint64 `json:"supply,omitempty"`      // Total supply of tokens
+	Symbol      string `json:"symbol,omitempty"`      // Ticker symbol of the asset
+	TokenID     string `json:"tokenId"`               // ID of the token (IE: the genesis txid)
+}
+
+/*
+Example (build request):
+{
+  "action": "transfer",
+  "asset": "usdt@issuer.com",
+  "amount": 100,
+  "outputs": [{"script": "76a914...88ac", "satoshis": 100}],
+  "purpose": "invoice #1234"
+}
+*/
+
+// SFPBuildRequest is the request body for the SFP build action
+//
+// The paymail in the url is the owner of the tokens, the provider must authenticate the request
+type SFPBuildRequest struct {
+	Action  string           `json:"action"`            // The action to build (IE: transfer or redeem)
+	Amount  uint64           `json:"amount"`            // Amount of tokens
+	Asset   string           `json:"asset"`             // The paymail of the asset (alias@domain.tld)
+	Outputs []*PaymentOutput `json:"outputs,omitempty"` // Outputs receiving the tokens (required for a transfer)
+	Purpose string           `json:"purpose,omitempty"` // Human-readable description of the purpose of the action
+}
+
+// SFPBuildResponse is the response from the SFPBuildAction() request
+type SFPBuildResponse struct {
+	StandardResponse
+	SFPBuildPayload
+}
+
+// SFPBuildPayload is the transaction built by the provider, the sender funds & signs it,
+// then sends it back with SFPAuthoriseAction()
+type SFPBuildPayload struct {
+	Fee       uint64 `json:"fee,omitempty"` // Satoshis the sender has to add for the fee
+	Hex       string `json:"hex"`           // The transaction with the token inputs & outputs (hex)
+	Reference string `json:"reference"`     // Reference of the action (used for the authorise request)
+}
+
+// SFPAuthoriseRequest is the request body for the SFP authorise action
+type SFPAuthoriseRequest struct {
+	Hex       string `json:"hex"`       // The transaction from the build action, funded & signed by the sender (hex)
+	Reference string `json:"reference"` // Reference from the build action
+}
+
+// SFPAuthoriseResponse is the response from the SFPAuthoriseAction() request
+type SFPAuthoriseResponse struct {
+	StandardResponse
+	SFPAuthorisePayload
+}
+
+// SFPAuthorisePayload is the transaction authorised (token inputs signed) by the provider
+type SFPAuthorisePayload struct {
+	Hex       string `json:"hex"`       // The authorised transaction (hex)
+	Reference string `json:"reference"` // Reference of the action
+	TxID      string `json:"txid"`      // ID of the authorised transaction
+}
+
+// GetSFPAssetInformation will return the information of a tokenised asset (the asset is a paymail)
+//
+// Specs: https://docs.moneybutton.com/docs/paymail/paymail-08-asset-information.html
+func (c *Client) GetSFPAssetInformation(assetURL, alias, domain string) (*SFPAssetResponse, error) {
+	return c.GetSFPAssetInformationContext(context.Background(), assetURL, alias, domain)
+}
+
+// GetSFPAssetInformationContext is the same as GetSFPAssetInformation() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) GetSFPAssetInformationContext(ctx context.Context, assetURL, alias, domain string) (response *SFPAssetResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.GetSFPAssetInformationForAddress(ctx, assetURL, address)
+}
+
+// GetSFPAssetInformationForAddress is the same as GetSFPAssetInformationContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) GetSFPAssetInformationForAddress(ctx context.Context, assetURL string, address Address) (response *SFPAssetResponse, err error) {
+
+	// Require a valid url & paymail
+	if err = validateServiceAddress(assetURL, address); err != nil {
+		return
+	}
+
+	// Fire the GET request
+	reqURL := replaceAddress(assetURL, address)
+	var resp StandardResponse
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
+		err = newRequestError(StepSFPAssetInformation, reqURL, err)
+		return
+	}
+
+	// Decode the response
+	response = &SFPAssetResponse{StandardResponse: resp}
+	if err = decodeServiceResponse(StepSFPAssetInformation, reqURL, resp, response); err != nil {
+		return
+	}
+
+	// Check for a token id
+	if len(response.TokenID) == 0 {
+		err = newInvalidResponseError(StepSFPAssetInformation, reqURL, response.StatusCode, errors.New("missing a returned tokenId"))
+	}
+	return
+}
+
+// SFPBuildAction will request the provider of the token owner (alias@domain) to build the transaction
+// for the action, see: SFPAuthoriseAction()
+//
+// Specs: https://docs.moneybutton.com/docs/sfp/paymail-09-sfp-build.html
+func (c *Client) SFPBuildAction(buildURL, alias, domain string,
+	buildRequest *SFPBuildRequest) (*SFPBuildResponse, error) {
+	return c.SFPBuildActionContext(context.Background(), buildURL, alias, domain, buildRequest)
+}
+
+// SFPBuildActionContext is the same as SFPBuildAction() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) SFPBuildActionContext(ctx context.Context, buildURL, alias, domain string,
+	buildRequest *SFPBuildRequest) (response *SFPBuildResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.SFPBuildActionForAddress(ctx, buildURL, address, buildRequest)
+}
+
+// SFPBuildActionForAddress is the same as SFPBuildActionContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) SFPBuildActionForAddress(ctx context.Context, buildURL string, address Address,
+	buildRequest *SFPBuildRequest) (response *SFPBuildResponse, err error) {
+
+	// Require a valid url & paymail
+	if err = validateServiceAddress(buildURL, address); err != nil {
+		return
+	}
+
+	// Basic requirements for request
+	if err = buildRequest.Validate(); err != nil {
+		return
+	}
+
+	// Fire the POST request
+	reqURL := replaceAddress(buildURL, address)
+	var resp StandardResponse
+	if resp, err = c.postRequest(ctx, reqURL, buildRequest); err != nil {
+		err = newRequestError(StepSFPBuild, reqURL, err)
+		return
+	}
+
+	// Decode the response
+	response = &SFPBuildResponse{StandardResponse: resp}
+	if err = decodeServiceResponse(StepSFPBuild, reqURL, resp, response); err != nil {
+		return
+	}
+
+	// Check for a transaction & reference
+	if len(response.Hex) == 0 || len(response.Reference) == 0 {
+		err = newInvalidResponseError(StepSFPBuild, reqURL, response.StatusCode, errors.New("missing a returned hex or reference"))
+	}
+	return
+}
+
+// SFPAuthoriseAction will send the funded & signed transaction from SFPBuildAction() to the provider
+// of the token owner (alias@domain), which authorises (signs) the token inputs
+//
+// Specs: https://docs.moneybutton.com/docs/sfp/paymail-10-sfp-authorise.html
+func (c *Client) SFPAuthoriseAction(authoriseURL, alias, domain string,
+	authoriseRequest *SFPAuthoriseRequest) (*SFPAuthoriseResponse, error) {
+	return c.SFPAuthoriseActionContext(context.Background(), authoriseURL, alias, domain, authoriseRequest)
+}
+
+// SFPAuthoriseActionContext is the same as SFPAuthoriseAction() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) SFPAuthoriseActionContext(ctx context.Context, authoriseURL, alias, domain string,
+	authoriseRequest *SFPAuthoriseRequest) (response *SFPAuthoriseResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.SFPAuthoriseActionForAddress(ctx, authoriseURL, address, authoriseRequest)
+}
+
+// SFPAuthoriseActionForAddress is the same as SFPAuthoriseActionContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) SFPAuthoriseActionForAddress(ctx context.Context, authoriseURL string, address Address,
+	authoriseRequest *SFPAuthoriseRequest) (response *SFPAuthoriseResponse, err error) {
+
+	// Require a valid url & paymail
+	if err = validateServiceAddress(authoriseURL, address); err != nil {
+		return
+	}
+
+	// Basic requirements for request
+	if authoriseRequest == nil {
+		err = errors.New("authoriseRequest cannot be nil")
+		return
+	} else if len(authoriseRequest.Hex) == 0 {
+		err = errors.New("hex is required")
+		return
+	} else if len(authoriseRequest.Reference) == 0 {
+		err = errors.New("reference is required")
+		return
+	}
+
+	// Fire the POST request
+	reqURL := replaceAddress(authoriseURL, address)
+	var resp StandardResponse
+	if resp, err = c.postRequest(ctx, reqURL, authoriseRequest); err != nil {
+		err = newRequestError(StepSFPAuthorise, reqURL, err)
+		return
+	}
+
+	// Decode the response
+	response = &SFPAuthoriseResponse{StandardResponse: resp}
+	if err = decodeServiceResponse(StepSFPAuthorise, reqURL, resp, response); err != nil {
+		return
+	}
+
+	// Check for a transaction
+	if len(response.Hex) == 0 || len(response.TxID) == 0 {
+		err = newInvalidResponseError(StepSFPAuthorise, reqURL, response.StatusCode, errors.New("missing a returned hex or txid"))
+	}
+	return
+}
+
+// Validate will check the required fields of the build request
+func (r *SFPBuildRequest) Validate() error {
+	if r == nil {
+		return errors.New("buildRequest cannot be nil")
+	} else if len(r.Action) == 0 {
+		return errors.New("action is required")
+	} else if r.Amount == 0 {
+		return errors.New("amount is required")
+	} else if err := ValidatePaymail(r.Asset); err != nil {
+		return fmt.Errorf("invalid asset: %w", err)
+	} else if r.Action == SFPActionTransfer && len(r.Outputs) == 0 {
+		return errors.New("outputs are required for a transfer")
+	}
+	return nil
+}
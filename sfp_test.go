@@ -0,0 +1,357 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAsset   = "usdt@" + testDomain
+	testSFPHex  = "0100000001ffff"
+	testSFPTxID = "9e8f6f3e2a0e83b5a2b7ab5b1f7e8b6c0c1c7e4d7b7a0c0f9e8d7c6b5a4f3e2d"
+)
+
+// mockSFPAssetInformation will mock the asset information response
+func mockSFPAssetInformation(statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, testServerURL+"asset/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			statusCode,
+			`{"asset": "`+testAlias+`@`+testDomain+`","name": "Test Token","symbol": "TEST","protocol": "SFP","tokenId": "`+testTokenID+`","supply": 1000}`,
+		),
+	)
+}
+
+// mockSFPBuild will mock the build action response
+func mockSFPBuild(statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"sfp/build/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(statusCode, `{"hex": "`+testSFPHex+`","reference": "`+testReference+`","fee": 200}`),
+	)
+}
+
+// mockSFPAuthorise will mock the authorise action response
+func mockSFPAuthorise(statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"sfp/authorise/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(statusCode, `{"hex": "`+testSFPHex+`","reference": "`+testReference+`","txid": "`+testSFPTxID+`"}`),
+	)
+}
+
+// testSFPBuildRequest will return a basic transfer request
+func testSFPBuildRequest() *SFPBuildRequest {
+	return &SFPBuildRequest{
+		Action:  SFPActionTransfer,
+		Amount:  10,
+		Asset:   testAsset,
+		Outputs: []*PaymentOutput{{Satoshis: 10, Script: testTokenScript}},
+	}
+}
+
+// TestClient_GetSFPAssetInformation will test the method GetSFPAssetInformation()
+func TestClient_GetSFPAssetInformation(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	assetURL := testServerURL + "asset/{alias}@{domain.tld}"
+
+	t.Run("successful response", func(t *testing.T) {
+		mockSFPAssetInformation(http.StatusOK)
+		asset, err := client.GetSFPAssetInformation(assetURL, testAlias, testDomain)
+		require.NoError(t, err)
+		require.NotNil(t, asset)
+		assert.Equal(t, http.StatusOK, asset.StatusCode)
+		assert.Equal(t, testTokenID, asset.TokenID)
+		assert.Equal(t, "TEST", asset.Symbol)
+		assert.Equal(t, uint64(1000), asset.Supply)
+	})
+
+	t.Run("missing token id", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"asset/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"name": "Test Token"}`),
+		)
+		_, err := client.GetSFPAssetInformation(assetURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepSFPAssetInformation, providerErr.Step)
+	})
+
+	t.Run("asset not found", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"asset/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"asset not found"}`),
+		)
+		_, err := client.GetSFPAssetInformation(assetURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPaymailNotFound)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"asset/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"tokenId":}`),
+		)
+		_, err := client.GetSFPAssetInformation(assetURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockSFPAssetInformation(http.StatusOK)
+		asset, err := client.GetSFPAssetInformationContext(canceledContext(), assetURL, testAlias, testDomain)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, asset)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.GetSFPAssetInformation("", testAlias, testDomain)
+		require.Error(t, err)
+		_, err = client.GetSFPAssetInformation("http://"+testDomain+"/asset", testAlias, testDomain)
+		require.Error(t, err)
+		_, err = client.GetSFPAssetInformation(assetURL, "", testDomain)
+		require.Error(t, err)
+		_, err = client.GetSFPAssetInformation(assetURL, testAlias, "")
+		require.Error(t, err)
+	})
+}
+
+// TestClient_SFPBuildAction will test the method SFPBuildAction()
+func TestClient_SFPBuildAction(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	buildURL := testServerURL + "sfp/build/{alias}@{domain.tld}"
+
+	t.Run("successful response", func(t *testing.T) {
+		mockSFPBuild(http.StatusOK)
+		build, err := client.SFPBuildAction(buildURL, testAlias, testDomain, testSFPBuildRequest())
+		require.NoError(t, err)
+		require.NotNil(t, build)
+		assert.Equal(t, testSFPHex, build.Hex)
+		assert.Equal(t, testReference, build.Reference)
+		assert.Equal(t, uint64(200), build.Fee)
+	})
+
+	t.Run("build fields are sent", func(t *testing.T) {
+		var sent string
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"sfp/build/"+testAlias+"@"+testDomain,
+			func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				sent = string(body)
+				return httpmock.NewStringResponse(http.StatusOK, `{"hex":"`+testSFPHex+`","reference":"`+testReference+`"}`), nil
+			},
+		)
+		_, err := client.SFPBuildAction(buildURL, testAlias, testDomain, testSFPBuildRequest())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"action":"transfer","amount":10,"asset":"`+testAsset+`","outputs":[{"satoshis":10,"script":"`+testTokenScript+`"}]}`, sent)
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"sfp/build/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"hex":"`+testSFPHex+`"}`),
+		)
+		_, err := client.SFPBuildAction(buildURL, testAlias, testDomain, testSFPBuildRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepSFPBuild, providerErr.Step)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"sfp/build/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusBadRequest, `{"code":"invalid-parameter","message":"invalid parameter: amount is required"}`),
+		)
+		_, err := client.SFPBuildAction(buildURL, testAlias, testDomain, testSFPBuildRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockSFPBuild(http.StatusOK)
+		build, err := client.SFPBuildActionContext(canceledContext(), buildURL, testAlias, testDomain, testSFPBuildRequest())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, build)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.SFPBuildAction("", testAlias, testDomain, testSFPBuildRequest())
+		require.Error(t, err)
+		_, err = client.SFPBuildAction(buildURL, "", testDomain, testSFPBuildRequest())
+		require.Error(t, err)
+		_, err = client.SFPBuildAction(buildURL, testAlias, "", testSFPBuildRequest())
+		require.Error(t, err)
+		_, err = client.SFPBuildAction(buildURL, testAlias, testDomain, nil)
+		require.Error(t, err)
+	})
+}
+
+// TestSFPBuildRequest_Validate will test the method Validate()
+func TestSFPBuildRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name          string
+		request       *SFPBuildRequest
+		expectedError bool
+	}{
+		{"valid transfer", testSFPBuildRequest(), false},
+		{"valid redeem", &SFPBuildRequest{Action: SFPActionRedeem, Amount: 10, Asset: testAsset}, false},
+		{"nil request", nil, true},
+		{"missing action", &SFPBuildRequest{Amount: 10, Asset: testAsset}, true},
+		{"missing amount", &SFPBuildRequest{Action: SFPActionRedeem, Asset: testAsset}, true},
+		{"invalid asset", &SFPBuildRequest{Action: SFPActionRedeem, Amount: 10, Asset: "usdt"}, true},
+		{"transfer without outputs", &SFPBuildRequest{Action: SFPActionTransfer, Amount: 10, Asset: testAsset}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.request.Validate(); test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestClient_SFPAuthoriseAction will test the method SFPAuthoriseAction()
+func TestClient_SFPAuthoriseAction(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	client := newTestClient(t)
+	authoriseURL := testServerURL + "sfp/authorise/{alias}@{domain.tld}"
+	authoriseRequest := &SFPAuthoriseRequest{Hex: testSFPHex, Reference: testReference}
+
+	t.Run("successful response", func(t *testing.T) {
+		mockSFPAuthorise(http.StatusOK)
+		authorised, err := client.SFPAuthoriseAction(authoriseURL, testAlias, testDomain, authoriseRequest)
+		require.NoError(t, err)
+		require.NotNil(t, authorised)
+		assert.Equal(t, testSFPTxID, authorised.TxID)
+		assert.Equal(t, testReference, authorised.Reference)
+	})
+
+	t.Run("missing txid", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"sfp/authorise/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusOK, `{"hex":"`+testSFPHex+`"}`),
+		)
+		_, err := client.SFPAuthoriseAction(authoriseURL, testAlias, testDomain, authoriseRequest)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, StepSFPAuthorise, providerErr.Step)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockSFPAuthorise(http.StatusOK)
+		authorised, err := client.SFPAuthoriseActionContext(canceledContext(), authoriseURL, testAlias, testDomain, authoriseRequest)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, authorised)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := client.SFPAuthoriseAction("", testAlias, testDomain, authoriseRequest)
+		require.Error(t, err)
+		_, err = client.SFPAuthoriseAction(authoriseURL, testAlias, testDomain, nil)
+		require.Error(t, err)
+		_, err = client.SFPAuthoriseAction(authoriseURL, testAlias, testDomain, &SFPAuthoriseRequest{Reference: testReference})
+		require.Error(t, err)
+		_, err = client.SFPAuthoriseAction(authoriseURL, testAlias, testDomain, &SFPAuthoriseRequest{Hex: testSFPHex})
+		require.Error(t, err)
+	})
+}
+
+// ExampleClient_GetSFPAssetInformation example using GetSFPAssetInformation()
+func ExampleClient_GetSFPAssetInformation() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockSFPAssetInformation(http.StatusOK)
+
+	// Fire the request
+	asset, err := client.GetSFPAssetInformation(
+		testServerURL+"asset/{alias}@{domain.tld}",
+		testAlias,
+		testDomain,
+	)
+	if err != nil {
+		fmt.Printf("error occurred in GetSFPAssetInformation: %s", err.Error())
+		return
+	}
+	fmt.Printf("found asset: %s (%s)", asset.Name, asset.Symbol)
+	// Output:found asset: Test Token (TEST)
+}
+
+// ExampleClient_SFPBuildAction example using SFPBuildAction()
+func ExampleClient_SFPBuildAction() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockSFPBuild(http.StatusOK)
+
+	// Fire the request
+	build, err := client.SFPBuildAction(
+		testServerURL+"sfp/build/{alias}@{domain.tld}",
+		testAlias,
+		testDomain,
+		testSFPBuildRequest(),
+	)
+	if err != nil {
+		fmt.Printf("error occurred in SFPBuildAction: %s", err.Error())
+		return
+	}
+	fmt.Printf("build reference: %s", build.Reference)
+	// Output:build reference: z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7
+}
+
+// ExampleClient_SFPAuthoriseAction example using SFPAuthoriseAction()
+func ExampleClient_SFPAuthoriseAction() {
+	// Load the client
+	client := newTestClient(nil)
+
+	mockSFPAuthorise(http.StatusOK)
+
+	// Fire the request
+	authorised, err := client.SFPAuthoriseAction(
+		testServerURL+"sfp/authorise/{alias}@{domain.tld}",
+		testAlias,
+		testDomain,
+		&SFPAuthoriseRequest{Hex: testSFPHex, Reference: testReference},
+	)
+	if err != nil {
+		fmt.Printf("error occurred in SFPAuthoriseAction: %s", err.Error())
+		return
+	}
+	fmt.Printf("authorised tx: %s", authorised.TxID)
+	// Output:authorised tx: 9e8f6f3e2a0e83b5a2b7ab5b1f7e8b6c0c1c7e4d7b7a0c0f9e8d7c6b5a4f3e2d
+}
+
+// BenchmarkClient_GetSFPAssetInformation benchmarks the method GetSFPAssetInformation()
+func BenchmarkClient_GetSFPAssetInformation(b *testing.B) {
+	client := newTestClient(nil)
+	mockSFPAssetInformation(http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetSFPAssetInformation(testServerURL+"asset/{alias}@{domain.tld}", testAlias, testDomain)
+	}
+}
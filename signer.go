@@ -0,0 +1,227 @@
+package paymail
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-bt/v2/unlocker"
+)
+
+// Signer signs messages with a key that does not need to be in process memory (IE: HSM, KMS or a signing service)
+//
+// Used for the SenderRequest signature, the P2P metadata signature & server-side signatures
+type Signer interface {
+	// PubKey will return the public key (compressed, hex) used to verify SignMessage()
+	PubKey(ctx context.Context) (string, error)
+
+	// SignMessage will return the Bitcoin Signed Message signature (compressed key) of the message
+	SignMessage(ctx context.Context, message string) (string, error)
+}
+
+// keySigner is a TransactionSigner using a private key in memory
+type keySigner struct {
+	getter     *unlocker.Getter
+	privateKey string
+	pubKey     string
+}
+
+// NewPrivateKeySigner will return an in-memory TransactionSigner for the private key (hex),
+// every input must be a P2PKH output of that key
+func NewPrivateKeySigner(privateKey string) (TransactionSigner, error) {
+	key, err := bitcoin.PrivateKeyFromString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return newKeySigner(key), nil
+}
+
+// NewWIFSigner will return an in-memory TransactionSigner for the private key (WIF),
+// every input must be a P2PKH output of that key
+func NewWIFSigner(wif string) (TransactionSigner, error) {
+	key, err := bitcoin.WifToPrivateKey(wif)
+	if err != nil {
+		return nil, err
+	}
+	return newKeySigner(key), nil
+}
+
+// newKeySigner will return the signer for the private key
+func newKeySigner(key *bec.PrivateKey) *keySigner {
+	return &keySigner{
+		getter:     &unlocker.Getter{PrivateKey: key},
+		privateKey: hex.EncodeToString(key.Serialise()),
+		pubKey:     bitcoin.PubKeyFromPrivateKey(key, true),
+	}
+}
+
+// Unlocker will return the unlocker for the locking script
+func (k *keySigner) Unlocker(ctx context.Context, lockingScript *bscript.Script) (bt.Unlocker, error) {
+	return k.getter.Unlocker(ctx, lockingScript)
+}
+
+// PubKey will return the compressed public key
+func (k *keySigner) PubKey(_ context.Context) (string, error) {
+	return k.pubKey, nil
+}
+
+// SignMessage will sign the message using the private key
+func (k *keySigner) SignMessage(_ context.Context, message string) (string, error) {
+	return bitcoin.SignMessage(k.privateKey, message, true)
+}
+
+/*
+Remote signer protocol (JSON over HTTP):
+
+	POST {endpoint}/pubkey  {"key_id": "..."}                     => {"pubkey": "<hex>"}
+	POST {endpoint}/sign    {"key_id": "...", "message": "..."}  => {"signature": "<base64>"}
+
+Errors are returned with a non-200 status code and {"message": "..."}
+*/
+
+// Remote signer routes
+const (
+	RemoteSignerPubKeyPath = "/pubkey"
+	RemoteSignerSignPath   = "/sign"
+)
+
+// RemoteSignerRequest is the request body for the remote signer
+type RemoteSignerRequest struct {
+	KeyID   string `json:"key_id"`            // ID of the key at the signing service
+	Message string `json:"message,omitempty"` // Message to sign (sign only)
+}
+
+// RemoteSignerResponse is the response body from the remote signer
+type RemoteSignerResponse struct {
+	Message   string `json:"message,omitempty"`   // Error message
+	PubKey    string `json:"pubkey,omitempty"`    // Public key (compressed, hex)
+	Signature string `json:"signature,omitempty"` // Bitcoin Signed Message signature (base64)
+}
+
+// remoteSigner is a Signer using a remote signing service
+type remoteSigner struct {
+	endpoint   string
+	httpClient *resty.Client
+	keyID      string
+}
+
+// NewRemoteSigner will return a Signer that calls the signing service at the endpoint
+// (IE: https://signer.internal/v1) for the key id, see: NewSignerHandler() for the service side
+//
+// The remote signer only signs messages, it cannot sign transaction inputs (see: ErrSignerCannotSignInputs)
+// If no HTTP client is given, a default Resty client is used
+func NewRemoteSigner(endpoint, keyID string, httpClient *resty.Client) (Signer, error) {
+	if len(endpoint) == 0 {
+		return nil, errors.New("missing remote signer endpoint")
+	}
+	if httpClient == nil {
+		httpClient = resty.New().SetTimeout(defaultHTTPTimeout)
+	}
+	return &remoteSigner{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+		keyID:      keyID,
+	}, nil
+}
+
+// PubKey will return the public key from the signing service
+func (r *remoteSigner) PubKey(ctx context.Context) (string, error) {
+	response, err := r.request(ctx, RemoteSignerPubKeyPath, &RemoteSignerRequest{KeyID: r.keyID})
+	if err != nil {
+		return "", err
+	} else if len(response.PubKey) == 0 {
+		return "", errors.New("remote signer returned an empty pubkey")
+	}
+	return response.PubKey, nil
+}
+
+// SignMessage will return the signature of the message from the signing service
+func (r *remoteSigner) SignMessage(ctx context.Context, message string) (string, error) {
+	response, err := r.request(ctx, RemoteSignerSignPath, &RemoteSignerRequest{KeyID: r.keyID, Message: message})
+	if err != nil {
+		return "", err
+	} else if len(response.Signature) == 0 {
+		return "", errors.New("remote signer returned an empty signature")
+	}
+	return response.Signature, nil
+}
+
+// request will fire the request to the signing service
+func (r *remoteSigner) request(ctx context.Context, path string,
+	data *RemoteSignerRequest) (response *RemoteSignerResponse, err error) {
+
+	var resp *resty.Response
+	if resp, err = r.httpClient.R().SetContext(ctx).SetBody(data).
+		SetHeader("Content-Type", "application/json").Post(r.endpoint + path); err != nil {
+		return
+	}
+
+	response = &RemoteSignerResponse{}
+	if err = json.Unmarshal(resp.Body(), response); err != nil {
+		response = nil
+		err = fmt.Errorf("invalid remote signer response (status %d): %w", resp.StatusCode(), err)
+		return
+	}
+	if resp.StatusCode() != http.StatusOK {
+		err = fmt.Errorf("remote signer error (status %d): %s", resp.StatusCode(), response.Message)
+		response = nil
+	}
+	return
+}
+
+// NewSignerHandler will return an http.Handler serving the remote signer protocol using the signers (by key id)
+//
+// Useful to fake a signing service locally (IE: httptest.NewServer(NewSignerHandler(...)))
+func NewSignerHandler(signers map[string]Signer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		// Parse the request
+		data := &RemoteSignerRequest{}
+		if req.Method != http.MethodPost {
+			writeSignerResponse(w, http.StatusMethodNotAllowed, &RemoteSignerResponse{Message: "method not allowed"})
+			return
+		} else if err := json.NewDecoder(req.Body).Decode(data); err != nil {
+			writeSignerResponse(w, http.StatusBadRequest, &RemoteSignerResponse{Message: "invalid request: " + err.Error()})
+			return
+		}
+		signer, ok := signers[data.KeyID]
+		if !ok {
+			writeSignerResponse(w, http.StatusNotFound, &RemoteSignerResponse{Message: "unknown key id: " + data.KeyID})
+			return
+		}
+
+		// Fire the signer
+		var err error
+		response := &RemoteSignerResponse{}
+		switch {
+		case strings.HasSuffix(req.URL.Path, RemoteSignerPubKeyPath):
+			response.PubKey, err = signer.PubKey(req.Context())
+		case strings.HasSuffix(req.URL.Path, RemoteSignerSignPath):
+			response.Signature, err = signer.SignMessage(req.Context(), data.Message)
+		default:
+			writeSignerResponse(w, http.StatusNotFound, &RemoteSignerResponse{Message: "not found"})
+			return
+		}
+		if err != nil {
+			writeSignerResponse(w, http.StatusInternalServerError, &RemoteSignerResponse{Message: err.Error()})
+			return
+		}
+		writeSignerResponse(w, http.StatusOK, response)
+	})
+}
+
+// writeSignerResponse will write the JSON response
+func writeSignerResponse(w http.ResponseWriter, status int, response *RemoteSignerResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,301 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorSigner is a Signer that always fails
+type errorSigner struct{}
+
+// PubKey will return an error
+func (e *errorSigner) PubKey(context.Context) (string, error) {
+	return "", errors.New("signer is down")
+}
+
+// SignMessage will return an error
+func (e *errorSigner) SignMessage(context.Context, string) (string, error) {
+	return "", errors.New("signer is down")
+}
+
+// newTestSigningService will return a fake signing service with the test private key (key id: test)
+func newTestSigningService(t *testing.T) *httptest.Server {
+	signer, err := NewPrivateKeySigner(testPrivateKey)
+	require.NoError(t, err)
+	server := httptest.NewServer(NewSignerHandler(map[string]Signer{"test": signer, "error": &errorSigner{}}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestNewPrivateKeySigner will test the method NewPrivateKeySigner()
+func TestNewPrivateKeySigner(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid key", func(t *testing.T) {
+		signer, err := NewPrivateKeySigner(testPrivateKey)
+		require.NoError(t, err)
+		pubKey, err := signer.PubKey(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, pubKey, PubKeyLength)
+
+		// Signature references the compressed key
+		signature, err := signer.SignMessage(context.Background(), testMessage)
+		require.NoError(t, err)
+		address, err := bitcoin.GetAddressFromPrivateKeyString(testPrivateKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, bitcoin.VerifyMessage(address, signature, testMessage))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		signer, err := NewPrivateKeySigner("invalid")
+		require.Error(t, err)
+		assert.Nil(t, signer)
+	})
+}
+
+// TestNewWIFSigner will test the method NewWIFSigner()
+func TestNewWIFSigner(t *testing.T) {
+	t.Parallel()
+
+	t.Run("same key as the private key signer", func(t *testing.T) {
+		wif, err := bitcoin.PrivateKeyToWifString(testPrivateKey)
+		require.NoError(t, err)
+		signer, err := NewWIFSigner(wif)
+		require.NoError(t, err)
+		keySigner, err := NewPrivateKeySigner(testPrivateKey)
+		require.NoError(t, err)
+
+		pubKey, err := signer.PubKey(context.Background())
+		require.NoError(t, err)
+		expected, err := keySigner.PubKey(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, expected, pubKey)
+
+		signature, err := signer.SignMessage(context.Background(), testMessage)
+		require.NoError(t, err)
+		address, err := bitcoin.GetAddressFromPrivateKeyString(testPrivateKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, bitcoin.VerifyMessage(address, signature, testMessage))
+	})
+
+	t.Run("invalid wif", func(t *testing.T) {
+		signer, err := NewWIFSigner("invalid")
+		require.Error(t, err)
+		assert.Nil(t, signer)
+	})
+}
+
+// TestNewRemoteSigner will test the method NewRemoteSigner()
+func TestNewRemoteSigner(t *testing.T) {
+	t.Parallel()
+
+	server := newTestSigningService(t)
+
+	t.Run("valid key", func(t *testing.T) {
+		signer, err := NewRemoteSigner(server.URL+"/", "test", nil)
+		require.NoError(t, err)
+		pubKey, err := signer.PubKey(context.Background())
+		require.NoError(t, err)
+		expected, err := bitcoin.PubKeyFromPrivateKeyString(testPrivateKey, true)
+		require.NoError(t, err)
+		assert.Equal(t, expected, pubKey)
+
+		signature, err := signer.SignMessage(context.Background(), testMessage)
+		require.NoError(t, err)
+		address, err := bitcoin.GetAddressFromPubKeyString(pubKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, bitcoin.VerifyMessage(address.AddressString, signature, testMessage))
+	})
+
+	t.Run("unknown key id", func(t *testing.T) {
+		signer, err := NewRemoteSigner(server.URL, "unknown", nil)
+		require.NoError(t, err)
+		_, err = signer.PubKey(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown key id")
+		_, err = signer.SignMessage(context.Background(), testMessage)
+		require.Error(t, err)
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		signer, err := NewRemoteSigner(server.URL, "error", nil)
+		require.NoError(t, err)
+		_, err = signer.SignMessage(context.Background(), testMessage)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signer is down")
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("not json"))
+		}))
+		defer invalid.Close()
+		signer, err := NewRemoteSigner(invalid.URL, "test", nil)
+		require.NoError(t, err)
+		_, err = signer.PubKey(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("{}"))
+		}))
+		defer empty.Close()
+		signer, err := NewRemoteSigner(empty.URL, "test", nil)
+		require.NoError(t, err)
+		_, err = signer.PubKey(context.Background())
+		require.Error(t, err)
+		_, err = signer.SignMessage(context.Background(), testMessage)
+		require.Error(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		signer, err := NewRemoteSigner(server.URL, "test", nil)
+		require.NoError(t, err)
+		_, err = signer.SignMessage(canceledContext(), testMessage)
+		require.Error(t, err)
+	})
+
+	t.Run("missing endpoint", func(t *testing.T) {
+		signer, err := NewRemoteSigner("", "test", nil)
+		require.Error(t, err)
+		assert.Nil(t, signer)
+	})
+}
+
+// TestNewSignerHandler will test the method NewSignerHandler()
+func TestNewSignerHandler(t *testing.T) {
+	t.Parallel()
+
+	server := newTestSigningService(t)
+
+	t.Run("method not allowed", func(t *testing.T) {
+		resp, err := http.Get(server.URL + RemoteSignerSignPath) //nolint:noctx // test request
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		resp, err := http.Post(server.URL+RemoteSignerSignPath, "application/json", strings.NewReader("{")) //nolint:noctx // test request
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/unknown", "application/json", strings.NewReader(`{"key_id":"test"}`)) //nolint:noctx // test request
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// TestSenderRequest_SignWithSigner will test the method SignWithSigner()
+func TestSenderRequest_SignWithSigner(t *testing.T) {
+	t.Parallel()
+
+	signer, err := NewPrivateKeySigner(testPrivateKey)
+	require.NoError(t, err)
+
+	t.Run("valid signature", func(t *testing.T) {
+		senderRequest := &SenderRequest{
+			Dt:           time.Now().UTC().Format(time.RFC3339),
+			SenderHandle: testAlias + "@" + testDomain,
+			Purpose:      testMessage,
+		}
+		signature, err := senderRequest.SignWithSigner(context.Background(), signer)
+		require.NoError(t, err)
+
+		// Verified with the compressed pubkey address (like the server)
+		address, err := bitcoin.GetAddressFromPrivateKeyString(testPrivateKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, senderRequest.Verify(address, signature))
+	})
+
+	t.Run("missing signer", func(t *testing.T) {
+		senderRequest := &SenderRequest{Dt: "dt", SenderHandle: testAlias + "@" + testDomain}
+		_, err := senderRequest.SignWithSigner(context.Background(), nil)
+		require.Error(t, err)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, err := (&SenderRequest{SenderHandle: testAlias + "@" + testDomain}).SignWithSigner(context.Background(), signer)
+		require.Error(t, err)
+		_, err = (&SenderRequest{Dt: "dt"}).SignWithSigner(context.Background(), signer)
+		require.Error(t, err)
+	})
+}
+
+// TestSignP2PMetaData will test the method SignP2PMetaData()
+func TestSignP2PMetaData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid signature", func(t *testing.T) {
+		signer, err := NewPrivateKeySigner(testPrivateKey)
+		require.NoError(t, err)
+		metaData := &P2PMetaData{Sender: testAlias + "@" + testDomain}
+		require.NoError(t, SignP2PMetaData(context.Background(), signer, metaData, testPrevTxID))
+
+		address, err := bitcoin.GetAddressFromPubKeyString(metaData.PubKey, true)
+		require.NoError(t, err)
+		assert.NoError(t, bitcoin.VerifyMessage(address.AddressString, metaData.Signature, testPrevTxID))
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		require.Error(t, SignP2PMetaData(context.Background(), &errorSigner{}, &P2PMetaData{}, testPrevTxID))
+	})
+
+	t.Run("missing signer or metadata", func(t *testing.T) {
+		signer, err := NewPrivateKeySigner(testPrivateKey)
+		require.NoError(t, err)
+		require.Error(t, SignP2PMetaData(context.Background(), nil, &P2PMetaData{}, testPrevTxID))
+		require.Error(t, SignP2PMetaData(context.Background(), signer, nil, testPrevTxID))
+	})
+
+	t.Run("separate metadata signer", func(t *testing.T) {
+		payment := newTestPayment(t, 10000)
+		payment.MetaDataSigner = &errorSigner{}
+		_, err := BuildP2PTransaction(context.Background(), newTestDestination(t, 1000), payment)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signer is down")
+	})
+}
+
+// ExampleNewRemoteSigner example using NewRemoteSigner()
+func ExampleNewRemoteSigner() {
+	// Fake signing service (use the real endpoint of your HSM/KMS signing service)
+	keySigner, _ := NewPrivateKeySigner(testPrivateKey)
+	server := httptest.NewServer(NewSignerHandler(map[string]Signer{"paymail-pki": keySigner}))
+	defer server.Close()
+
+	// Sign a sender request using the remote signer
+	signer, _ := NewRemoteSigner(server.URL, "paymail-pki", nil)
+	senderRequest := &SenderRequest{Dt: "2020-04-09T16:08:06.419Z", SenderHandle: testAlias + "@" + testDomain}
+	signature, err := senderRequest.SignWithSigner(context.Background(), signer)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("signed: %t", len(signature) > 0)
+	// Output:signed: true
+}
+
+// BenchmarkSenderRequest_SignWithSigner benchmarks the method SignWithSigner()
+func BenchmarkSenderRequest_SignWithSigner(b *testing.B) {
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	senderRequest := &SenderRequest{Dt: "2020-04-09T16:08:06.419Z", SenderHandle: testAlias + "@" + testDomain}
+	for i := 0; i < b.N; i++ {
+		_, _ = senderRequest.SignWithSigner(context.Background(), signer)
+	}
+}
@@ -2,8 +2,11 @@ package paymail
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net"
+	"sort"
 	"strings"
 )
 
@@ -25,10 +28,42 @@ func (c *Client) defaultResolver() net.Resolver {
 	}
 }
 
+// srvRandom returns a random number in [0, n) for the weighted selection of SRV records
+var srvRandom = rand.Intn //nolint:gosec // weighted selection does not need a secure random number
+
 // GetSRVRecord will get the SRV record for a given domain name
 //
+// # If multiple records are found, the first record (ordered per RFC 2782) is returned
+//
 // Specs: http://bsvalias.org/02-01-host-discovery.html
-func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SRV, err error) {
+func (c *Client) GetSRVRecord(service, protocol, domainName string) (*net.SRV, error) {
+	return c.GetSRVRecordContext(context.Background(), service, protocol, domainName)
+}
+
+// GetSRVRecordContext is the same as GetSRVRecord() but accepts a context
+// that is used for cancellation and deadlines on the DNS lookup
+func (c *Client) GetSRVRecordContext(ctx context.Context, service, protocol, domainName string) (srv *net.SRV, err error) {
+	var records []*net.SRV
+	if records, err = c.GetSRVRecordsContext(ctx, service, protocol, domainName); err != nil {
+		return
+	}
+	srv = records[0]
+	return
+}
+
+// GetSRVRecords will get all the SRV records for a given domain name, ordered by priority
+// and then weighted-random selection, so the records can be tried in order for failover
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc2782
+func (c *Client) GetSRVRecords(service, protocol, domainName string) ([]*net.SRV, error) {
+	return c.GetSRVRecordsContext(context.Background(), service, protocol, domainName)
+}
+
+// GetSRVRecordsContext is the same as GetSRVRecords() but accepts a context
+// that is used for cancellation and deadlines on the DNS lookup
+func (c *Client) GetSRVRecordsContext(ctx context.Context, service, protocol,
+	domainName string) (records []*net.SRV, err error) {
+
 	// Invalid parameters?
 	if len(service) == 0 { // Use the default from paymail specs
 		service = DefaultServiceName
@@ -47,41 +82,148 @@ func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SR
 	// The computed cname to check against
 	cnameCheck := fmt.Sprintf("_%s._%s.%s.", service, protocol, domainName)
 
+	// Check the cache (if enabled)
+	if c.options.srvTTL > 0 {
+		if data, found := c.options.cache.Get(ctx, srvCacheKeyPrefix+cnameCheck); found {
+			var cached []*net.SRV
+			if err = json.Unmarshal(data, &cached); err == nil && len(cached) > 0 {
+				records = orderSRVRecords(cached)
+				return
+			}
+			err = nil
+		}
+	}
+
 	// Lookup the SRV record
 	var cname string
-	var records []*net.SRV
 	if cname, records, err = c.resolver.LookupSRV(
-		context.Background(), service, protocol, domainName,
+		ctx, service, protocol, domainName,
 	); err != nil || len(records) == 0 {
+		// Do not fall back to the default record if the lookup was canceled or timed out
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+
 		// @rohenaz: Paymail spec says if SRV record doesn't exist, assume it is <domain>.<tld> and port of 443
 		err = nil          // Hack
 		cname = cnameCheck // Hack
-		records = append(records, &net.SRV{
+		records = []*net.SRV{{
 			Port:     DefaultPort,
 			Priority: DefaultPriority,
 			Target:   domainName,
 			Weight:   DefaultWeight,
-		})
+		}}
 	}
 
 	// Basic CNAME check (sanity check!)
 	if cname != cnameCheck {
-		err = fmt.Errorf(
-			"srv cname was invalid or not found using: %s and expected: %s",
-			cnameCheck, cname,
-		)
+		err = &ProviderError{
+			Err: ErrDNS,
+			Message: fmt.Sprintf(
+				"srv cname was invalid or not found using: %s and expected: %s",
+				cnameCheck, cname,
+			),
+			Step: StepSRV,
+			URL:  domainName,
+		}
+		records = nil
 		return
 	}
 
-	// Only return the first record (in case multiple are returned)
-	srv = records[0]
+	// Copy the records and remove any period on the end
+	found := make([]*net.SRV, 0, len(records))
+	for _, record := range records {
+		found = append(found, &net.SRV{
+			Port:     record.Port,
+			Priority: record.Priority,
+			Target:   strings.TrimSuffix(record.Target, "."),
+			Weight:   record.Weight,
+		})
+	}
 
-	// Remove any period on the end
-	srv.Target = strings.TrimSuffix(srv.Target, ".")
+	// A single record with a target of "." means the service is decidedly not available
+	if len(found) == 1 && len(found[0].Target) == 0 {
+		err = &ProviderError{
+			Err:     ErrDNS,
+			Message: fmt.Sprintf("srv record for %s shows the service is not available", domainName),
+			Step:    StepSRV,
+			URL:     domainName,
+		}
+		records = nil
+		return
+	}
+
+	// Cache the records (if enabled)
+	if c.options.srvTTL > 0 {
+		if data, jsonErr := json.Marshal(found); jsonErr == nil {
+			c.options.cache.Set(ctx, srvCacheKeyPrefix+cnameCheck, data, c.options.srvTTL)
+		}
+	}
+
+	// Order the records (priority & weight)
+	records = orderSRVRecords(found)
 
 	return
 }
 
+// orderSRVRecords will order the records by priority (lowest first) and then by
+// weighted-random selection within each priority, returning a new slice
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc2782 (see: "Weight")
+func orderSRVRecords(records []*net.SRV) []*net.SRV {
+
+	// Group by priority (lowest first), zero weight records go first in each group
+	sorted := make([]*net.SRV, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		return sorted[i].Weight == 0 && sorted[j].Weight != 0
+	})
+
+	// Weighted-random selection within each priority group
+	ordered := make([]*net.SRV, 0, len(sorted))
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].Priority == sorted[start].Priority {
+			end++
+		}
+		group := sorted[start:end]
+		for len(group) > 0 {
+			var sum int
+			for _, record := range group {
+				sum += int(record.Weight)
+			}
+			pick := srvRandom(sum + 1)
+			var running int
+			for i, record := range group {
+				if running += int(record.Weight); running >= pick {
+					ordered = append(ordered, record)
+					group = append(group[:i], group[i+1:]...)
+					break
+				}
+			}
+		}
+		start = end
+	}
+
+	return ordered
+}
+
+// InvalidateSRVRecord will remove the cached SRV record for a given domain name
+func (c *Client) InvalidateSRVRecord(ctx context.Context, service, protocol, domainName string) {
+	if len(service) == 0 {
+		service = DefaultServiceName
+	}
+	if len(protocol) == 0 {
+		protocol = DefaultProtocol
+	}
+	protocol = strings.TrimSpace(strings.ToLower(protocol))
+	c.options.cache.Delete(ctx, srvCacheKeyPrefix+fmt.Sprintf("_%s._%s.%s.", service, protocol, domainName))
+}
+
 // ValidateSRVRecord will check for a valid SRV record for paymail following specifications
 //
 // Specs: http://bsvalias.org/02-01-host-discovery.html
@@ -121,3 +263,40 @@ func (c *Client) ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, prio
 
 	return nil
 }
+
+// ValidateSRVRecords will check a set of SRV records (IE: from GetSRVRecords()) for paymail
+//
+// Every record must have a target that resolves and use the given port (default is 443).
+// Priority and weight are not checked, since they are used for failover between the records.
+//
+// Specs: http://bsvalias.org/02-01-host-discovery.html
+func (c *Client) ValidateSRVRecords(ctx context.Context, records []*net.SRV, port uint16) error {
+
+	// Check the parameters
+	if len(records) == 0 {
+		return fmt.Errorf("invalid parameter: srv records are missing")
+	}
+	if port <= 0 { // Use the default from paymail specs
+		port = uint16(DefaultPort)
+	}
+
+	// Check each record
+	for index, srv := range records {
+		if srv == nil {
+			return fmt.Errorf("srv record %d is nil", index)
+		} else if len(srv.Target) == 0 {
+			return fmt.Errorf("srv record %d target is invalid or empty", index)
+		} else if srv.Port != port {
+			return fmt.Errorf("srv record %d (%s) port %d does not match %d", index, srv.Target, srv.Port, port)
+		}
+
+		// Test resolving the target
+		if addresses, err := c.resolver.LookupHost(ctx, srv.Target); err != nil {
+			return fmt.Errorf("srv record %d (%s) failed to resolve: %w", index, srv.Target, err)
+		} else if len(addresses) == 0 {
+			return fmt.Errorf("srv target %s could not resolve a host", srv.Target)
+		}
+	}
+
+	return nil
+}
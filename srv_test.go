@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// testFailoverDomain is a domain with multiple SRV records (primary & backup)
+const testFailoverDomain = "failover.com"
+
 // TestClient_GetSRVRecord will test the method GetSRVRecord()
 func TestClient_GetSRVRecord(t *testing.T) {
 	// t.Parallel() (turned off - race condition)
@@ -83,6 +87,7 @@ func TestClient_GetSRVRecord(t *testing.T) {
 			{"all empty", "", "", ""},
 			{"missing domain", DefaultServiceName, DefaultProtocol, ""},
 			{"invalid cname", "invalid", DefaultProtocol, testDomain},
+			{"service not available", DefaultServiceName, DefaultProtocol, "unavailable.com"},
 		}
 		for _, test := range tests {
 			t.Run(test.name, func(t *testing.T) {
@@ -94,6 +99,137 @@ func TestClient_GetSRVRecord(t *testing.T) {
 	})
 }
 
+// TestClient_GetSRVRecordContext will test the method GetSRVRecordContext()
+func TestClient_GetSRVRecordContext(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestClient(t)
+
+	t.Run("valid - with context", func(t *testing.T) {
+		srv, err := client.GetSRVRecordContext(context.Background(), DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.NotNil(t, srv)
+		assert.Equal(t, "www."+testDomain, srv.Target)
+	})
+
+	t.Run("canceled context does not fall back to the default record", func(t *testing.T) {
+		srv, err := client.GetSRVRecordContext(canceledContext(), DefaultServiceName, DefaultProtocol, "unknown-domain.com")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, srv)
+	})
+}
+
+// TestClient_GetSRVRecords will test the method GetSRVRecords()
+func TestClient_GetSRVRecords(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestClient(t)
+
+	t.Run("ordered by priority", func(t *testing.T) {
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testFailoverDomain)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(records))
+		assert.Equal(t, "primary."+testFailoverDomain, records[0].Target)
+		assert.Equal(t, "backup."+testFailoverDomain, records[1].Target)
+	})
+
+	t.Run("single record", func(t *testing.T) {
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(records))
+		assert.Equal(t, "www."+testDomain, records[0].Target)
+	})
+
+	t.Run("first record is returned by GetSRVRecord", func(t *testing.T) {
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testFailoverDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "primary."+testFailoverDomain, srv.Target)
+	})
+
+	t.Run("cached records are ordered", func(t *testing.T) {
+		cachedClient := newTestClient(t, WithSRVCacheTTL(time.Minute))
+		for i := 0; i < 2; i++ {
+			records, err := cachedClient.GetSRVRecords(DefaultServiceName, DefaultProtocol, testFailoverDomain)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(records))
+			assert.Equal(t, "primary."+testFailoverDomain, records[0].Target)
+		}
+	})
+
+	t.Run("invalid domain", func(t *testing.T) {
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, "")
+		require.Error(t, err)
+		assert.Nil(t, records)
+	})
+
+	t.Run("service not available", func(t *testing.T) {
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, "unavailable.com")
+		require.Error(t, err)
+		assert.Nil(t, records)
+	})
+}
+
+// Test_orderSRVRecords will test the method orderSRVRecords()
+func Test_orderSRVRecords(t *testing.T) {
+	// t.Parallel() (turned off - overrides srvRandom)
+
+	records := []*net.SRV{
+		{Target: "c", Priority: 20, Weight: 0},
+		{Target: "a", Priority: 10, Weight: 90},
+		{Target: "b", Priority: 10, Weight: 10},
+		{Target: "z", Priority: 10, Weight: 0},
+	}
+
+	t.Run("zero weight first on the lowest pick", func(t *testing.T) {
+		defer restoreSRVRandom(func(int) int { return 0 })()
+		ordered := orderSRVRecords(records)
+		require.Equal(t, 4, len(ordered))
+		assert.Equal(t, []string{"z", "a", "b", "c"}, srvTargets(ordered))
+	})
+
+	t.Run("highest pick selects the last record", func(t *testing.T) {
+		defer restoreSRVRandom(func(n int) int { return n - 1 })()
+		ordered := orderSRVRecords(records)
+		assert.Equal(t, []string{"b", "a", "z", "c"}, srvTargets(ordered))
+	})
+
+	t.Run("weighted distribution", func(t *testing.T) {
+		var first int
+		for i := 0; i < 1000; i++ {
+			if orderSRVRecords(records[1:3])[0].Target == "a" {
+				first++
+			}
+		}
+		assert.Greater(t, first, 800)
+		assert.Less(t, first, 980)
+	})
+
+	t.Run("does not modify the records", func(t *testing.T) {
+		_ = orderSRVRecords(records)
+		assert.Equal(t, []string{"c", "a", "b", "z"}, srvTargets(records))
+	})
+
+	t.Run("empty records", func(t *testing.T) {
+		assert.Equal(t, 0, len(orderSRVRecords(nil)))
+	})
+}
+
+// restoreSRVRandom will set srvRandom and return a func to restore the original
+func restoreSRVRandom(random func(int) int) func() {
+	original := srvRandom
+	srvRandom = random
+	return func() { srvRandom = original }
+}
+
+// srvTargets will return the targets of the records
+func srvTargets(records []*net.SRV) (targets []string) {
+	for _, record := range records {
+		targets = append(targets, record.Target)
+	}
+	return
+}
+
 // ExampleClient_GetSRVRecord example using GetSRVRecord()
 //
 // See more examples in /examples/
@@ -309,3 +445,55 @@ func BenchmarkClient_ValidateSRVRecord(b *testing.B) {
 		)
 	}
 }
+
+// TestClient_ValidateSRVRecords will test the method ValidateSRVRecords()
+func TestClient_ValidateSRVRecords(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestClient(t)
+
+	t.Run("valid set", func(t *testing.T) {
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testFailoverDomain)
+		require.NoError(t, err)
+		require.NoError(t, client.ValidateSRVRecords(context.Background(), records, 0))
+	})
+
+	t.Run("invalid cases", func(t *testing.T) {
+		var tests = []struct {
+			name    string
+			records []*net.SRV
+			port    uint16
+		}{
+			{"no records", nil, DefaultPort},
+			{"nil record", []*net.SRV{{Target: testDomain, Port: DefaultPort}, nil}, DefaultPort},
+			{"missing target", []*net.SRV{{Target: testDomain, Port: DefaultPort}, {Port: DefaultPort}}, DefaultPort},
+			{"invalid port", []*net.SRV{{Target: testDomain, Port: DefaultPort}, {Target: testDomain, Port: 123}}, DefaultPort},
+			{"no host records", []*net.SRV{{Target: testDomain, Port: DefaultPort}, {Target: "norecords.com", Port: DefaultPort}}, DefaultPort},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				require.Error(t, client.ValidateSRVRecords(context.Background(), test.records, test.port))
+			})
+		}
+	})
+}
+
+// ExampleClient_GetSRVRecords example using GetSRVRecords()
+//
+// See more examples in /examples/
+func ExampleClient_GetSRVRecords() {
+	client := newTestClient(nil)
+	records, _ := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testFailoverDomain)
+	for _, record := range records {
+		fmt.Printf("%s:%d (priority %d) ", record.Target, record.Port, record.Priority)
+	}
+	// Output:primary.failover.com:443 (priority 10) backup.failover.com:443 (priority 20)
+}
+
+// BenchmarkClient_GetSRVRecords benchmarks the method GetSRVRecords()
+func BenchmarkClient_GetSRVRecords(b *testing.B) {
+	client := newTestClient(nil)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testFailoverDomain)
+	}
+}
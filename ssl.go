@@ -11,11 +11,23 @@ import (
 // CheckSSL will do a basic check on the host to see if there is a valid SSL cert
 //
 // All paymail requests should be via HTTPS and have a valid certificate
-func (c *Client) CheckSSL(host string) (valid bool, err error) {
+// Use CheckTLS() for a detailed report (custom port, chain, SANs, expiry & OCSP)
+func (c *Client) CheckSSL(host string) (bool, error) {
+	return c.CheckSSLContext(context.Background(), host)
+}
+
+// CheckSSLContext is the same as CheckSSL() but accepts a context
+// that is used for cancellation and deadlines on the DNS lookup and TLS dial
+func (c *Client) CheckSSLContext(ctx context.Context, host string) (valid bool, err error) {
 
 	// Lookup the host
 	var ips []net.IPAddr
-	if ips, err = c.resolver.LookupIPAddr(context.Background(), host); err != nil {
+	if ips, err = c.resolver.LookupIPAddr(ctx, host); err != nil {
+		providerErr := &ProviderError{Cause: err, Err: ErrDNS, Step: StepSSL, URL: host}
+		if ctx.Err() != nil {
+			providerErr.Err = classifyError(err)
+		}
+		err = providerErr
 		return
 	}
 
@@ -24,21 +36,29 @@ func (c *Client) CheckSSL(host string) (valid bool, err error) {
 		for _, ip := range ips {
 
 			// Set the dialer
-			dialer := net.Dialer{
-				Timeout:  c.options.sslTimeout,
-				Deadline: time.Now().Add(c.options.sslDeadline),
+			dialer := tls.Dialer{
+				NetDialer: &net.Dialer{
+					Timeout:  c.options.sslTimeout,
+					Deadline: time.Now().Add(c.options.sslDeadline),
+				},
+				Config: &tls.Config{ //nolint:gosec // no need to check for unhandled errors
+					ServerName: host,
+				},
 			}
 
 			// Set the connection
-			connection, dialErr := tls.DialWithDialer(
-				&dialer,
+			conn, dialErr := dialer.DialContext(
+				ctx,
 				DefaultProtocol,
 				fmt.Sprintf("[%s]:%d", ip.String(), DefaultPort),
-				&tls.Config{ //nolint:gosec // no need to check for unhandled errors
-					ServerName: host,
-				},
 			)
 			if dialErr != nil {
+				// Stop checking if the context was canceled or expired
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					err = ctxErr
+					return
+				}
+
 				// catch missing ipv6 connectivity
 				// if the ip is ipv6 and the resulting error is "no route to host", the record is skipped
 				// otherwise the check will switch to critical
@@ -56,6 +76,7 @@ func (c *Client) CheckSSL(host string) (valid bool, err error) {
 				*/
 				continue
 			}
+			connection := conn.(*tls.Conn) //nolint:forcetypeassert // tls.Dialer always returns a *tls.Conn
 
 			// remember the checked certs based on their Signature
 			checkedCerts := make(map[string]struct{})
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -53,6 +54,20 @@ func TestClient_CheckSSL(t *testing.T) {
 	})
 }
 
+// TestClient_CheckSSLContext will test the method CheckSSLContext()
+func TestClient_CheckSSLContext(t *testing.T) {
+	// t.Parallel() cannot use newTestClient() race condition
+
+	client := newTestClient(t)
+
+	t.Run("canceled context", func(t *testing.T) {
+		valid, err := client.CheckSSLContext(canceledContext(), "example.com")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, false, valid)
+	})
+}
+
 // ExampleClient_CheckSSL example using CheckSSL()
 //
 // See more examples in /examples/
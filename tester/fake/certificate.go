@@ -0,0 +1,75 @@
+package fake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+)
+
+// certificateValidity is how long the generated certificates are valid
+const certificateValidity = 365 * 24 * time.Hour
+
+// newCertificate will generate a CA and a leaf certificate (signed by the CA) for the hosts
+//
+// The leaf is also valid for 127.0.0.1 and ::1, the pool contains the CA
+func newCertificate(hosts ...string) (tls.Certificate, *x509.CertPool, error) {
+
+	// Generate the CA
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		NotAfter:              now.Add(certificateValidity),
+		NotBefore:             now.Add(-time.Hour),
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "go-paymail fake provider CA", Organization: []string{"go-paymail"}},
+	}
+	var caDER []byte
+	if caDER, err = x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	var ca *x509.Certificate
+	if ca, err = x509.ParseCertificate(caDER); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	// Generate the leaf
+	var leafKey *ecdsa.PrivateKey
+	if leafKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	leafTemplate := &x509.Certificate{
+		DNSNames:     hosts,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		NotAfter:     now.Add(certificateValidity),
+		NotBefore:    now.Add(-time.Hour),
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: hosts[0], Organization: []string{"go-paymail"}},
+	}
+	var leafDER []byte
+	if leafDER, err = x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	// Create the pool with the CA
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	return tls.Certificate{
+		Certificate: [][]byte{leafDER, caDER},
+		PrivateKey:  leafKey,
+	}, pool, nil
+}
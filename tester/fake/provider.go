@@ -0,0 +1,223 @@
+/*
+Package fake is an in-process paymail provider for testing
+
+The provider runs the real server package (routes, validation & capabilities) on an
+httptest.Server, backed by a memory.Provider seeded with aliases & keys.
+It is a separate package from tester, since it imports the paymail & server packages.
+*/
+package fake
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/interfaces"
+	"github.com/tonicpow/go-paymail/server"
+	"github.com/tonicpow/go-paymail/server/memory"
+	"github.com/tonicpow/go-paymail/tester"
+)
+
+// Provider is a fake paymail provider running on an httptest.Server
+type Provider struct {
+	Config  *server.Configuration // Configuration of the paymail server
+	Domain  string                // Paymail domain of the provider
+	Server  *httptest.Server      // The running server
+	Service *memory.Provider      // In-memory service provider
+
+	port    int            // Port of the server
+	rootCAs *x509.CertPool // Root CAs for the TLS certificate (nil if TLS is disabled)
+	tls     bool           // If the server is using TLS
+}
+
+// ProviderOps allow functional options to be supplied
+// that overwrite default options.
+type ProviderOps func(p *providerOptions)
+
+// providerOptions holds the options for the fake provider
+type providerOptions struct {
+	configOps []server.ConfigOps
+	paymails  []*memory.Paymail
+	tls       bool
+}
+
+// WithAlias will add a paymail (alias@domain) with a new HD key
+func WithAlias(alias, name, avatar string) ProviderOps {
+	return func(p *providerOptions) {
+		p.paymails = append(p.paymails, &memory.Paymail{Alias: alias, Avatar: avatar, Name: name})
+	}
+}
+
+// WithPaymail will add a paymail using the given HD key (xPriv),
+// a new HD key is generated if the xPriv is empty
+func WithPaymail(alias, name, avatar, xPriv string) ProviderOps {
+	return func(p *providerOptions) {
+		p.paymails = append(p.paymails, &memory.Paymail{Alias: alias, Avatar: avatar, Name: name, XPriv: xPriv})
+	}
+}
+
+// WithConfigOps will add server configuration options (IE: server.WithSenderValidation())
+//
+// Default is the P2P capabilities for the domain
+func WithConfigOps(ops ...server.ConfigOps) ProviderOps {
+	return func(p *providerOptions) {
+		p.configOps = append(p.configOps, ops...)
+	}
+}
+
+// WithTLS will run the server using TLS (required for the paymail client)
+//
+// The certificate is issued for the domain by a generated CA, see: RootCAs()
+func WithTLS() ProviderOps {
+	return func(p *providerOptions) {
+		p.tls = true
+	}
+}
+
+// NewProvider will start a fake paymail provider for the domain
+//
+// Close() should be called when done
+func NewProvider(domain string, opts ...ProviderOps) (*Provider, error) {
+
+	// Set the options
+	options := &providerOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Seed the service provider
+	p := &Provider{
+		Domain:  strings.ToLower(domain),
+		Service: memory.NewProvider(memory.WithDomains(domain)),
+		tls:     options.tls,
+	}
+	for _, record := range options.paymails {
+		if _, err := p.Service.AddPaymail(record.Alias, p.Domain, record.Name, record.Avatar, record.XPriv); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the configuration
+	var err error
+	if p.Config, err = server.NewConfig(p.Service, append([]server.ConfigOps{
+		server.WithDomain(p.Domain),
+		server.WithP2PCapabilities(),
+	}, options.configOps...)...); err != nil {
+		return nil, err
+	}
+
+	// Start the server
+	p.Server = httptest.NewUnstartedServer(server.Handlers(p.Config))
+	if p.tls {
+		var certificate tls.Certificate
+		if certificate, p.rootCAs, err = newCertificate(p.Domain); err != nil {
+			return nil, err
+		}
+		p.Server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+		p.Server.StartTLS()
+	} else {
+		p.Config.Prefix = "http://"
+		p.Server.Start()
+	}
+
+	// Set the port
+	serverURL, _ := url.Parse(p.Server.URL)
+	p.port, _ = strconv.Atoi(serverURL.Port())
+
+	return p, nil
+}
+
+// Close will shut down the server
+func (p *Provider) Close() {
+	p.Server.Close()
+}
+
+// Port will return the port of the server
+func (p *Provider) Port() int {
+	return p.port
+}
+
+// URL will return the base url of the server (IE: https://127.0.0.1:1234)
+func (p *Provider) URL() string {
+	return p.Server.URL
+}
+
+// RootCAs will return the pool with the CA of the TLS certificate (nil if TLS is disabled)
+func (p *Provider) RootCAs() *x509.CertPool {
+	return p.rootCAs
+}
+
+// AddAlias will add a paymail (alias@domain) to the running provider
+//
+// A new HD key is generated if the xPriv is empty
+func (p *Provider) AddAlias(alias, name, avatar, xPriv string) (*memory.Paymail, error) {
+	return p.Service.AddPaymail(alias, p.Domain, name, avatar, xPriv)
+}
+
+// Paymail will return the paymail (including the keys) for the alias, or nil if not found
+func (p *Provider) Paymail(alias string) *paymail.AddressInformation {
+	info, _ := p.Service.GetPaymailByAlias(context.Background(), alias, p.Domain, nil)
+	return info
+}
+
+// Transactions will return the transactions received by the provider
+func (p *Provider) Transactions() []*memory.Transaction {
+	return p.Service.Transactions()
+}
+
+// Resolver will return a resolver where the SRV record of the domain points to the server,
+// any other lookup is sent to the live resolver
+func (p *Provider) Resolver(liveResolver interfaces.DNSResolver) interfaces.DNSResolver {
+	return tester.NewCustomResolver(
+		liveResolver,
+		map[string][]string{
+			p.Domain: {"127.0.0.1"},
+		},
+		map[string][]*net.SRV{
+			paymail.DefaultServiceName + paymail.DefaultProtocol + p.Domain: {{
+				Port:     uint16(p.port),
+				Priority: paymail.DefaultPriority,
+				Target:   p.Domain + ".",
+				Weight:   paymail.DefaultWeight,
+			}},
+		},
+		map[string][]net.IPAddr{
+			p.Domain: {{IP: net.IPv4(127, 0, 0, 1)}},
+		},
+	)
+}
+
+// HTTPClient will return a Resty client that sends every request to the server
+// (trusting the TLS certificate), since the capabilities do not include the port
+func (p *Provider) HTTPClient() *resty.Client {
+	address := p.Server.Listener.Addr().String()
+	return resty.NewWithClient(&http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, address)
+			},
+			TLSClientConfig: &tls.Config{RootCAs: p.rootCAs, MinVersion: tls.VersionTLS12},
+		},
+	})
+}
+
+// NewClient will return a paymail client wired to the provider (requires WithTLS())
+//
+// The client uses HTTPClient(), Resolver() and trusts RootCAs() for CheckTLS()
+func (p *Provider) NewClient(opts ...paymail.ClientOps) (paymail.ClientInterface, error) {
+	client, err := paymail.NewClient(append([]paymail.ClientOps{paymail.WithTLSRootCAs(p.rootCAs)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	client.WithCustomHTTPClient(p.HTTPClient())
+	client.WithCustomResolver(p.Resolver(client.GetResolver()))
+	return client, nil
+}
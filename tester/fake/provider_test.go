@@ -0,0 +1,271 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bitcoin/v2"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail"
+	"github.com/tonicpow/go-paymail/server"
+)
+
+const (
+	testAlias  = "mrz"
+	testDomain = "test.com"
+)
+
+// newTestProvider will return a TLS provider with a single alias
+func newTestProvider(t *testing.T, opts ...ProviderOps) *Provider {
+	p, err := NewProvider(testDomain, append([]ProviderOps{
+		WithTLS(), WithAlias(testAlias, "MrZ", "https://github.com/mrz1836.png"),
+	}, opts...)...)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// TestNewProvider will test the method NewProvider()
+func TestNewProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tls provider", func(t *testing.T) {
+		p := newTestProvider(t)
+		assert.Equal(t, testDomain, p.Domain)
+		assert.Greater(t, p.Port(), 0)
+		assert.Contains(t, p.URL(), "https://")
+		assert.NotNil(t, p.RootCAs())
+
+		info := p.Paymail(testAlias)
+		require.NotNil(t, info)
+		assert.Equal(t, testDomain, info.Domain)
+		assert.Len(t, info.PubKey, paymail.PubKeyLength)
+		assert.NotEmpty(t, info.PrivateKey)
+		assert.Nil(t, p.Paymail("unknown"))
+	})
+
+	t.Run("plain http provider", func(t *testing.T) {
+		p, err := NewProvider(testDomain, WithAlias(testAlias, "MrZ", ""))
+		require.NoError(t, err)
+		defer p.Close()
+		assert.Contains(t, p.URL(), "http://")
+		assert.Nil(t, p.RootCAs())
+
+		req, err := http.NewRequest(http.MethodGet, p.URL()+"/.well-known/"+paymail.DefaultServiceName, nil)
+		require.NoError(t, err)
+		req.Host = testDomain
+		resp, err := p.HTTPClient().GetClient().Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("invalid xpriv", func(t *testing.T) {
+		p, err := NewProvider(testDomain, WithPaymail(testAlias, "MrZ", "", "invalid"))
+		require.Error(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		p, err := NewProvider(testDomain, WithConfigOps(server.WithCapabilities(&paymail.CapabilitiesPayload{})))
+		require.Error(t, err)
+		assert.Nil(t, p)
+	})
+}
+
+// TestProvider_EndToEnd will test the client against the provider
+func TestProvider_EndToEnd(t *testing.T) {
+	t.Parallel()
+
+	p := newTestProvider(t)
+	client, err := p.NewClient()
+	require.NoError(t, err)
+	info := p.Paymail(testAlias)
+	address := testAlias + "@" + testDomain
+
+	t.Run("pki", func(t *testing.T) {
+		result, err := client.Resolve(address, &paymail.ResolveRequest{Operation: paymail.ResolveOperationPKI})
+		require.NoError(t, err)
+		assert.Equal(t, uint16(p.Port()), result.SRV.Port)
+		assert.Equal(t, info.PubKey, result.PKI.PubKey)
+	})
+
+	t.Run("public profile", func(t *testing.T) {
+		result, err := client.Resolve(address, &paymail.ResolveRequest{Operation: paymail.ResolveOperationPublicProfile})
+		require.NoError(t, err)
+		assert.Equal(t, "MrZ", result.PublicProfile.Name)
+	})
+
+	t.Run("verify pubkey", func(t *testing.T) {
+		capabilities, err := client.GetCapabilities(testDomain, p.Port())
+		require.NoError(t, err)
+		verification, err := client.VerifyPubKey(
+			capabilities.GetString(paymail.BRFCVerifyPublicKeyOwner, ""), testAlias, testDomain, info.PubKey,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, true, verification.Match)
+	})
+
+	t.Run("payment destination and send transaction", func(t *testing.T) {
+		result, err := client.Resolve(address, &paymail.ResolveRequest{
+			Operation:      paymail.ResolveOperationPaymentDestination,
+			PaymentRequest: &paymail.PaymentRequest{Satoshis: 1000},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.PaymentDestination.Outputs, 1)
+		assert.Equal(t, uint64(1000), result.PaymentDestination.Outputs[0].Satoshis)
+		assert.NotEmpty(t, result.PaymentDestination.Reference)
+
+		// Build a transaction paying the output
+		tx := bt.NewTx()
+		require.NoError(t, tx.PayToAddress(result.PaymentDestination.Outputs[0].Address, 1000))
+
+		sent, err := client.SendP2PTransaction(
+			result.Capabilities.GetString(paymail.BRFCP2PTransactions, ""), testAlias, testDomain,
+			&paymail.P2PTransaction{
+				Hex:       tx.String(),
+				MetaData:  &paymail.P2PMetaData{Note: "test payment"},
+				Reference: result.PaymentDestination.Reference,
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, tx.TxID(), sent.TxID)
+
+		transactions := p.Transactions()
+		require.Len(t, transactions, 1)
+		assert.Equal(t, address, transactions[0].Address)
+		assert.Equal(t, result.PaymentDestination.Reference, transactions[0].Reference)
+	})
+
+	t.Run("send a p2p payment", func(t *testing.T) {
+		result, err := client.Resolve(address, &paymail.ResolveRequest{
+			Operation:      paymail.ResolveOperationPaymentDestination,
+			PaymentRequest: &paymail.PaymentRequest{Satoshis: 2000},
+		})
+		require.NoError(t, err)
+
+		// Fund the payment with a utxo of the sender's key
+		privateKey, err := bitcoin.CreatePrivateKeyString()
+		require.NoError(t, err)
+		signer, err := paymail.NewPrivateKeySigner(privateKey)
+		require.NoError(t, err)
+		senderAddress, err := bitcoin.GetAddressFromPrivateKeyString(privateKey, true)
+		require.NoError(t, err)
+		lockingScript, err := bscript.NewP2PKHFromAddress(senderAddress)
+		require.NoError(t, err)
+
+		sent, err := client.SendP2PPayment(
+			result.Capabilities.GetString(paymail.BRFCP2PTransactions, ""), testAlias, testDomain,
+			&result.PaymentDestination.PaymentDestinationPayload, &paymail.P2PPayment{
+				Sender: "satchmo@" + testDomain,
+				Signer: signer,
+				UTXOs: paymail.NewStaticUTXOSource(&bt.UTXO{
+					LockingScript: lockingScript, Satoshis: 5000, TxID: make([]byte, 32),
+				}),
+			},
+		)
+		require.NoError(t, err)
+
+		transactions := p.Transactions()
+		require.Len(t, transactions, 2)
+		assert.Equal(t, sent.TxID, transactions[1].TxID)
+		assert.Equal(t, "satchmo@"+testDomain, transactions[1].MetaData.Sender)
+	})
+
+	t.Run("resolve a payto uri", func(t *testing.T) {
+		payTo, err := paymail.NewPayToURI(address, 1500, "coffee")
+		require.NoError(t, err)
+		result, err := client.ResolvePayToURI(payTo.String())
+		require.NoError(t, err)
+		require.Len(t, result.PaymentDestination.Outputs, 1)
+		assert.Equal(t, uint64(1500), result.PaymentDestination.Outputs[0].Satoshis)
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		_, err := client.Resolve("unknown@"+testDomain, &paymail.ResolveRequest{Operation: paymail.ResolveOperationPKI})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, paymail.ErrPaymailNotFound)
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		report, err := client.Inspect(address, &paymail.InspectRequest{SkipDNSSEC: true})
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Count(paymail.InspectStatusFail), report.String())
+	})
+
+	t.Run("alias added while running", func(t *testing.T) {
+		added, err := p.AddAlias("satchmo", "Satchmo", "", "")
+		require.NoError(t, err)
+
+		result, err := client.Resolve("satchmo@"+testDomain, &paymail.ResolveRequest{Operation: paymail.ResolveOperationPKI})
+		require.NoError(t, err)
+		assert.Equal(t, added.PubKey, result.PKI.PubKey)
+	})
+}
+
+// TestProvider_ReceiverApprovals will test the receiver approvals against the provider
+func TestProvider_ReceiverApprovals(t *testing.T) {
+	t.Parallel()
+
+	p := newTestProvider(t, WithConfigOps(server.WithReceiverApprovals(true)))
+	client, err := p.NewClient()
+	require.NoError(t, err)
+	capabilities, err := client.GetCapabilities(testDomain, p.Port())
+	require.NoError(t, err)
+	approvalURL := capabilities.GetString(paymail.BRFCReceiverApprovals, "")
+	require.NotEmpty(t, approvalURL)
+	destinationURL := capabilities.GetString(paymail.BRFCP2PPaymentDestination, "")
+
+	// Submit the payment for approval
+	submitted, err := client.SubmitPaymentApproval(approvalURL, testAlias, testDomain, &paymail.SenderRequest{
+		Amount:       1000,
+		Dt:           time.Now().UTC().Format(time.RFC3339),
+		SenderHandle: "satchmo@" + testDomain,
+	})
+	require.NoError(t, err)
+	assert.True(t, submitted.IsPending())
+
+	// No destination until approved
+	_, err = client.GetP2PPaymentDestination(destinationURL, testAlias, testDomain,
+		&paymail.PaymentRequest{ApprovalID: submitted.ID, Satoshis: 1000})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, paymail.ErrPaymentNotApproved)
+
+	// The receiver approves, the sender polls & requests the destination
+	require.NoError(t, p.Service.ApprovePayment(submitted.ID))
+	approval, err := client.GetPaymentApproval(approvalURL, testAlias, testDomain, submitted.ID)
+	require.NoError(t, err)
+	assert.True(t, approval.IsApproved())
+
+	destination, err := client.GetP2PPaymentDestination(destinationURL, testAlias, testDomain,
+		&paymail.PaymentRequest{ApprovalID: approval.ID, Satoshis: 1000})
+	require.NoError(t, err)
+	assert.NotEmpty(t, destination.Reference)
+
+	// Unknown approval
+	_, err = client.GetPaymentApproval(approvalURL, testAlias, testDomain, "unknown")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, paymail.ErrApprovalNotFound)
+}
+
+// ExampleNewProvider example using NewProvider()
+func ExampleNewProvider() {
+	p, err := NewProvider(testDomain, WithTLS(), WithAlias(testAlias, "MrZ", ""))
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	defer p.Close()
+
+	// Use a client that is wired to the provider
+	client, _ := p.NewClient()
+	result, _ := client.Resolve(testAlias+"@"+testDomain, &paymail.ResolveRequest{Operation: paymail.ResolveOperationPKI})
+	fmt.Printf("pubkey matches: %t", result.PKI.PubKey == p.Paymail(testAlias).PubKey)
+	// Output:pubkey matches: true
+}
@@ -0,0 +1,171 @@
+package paymail
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimestampSkew is the allowed difference (before/after) between the "dt" and the current time
+//
+// Specs: http://bsvalias.org/04-02-sender-validation.html
+const DefaultTimestampSkew = 2 * time.Minute
+
+var (
+	// ErrTimestampFormat is when the timestamp is not an ISO-8601 date & time
+	ErrTimestampFormat = errors.New("timestamp is not a valid ISO-8601 date & time")
+
+	// ErrTimestampSkew is when the timestamp is too far in the past or the future
+	ErrTimestampSkew = errors.New("timestamp is outside the allowed skew")
+)
+
+// timestampLayouts are the ISO-8601 layouts with a zone (Z, +hh:mm, +hhmm or +hh)
+//
+// The fraction (IE: .419) is optional in all layouts
+var timestampLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02T15:04Z07:00",
+	"20060102T150405.999999999Z0700",
+	"20060102T150405.999999999Z07",
+}
+
+// timestampLocalLayouts are the ISO-8601 layouts without a zone
+var timestampLocalLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"20060102T150405.999999999",
+}
+
+// TimestampValidator validates the "dt" of the sender requests against a clock
+type TimestampValidator struct {
+	clock      func() time.Time // Returns the current time (default: time.Now)
+	futureSkew time.Duration    // How far the timestamp can be in the future
+	location   *time.Location   // Location for timestamps without a zone (default: UTC)
+	pastSkew   time.Duration    // How far the timestamp can be in the past
+}
+
+// TimestampOps allow functional options to be supplied that overwrite default validator options
+type TimestampOps func(v *TimestampValidator)
+
+// WithTimestampClock will overwrite the clock used for the current time (IE: a fixed time in tests)
+func WithTimestampClock(clock func() time.Time) TimestampOps {
+	return func(v *TimestampValidator) {
+		if clock != nil {
+			v.clock = clock
+		}
+	}
+}
+
+// WithTimestampSkew will overwrite the allowed skew in the past & the future
+func WithTimestampSkew(past, future time.Duration) TimestampOps {
+	return func(v *TimestampValidator) {
+		if past >= 0 {
+			v.pastSkew = past
+		}
+		if future >= 0 {
+			v.futureSkew = future
+		}
+	}
+}
+
+// WithTimestampLocation will overwrite the location used for timestamps without a zone
+func WithTimestampLocation(location *time.Location) TimestampOps {
+	return func(v *TimestampValidator) {
+		if location != nil {
+			v.location = location
+		}
+	}
+}
+
+// NewTimestampValidator will return a new validator (default: time.Now, ±2 minutes & UTC)
+func NewTimestampValidator(opts ...TimestampOps) *TimestampValidator {
+	v := &TimestampValidator{
+		clock:      time.Now,
+		futureSkew: DefaultTimestampSkew,
+		location:   time.UTC,
+		pastSkew:   DefaultTimestampSkew,
+	}
+
+	// Overwrite defaults with any set by user
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Now will return the current time from the clock of the validator
+func (v *TimestampValidator) Now() time.Time {
+	if v == nil {
+		v = defaultTimestampValidator
+	}
+	return v.clock()
+}
+
+// Skew will return the allowed skew in the past & the future
+func (v *TimestampValidator) Skew() (past, future time.Duration) {
+	if v == nil {
+		v = defaultTimestampValidator
+	}
+	return v.pastSkew, v.futureSkew
+}
+
+// Parse will parse the ISO-8601 timestamp, timestamps without a zone use the location of the validator
+func (v *TimestampValidator) Parse(timestamp string) (dt time.Time, err error) {
+	if v == nil {
+		v = defaultTimestampValidator
+	}
+	timestamp = strings.TrimSpace(timestamp)
+
+	// Try the layouts with a zone first
+	for _, layout := range timestampLayouts {
+		if dt, err = time.Parse(layout, timestamp); err == nil {
+			return
+		}
+	}
+
+	// Fall back on the layouts without a zone
+	for _, layout := range timestampLocalLayouts {
+		if dt, err = time.ParseInLocation(layout, timestamp, v.location); err == nil {
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: %s", ErrTimestampFormat, timestamp)
+	return
+}
+
+// Validate will test if the timestamp is valid and within the allowed skew of the clock
+//
+// A nil validator uses the defaults (time.Now, ±2 minutes & UTC)
+func (v *TimestampValidator) Validate(timestamp string) error {
+	if v == nil {
+		v = defaultTimestampValidator
+	}
+
+	// Parse the ISO-8601 timestamp
+	dt, err := v.Parse(timestamp)
+	if err != nil {
+		return err
+	}
+
+	// Timestamp cannot be too far in the past or the future
+	now := v.clock()
+	if dt.Before(now.Add(-v.pastSkew)) {
+		return fmt.Errorf("%w: %s is in the past", ErrTimestampSkew, timestamp)
+	} else if dt.After(now.Add(v.futureSkew)) {
+		return fmt.Errorf("%w: %s is in the future", ErrTimestampSkew, timestamp)
+	}
+
+	return nil
+}
+
+// defaultTimestampValidator is used by ValidateTimestamp() and ParseTimestamp()
+var defaultTimestampValidator = NewTimestampValidator()
+
+// ParseTimestamp will parse the ISO-8601 timestamp (timestamps without a zone are UTC)
+func ParseTimestamp(timestamp string) (time.Time, error) {
+	return defaultTimestampValidator.Parse(timestamp)
+}
@@ -0,0 +1,170 @@
+package paymail
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimestampNow is the fixed time used for the timestamp tests
+var testTimestampNow = time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC)
+
+// testTimestampClock will return the fixed time for the timestamp tests
+func testTimestampClock() time.Time {
+	return testTimestampNow
+}
+
+// TestTimestampValidator_Parse will test the method Parse()
+func TestTimestampValidator_Parse(t *testing.T) {
+	t.Parallel()
+
+	v := NewTimestampValidator()
+
+	var tests = []struct {
+		timestamp     string
+		expectedTime  time.Time
+		expectedError bool
+	}{
+		{"2020-04-09T16:08:06Z", testTimestampNow, false},
+		{"2020-04-09T16:08:06.419Z", testTimestampNow.Add(419 * time.Millisecond), false},
+		{"2020-04-09T16:08:06.419", testTimestampNow.Add(419 * time.Millisecond), false},
+		{"2020-04-09T16:08:06", testTimestampNow, false},
+		{"2020-04-09T18:08:06+02:00", testTimestampNow, false},
+		{"2020-04-09T18:08:06+0200", testTimestampNow, false},
+		{"2020-04-09T18:08:06+02", testTimestampNow, false},
+		{"2020-04-09T16:08Z", testTimestampNow.Add(-6 * time.Second), false},
+		{"20200409T160806Z", testTimestampNow, false},
+		{"20200409T160806", testTimestampNow, false},
+		{" 2020-04-09T16:08:06Z ", testTimestampNow, false},
+		{"", time.Time{}, true},
+		{"0", time.Time{}, true},
+		{"2020-04-09", time.Time{}, true},
+		{"2020-04-09 16:08:06", time.Time{}, true},
+		{"2020-04-09T16:08:06B", time.Time{}, true},
+		{"0001-01-01 00:00:00 +0000 UTC", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.timestamp, func(t *testing.T) {
+			dt, err := v.Parse(test.timestamp)
+			if test.expectedError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrTimestampFormat)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, test.expectedTime.Equal(dt), "expected %s but got %s", test.expectedTime, dt)
+		})
+	}
+
+	t.Run("custom location", func(t *testing.T) {
+		location := time.FixedZone("UTC+2", 2*60*60)
+		dt, err := NewTimestampValidator(WithTimestampLocation(location)).Parse("2020-04-09T18:08:06")
+		require.NoError(t, err)
+		assert.True(t, testTimestampNow.Equal(dt))
+	})
+}
+
+// TestTimestampValidator_Validate will test the method Validate()
+func TestTimestampValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default skew", func(t *testing.T) {
+		v := NewTimestampValidator(WithTimestampClock(testTimestampClock))
+
+		var tests = []struct {
+			timestamp     string
+			expectedError bool
+		}{
+			{"2020-04-09T16:08:06Z", false},
+			{"2020-04-09T16:08:06.419", false},
+			{"2020-04-09T16:06:06Z", false},
+			{"2020-04-09T16:10:06Z", false},
+			{"2020-04-09T16:06:05Z", true},
+			{"2020-04-09T16:10:07Z", true},
+			{"2020-04-09T18:08:06Z", true},
+			{"invalid", true},
+		}
+
+		for _, test := range tests {
+			if err := v.Validate(test.timestamp); err != nil && !test.expectedError {
+				t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.timestamp, err.Error())
+			} else if err == nil && test.expectedError {
+				t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.timestamp)
+			}
+		}
+	})
+
+	t.Run("custom skew", func(t *testing.T) {
+		v := NewTimestampValidator(
+			WithTimestampClock(testTimestampClock),
+			WithTimestampSkew(10*time.Minute, 30*time.Second),
+		)
+		past, future := v.Skew()
+		assert.Equal(t, 10*time.Minute, past)
+		assert.Equal(t, 30*time.Second, future)
+		assert.Equal(t, testTimestampNow, v.Now())
+
+		require.NoError(t, v.Validate("2020-04-09T15:59:06Z"))
+		err := v.Validate("2020-04-09T15:57:06Z")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTimestampSkew)
+		err = v.Validate("2020-04-09T16:09:06Z")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTimestampSkew)
+	})
+
+	t.Run("invalid options are ignored", func(t *testing.T) {
+		v := NewTimestampValidator(WithTimestampClock(nil), WithTimestampSkew(-1, -1), WithTimestampLocation(nil))
+		past, future := v.Skew()
+		assert.Equal(t, DefaultTimestampSkew, past)
+		assert.Equal(t, DefaultTimestampSkew, future)
+		require.NoError(t, v.Validate(time.Now().UTC().Format(time.RFC3339)))
+	})
+
+	t.Run("nil validator uses the defaults", func(t *testing.T) {
+		var v *TimestampValidator
+		require.NoError(t, v.Validate(time.Now().UTC().Format(time.RFC3339Nano)))
+		assert.Error(t, v.Validate("2020-04-09T16:08:06Z"))
+	})
+}
+
+// TestParseTimestamp will test the method ParseTimestamp()
+func TestParseTimestamp(t *testing.T) {
+	t.Parallel()
+
+	dt, err := ParseTimestamp("2020-04-09T16:08:06.419")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, dt.Location())
+	assert.True(t, testTimestampNow.Add(419*time.Millisecond).Equal(dt))
+
+	_, err = ParseTimestamp("invalid")
+	assert.ErrorIs(t, err, ErrTimestampFormat)
+}
+
+// ExampleTimestampValidator_Validate example using Validate()
+//
+// See more examples in /examples/
+func ExampleTimestampValidator_Validate() {
+	v := NewTimestampValidator(
+		WithTimestampClock(func() time.Time { return time.Date(2020, 4, 9, 16, 8, 6, 0, time.UTC) }),
+		WithTimestampSkew(5*time.Minute, time.Minute),
+	)
+	if err := v.Validate("2020-04-09T16:04:06.419"); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("timestamp is valid")
+	// Output:timestamp is valid
+}
+
+// BenchmarkTimestampValidator_Validate benchmarks the method Validate()
+func BenchmarkTimestampValidator_Validate(b *testing.B) {
+	v := NewTimestampValidator(WithTimestampClock(testTimestampClock))
+	for i := 0; i < b.N; i++ {
+		_ = v.Validate("2020-04-09T16:08:06.419Z")
+	}
+}
@@ -0,0 +1,211 @@
+package paymail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// TLS check statuses used in TLSResult.Status
+const (
+	TLSStatusCritical = "critical" // Certificate expires within the critical threshold
+	TLSStatusError    = "error"    // Connection, handshake or certificate verification failed
+	TLSStatusOK       = "ok"       // Certificate is valid
+	TLSStatusWarning  = "warning"  // Certificate expires within the warning threshold
+)
+
+// tlsVersions are the names of the TLS versions
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// TLSReport is the report returned from CheckTLS()
+type TLSReport struct {
+	CheckTime time.Time    `json:"check_time"`
+	Host      string       `json:"host"`
+	Port      int          `json:"port"`
+	Results   []*TLSResult `json:"results"` // Results for each IP address of the host
+	Valid     bool         `json:"valid"`   // Every IP connected and every handshake was valid
+}
+
+// TLSResult is the result of the TLS check for a single IP address
+type TLSResult struct {
+	Chain         []*TLSCertificate `json:"chain,omitempty"`        // Verified chain (or the presented certificates if not verified)
+	CipherSuite   string            `json:"cipher_suite,omitempty"` // Negotiated cipher suite
+	DaysToExpiry  int               `json:"days_to_expiry"`         // Days until the leaf certificate expires
+	Error         string            `json:"error,omitempty"`        // Connection or verification error
+	HostnameMatch bool              `json:"hostname_match"`         // If the leaf subject/SANs match the host
+	IP            string            `json:"ip"`                     // IP address that was checked
+	NotAfter      time.Time         `json:"not_after"`              // Expiry of the leaf certificate
+	OCSPStapled   bool              `json:"ocsp_stapled"`           // If an OCSP response was stapled
+	SANs          []string          `json:"sans,omitempty"`         // DNS names (and IPs) of the leaf certificate
+	Status        string            `json:"status"`                 // ok, warning, critical or error
+	Subject       string            `json:"subject,omitempty"`      // Subject of the leaf certificate
+	Verified      bool              `json:"verified"`               // If the chain was verified against the root pool
+	Version       string            `json:"version,omitempty"`      // Negotiated TLS version
+}
+
+// TLSCertificate is a certificate in the chain
+type TLSCertificate struct {
+	IsCA      bool      `json:"is_ca"`
+	Issuer    string    `json:"issuer"`
+	NotAfter  time.Time `json:"not_after"`
+	NotBefore time.Time `json:"not_before"`
+	Subject   string    `json:"subject"`
+}
+
+// CheckTLS will check the TLS certificate on every IP address of the host & port
+// (use the target & port from the SRV record) and return a detailed report
+//
+// The chain is verified against the system roots or the pool from WithTLSRootCAs(),
+// and the expiry is checked against the thresholds from WithTLSExpiryThresholds()
+func (c *Client) CheckTLS(host string, port int) (*TLSReport, error) {
+	return c.CheckTLSContext(context.Background(), host, port)
+}
+
+// CheckTLSContext is the same as CheckTLS() but accepts a context
+// that is used for cancellation and deadlines on the DNS lookup and TLS dials
+func (c *Client) CheckTLSContext(ctx context.Context, host string, port int) (report *TLSReport, err error) {
+
+	// Use the default port from the paymail specs
+	if port <= 0 {
+		port = DefaultPort
+	}
+
+	// Start the report
+	report = &TLSReport{
+		CheckTime: time.Now(),
+		Host:      host,
+		Port:      port,
+	}
+
+	// Lookup the host
+	var ips []net.IPAddr
+	if ips, err = c.resolver.LookupIPAddr(ctx, host); err != nil {
+		providerErr := &ProviderError{Cause: err, Err: ErrDNS, Step: StepSSL, URL: host}
+		if ctx.Err() != nil {
+			providerErr.Err = classifyError(err)
+		}
+		err = providerErr
+		return
+	} else if len(ips) == 0 {
+		err = &ProviderError{Err: ErrDNS, Message: "no ip addresses found for host: " + host, Step: StepSSL, URL: host}
+		return
+	}
+
+	// Check each ip address (any ip that fails to connect or verify makes the report invalid)
+	report.Valid = true
+	for _, ip := range ips {
+		result := c.checkTLSAddress(ctx, host, ip.String(), port)
+		report.Results = append(report.Results, result)
+		if result.Status != TLSStatusOK && result.Status != TLSStatusWarning {
+			report.Valid = false
+		}
+
+		// Stop checking if the context was canceled or expired
+		if err = ctx.Err(); err != nil {
+			report.Valid = false
+			return
+		}
+	}
+
+	return
+}
+
+// checkTLSAddress will check the TLS certificate on a single ip address
+func (c *Client) checkTLSAddress(ctx context.Context, host, ip string, port int) (result *TLSResult) {
+	result = &TLSResult{IP: ip, Status: TLSStatusError}
+
+	// Dial and complete the handshake (the chain is verified below, so it can be reported if invalid)
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{
+			Timeout:  c.options.sslTimeout,
+			Deadline: time.Now().Add(c.options.sslDeadline),
+		},
+		Config: &tls.Config{ //nolint:gosec // the chain is verified manually after the handshake
+			InsecureSkipVerify: true,
+			ServerName:         host,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, DefaultProtocol, net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	connection := conn.(*tls.Conn) //nolint:forcetypeassert // tls.Dialer always returns a *tls.Conn
+	state := connection.ConnectionState()
+	_ = connection.Close()
+
+	// Set the connection details
+	result.Version = tlsVersions[state.Version]
+	if len(result.Version) == 0 {
+		result.Version = fmt.Sprintf("0x%04x", state.Version)
+	}
+	result.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	result.OCSPStapled = len(state.OCSPResponse) > 0
+	if len(state.PeerCertificates) == 0 {
+		result.Error = "no certificates presented"
+		return
+	}
+
+	// Set the leaf details
+	leaf := state.PeerCertificates[0]
+	result.Subject = leaf.Subject.String()
+	result.SANs = append(result.SANs, leaf.DNSNames...)
+	for _, address := range leaf.IPAddresses {
+		result.SANs = append(result.SANs, address.String())
+	}
+	result.NotAfter = leaf.NotAfter
+	result.DaysToExpiry = int(time.Until(leaf.NotAfter).Hours() / 24)
+	result.HostnameMatch = leaf.VerifyHostname(host) == nil
+
+	// Verify the chain
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, verifyErr := leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Intermediates: intermediates,
+		Roots:         c.options.tlsRootCAs,
+	})
+	chain := state.PeerCertificates
+	if verifyErr == nil && len(chains) > 0 {
+		chain = chains[0]
+		result.Verified = true
+	}
+	for _, cert := range chain {
+		result.Chain = append(result.Chain, &TLSCertificate{
+			IsCA:      cert.IsCA,
+			Issuer:    cert.Issuer.String(),
+			NotAfter:  cert.NotAfter,
+			NotBefore: cert.NotBefore,
+			Subject:   cert.Subject.String(),
+		})
+	}
+	if verifyErr != nil {
+		result.Error = verifyErr.Error()
+		return
+	}
+
+	// Check the expiry thresholds
+	remaining := time.Until(leaf.NotAfter)
+	switch {
+	case remaining <= c.options.tlsCritical:
+		result.Status = TLSStatusCritical
+		result.Error = fmt.Sprintf("certificate expires in less than %s", c.options.tlsCritical)
+	case remaining <= c.options.tlsWarning:
+		result.Status = TLSStatusWarning
+	default:
+		result.Status = TLSStatusOK
+	}
+
+	return
+}
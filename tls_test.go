@@ -0,0 +1,232 @@
+package paymail
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonicpow/go-paymail/tester"
+)
+
+// newTLSTestServer will return a local TLS server (with a stapled OCSP response) and its port
+func newTLSTestServer(t testing.TB) (*httptest.Server, int) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.StartTLS()
+	server.TLS.Certificates[0].OCSPStaple = []byte("ocsp-response")
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return server, port
+}
+
+// newTLSTestClient will return a client that resolves the test hosts to the local TLS server
+func newTLSTestClient(t testing.TB, opts ...ClientOps) ClientInterface {
+	client, err := NewClient(opts...)
+	require.NoError(t, err)
+
+	local := []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}
+	client.WithCustomResolver(tester.NewCustomResolver(
+		client.GetResolver(), nil, nil,
+		map[string][]net.IPAddr{
+			"example.com":  local, // The httptest certificate is issued for example.com
+			"mismatch.com": local,
+		},
+	))
+	return client
+}
+
+// tlsTestRootCAs will return a pool with the httptest certificate
+func tlsTestRootCAs(server *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return pool
+}
+
+// TestClient_CheckTLS will test the method CheckTLS()
+func TestClient_CheckTLS(t *testing.T) {
+	t.Parallel()
+
+	server, port := newTLSTestServer(t)
+	defer server.Close()
+
+	t.Run("valid certificate", func(t *testing.T) {
+		client := newTLSTestClient(t, WithTLSRootCAs(tlsTestRootCAs(server)))
+
+		report, err := client.CheckTLS("example.com", port)
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		assert.Equal(t, true, report.Valid)
+		assert.Equal(t, "example.com", report.Host)
+		assert.Equal(t, port, report.Port)
+		assert.Equal(t, false, report.CheckTime.IsZero())
+		require.Len(t, report.Results, 1)
+
+		result := report.Results[0]
+		assert.Equal(t, TLSStatusOK, result.Status)
+		assert.Equal(t, "127.0.0.1", result.IP)
+		assert.Equal(t, "", result.Error)
+		assert.Equal(t, "TLS 1.3", result.Version)
+		assert.NotEmpty(t, result.CipherSuite)
+		assert.Equal(t, true, result.Verified)
+		assert.Equal(t, true, result.HostnameMatch)
+		assert.Equal(t, true, result.OCSPStapled)
+		assert.Contains(t, result.SANs, "example.com")
+		assert.Contains(t, result.SANs, "127.0.0.1")
+		assert.Contains(t, result.Subject, "Acme Co")
+		assert.Equal(t, server.Certificate().NotAfter, result.NotAfter)
+		assert.Greater(t, result.DaysToExpiry, 365)
+		require.Len(t, result.Chain, 1)
+		assert.Equal(t, result.Chain[0].Subject, result.Chain[0].Issuer)
+	})
+
+	t.Run("expiry warning", func(t *testing.T) {
+		client := newTLSTestClient(t,
+			WithTLSRootCAs(tlsTestRootCAs(server)),
+			WithTLSExpiryThresholds(100*365*24*time.Hour, 0),
+		)
+
+		report, err := client.CheckTLS("example.com", port)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, true, report.Valid)
+		assert.Equal(t, TLSStatusWarning, report.Results[0].Status)
+	})
+
+	t.Run("expiry critical", func(t *testing.T) {
+		client := newTLSTestClient(t,
+			WithTLSRootCAs(tlsTestRootCAs(server)),
+			WithTLSExpiryThresholds(100*365*24*time.Hour, 100*365*24*time.Hour),
+		)
+
+		report, err := client.CheckTLS("example.com", port)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, false, report.Valid)
+		assert.Equal(t, TLSStatusCritical, report.Results[0].Status)
+		assert.NotEmpty(t, report.Results[0].Error)
+	})
+
+	t.Run("unknown authority", func(t *testing.T) {
+		client := newTLSTestClient(t, WithTLSRootCAs(x509.NewCertPool()))
+
+		report, err := client.CheckTLS("example.com", port)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, false, report.Valid)
+
+		result := report.Results[0]
+		assert.Equal(t, TLSStatusError, result.Status)
+		assert.Equal(t, false, result.Verified)
+		assert.Equal(t, true, result.HostnameMatch)
+		assert.NotEmpty(t, result.Error)
+		assert.Len(t, result.Chain, 1)
+	})
+
+	t.Run("hostname mismatch", func(t *testing.T) {
+		client := newTLSTestClient(t, WithTLSRootCAs(tlsTestRootCAs(server)))
+
+		report, err := client.CheckTLS("mismatch.com", port)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, false, report.Valid)
+		assert.Equal(t, TLSStatusError, report.Results[0].Status)
+		assert.Equal(t, false, report.Results[0].HostnameMatch)
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		client := newTLSTestClient(t, WithTLSRootCAs(tlsTestRootCAs(server)))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		closedPort := listener.Addr().(*net.TCPAddr).Port
+		require.NoError(t, listener.Close())
+
+		report, err := client.CheckTLS("example.com", closedPort)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, false, report.Valid)
+		assert.Equal(t, TLSStatusError, report.Results[0].Status)
+		assert.Equal(t, "", report.Results[0].Version)
+		assert.NotEmpty(t, report.Results[0].Error)
+	})
+
+	t.Run("one ip address is unreachable", func(t *testing.T) {
+		client, err := NewClient(WithTLSRootCAs(tlsTestRootCAs(server)))
+		require.NoError(t, err)
+		client.WithCustomResolver(tester.NewCustomResolver(
+			client.GetResolver(), nil, nil,
+			map[string][]net.IPAddr{
+				"example.com": {{IP: net.ParseIP("127.0.0.1")}, {IP: net.ParseIP("127.0.0.2")}}, // Server only listens on 127.0.0.1
+			},
+		))
+
+		var report *TLSReport
+		report, err = client.CheckTLS("example.com", port)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 2)
+		assert.Equal(t, false, report.Valid)
+		assert.Equal(t, TLSStatusOK, report.Results[0].Status)
+		assert.Equal(t, TLSStatusError, report.Results[1].Status)
+		assert.Equal(t, "", report.Results[1].Version)
+		assert.NotEmpty(t, report.Results[1].Error)
+	})
+
+	t.Run("default port", func(t *testing.T) {
+		client := newTLSTestClient(t, WithSSLTimeout(time.Second))
+
+		report, err := client.CheckTLSContext(canceledContext(), "example.com", 0)
+		require.Error(t, err)
+		require.NotNil(t, report)
+		assert.Equal(t, DefaultPort, report.Port)
+		assert.Equal(t, false, report.Valid)
+	})
+}
+
+// TestClient_CheckTLSContext will test the method CheckTLSContext()
+func TestClient_CheckTLSContext(t *testing.T) {
+	// t.Parallel() cannot use newTestClient() race condition
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err := client.CheckTLSContext(canceledContext(), testDomain, DefaultPort)
+		require.Error(t, err)
+	})
+}
+
+// ExampleClient_CheckTLS example using CheckTLS()
+//
+// See more examples in /examples/
+func ExampleClient_CheckTLS() {
+	server, port := newTLSTestServer(&testing.T{})
+	defer server.Close()
+
+	client := newTLSTestClient(&testing.T{}, WithTLSRootCAs(tlsTestRootCAs(server)))
+
+	report, _ := client.CheckTLS("example.com", port)
+	fmt.Printf("valid: %t, status: %s, ocsp stapled: %t", report.Valid, report.Results[0].Status, report.Results[0].OCSPStapled)
+	// Output:valid: true, status: ok, ocsp stapled: true
+}
+
+// BenchmarkClient_CheckTLS benchmarks the method CheckTLS()
+func BenchmarkClient_CheckTLS(b *testing.B) {
+	server, port := newTLSTestServer(b)
+	defer server.Close()
+
+	client := newTLSTestClient(b, WithTLSRootCAs(tlsTestRootCAs(server)))
+	for i := 0; i < b.N; i++ {
+		_, _ = client.CheckTLS("example.com", port)
+	}
+}
@@ -1,12 +1,15 @@
 package paymail
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
-	"time"
 
 	"github.com/mrz1836/go-sanitize"
 	"github.com/mrz1836/go-validate"
+	"golang.org/x/net/idna"
 )
 
 // SanitisedPaymail contains elements of a sanitized paymail address.
@@ -34,53 +37,71 @@ func ValidateAndSanitisePaymail(paymail string, isBeta bool) (*SanitisedPaymail,
 
 // SanitizePaymail will take an input and return the sanitized version (alias@domain.tld)
 //
-// Alias is the first part of the address (alias @)
-// Domain is the lowercase sanitized version (domain.tld)
+// Alias is the first part of the address (alias @), unicode aliases are normalised (see: NormalizeAlias())
+// Domain is the lowercase sanitized version (domain.tld), IDN domains are converted to punycode
 // Address is the full sanitized paymail address (alias@domain.tld)
 func SanitizePaymail(paymailAddress string) (alias, domain, address string) {
 
-	// Sanitize the paymail address
-	address = sanitize.Email(paymailAddress, false)
-
 	// Split the email parts (alias @ domain)
-	parts := strings.Split(address, "@")
+	parts := strings.Split(strings.Replace(paymailAddress, "mailto:", "", -1), "@")
 
-	// Sanitize the domain name (force to lowercase, remove www.)
+	// Sanitize the domain name (force to lowercase, remove www., punycode)
 	if len(parts) > 1 {
-		domain, _ = sanitize.Domain(parts[1], false, true)
+		if isIDN(parts[1]) {
+			domain, _ = SanitizeDomain(parts[1])
+		} else {
+			domain, _ = sanitize.Domain(sanitize.Email(parts[1], false), false, true)
+		}
 	}
 
-	// Set the alias (lowercase, no spaces)
-	alias = strings.TrimSpace(strings.ToLower(parts[0]))
+	// Set the alias (lowercase, NFC, no spaces)
+	alias = sanitizeAlias(parts[0])
 
 	// Paymail address does not meet the basic requirement of an email address
 	// Since we don't return an error, we will return an empty result
-	if len(alias) == 0 || len(domain) == 0 {
-		address = ""
+	if len(alias) == 0 || len(domain) == 0 || IsConfusable(alias) {
+		return
 	}
 
+	address = alias + "@" + domain
 	return
 }
 
 // ValidatePaymail will do a basic validation on the paymail format (email address format)
 //
+// IDN paymail addresses are validated in their punycode form (unicode aliases must pass NormalizeAlias())
 // This will not check to see if the paymail address is active via the provider
 func ValidatePaymail(paymailAddress string) error {
 
+	// Convert the IDN parts (alias & domain)
+	asciiAddress, err := toASCIIPaymail(paymailAddress)
+	if err != nil {
+		return fmt.Errorf("paymail address failed format validation: %w", err)
+	}
+
 	// Validate the format for the paymail address (paymail addresses follow conventional email requirements)
-	if _, err := validate.IsValidEmail(paymailAddress, false); err != nil {
+	if _, err = validate.IsValidEmail(asciiAddress, false); err != nil {
 		return fmt.Errorf("paymail address failed format validation: %w", err)
 	}
 
 	return nil
 }
 
-// ValidateDomain will do a basic validation on the domain format
+// ValidateDomain will do a basic validation on the domain format (IDN domains are validated in punycode)
 //
 // This will not check to see if the domain is an active paymail provider
 // This will not check DNS records to make sure the domain is active
 func ValidateDomain(domain string) error {
 
+	// Convert the IDN domain
+	if isIDN(domain) {
+		asciiDomain, err := ToASCIIDomain(domain)
+		if err != nil {
+			return fmt.Errorf("domain name is invalid: %s: %w", domain, err)
+		}
+		domain = asciiDomain
+	}
+
 	// Check for a real domain (require at least one period)
 	if !strings.Contains(domain, ".") {
 		return fmt.Errorf("domain name is invalid: %s", domain)
@@ -91,43 +112,54 @@ func ValidateDomain(domain string) error {
 	return nil
 }
 
-// ConvertHandle will convert a $handle or 1handle to a paymail address
+// toASCIIPaymail will return the punycode form of the IDN paymail address (ASCII addresses are unchanged)
+func toASCIIPaymail(paymailAddress string) (string, error) {
+	at := strings.LastIndex(paymailAddress, "@")
+	if at < 0 || !isIDN(paymailAddress) {
+		return paymailAddress, nil
+	}
+	alias, domain := paymailAddress[:at], paymailAddress[at+1:]
+
+	// Unicode alias (validated with the alias policy)
+	if !isASCII(alias) {
+		normalized, err := NormalizeAlias(alias)
+		if err != nil {
+			return "", err
+		} else if alias, err = idna.Punycode.ToASCII(normalized); err != nil {
+			return "", err
+		}
+	}
+
+	// IDN domain
+	if isIDN(domain) {
+		var err error
+		if domain, err = ToASCIIDomain(domain); err != nil {
+			return "", err
+		}
+	}
+	return alias + "@" + domain, nil
+}
+
+// ConvertHandle will convert a $handle or 1handle to a paymail address using the DefaultHandleRegistry
 //
 // For HandCash: $handle = handle@handcash.io or handle@beta.handcash.io
 // For RelayX:   1handle = handle@relayx.io
+//
+// The handle is returned unchanged if it cannot be converted (remote lookups require Client.ConvertHandle())
 func ConvertHandle(handle string, isBeta bool) string {
-	if strings.HasPrefix(handle, "$") {
-		if isBeta {
-			return strings.ToLower(strings.Replace(handle, "$", "", -1)) + "@beta.handcash.io"
-		}
-		return strings.ToLower(strings.Replace(handle, "$", "", -1)) + "@handcash.io"
-	} else if strings.HasPrefix(handle, "1") && len(handle) < 25 && !strings.Contains(handle, "@") {
-		return strings.ToLower(strings.Replace(handle, "1", "", -1)) + "@relayx.io"
+	paymailAddress, err := DefaultHandleRegistry.Convert(context.Background(), nil, handle, isBeta)
+	if err != nil {
+		return handle
 	}
-	return handle
+	return paymailAddress
 }
 
 // ValidateTimestamp will test if the timestamp is valid
 //
 // This is used to validate the "dt" parameter in resolve_address.go
-// Allowing 3 minutes before/after for
+// Allowing 2 minutes before/after the current time (see: TimestampValidator)
 func ValidateTimestamp(timestamp string) error {
-
-	// Parse the time using the RFC3339 layout
-	dt, err := time.Parse(time.RFC3339, timestamp)
-	if err != nil {
-		return err
-	}
-
-	// Timestamp cannot be more than 2 minutes in the past
-	// Specs: http://bsvalias.org/04-02-sender-validation.html
-	if dt.Before(time.Now().UTC().Add(-2 * time.Minute)) {
-		return fmt.Errorf("timestamp: %s is in the past", timestamp)
-	} else if dt.After(time.Now().UTC().Add(2 * time.Minute)) {
-		return fmt.Errorf("timestamp: %s is in the future", timestamp)
-	}
-
-	return nil
+	return defaultTimestampValidator.Validate(timestamp)
 }
 
 // replaceAliasDomain will replace the alias and domain with the correct values
@@ -142,3 +174,44 @@ func replaceAliasDomain(urlString, alias, domain string) string {
 func replacePubKey(urlString, pubKey string) string {
 	return strings.Replace(urlString, "{pubkey}", pubKey, -1)
 }
+
+// requestAddress will return the paymail address of the alias & domain for the service requests
+// (validated & sanitized), so an invalid address never reaches the wire
+func requestAddress(alias, domain string) (Address, error) {
+	if len(alias) == 0 {
+		return Address{}, fmt.Errorf("missing alias: %w", ErrMissingAddress)
+	} else if len(domain) == 0 {
+		return Address{}, fmt.Errorf("missing domain: %w", ErrMissingAddress)
+	}
+	return NewAddress(alias, domain)
+}
+
+// validateServiceAddress will check the service url & the paymail address of the service requests
+func validateServiceAddress(serviceURL string, address Address) error {
+	if len(serviceURL) == 0 || !strings.Contains(serviceURL, "https://") {
+		return fmt.Errorf("invalid url: %s", serviceURL)
+	} else if address.IsZero() {
+		return ErrMissingAddress
+	}
+	return nil
+}
+
+// replaceAddress will replace the alias & domain of the service url with the address
+func replaceAddress(serviceURL string, address Address) string {
+	return replaceAliasDomain(serviceURL, address.Alias(), address.Domain())
+}
+
+// decodeServiceResponse will test the status code & decode the body of the service responses
+func decodeServiceResponse(step, reqURL string, resp StandardResponse, response interface{}) error {
+
+	// Test the status code
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotModified {
+		return newResponseError(step, reqURL, resp.StatusCode, resp.Body)
+	}
+
+	// Decode the body of the response
+	if err := json.Unmarshal(resp.Body, response); err != nil {
+		return newInvalidResponseError(step, reqURL, resp.StatusCode, err)
+	}
+	return nil
+}
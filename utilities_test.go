@@ -28,6 +28,11 @@ func TestSanitizePaymail(t *testing.T) {
 		{"test@domain", "test", "domain", "test@domain"},
 		{"domain.com", "domain.com", "", ""},
 		{"1337@Test.com", "1337", testDomain, "1337@" + testDomain},
+		{"Müller@Bücher.de", "müller", "xn--bcher-kva.de", "müller@xn--bcher-kva.de"},
+		{"mu\u0308ller@xn--bcher-kva.de", "müller", "xn--bcher-kva.de", "müller@xn--bcher-kva.de"},
+		{"test@www.bücher.de", "test", "xn--bcher-kva.de", "test@xn--bcher-kva.de"},
+		{"p\u0430ypal@" + testDomain, "p\u0430ypal", testDomain, ""},
+		{"test@p\u0430ypal.com", "test", "", ""},
 	}
 
 	for _, test := range tests {
@@ -74,6 +79,12 @@ func TestValidatePaymail(t *testing.T) {
 		{"test@.com", true},
 		{"test@.com..", true},
 		{"test@.com.", true},
+		{"müller@bücher.de", false},
+		{"Müller@xn--bcher-kva.de", false},
+		{"田中@例え.jp", false},
+		{"p\u0430ypal@domain.com", true},
+		{"test@p\u0430ypal.com", true},
+		{"mül ler@bücher.de", true},
 	}
 
 	for _, test := range tests {
@@ -120,6 +131,9 @@ func TestValidateDomain(t *testing.T) {
 		{"test@domain.com", true},
 		{"example..", true},
 		{"example.c", false},
+		{"bücher.de", false},
+		{"xn--bcher-kva.de", false},
+		{"p\u0430ypal.com", true},
 	}
 
 	for _, test := range tests {
@@ -168,6 +182,10 @@ func TestConvertHandle(t *testing.T) {
 		{"1handle", false, "handle@relayx.io"},
 		{"1337@" + testDomain, false, "1337@" + testDomain},
 		{"1337", false, "337@relayx.io"},
+		{"1abc1", false, "abc1@relayx.io"},
+		{"1a1b", false, "a1b@relayx.io"},
+		{"1101", false, "101@relayx.io"},
+		{"$a$b", false, "a$b@handcash.io"},
 		{"1PN7K19Jmj7QQCpUg37WHpSRUw5gKhJVRa", false, "1PN7K19Jmj7QQCpUg37WHpSRUw5gKhJVRa"},
 		{"$misterz", true, "misterz@beta.handcash.io"},
 	}
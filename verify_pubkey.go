@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -34,7 +35,27 @@ type VerificationPayload struct {
 // VerifyPubKey will try to match a handle and pubkey
 //
 // Specs: https://bsvalias.org/05-verify-public-key-owner.html
-func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error) {
+func (c *Client) VerifyPubKey(verifyURL, alias, domain string, pubKey string) (*VerificationResponse, error) {
+	return c.VerifyPubKeyContext(context.Background(), verifyURL, alias, domain, pubKey)
+}
+
+// VerifyPubKeyContext is the same as VerifyPubKey() but accepts a context
+// that is used for cancellation and deadlines on the HTTP request
+func (c *Client) VerifyPubKeyContext(ctx context.Context, verifyURL, alias, domain string,
+	pubKey string) (response *VerificationResponse, err error) {
+
+	// Validate & sanitize the paymail address
+	var address Address
+	if address, err = requestAddress(alias, domain); err != nil {
+		return
+	}
+	return c.VerifyPubKeyForAddress(ctx, verifyURL, address, pubKey)
+}
+
+// VerifyPubKeyForAddress is the same as VerifyPubKeyContext() but accepts a paymail address (see: ParseAddress()),
+// the zero address is rejected before the request
+func (c *Client) VerifyPubKeyForAddress(ctx context.Context, verifyURL string, address Address,
+	pubKey string) (response *VerificationResponse, err error) {
 
 	// Require a valid url
 	if len(verifyURL) == 0 || !strings.Contains(verifyURL, "https://") {
@@ -43,11 +64,8 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 	}
 
 	// Basic requirements for request
-	if len(alias) == 0 {
-		err = fmt.Errorf("missing alias")
-		return
-	} else if len(domain) == 0 {
-		err = fmt.Errorf("missing domain")
+	if address.IsZero() {
+		err = ErrMissingAddress
 		return
 	} else if len(pubKey) == 0 {
 		err = fmt.Errorf("missing pubKey")
@@ -56,11 +74,12 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/verifypubkey/{alias}@{domain.tld}/{pubkey}
-	reqURL := replacePubKey(replaceAliasDomain(verifyURL, alias, domain), pubKey)
+	reqURL := replacePubKey(replaceAddress(verifyURL, address), pubKey)
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
+		err = newRequestError(StepVerifyPubKey, reqURL, err)
 		return
 	}
 
@@ -69,36 +88,33 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newResponseError(StepVerifyPubKey, reqURL, response.StatusCode, resp.Body)
 		return
 	}
 
 	// Decode the body of the response
 	if err = json.Unmarshal(resp.Body, &response); err != nil {
+		err = newInvalidResponseError(StepVerifyPubKey, reqURL, response.StatusCode, err)
 		return
 	}
 
 	// Invalid version?
 	if len(response.BsvAlias) == 0 {
-		err = fmt.Errorf("missing bsvalias version")
+		err = newInvalidResponseError(StepVerifyPubKey, reqURL, response.StatusCode, fmt.Errorf("missing bsvalias version"))
 		return
 	}
 
 	// Check basic requirements (alias@domain.tld)
-	if response.Handle != alias+"@"+domain {
-		err = fmt.Errorf("verify response handle %s does not match paymail address: %s", response.Handle, alias+"@"+domain)
+	if response.Handle != address.String() {
+		err = newInvalidResponseError(StepVerifyPubKey, reqURL, response.StatusCode, fmt.Errorf("verify response handle %s does not match paymail address: %s", response.Handle, address.String()))
 		return
 	}
 
 	// Check the PubKey length
 	if len(response.PubKey) == 0 {
-		err = fmt.Errorf("pki response is missing a PubKey value")
+		err = newInvalidResponseError(StepVerifyPubKey, reqURL, response.StatusCode, fmt.Errorf("pki response is missing a PubKey value"))
 	} else if len(response.PubKey) != PubKeyLength {
-		err = fmt.Errorf("returned pubkey is not the required length of %d, got: %d", PubKeyLength, len(response.PubKey))
+		err = newInvalidResponseError(StepVerifyPubKey, reqURL, response.StatusCode, fmt.Errorf("returned pubkey is not the required length of %d, got: %d", PubKeyLength, len(response.PubKey)))
 	}
 
 	return
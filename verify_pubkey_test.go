@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -32,6 +33,20 @@ func TestClient_VerifyPubKey(t *testing.T) {
 		assert.Equal(t, true, verification.Match)
 	})
 
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockVerifyPubKey(http.StatusOK)
+
+		verification, err := client.VerifyPubKeyContext(
+			canceledContext(), testServerURL+"verifypubkey/{alias}@{domain.tld}/{pubkey}",
+			testAlias, testDomain, testPubKey,
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, verification)
+	})
+
 	t.Run("successful response - status not modified", func(t *testing.T) {
 		client := newTestClient(t)
 